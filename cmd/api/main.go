@@ -8,32 +8,52 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nats-io/nats.go"
 	"github.com/olexsmir/onasty/internal/config"
+	"github.com/olexsmir/onasty/internal/envelope"
 	"github.com/olexsmir/onasty/internal/events/mailermq"
 	"github.com/olexsmir/onasty/internal/hasher"
 	"github.com/olexsmir/onasty/internal/jwtutil"
 	"github.com/olexsmir/onasty/internal/logger"
 	"github.com/olexsmir/onasty/internal/metrics"
+	"github.com/olexsmir/onasty/internal/models"
 	"github.com/olexsmir/onasty/internal/oauth"
 	"github.com/olexsmir/onasty/internal/service/authsrv"
 	"github.com/olexsmir/onasty/internal/service/notesrv"
+	"github.com/olexsmir/onasty/internal/service/quotasrv"
 	"github.com/olexsmir/onasty/internal/service/usersrv"
+	"github.com/olexsmir/onasty/internal/service/webhooksrv"
+	"github.com/olexsmir/onasty/internal/sluggen"
+	"github.com/olexsmir/onasty/internal/store/blob"
+	"github.com/olexsmir/onasty/internal/store/blob/fsblob"
+	"github.com/olexsmir/onasty/internal/store/blob/s3blob"
+	"github.com/olexsmir/onasty/internal/store/psql/attachmentrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/changeemailrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
 	"github.com/olexsmir/onasty/internal/store/psql/passwordtokrepo"
+	"github.com/olexsmir/onasty/internal/store/psql/quotarepo"
 	"github.com/olexsmir/onasty/internal/store/psql/sessionrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/userepo"
 	"github.com/olexsmir/onasty/internal/store/psql/vertokrepo"
+	"github.com/olexsmir/onasty/internal/store/psql/webhookrepo"
 	"github.com/olexsmir/onasty/internal/store/psqlutil"
 	"github.com/olexsmir/onasty/internal/store/rdb"
+	"github.com/olexsmir/onasty/internal/store/rdb/noteattempts"
 	"github.com/olexsmir/onasty/internal/store/rdb/notecache"
+	"github.com/olexsmir/onasty/internal/store/rdb/notecodes"
+	"github.com/olexsmir/onasty/internal/store/rdb/notecounter"
+	"github.com/olexsmir/onasty/internal/store/rdb/notelinksends"
+	"github.com/olexsmir/onasty/internal/store/rdb/notereads"
 	"github.com/olexsmir/onasty/internal/store/rdb/usercache"
 	httptransport "github.com/olexsmir/onasty/internal/transport/http"
 	"github.com/olexsmir/onasty/internal/transport/http/httpserver"
 	"github.com/olexsmir/onasty/internal/transport/http/ratelimit"
+	"github.com/olexsmir/onasty/internal/worker/deadman"
+	"github.com/olexsmir/onasty/internal/worker/dispatcher"
+	"github.com/olexsmir/onasty/internal/worker/reaper"
 )
 
 func main() {
@@ -55,6 +75,11 @@ func run(ctx context.Context) error {
 		return err
 	}
 
+	// ips are stored hashed with the key, without it they could be brute forced back
+	if cfg.QuotaIPHashKey == "" {
+		return errQuotaIPHashKeyNotSet
+	}
+
 	// semi dev mode
 	if !cfg.AppEnv.IsDevMode() {
 		gin.SetMode(gin.ReleaseMode)
@@ -76,8 +101,36 @@ func run(ctx context.Context) error {
 		return err
 	}
 
-	userPasswordHasher := hasher.NewSHA256Hasher(cfg.PasswordSalt)
-	notePasswordHasher := hasher.NewSHA256Hasher(cfg.NotePasswordSalt)
+	userPasswordHasher, err := hasher.NewPasswordHasher(newPasswordHasherConfig(cfg, cfg.PasswordSalt))
+	if err != nil {
+		return err
+	}
+
+	notePasswordHasher, err := hasher.NewPasswordHasher(newPasswordHasherConfig(cfg, cfg.NotePasswordSalt))
+	if err != nil {
+		return err
+	}
+
+	slugPolicy, err := sluggen.NewPolicy(sluggen.Config{
+		Strategy:     sluggen.Strategy(cfg.SlugStrategy),
+		Base62Length: cfg.SlugBase62Length,
+		WordsCount:   cfg.SlugWordsCount,
+		MinEntropy:   float64(cfg.SlugMinEntropyBits),
+	})
+	if err != nil {
+		return err
+	}
+
+	noteEncryptor, err := envelope.NewFromKeyfile(cfg.NoteEncryptionKeyfile)
+	if err != nil {
+		return err
+	}
+
+	attachmentsStore, err := newAttachmentsStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
 	jwtTokenizer := jwtutil.NewJWTUtil(cfg.JwtSigningKey, cfg.JwtAccessTokenTTL)
 
 	googleOauth := oauth.NewGoogleProvider(
@@ -98,9 +151,76 @@ func run(ctx context.Context) error {
 	pwdtokrepo := passwordtokrepo.NewPasswordResetTokenRepo(psqlDB)
 	changeemailrepo := changeemailrepo.New(psqlDB)
 
+	webhookrepo := webhookrepo.New(psqlDB, noteEncryptor)
+	webhooksrv := webhooksrv.New(webhookrepo, webhooksrv.Config{
+		Timeout:              cfg.WebhooksTimeout,
+		MaxAttempts:          cfg.WebhooksMaxAttempts,
+		Backoff:              cfg.WebhooksBackoff,
+		DeliveryLogRetention: cfg.WebhooksDeliveryLogRetention,
+		AllowInternalAddrs:   cfg.WebhooksAllowInternalAddrs,
+	})
+
 	notecache := notecache.New(redisDB, cfg.CacheNoteTTL)
-	noterepo := noterepo.New(psqlDB)
-	notesrv := notesrv.New(noterepo, notePasswordHasher, notecache)
+	noteattempts := noteattempts.New(redisDB, cfg.NotePasswordLockout)
+	notecodes := notecodes.New(redisDB, cfg.NoteCodeTTL)
+	notereads := notereads.New(redisDB, cfg.NoteReadTokenTTL)
+	notelinksends := notelinksends.New(redisDB)
+	noterepo := noterepo.New(psqlDB, noteEncryptor)
+	attachmentrepo := attachmentrepo.New(psqlDB)
+
+	// attachments aren't re-encrypted, so keys they're encrypted with cannot be removed from the keyfile
+	attachmentKeyIDs, err := attachmentrepo.GetContentKeyIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := noteEncryptor.CheckKeys(ctx, attachmentKeyIDs); err != nil {
+		return fmt.Errorf("attachments are encrypted with the key that is not in the keyfile: %w", err)
+	}
+
+	quotarepo := quotarepo.New(psqlDB)
+	notecounter := notecounter.New(redisDB)
+	quotasrv := quotasrv.New(quotarepo, noterepo, notecounter, quotasrv.Config{
+		Anonymous: models.Quota{
+			Tier:            models.QuotaTierAnonymous,
+			MaxContentBytes: cfg.QuotaAnonymousMaxContentSizeKb * 1024,
+			NotesPerDay:     cfg.QuotaAnonymousNotesPerDay,
+			MaxActiveNotes:  cfg.QuotaAnonymousMaxActiveNotes,
+			MaxExpiresIn:    cfg.QuotaAnonymousMaxExpiresIn,
+		},
+		Registered: models.Quota{
+			Tier:            models.QuotaTierRegistered,
+			MaxContentBytes: cfg.QuotaRegisteredMaxContentSizeKb * 1024,
+			NotesPerDay:     cfg.QuotaRegisteredNotesPerDay,
+			MaxActiveNotes:  cfg.QuotaRegisteredMaxActiveNotes,
+			MaxExpiresIn:    cfg.QuotaRegisteredMaxExpiresIn,
+		},
+		IPHashKey: cfg.QuotaIPHashKey,
+	})
+
+	notesrv := notesrv.New(noterepo, notePasswordHasher, notecache, notesrv.Deps{
+		AttachmentRepo: attachmentrepo,
+		Blobs:          attachmentsStore,
+		BlobEnc:        noteEncryptor,
+		Slugs:          slugPolicy,
+		Attempts:       noteattempts,
+		Codes:          notecodes,
+		Reads:          notereads,
+		LinkSends:      notelinksends,
+		Mailer:         mailermq,
+		Webhooks:       webhooksrv,
+		Quotas:         quotasrv,
+	}, notesrv.Config{
+		Attachments: notesrv.AttachmentsConfig{
+			MaxSize:        int64(cfg.AttachmentMaxSizeKb) * 1024,
+			MaxCount:       cfg.AttachmentsMaxCount,
+			DownloadWindow: cfg.AttachmentDownloadWindow,
+		},
+		MaxPasswordAttempts: cfg.NotePasswordMaxAttempts,
+		MaxCodeAttempts:     cfg.NoteCodeMaxAttempts,
+		MaxLinkSendsPerHour: cfg.NoteLinkSendsPerHour,
+		AccessRequestTTL:    cfg.NoteAccessRequestTTL,
+	})
 
 	userepo := userepo.New(psqlDB)
 	usercache := usercache.New(redisDB, cfg.CacheUsersTTL)
@@ -110,6 +230,7 @@ func run(ctx context.Context) error {
 		pwdtokrepo,
 		changeemailrepo,
 		noterepo,
+		quotasrv,
 		userPasswordHasher,
 		mailermq,
 		cfg.VerificationTokenTTL,
@@ -147,6 +268,7 @@ func run(ctx context.Context) error {
 		authsrv,
 		usersrv,
 		notesrv,
+		webhooksrv,
 		cfg.AppEnv,
 		cfg.AppURL,
 		cfg.FrontendURL,
@@ -181,6 +303,44 @@ func run(ctx context.Context) error {
 		}()
 	}
 
+	// background workers
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	if cfg.ReaperEnabled {
+		reaper := reaper.New(psqlDB, notesrv, reaper.Config{
+			Interval:       cfg.ReaperInterval,
+			Retention:      cfg.ReaperRetention,
+			AuditRetention: cfg.ReaperAuditRetention,
+		})
+		workers.Go(func() {
+			slog.Info("starting reaper", "interval", cfg.ReaperInterval, "retention", cfg.ReaperRetention)
+			reaper.Run(workersCtx)
+		})
+	}
+
+	if cfg.DeadmanEnabled {
+		deadman := deadman.New(psqlDB, notesrv, deadman.Config{
+			Interval:     cfg.DeadmanInterval,
+			RemindBefore: cfg.DeadmanRemindBefore,
+		})
+		workers.Go(func() {
+			slog.Info("starting dead man's switch worker", "interval", cfg.DeadmanInterval)
+			deadman.Run(workersCtx)
+		})
+	}
+
+	if cfg.WebhooksDispatcherEnabled {
+		dispatcher := dispatcher.New(webhooksrv, dispatcher.Config{
+			Interval: cfg.WebhooksDispatchInterval,
+		})
+		workers.Go(func() {
+			slog.Info("starting webhook dispatcher", "interval", cfg.WebhooksDispatchInterval)
+			dispatcher.Run(workersCtx)
+		})
+	}
+
 	// graceful shutdown
 	quitCh := make(chan os.Signal, 1)
 	signal.Notify(quitCh, os.Interrupt)
@@ -190,6 +350,9 @@ func run(ctx context.Context) error {
 		return errors.Join(errors.New("failed to stop http server"), err)
 	}
 
+	stopWorkers()
+	workers.Wait()
+
 	if err := psqlDB.Close(); err != nil {
 		return errors.Join(errors.New("failed to close postgres connection"), err)
 	}
@@ -200,3 +363,43 @@ func run(ctx context.Context) error {
 
 	return nil
 }
+
+var (
+	errUnknownAttachmentsStorage = errors.New("unknown attachments storage")
+	errQuotaIPHashKeyNotSet      = errors.New("QUOTA_IP_HASH_KEY is not set")
+)
+
+//nolint:ireturn // the storage is selected by config
+func newAttachmentsStore(ctx context.Context, cfg *config.Config) (blob.Storer, error) {
+	switch cfg.AttachmentsStorage {
+	case "fs":
+		return fsblob.New(cfg.AttachmentsFSDir)
+	case "s3":
+		return s3blob.New(ctx, s3blob.Config{
+			Endpoint:  cfg.AttachmentsS3Endpoint,
+			Region:    cfg.AttachmentsS3Region,
+			Bucket:    cfg.AttachmentsS3Bucket,
+			AccessKey: cfg.AttachmentsS3AccessKey,
+			SecretKey: cfg.AttachmentsS3SecretKey,
+			UseSSL:    cfg.AttachmentsS3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownAttachmentsStorage, cfg.AttachmentsStorage)
+	}
+}
+
+//nolint:gosec // the params are small numbers set by the operator
+func newPasswordHasherConfig(cfg *config.Config, legacySalt string) hasher.PasswordConfig {
+	return hasher.PasswordConfig{
+		Algorithm: cfg.PasswordHasher,
+		Argon2id: hasher.Argon2idParams{
+			Memory:      uint32(cfg.PasswordArgon2idMemoryKb),
+			Iterations:  uint32(cfg.PasswordArgon2idIterations),
+			Parallelism: uint8(cfg.PasswordArgon2idParallelism),
+			SaltLength:  hasher.DefaultArgon2idParams.SaltLength,
+			KeyLength:   hasher.DefaultArgon2idParams.KeyLength,
+		},
+		BcryptCost: cfg.PasswordBcryptCost,
+		LegacySalt: legacySalt,
+	}
+}
@@ -0,0 +1,138 @@
+// quota overrides limits of the user's quota, limits that aren't set are inherited from the registered tier.
+//
+//	go run ./cmd/quota --email user@example.com --notes-per-day 1000 --max-expires-in 720h
+//	go run ./cmd/quota --email user@example.com --reset
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/config"
+	"github.com/olexsmir/onasty/internal/logger"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psql/quotarepo"
+	"github.com/olexsmir/onasty/internal/store/psql/userepo"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+)
+
+var (
+	errEmailNotSet    = errors.New("--email is not set")
+	errNoLimitsSet    = errors.New("no limits are set, use --reset to delete the override")
+	errLimitIsInvalid = errors.New("limits cannot be negative")
+)
+
+type options struct {
+	email string
+	reset bool
+
+	// nil limits are inherited from the tier
+	maxContentBytes *int
+	notesPerDay     *int
+	maxActiveNotes  *int
+	maxExpiresIn    *time.Duration
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.email, "email", "", "email of the user")
+	flag.BoolVar(&opts.reset, "reset", false, "delete the override, so the tier's limits apply again")
+	flag.Func("max-content-bytes", "max size of the note's content, 0 means unlimited", intFlag(&opts.maxContentBytes))
+	flag.Func("notes-per-day", "how many notes can be created per day, 0 means unlimited", intFlag(&opts.notesPerDay))
+	flag.Func("max-active-notes", "how many unread notes there can be, 0 means unlimited", intFlag(&opts.maxActiveNotes))
+	flag.Func("max-expires-in", "how far notes can expire, 0 means unlimited", durationFlag(&opts.maxExpiresIn))
+	flag.Parse()
+
+	if err := run(context.Background(), opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts options) error {
+	if opts.email == "" {
+		return errEmailNotSet
+	}
+
+	cfg := config.NewConfig()
+
+	if err := logger.SetDefault(cfg.LogLevel, cfg.LogFormat, cfg.LogShowLine); err != nil {
+		return err
+	}
+
+	psql, err := psqlutil.Connect(ctx, cfg.PostgresDSN)
+	if err != nil {
+		return err
+	}
+	defer psql.Close() //nolint:errcheck
+
+	user, err := userepo.New(psql).GetByEmail(ctx, opts.email)
+	if err != nil {
+		return err
+	}
+
+	quotarepo := quotarepo.New(psql)
+	if opts.reset {
+		if err := quotarepo.DeleteOverrideByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+
+		slog.Info("quota override deleted", "user_id", user.ID)
+		return nil
+	}
+
+	if opts.maxContentBytes == nil && opts.notesPerDay == nil &&
+		opts.maxActiveNotes == nil && opts.maxExpiresIn == nil {
+		return errNoLimitsSet
+	}
+
+	if err := quotarepo.SetOverride(ctx, models.QuotaOverride{
+		UserID:          user.ID,
+		MaxContentBytes: opts.maxContentBytes,
+		NotesPerDay:     opts.notesPerDay,
+		MaxActiveNotes:  opts.maxActiveNotes,
+		MaxExpiresIn:    opts.maxExpiresIn,
+	}); err != nil {
+		return err
+	}
+
+	slog.Info("quota override set", "user_id", user.ID)
+
+	return nil
+}
+
+func intFlag(dst **int) func(string) error {
+	return func(s string) error {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return errLimitIsInvalid
+		}
+
+		*dst = &v
+		return nil
+	}
+}
+
+func durationFlag(dst **time.Duration) func(string) error {
+	return func(s string) error {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return errLimitIsInvalid
+		}
+
+		*dst = &v
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+// reencrypt re-encrypts content of all notes, and webhooks' secrets with the current key
+// from NOTE_ENCRYPTION_KEYFILE. Note attachments aren't re-encrypted, old keys they're encrypted with are reported.
+// It's meant to be run after the key rotation, or after the encryption is enabled,
+// the api can keep serving requests while it's running.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/olexsmir/onasty/internal/config"
+	"github.com/olexsmir/onasty/internal/envelope"
+	"github.com/olexsmir/onasty/internal/logger"
+	"github.com/olexsmir/onasty/internal/store/psql/attachmentrepo"
+	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
+	"github.com/olexsmir/onasty/internal/store/psql/webhookrepo"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+)
+
+var errKeyfileNotSet = errors.New("NOTE_ENCRYPTION_KEYFILE is not set")
+
+func main() {
+	batchSize := flag.Int("batch-size", 100, "number of notes re-encrypted per batch")
+	flag.Parse()
+
+	if err := run(context.Background(), *batchSize); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, batchSize int) error {
+	cfg := config.NewConfig()
+
+	if err := logger.SetDefault(cfg.LogLevel, cfg.LogFormat, cfg.LogShowLine); err != nil {
+		return err
+	}
+
+	if cfg.NoteEncryptionKeyfile == "" {
+		return errKeyfileNotSet
+	}
+
+	noteEncryptor, err := envelope.NewFromKeyfile(cfg.NoteEncryptionKeyfile)
+	if err != nil {
+		return err
+	}
+
+	psql, err := psqlutil.Connect(ctx, cfg.PostgresDSN)
+	if err != nil {
+		return err
+	}
+	defer psql.Close() //nolint:errcheck
+
+	currentKeyID, err := noteEncryptor.CurrentKeyID(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("re-encrypting notes", "key_id", currentKeyID, "batch_size", batchSize)
+
+	reencrypted, err := noterepo.New(psql, noteEncryptor).ReencryptContent(ctx, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to re-encrypt notes(%d done): %w", reencrypted, err)
+	}
+
+	slog.Info("notes re-encrypted successfully", "count", reencrypted)
+
+	reencrypted, err = webhookrepo.New(psql, noteEncryptor).ReencryptSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to re-encrypt webhook secrets(%d done): %w", reencrypted, err)
+	}
+
+	slog.Info("webhook secrets re-encrypted successfully", "count", reencrypted)
+
+	// attachments aren't re-encrypted, so the old keys they're encrypted with should be kept
+	attachmentKeyIDs, err := attachmentrepo.New(psql).GetContentKeyIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldKeyIDs := slices.DeleteFunc(attachmentKeyIDs, func(id string) bool { return id == currentKeyID })
+	if len(oldKeyIDs) != 0 {
+		slog.Warn("attachments are still encrypted with old keys, keep them in the keyfile", "key_ids", oldKeyIDs)
+	}
+
+	return nil
+}
@@ -31,16 +31,15 @@ func run(ctx context.Context) error {
 		return err
 	}
 
-	userHasher := hasher.NewSHA256Hasher(cfg.PasswordSalt)
-	noteHasher := hasher.NewSHA256Hasher(cfg.NotePasswordSalt)
+	passwordHasher := hasher.NewArgon2idHasher(hasher.DefaultArgon2idParams)
 
-	if err := seedUsers(ctx, userHasher, psql); err != nil {
+	if err := seedUsers(ctx, passwordHasher, psql); err != nil {
 		return fmt.Errorf("failed to seed users: %w", err)
 	}
 
 	slog.Info("Users seeded successfully")
 
-	if err := seedNotes(ctx, noteHasher, psql); err != nil {
+	if err := seedNotes(ctx, passwordHasher, psql); err != nil {
 		return fmt.Errorf("failed to seed notes: %w", err)
 	}
 
@@ -78,6 +78,7 @@ func seedNotes(
 		values ($1, $2, $3, $4, $5)
 		on conflict (slug) do update set
 			content = excluded.content,
+			content_key_id = excluded.content_key_id,
 			keep_before_expiration = excluded.keep_before_expiration,
 			password = excluded.password,
 			expires_at = excluded.expires_at
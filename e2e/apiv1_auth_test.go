@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -30,12 +31,11 @@ func (e *AppTestSuite) TestAuthV1_SignUP() {
 	)
 
 	dbUser := e.getUserByEmail(email)
-	hashedPasswd, err := e.hasher.Hash(password)
-	e.require.NoError(err)
 
 	e.Equal(http.StatusCreated, httpResp.Code)
 	e.Equal(dbUser.Email, email)
-	e.Equal(dbUser.Password, hashedPasswd)
+	e.True(strings.HasPrefix(dbUser.Password, "$argon2id$"))
+	e.NoError(e.hasher.Compare(dbUser.Password, password))
 }
 
 func (e *AppTestSuite) TestAuthV1_SignUP_badrequest() {
@@ -198,6 +198,42 @@ func (e *AppTestSuite) TestAuthV1_SignIn() {
 	e.Equal(parsedToken.UserID, uid.String())
 }
 
+func (e *AppTestSuite) TestAuthV1_SignIn_rehashesLegacyPassword() {
+	email, password := e.randomEmail(), e.uuid()
+	uid := e.insertUser(email, password, true)
+
+	legacyHash, err := hasher.NewSHA256Hasher(e.getConfig().PasswordSalt).Hash(password)
+	e.require.NoError(err)
+
+	_, err = e.postgresDB.Exec(e.ctx, "update users set password = $1 where id = $2", legacyHash, uid)
+	e.require.NoError(err)
+
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/auth/signin",
+		e.jsonify(apiv1AuthSignInRequest{
+			Email:    email,
+			Password: password,
+		}),
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	dbUser := e.getUserByEmail(email)
+	e.True(strings.HasPrefix(dbUser.Password, "$argon2id$"))
+	e.NoError(e.hasher.Compare(dbUser.Password, password))
+
+	// the user can still sign in with the rehashed password
+	httpResp = e.httpRequest(
+		http.MethodPost,
+		"/api/v1/auth/signin",
+		e.jsonify(apiv1AuthSignInRequest{
+			Email:    email,
+			Password: password,
+		}),
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
 func (e *AppTestSuite) TestAuthV1_SignIn_wrong() {
 	email, unactivatedEmail, password := e.randomEmail(), e.randomEmail(), e.uuid()
 
@@ -0,0 +1,307 @@
+package e2e_test
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type apiv1InboxNoteResponse struct {
+	Slug          string    `json:"slug"`
+	SenderEmail   string    `json:"sender_email"`
+	HasPassword   bool      `json:"has_password"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	AvailableFrom time.Time `json:"available_from"`
+}
+
+func (e *AppTestSuite) TestInboxV1() {
+	senderEmail := e.randomEmail()
+	_, senderToks := e.createAndSingIn(senderEmail, e.uuid())
+
+	recipientEmail := e.randomEmail()
+	_, recipientToks := e.createAndSingIn(recipientEmail, e.uuid())
+
+	content := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    content,
+		InboxEmail: recipientEmail,
+	}, senderToks.AccessToken)
+
+	notes := e.listNotes(senderToks.AccessToken, url.Values{})
+	e.Require().Len(notes.Notes, 1)
+	e.Equal(recipientEmail, notes.Notes[0].InboxEmail)
+	e.True(notes.Notes[0].DeliveredAt.IsZero())
+
+	inbox := e.getInbox(recipientToks.AccessToken)
+	e.Require().Len(inbox, 1)
+	e.Equal(slug, inbox[0].Slug)
+	e.Equal(senderEmail, inbox[0].SenderEmail)
+	e.False(inbox[0].HasPassword)
+
+	notes = e.listNotes(senderToks.AccessToken, url.Values{})
+	e.False(notes.Notes[0].DeliveredAt.IsZero())
+	e.True(notes.Notes[0].ReadAt.IsZero())
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil, recipientToks.AccessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(content, body.Content)
+
+	// the note is burnt on read
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil, recipientToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	e.Empty(e.getInbox(recipientToks.AccessToken))
+
+	notes = e.listNotes(senderToks.AccessToken, url.Values{})
+	e.False(notes.Notes[0].ReadAt.IsZero())
+}
+
+func (e *AppTestSuite) TestInboxV1_onlyRecipient() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	_, recipientToks := e.createAndSingIn(recipientEmail, e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		InboxEmail: recipientEmail,
+	}, senderToks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil, otherToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil, senderToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil)
+	e.Equal(http.StatusUnauthorized, httpResp.Code)
+
+	e.Empty(e.getInbox(otherToks.AccessToken))
+
+	// the note is still there for the recipient
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil, recipientToks.AccessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestInboxV1_metadataOnlyRecipient() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	_, recipientToks := e.createAndSingIn(recipientEmail, e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		InboxEmail: recipientEmail,
+	}, senderToks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil, otherToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil, recipientToks.AccessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestInboxV1_cannotRequireApproval() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	_, recipientToks := e.createAndSingIn(recipientEmail, e.uuid())
+
+	// inbox notes are read without an access token, so they cannot require approval
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          e.uuid(),
+		RequiresApproval: true,
+		InboxEmail:       recipientEmail,
+	}), senderToks.AccessToken)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteApprovalWithInbox.Error(), body.Message)
+
+	e.Empty(e.getInbox(recipientToks.AccessToken))
+}
+
+func (e *AppTestSuite) TestInboxV1_recipientDeleted() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	recipientID, _ := e.createAndSingIn(recipientEmail, e.uuid())
+
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		InboxEmail: recipientEmail,
+	}, senderToks.AccessToken)
+
+	e.deleteUser(recipientID)
+
+	// the sender still sees the note, but no one can read it
+	notes := e.listNotes(senderToks.AccessToken, url.Values{})
+	e.Require().Len(notes.Notes, 1)
+	e.Equal(slug, notes.Notes[0].Slug)
+	e.True(notes.Notes[0].InboxRecipientDeleted)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestInboxV1_withPassword() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	_, recipientToks := e.createAndSingIn(recipientEmail, e.uuid())
+
+	content := e.uuid()
+	password := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    content,
+		Password:   password,
+		InboxEmail: recipientEmail,
+	}, senderToks.AccessToken)
+
+	inbox := e.getInbox(recipientToks.AccessToken)
+	e.Require().Len(inbox, 1)
+	e.True(inbox[0].HasPassword)
+
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: password}),
+	)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(
+		http.MethodPost,
+		"/api/v1/inbox/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: password}),
+		recipientToks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(content, body.Content)
+}
+
+func (e *AppTestSuite) TestInboxV1_expired() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	_, recipientToks := e.createAndSingIn(recipientEmail, e.uuid())
+
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		InboxEmail: recipientEmail,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}, senderToks.AccessToken)
+
+	e.expireNote(slug)
+
+	e.Empty(e.getInbox(recipientToks.AccessToken))
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/inbox/"+slug, nil, recipientToks.AccessToken)
+	e.Equal(http.StatusGone, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestInboxV1_Create_invalid() {
+	_, senderToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	recipientEmail := e.randomEmail()
+	e.createAndSingIn(recipientEmail, e.uuid())
+
+	notActivatedEmail := e.randomEmail()
+	e.insertUser(notActivatedEmail, e.uuid(), false)
+
+	tests := []struct {
+		name  string
+		inp   apiv1NoteCreateRequest
+		token []string
+		err   error
+	}{
+		{
+			name: "unknown email",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				InboxEmail: e.randomEmail(),
+			},
+			token: []string{senderToks.AccessToken},
+			err:   models.ErrNoteInboxRecipientNotFound,
+		},
+		{
+			name: "not activated user",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				InboxEmail: notActivatedEmail,
+			},
+			token: []string{senderToks.AccessToken},
+			err:   models.ErrNoteInboxRecipientNotFound,
+		},
+		{
+			name: "anonymous sender",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				InboxEmail: recipientEmail,
+			},
+			token: nil,
+			err:   models.ErrNoteInboxWithoutAuthor,
+		},
+		{
+			name: "with recipients",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				InboxEmail: recipientEmail,
+				Recipients: 2,
+			},
+			token: []string{senderToks.AccessToken},
+			err:   models.ErrNoteInboxWithRecipients,
+		},
+		{
+			name: "with dead man's switch",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				InboxEmail: recipientEmail,
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			token: []string{senderToks.AccessToken},
+			err:   models.ErrNoteInboxWithSwitch,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp), tt.token...)
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+func (e *AppTestSuite) getInbox(accessToken string) []apiv1InboxNoteResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/inbox", nil, accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body []apiv1InboxNoteResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
@@ -0,0 +1,390 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type (
+	apiv1NoteAccessRequestRequest struct {
+		Reason string `json:"reason"`
+	}
+	apiv1NoteAccessRequestCreatedResponse struct {
+		ID        string    `json:"id"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	apiv1NoteAccessRequestResponse struct {
+		ID        string    `json:"id"`
+		Reason    string    `json:"reason"`
+		Status    string    `json:"status"`
+		IP        string    `json:"ip"`
+		UserAgent string    `json:"user_agent"`
+		CreatedAt time.Time `json:"created_at"`
+		ExpiresAt time.Time `json:"expires_at"`
+		DecidedAt time.Time `json:"decided_at"`
+		UsedAt    time.Time `json:"used_at"`
+	}
+)
+
+func (e *AppTestSuite) TestNoteV1_Approval() {
+	authorEmail := e.randomEmail()
+	_, toks := e.createAndSingIn(authorEmail, e.uuid())
+
+	content := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          content,
+		RequiresApproval: true,
+	}, toks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var meta apiv1NoteMetadataResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &meta)
+	e.True(meta.ApprovalRequired)
+
+	// the link alone isn't enough to read the note
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusForbidden, httpResp.Code)
+
+	var errBody errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &errBody)
+	e.Equal(models.ErrNoteApprovalRequired.Error(), errBody.Message)
+
+	reason := "rotating the production database credentials"
+	id, cookie := e.requestNoteAccess(slug, reason)
+	e.Equal("note_access_request:"+slug, mockMailStore[authorEmail])
+
+	// not approved yet
+	e.Equal(http.StatusForbidden, e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie).Code)
+
+	requests := e.getNoteAccessRequests(toks.AccessToken, slug)
+	e.Require().Len(requests, 1)
+	e.Equal(id, requests[0].ID)
+	e.Equal(reason, requests[0].Reason)
+	e.Equal(string(models.NoteAccessRequestPending), requests[0].Status)
+	e.Equal(testReaderNetwork, requests[0].IP)
+	e.Equal(testReaderUserAgent, requests[0].UserAgent)
+
+	httpResp = e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve")
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	// only the reader who has made the request can read the note
+	e.Equal(http.StatusForbidden, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+
+	httpResp = e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(content, body.Content)
+
+	httpResp = e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	requests = e.getNoteAccessRequests(toks.AccessToken, slug)
+	e.Require().Len(requests, 1)
+	e.Equal(string(models.NoteAccessRequestUsed), requests[0].Status)
+	e.False(requests[0].DecidedAt.IsZero())
+	e.False(requests[0].UsedAt.IsZero())
+
+	events := e.getNoteAccessEvents(toks.AccessToken, slug)
+	e.Require().Len(events, 4)
+	e.Equal(string(models.NoteAccessRead), events[0].Event)
+	e.Equal(string(models.NoteAccessApproved), events[1].Event)
+	e.Equal(string(models.NoteAccessRequested), events[2].Event)
+	e.Equal(string(models.NoteAccessMetadata), events[3].Event)
+	e.Equal(testReaderNetwork, events[1].IP)
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_withPassword() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	content := e.uuid()
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          content,
+		Password:         passwd,
+		RequiresApproval: true,
+	}, toks.AccessToken)
+
+	id, cookie := e.requestNoteAccess(slug, "deploy")
+	e.Equal(http.StatusNoContent, e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve").Code)
+
+	// the approval doesn't replace the password
+	httpResp := e.noteAccessRequest(http.MethodPost, "/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: e.uuid()}), cookie)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.noteAccessRequest(http.MethodPost, "/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: passwd}), cookie)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(content, body.Content)
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_eachReadApproved() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:              e.uuid(),
+		KeepBeforeExpiration: true,
+		ExpiresAt:            time.Now().Add(time.Hour),
+		RequiresApproval:     true,
+	}, toks.AccessToken)
+
+	id, cookie := e.requestNoteAccess(slug, "deploy")
+	e.Equal(http.StatusNoContent, e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve").Code)
+
+	e.Equal(http.StatusOK, e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie).Code)
+
+	// the note is kept, but the approved request is used
+	httpResp := e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie)
+	e.Equal(http.StatusForbidden, httpResp.Code)
+
+	id, cookie = e.requestNoteAccess(slug, "deploy again")
+	e.Equal(http.StatusNoContent, e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve").Code)
+	e.Equal(http.StatusOK, e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_denied() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          e.uuid(),
+		RequiresApproval: true,
+	}, toks.AccessToken)
+
+	id, cookie := e.requestNoteAccess(slug, "trust me")
+	e.Equal(http.StatusNoContent, e.decideNoteAccessRequest(toks.AccessToken, slug, id, "deny").Code)
+
+	e.Equal(http.StatusForbidden, e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie).Code)
+
+	// the decision is final
+	httpResp := e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve")
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	var errBody errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &errBody)
+	e.Equal(models.ErrNoteAccessRequestNotPending.Error(), errBody.Message)
+
+	requests := e.getNoteAccessRequests(toks.AccessToken, slug)
+	e.Require().Len(requests, 1)
+	e.Equal(string(models.NoteAccessRequestDenied), requests[0].Status)
+
+	events := e.getNoteAccessEvents(toks.AccessToken, slug)
+	e.Require().Len(events, 2)
+	e.Equal(string(models.NoteAccessDenied), events[0].Event)
+	e.Equal(string(models.NoteAccessRequested), events[1].Event)
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_expired() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          e.uuid(),
+		RequiresApproval: true,
+	}, toks.AccessToken)
+
+	id, _ := e.requestNoteAccess(slug, "deploy")
+	e.expireNoteAccessRequest(id)
+
+	requests := e.getNoteAccessRequests(toks.AccessToken, slug)
+	e.Require().Len(requests, 1)
+	e.Equal(string(models.NoteAccessRequestExpired), requests[0].Status)
+
+	httpResp := e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve")
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	// approved requests expire as well, if they aren't used in time
+	id, cookie := e.requestNoteAccess(slug, "deploy")
+	e.Equal(http.StatusNoContent, e.decideNoteAccessRequest(toks.AccessToken, slug, id, "approve").Code)
+	e.expireNoteAccessRequest(id)
+
+	e.Equal(http.StatusForbidden, e.noteAccessRequest(http.MethodGet, "/api/v1/note/"+slug, nil, cookie).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_notAuthor() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          e.uuid(),
+		RequiresApproval: true,
+	}, toks.AccessToken)
+
+	id, _ := e.requestNoteAccess(slug, "deploy")
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/access-requests", nil, otherToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	e.Equal(http.StatusNotFound, e.decideNoteAccessRequest(otherToks.AccessToken, slug, id, "approve").Code)
+	e.Equal(http.StatusNotFound, e.decideNoteAccessRequest(toks.AccessToken, slug, e.uuid(), "approve").Code)
+
+	httpResp = e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/access-requests/"+id+"/approve", nil)
+	e.Equal(http.StatusUnauthorized, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_requestInvalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	approvalSlug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:          e.uuid(),
+		RequiresApproval: true,
+	}, toks.AccessToken)
+	plainSlug := e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}, toks.AccessToken) //nolint:exhaustruct
+
+	tests := []struct {
+		name   string
+		slug   string
+		reason string
+		status int
+	}{
+		{name: "empty reason", slug: approvalSlug, reason: " ", status: http.StatusBadRequest},
+		{name: "approval not required", slug: plainSlug, reason: "deploy", status: http.StatusBadRequest},
+		{name: "note not found", slug: e.uuid(), reason: "deploy", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.readerRequest(http.MethodPost, "/api/v1/note/"+tt.slug+"/access-requests",
+				e.jsonify(apiv1NoteAccessRequestRequest{Reason: tt.reason}))
+			e.Equal(tt.status, httpResp.Code)
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Approval_createInvalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	inboxEmail := e.randomEmail()
+	e.createAndSingIn(inboxEmail, e.uuid())
+
+	tests := []struct {
+		name  string
+		inp   apiv1NoteCreateRequest
+		token []string
+		err   error
+	}{
+		{
+			name: "anonymous author",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:          e.uuid(),
+				RequiresApproval: true,
+			},
+			token: nil,
+			err:   models.ErrNoteApprovalWithoutAuthor,
+		},
+		{
+			name: "with dead man's switch",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:          e.uuid(),
+				RequiresApproval: true,
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			token: []string{toks.AccessToken},
+			err:   models.ErrNoteApprovalWithSwitch,
+		},
+		{
+			name: "sent to an inbox",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:          e.uuid(),
+				RequiresApproval: true,
+				InboxEmail:       inboxEmail,
+			},
+			token: []string{toks.AccessToken},
+			err:   models.ErrNoteApprovalWithInbox,
+		},
+		{
+			name: "bound to an email",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:          e.uuid(),
+				RequiresApproval: true,
+				BoundEmail:       e.randomEmail(),
+			},
+			token: []string{toks.AccessToken},
+			err:   models.ErrNoteApprovalWithBound,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp), tt.token...)
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+// requestNoteAccess requests access to the note as a reader, and returns id of the request,
+// and the cookie the note is read with, once the request is approved.
+func (e *AppTestSuite) requestNoteAccess(slug, reason string) (string, *http.Cookie) {
+	httpResp := e.readerRequest(http.MethodPost, "/api/v1/note/"+slug+"/access-requests",
+		e.jsonify(apiv1NoteAccessRequestRequest{Reason: reason}))
+	e.Require().Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteAccessRequestCreatedResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	cookies := httpResp.Result().Cookies()
+	e.Require().Len(cookies, 1)
+	e.Equal("/api/v1/note/"+slug, cookies[0].Path)
+	e.True(cookies[0].HttpOnly)
+	e.Equal(http.SameSiteStrictMode, cookies[0].SameSite)
+
+	// the cookie has to be kept until the approved request expires
+	e.Greater(
+		time.Duration(cookies[0].MaxAge)*time.Second,
+		time.Until(body.ExpiresAt)+e.getConfig().NoteAccessRequestTTL-time.Minute,
+	)
+
+	return body.ID, cookies[0]
+}
+
+// noteAccessRequest sends http request to the server as the reader with the access request cookie.
+func (e *AppTestSuite) noteAccessRequest(
+	method, url string,
+	body []byte,
+	cookie *http.Cookie,
+) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	e.require.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("User-Agent", testReaderUserAgent)
+	req.RemoteAddr = testReaderIP + ":4242"
+	req.AddCookie(cookie)
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (e *AppTestSuite) getNoteAccessRequests(accessToken, slug string) []apiv1NoteAccessRequestResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/access-requests", nil, accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body []apiv1NoteAccessRequestResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
+
+func (e *AppTestSuite) decideNoteAccessRequest(
+	accessToken, slug, id, decision string,
+) *httptest.ResponseRecorder {
+	return e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note/"+slug+"/access-requests/"+id+"/"+decision,
+		nil,
+		accessToken,
+	)
+}
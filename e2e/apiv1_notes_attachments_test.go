@@ -0,0 +1,332 @@
+package e2e_test
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/store/blob"
+)
+
+type (
+	apiv1NoteAttachmentResponse struct {
+		ID          uuid.UUID `json:"id"`
+		Filename    string    `json:"filename"`
+		ContentType string    `json:"content_type"`
+		Size        int64     `json:"size"`
+	}
+	testAttachment struct {
+		filename string
+		content  string
+	}
+)
+
+// createNoteWithAttachments creates note with multipart/form-data request
+func (e *AppTestSuite) createNoteWithAttachments(
+	note apiv1NoteCreateRequest,
+	attachments []testAttachment,
+	accessToken ...string,
+) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	notePart, err := mw.CreateFormField("note")
+	e.require.NoError(err)
+	_, err = notePart.Write(e.jsonify(note))
+	e.require.NoError(err)
+
+	for _, a := range attachments {
+		part, err := mw.CreateFormFile("attachments", a.filename)
+		e.require.NoError(err)
+		_, err = io.WriteString(part, a.content)
+		e.require.NoError(err)
+	}
+	e.require.NoError(mw.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/note", &body)
+	e.require.NoError(err)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	if len(accessToken) == 1 {
+		req.Header.Set("Authorization", "Bearer "+accessToken[0])
+	}
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (e *AppTestSuite) downloadAttachment(slug string, id uuid.UUID, readToken string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/note/"+slug+"/attachments/"+id.String(), nil)
+	e.require.NoError(err)
+	req.Header.Set("X-Read-Token", readToken)
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+// requireBlobDeleted checks that the attachment's blob is deleted from the blob store
+func (e *AppTestSuite) requireBlobDeleted(id uuid.UUID) {
+	_, err := e.blobStore.Get(e.ctx, id.String())
+	e.require.ErrorIs(err, blob.ErrNotFound)
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_withAttachments() {
+	attachments := []testAttachment{
+		{filename: ".env", content: "SECRET_KEY=" + e.uuid()},
+		{filename: "id_ed25519", content: strings.Repeat("key", 1000)},
+	}
+
+	httpResp := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid(), MaxViews: 2}, //nolint:exhaustruct
+		attachments,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.require.Len(body.Attachments, len(attachments))
+
+	for i, a := range attachments {
+		e.Equal(a.filename, body.Attachments[i].Filename)
+		e.Equal(int64(len(a.content)), body.Attachments[i].Size)
+		e.Equal("application/octet-stream", body.Attachments[i].ContentType)
+
+		// blobs are encrypted at rest
+		stored, err := e.blobStore.Get(e.ctx, body.Attachments[i].ID.String())
+		e.require.NoError(err)
+		storedContent, err := io.ReadAll(stored)
+		e.require.NoError(err)
+		e.require.NoError(stored.Close())
+		e.NotContains(string(storedContent), a.content)
+
+		// the note isn't burnt yet, so attachments can be downloaded more than once
+		for range 2 {
+			httpResp = e.downloadAttachment(bodyCreated.Slug, body.Attachments[i].ID, body.ReadToken)
+			e.Equal(http.StatusOK, httpResp.Code)
+			e.Equal(a.content, httpResp.Body.String())
+			e.Equal("nosniff", httpResp.Header().Get("X-Content-Type-Options"))
+			e.Contains(httpResp.Header().Get("Content-Disposition"), "attachment")
+		}
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_GetAttachment_burntNote() {
+	content := e.uuid()
+	httpResp := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid()}, //nolint:exhaustruct
+		[]testAttachment{{filename: "cert.pem", content: content}},
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.require.Len(body.Attachments, 1)
+
+	// the note is burnt, so its attachments are not listed anymore, but can be downloaded once
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	var bodyRead apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyRead)
+	e.Empty(bodyRead.Attachments)
+
+	attachmentID := body.Attachments[0].ID
+	httpResp = e.downloadAttachment(bodyCreated.Slug, attachmentID, body.ReadToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Equal(content, httpResp.Body.String())
+
+	httpResp = e.downloadAttachment(bodyCreated.Slug, attachmentID, body.ReadToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	_, exists := e.getNoteAttachmentNoteID(attachmentID)
+	e.False(exists)
+	e.requireBlobDeleted(attachmentID)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetAttachment_notFound() {
+	httpResp := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid()}, //nolint:exhaustruct
+		[]testAttachment{{filename: "a.txt", content: e.uuid()}},
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	httpResp = e.downloadAttachment(bodyCreated.Slug, uuid.Must(uuid.NewV4()), body.ReadToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug+"/attachments/not-an-id", nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetAttachment_withoutReadToken() {
+	httpResp := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid(), Password: e.uuid()}, //nolint:exhaustruct
+		[]testAttachment{{filename: "a.txt", content: e.uuid()}},
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	attachments := e.getNoteAttachmentIDsBySlug(bodyCreated.Slug)
+	e.require.Len(attachments, 1)
+
+	// the password isn't given, so the note isn't read, and no token is issued
+	httpResp = e.downloadAttachment(bodyCreated.Slug, attachments[0], "")
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	// tokens of other notes don't work either
+	other := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid()}, //nolint:exhaustruct
+		[]testAttachment{{filename: "b.txt", content: e.uuid()}},
+	)
+	e.Equal(http.StatusCreated, other.Code)
+
+	var otherCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(other.Body, &otherCreated)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+otherCreated.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var otherBody apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &otherBody)
+	e.require.NotEmpty(otherBody.ReadToken)
+
+	httpResp = e.downloadAttachment(bodyCreated.Slug, attachments[0], otherBody.ReadToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_withAttachmentsInvalid() {
+	tests := []struct {
+		name        string
+		attachments []testAttachment
+		code        int
+	}{
+		{
+			name: "too large",
+			attachments: []testAttachment{
+				{filename: "large", content: strings.Repeat("a", testAttachmentMaxSizeKb*1024+1)},
+			},
+			code: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "too many",
+			attachments: func() []testAttachment {
+				var attachments []testAttachment
+				for range testAttachmentsMaxCount + 1 {
+					attachments = append(attachments, testAttachment{filename: e.uuid(), content: e.uuid()})
+				}
+				return attachments
+			}(),
+			code: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid filename",
+			attachments: []testAttachment{{filename: "..", content: e.uuid()}},
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			slug := e.uuid()
+			httpResp := e.createNoteWithAttachments(
+				apiv1NoteCreateRequest{Slug: slug, Content: e.uuid()}, //nolint:exhaustruct
+				tt.attachments,
+			)
+			e.Equal(tt.code, httpResp.Code)
+			e.Empty(e.getNoteBySlug(slug))
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Delete_withAttachments() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	httpResp := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid()}, //nolint:exhaustruct
+		[]testAttachment{{filename: "a.txt", content: e.uuid()}},
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	attachments := e.getNoteAttachmentIDsBySlug(bodyCreated.Slug)
+	e.require.Len(attachments, 1)
+
+	httpResp = e.httpRequest(http.MethodDelete, "/api/v1/note/"+bodyCreated.Slug, nil, toks.AccessToken)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	_, exists := e.getNoteAttachmentNoteID(attachments[0])
+	e.False(exists)
+	e.requireBlobDeleted(attachments[0])
+}
+
+func (e *AppTestSuite) TestReaper_Reap_attachments() {
+	expired := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:   e.uuid(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+		[]testAttachment{{filename: "expired", content: e.uuid()}},
+	)
+	e.Equal(http.StatusCreated, expired.Code)
+
+	var expiredBody apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(expired.Body, &expiredBody)
+
+	e.expireNote(expiredBody.Slug)
+
+	unread := e.createNoteWithAttachments(
+		apiv1NoteCreateRequest{Content: e.uuid()}, //nolint:exhaustruct
+		[]testAttachment{{filename: "unread", content: e.uuid()}},
+	)
+	e.Equal(http.StatusCreated, unread.Code)
+
+	var unreadBody apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(unread.Body, &unreadBody)
+
+	expiredAttachments := e.getNoteAttachmentIDsBySlug(expiredBody.Slug)
+	unreadAttachments := e.getNoteAttachmentIDsBySlug(unreadBody.Slug)
+
+	_, err := e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+
+	_, exists := e.getNoteAttachmentNoteID(expiredAttachments[0])
+	e.False(exists)
+	e.requireBlobDeleted(expiredAttachments[0])
+
+	noteID, exists := e.getNoteAttachmentNoteID(unreadAttachments[0])
+	e.True(exists)
+	e.True(noteID.Valid)
+}
@@ -0,0 +1,136 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	testReaderIP        = "203.0.113.42"
+	testReaderNetwork   = "203.0.113.0/24"
+	testReaderUserAgent = "e2e-reader/1.0"
+)
+
+type apiv1NoteAccessEventResponse struct {
+	Event     string    `json:"event"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (e *AppTestSuite) TestNoteV1_Audit() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: passwd,
+	}, toks.AccessToken)
+
+	e.Equal(http.StatusOK, e.readerRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil).Code)
+
+	httpResp := e.readerRequest(http.MethodPost, "/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: e.uuid()}))
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.readerRequest(http.MethodPost, "/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: passwd}))
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	// already read note isn't read again
+	httpResp = e.readerRequest(http.MethodPost, "/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: passwd}))
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	events := e.getNoteAccessEvents(toks.AccessToken, slug)
+	e.Require().Len(events, 3)
+	e.Equal("read", events[0].Event)
+	e.Equal("wrong_password", events[1].Event)
+	e.Equal("metadata", events[2].Event)
+
+	for _, ev := range events {
+		e.Equal(testReaderNetwork, ev.IP)
+		e.Equal(testReaderUserAgent, ev.UserAgent)
+		e.WithinDuration(time.Now(), ev.CreatedAt, time.Minute)
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Audit_preservedOnRead() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:              e.uuid(),
+		KeepBeforeExpiration: true,
+		ExpiresAt:            time.Now().Add(time.Hour),
+	}, toks.AccessToken)
+
+	for range 2 {
+		e.Equal(http.StatusOK, e.readerRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+	}
+
+	events := e.getNoteAccessEvents(toks.AccessToken, slug)
+	e.Require().Len(events, 2)
+	e.Equal("read", events[0].Event)
+	e.Equal("read", events[1].Event)
+}
+
+func (e *AppTestSuite) TestNoteV1_Audit_notAuthor() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}, toks.AccessToken) //nolint:exhaustruct
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/audit", nil, otherToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/audit", nil)
+	e.Equal(http.StatusUnauthorized, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Audit_deletedWithNote() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}, toks.AccessToken) //nolint:exhaustruct
+	noteID := e.getNoteBySlug(slug).ID
+
+	e.Equal(http.StatusOK, e.readerRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil).Code)
+	e.Equal(1, e.countNoteAccessEvents(noteID))
+
+	httpResp := e.httpRequest(http.MethodDelete, "/api/v1/note/"+slug, nil, toks.AccessToken)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	e.Equal(0, e.countNoteAccessEvents(noteID))
+}
+
+// readerRequest sends http request to the server as a reader with known ip and user agent
+func (e *AppTestSuite) readerRequest(method, url string, body []byte) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	e.require.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("User-Agent", testReaderUserAgent)
+	req.RemoteAddr = testReaderIP + ":4242"
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (e *AppTestSuite) getNoteAccessEvents(accessToken, slug string) []apiv1NoteAccessEventResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/audit", nil, accessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body []apiv1NoteAccessEventResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	return body
+}
+
+func (e *AppTestSuite) countNoteAccessEvents(noteID uuid.UUID) int {
+	var count int
+	err := e.postgresDB.QueryRow(e.ctx, "select count(*) from note_access_events where note_id = $1", noteID).
+		Scan(&count)
+	e.require.NoError(err)
+
+	return count
+}
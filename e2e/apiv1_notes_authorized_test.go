@@ -2,6 +2,7 @@ package e2e_test
 
 import (
 	"net/http"
+	"net/url"
 	"slices"
 	"time"
 )
@@ -58,6 +59,35 @@ func (e *AppTestSuite) TestNoteV1_Delete() {
 	e.Empty(dbNote)
 }
 
+func (e *AppTestSuite) TestNoteV1_Delete_notAuthor() {
+	_, authorToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content: "sample content for the test",
+		}),
+		authorToks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	httpResp = e.httpRequest(
+		http.MethodDelete,
+		"/api/v1/note/"+body.Slug,
+		nil,
+		otherToks.AccessToken,
+	)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	dbNote := e.getNoteBySlug(body.Slug)
+	e.NotEmpty(dbNote)
+}
+
 type apiV1NotePatchRequest struct {
 	ExpiresAt            time.Time `json:"expires_at"`
 	KeepBeforeExpiration bool      `json:"keep_before_expiration"`
@@ -152,7 +182,7 @@ func (e *AppTestSuite) TestNoteV1_UpdatePassword() {
 	dbNote := e.getNoteBySlug(body.Slug)
 	e.NotEmpty(dbNote.Password)
 
-	err := e.hasher.Compare(dbNote.Password, passwd)
+	err := e.noteHasher.Compare(dbNote.Password, passwd)
 	e.require.NoError(err)
 }
 
@@ -206,9 +236,21 @@ type apiv1NoteGetAllResponse struct {
 	Slug                 string    `json:"slug"`
 	KeepBeforeExpiration bool      `json:"keep_before_expiration"`
 	HasPassword          bool      `json:"has_password"`
+	MaxViews             int       `json:"max_views"`
+	Views                int       `json:"views"`
+	AllowReply           bool      `json:"allow_reply"`
+	ReplySlug            string    `json:"reply_slug"`
+	GroupID              string    `json:"group_id"`
+	RecipientLabel       string    `json:"recipient_label"`
+	Version              int       `json:"version"`
 	CreatedAt            time.Time `json:"created_at"`
 	ExpiresAt            time.Time `json:"expires_at"`
+	AvailableFrom        time.Time `json:"available_from"`
 	ReadAt               time.Time `json:"read_at"`
+	InboxEmail           string    `json:"inbox_email"`
+	DeliveredAt          time.Time `json:"delivered_at"`
+
+	InboxRecipientDeleted bool `json:"inbox_recipient_deleted"`
 }
 
 func (e *AppTestSuite) TestNoteV1_GetAll() {
@@ -250,11 +292,25 @@ func (e *AppTestSuite) TestNoteV1_GetAll() {
 
 	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note", nil, toks.AccessToken)
 
-	var body []apiv1NoteGetAllResponse
+	var body apiv1NotesPageResponse
 	e.readBodyAndUnjsonify(httpResp.Body, &body)
 
 	e.Equal(http.StatusOK, httpResp.Code)
-	e.Len(body, len(notesInfo))
+	e.Len(body.Notes, len(notesInfo))
+	e.Equal(len(notesInfo), body.Total)
+	e.Empty(body.NextCursor)
+
+	for _, ni := range notesInfo {
+		idx := slices.IndexFunc(body.Notes, func(n apiv1NoteGetAllResponse) bool { return n.Slug == ni.slug })
+		e.require.NotEqual(-1, idx)
+		e.Equal(1, body.Notes[idx].MaxViews)
+
+		if ni.read {
+			e.Equal(1, body.Notes[idx].Views)
+		} else {
+			e.Zero(body.Notes[idx].Views)
+		}
+	}
 }
 
 func (e *AppTestSuite) TestNoteV1_GetAllRead_inaccesibleForAnUnauthorized() {
@@ -295,14 +351,30 @@ func (e *AppTestSuite) TestNoteV1_GetAllRead() {
 		e.Equal(http.StatusOK, httpResp.Code)
 	}
 
-	// check if all notes are returned
-	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/read", nil, toks.AccessToken)
+	// check if all notes are returned, page by page
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/read?limit=3", nil, toks.AccessToken)
 
-	var body []apiv1NoteGetAllResponse
+	var body apiv1NotesPageResponse
 	e.readBodyAndUnjsonify(httpResp.Body, &body)
 
 	e.Equal(http.StatusOK, httpResp.Code)
-	e.require.Len(body, len(notesInfo))
+	e.Equal(len(notesInfo), body.Total)
+	e.require.Len(body.Notes, 3)
+	e.require.NotEmpty(body.NextCursor)
+
+	httpResp = e.httpRequest(
+		http.MethodGet,
+		"/api/v1/note/read?limit=3&cursor="+url.QueryEscape(body.NextCursor),
+		nil,
+		toks.AccessToken,
+	)
+
+	var nextBody apiv1NotesPageResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &nextBody)
+
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Len(nextBody.Notes, len(notesInfo)-3)
+	e.Empty(nextBody.NextCursor)
 }
 
 func (e *AppTestSuite) TestNoteV1_GetAllUnread_inaccesibleForAnUnauthorized() {
@@ -357,11 +429,16 @@ func (e *AppTestSuite) TestNoteV1_GetAllUnread() {
 		}
 	}
 
-	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/unread", nil, toks.AccessToken)
+	// the status of the endpoint cannot be overridden
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/unread?status=read", nil, toks.AccessToken)
 
-	var body []apiv1NoteGetAllResponse
+	var body apiv1NotesPageResponse
 	e.readBodyAndUnjsonify(httpResp.Body, &body)
 
 	e.Equal(http.StatusOK, httpResp.Code)
-	e.Len(body, unreadNotesTotal)
+	e.Equal(unreadNotesTotal, body.Total)
+	e.Len(body.Notes, unreadNotesTotal)
+	for _, n := range body.Notes {
+		e.True(n.ReadAt.IsZero())
+	}
 }
@@ -0,0 +1,142 @@
+package e2e_test
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type (
+	apiv1NoteNotAvailableYetResponse struct {
+		Message       string    `json:"message"`
+		AvailableFrom time.Time `json:"available_from"`
+	}
+	apiv1NoteAvailabilityPatchRequest struct {
+		AvailableFrom time.Time `json:"available_from"`
+	}
+)
+
+func (e *AppTestSuite) TestNoteV1_Get_notAvailableYet() {
+	availableFrom := time.Now().Add(time.Hour).Truncate(time.Second)
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:       e.uuid(),
+		AvailableFrom: availableFrom,
+	})
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusTooEarly, httpResp.Code)
+	e.NotEmpty(httpResp.Header().Get("Retry-After"))
+
+	var body apiv1NoteNotAvailableYetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteNotAvailableYet.Error(), body.Message)
+	e.True(availableFrom.Equal(body.AvailableFrom))
+
+	// the note isn't burnt by reading it too early
+	e.Empty(e.getNoteBySlug(slug).ReadAt)
+
+	e.makeNoteAvailable(slug)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_notAvailableYet() {
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:       e.uuid(),
+		Password:      passwd,
+		AvailableFrom: time.Now().Add(time.Hour),
+	})
+
+	// wrong passwords aren't counted before the note is available
+	for range testNotePasswordMaxAttempts {
+		e.Equal(http.StatusTooEarly, e.viewNote(slug, e.uuid()).Code)
+	}
+	e.Equal(http.StatusTooEarly, e.viewNote(slug, passwd).Code)
+
+	e.makeNoteAvailable(slug)
+	e.Equal(http.StatusOK, e.viewNote(slug, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_availableAfterExpiration() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:       e.uuid(),
+			ExpiresAt:     time.Now().Add(time.Hour),
+			AvailableFrom: time.Now().Add(2 * time.Hour),
+		}),
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteAvailabilityAfterExpiration.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateAvailability() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:   e.uuid(),
+		ExpiresAt: time.Now().Add(2 * time.Hour),
+	}, toks.AccessToken)
+
+	availableFrom := time.Now().Add(time.Hour).Truncate(time.Second)
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/expires",
+		e.jsonify(apiv1NoteAvailabilityPatchRequest{AvailableFrom: availableFrom}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	notes := e.listNotes(toks.AccessToken, url.Values{})
+	e.Require().Len(notes.Notes, 1)
+	e.True(availableFrom.Equal(notes.Notes[0].AvailableFrom))
+
+	e.Equal(http.StatusTooEarly, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+
+	// the note cannot become available after it expires
+	httpResp = e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/expires",
+		e.jsonify(apiv1NoteAvailabilityPatchRequest{AvailableFrom: time.Now().Add(3 * time.Hour)}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	// zero time makes the note available right away
+	httpResp = e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/expires",
+		e.jsonify(apiv1NoteAvailabilityPatchRequest{AvailableFrom: time.Time{}}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	e.Equal(http.StatusOK, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_notAvailableYet() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:       e.uuid(),
+		AllowReply:    true,
+		AvailableFrom: time.Now().Add(time.Hour),
+	}, toks.AccessToken)
+
+	httpResp := e.replyToNote(slug, e.getReadToken(slug), apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+// makeNoteAvailable moves note's availability time to the past, as if it has come
+func (e *AppTestSuite) makeNoteAvailable(slug string) {
+	_, err := e.postgresDB.Exec(e.ctx,
+		"update notes set available_from = $1 where slug = $2",
+		time.Now().Add(-time.Minute), slug)
+	e.require.NoError(err)
+}
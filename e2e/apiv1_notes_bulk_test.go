@@ -0,0 +1,261 @@
+package e2e_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type (
+	apiv1NotesBulkRequest struct {
+		Action    string                       `json:"action"`
+		Slugs     []string                     `json:"slugs,omitempty"`
+		Filter    *apiv1NotesBulkFilterRequest `json:"filter,omitempty"`
+		ExpiresAt time.Time                    `json:"expires_at,omitzero"`
+		Password  string                       `json:"password,omitempty"`
+	}
+	apiv1NotesBulkFilterRequest struct {
+		Status        string    `json:"status,omitempty"`
+		CreatedBefore time.Time `json:"created_before,omitzero"`
+	}
+	apiv1NotesBulkResponse struct {
+		Results []struct {
+			Slug   string `json:"slug"`
+			Status string `json:"status"`
+		} `json:"results"`
+		Done int `json:"done"`
+	}
+)
+
+func (e *AppTestSuite) TestNoteV1_Bulk_deleteBySlugs() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	first := e.createNoteAs(toks.AccessToken)
+	second := e.createNoteAs(toks.AccessToken)
+	kept := e.createNoteAs(toks.AccessToken)
+	others := e.createNoteAs(otherToks.AccessToken)
+	missing := e.uuid()
+
+	res := e.bulkNotes(toks.AccessToken, apiv1NotesBulkRequest{ //nolint:exhaustruct
+		Action: string(models.NoteBulkActionDelete),
+		Slugs:  []string{first, second, others, missing},
+	})
+	e.Equal(2, res.Done)
+	e.Equal(map[string]string{
+		first:   string(models.NoteBulkStatusDone),
+		second:  string(models.NoteBulkStatusDone),
+		others:  string(models.NoteBulkStatusNotFound),
+		missing: string(models.NoteBulkStatusNotFound),
+	}, e.bulkStatuses(res))
+
+	e.Empty(e.getNoteBySlug(first))
+	e.Empty(e.getNoteBySlug(second))
+	e.NotEmpty(e.getNoteBySlug(kept))
+	e.NotEmpty(e.getNoteBySlug(others))
+}
+
+func (e *AppTestSuite) TestNoteV1_Bulk_deleteByFilter() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	old := e.createNoteAs(toks.AccessToken)
+	oldRead := e.createNoteAs(toks.AccessToken)
+	fresh := e.createNoteAs(toks.AccessToken)
+
+	for _, slug := range []string{old, oldRead} {
+		_, err := e.postgresDB.Exec(e.ctx,
+			"update notes set created_at = $1 where slug = $2",
+			time.Now().Add(-8*24*time.Hour), slug)
+		e.require.NoError(err)
+	}
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+oldRead, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	// all unread older than 7 days
+	res := e.bulkNotes(toks.AccessToken, apiv1NotesBulkRequest{ //nolint:exhaustruct
+		Action: string(models.NoteBulkActionDelete),
+		Filter: &apiv1NotesBulkFilterRequest{
+			Status:        string(models.NoteStatusUnread),
+			CreatedBefore: time.Now().Add(-7 * 24 * time.Hour),
+		},
+	})
+	e.Equal(1, res.Done)
+	e.Equal(map[string]string{old: string(models.NoteBulkStatusDone)}, e.bulkStatuses(res))
+
+	e.Empty(e.getNoteBySlug(old))
+	e.NotEmpty(e.getNoteBySlug(oldRead))
+	e.NotEmpty(e.getNoteBySlug(fresh))
+}
+
+func (e *AppTestSuite) TestNoteV1_Bulk_expire() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	unread := e.createNoteAs(toks.AccessToken)
+	read := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+read, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	res := e.bulkNotes(toks.AccessToken, apiv1NotesBulkRequest{ //nolint:exhaustruct
+		Action: string(models.NoteBulkActionExpire),
+		Slugs:  []string{unread, read},
+	})
+	e.Equal(1, res.Done)
+	e.Equal(map[string]string{
+		unread: string(models.NoteBulkStatusDone),
+		read:   string(models.NoteBulkStatusSkipped),
+	}, e.bulkStatuses(res))
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+unread, nil)
+	e.Equal(http.StatusGone, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Bulk_setExpiration() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+
+	expiresAt := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	res := e.bulkNotes(toks.AccessToken, apiv1NotesBulkRequest{ //nolint:exhaustruct
+		Action:    string(models.NoteBulkActionSetExpiration),
+		Slugs:     []string{slug},
+		ExpiresAt: expiresAt,
+	})
+	e.Equal(1, res.Done)
+
+	dbNote := e.getNoteBySlug(slug)
+	e.True(expiresAt.Equal(dbNote.ExpiresAt))
+}
+
+func (e *AppTestSuite) TestNoteV1_Bulk_setExpirationBeforeAvailability() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	available := e.createNoteAs(toks.AccessToken)
+	notAvailable := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:       e.uuid(),
+		AvailableFrom: time.Now().Add(72 * time.Hour),
+	}, toks.AccessToken)
+
+	expiresAt := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	res := e.bulkNotes(toks.AccessToken, apiv1NotesBulkRequest{ //nolint:exhaustruct
+		Action:    string(models.NoteBulkActionSetExpiration),
+		Slugs:     []string{available, notAvailable},
+		ExpiresAt: expiresAt,
+	})
+	e.Equal(1, res.Done)
+	e.Equal(map[string]string{
+		available:    string(models.NoteBulkStatusDone),
+		notAvailable: string(models.NoteBulkStatusSkipped),
+	}, e.bulkStatuses(res))
+
+	e.True(e.getNoteBySlug(notAvailable).ExpiresAt.IsZero())
+}
+
+func (e *AppTestSuite) TestNoteV1_Bulk_setPassword() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+	passwd := e.uuid()
+
+	res := e.bulkNotes(toks.AccessToken, apiv1NotesBulkRequest{ //nolint:exhaustruct
+		Action:   string(models.NoteBulkActionSetPassword),
+		Filter:   &apiv1NotesBulkFilterRequest{}, //nolint:exhaustruct
+		Password: passwd,
+	})
+	e.Equal(1, res.Done)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: passwd}),
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Bulk_invalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	tests := []struct {
+		name string
+		inp  apiv1NotesBulkRequest
+		err  error
+	}{
+		{
+			name: "unknown action",
+			inp:  apiv1NotesBulkRequest{Action: "read", Slugs: []string{"a"}}, //nolint:exhaustruct
+			err:  models.ErrNoteBulkActionInvalid,
+		},
+		{
+			name: "no selector",
+			inp:  apiv1NotesBulkRequest{Action: "delete"}, //nolint:exhaustruct
+			err:  models.ErrNoteBulkSelectorInvalid,
+		},
+		{
+			name: "expiration in the past",
+			inp: apiv1NotesBulkRequest{ //nolint:exhaustruct
+				Action:    string(models.NoteBulkActionSetExpiration),
+				Slugs:     []string{"a"},
+				ExpiresAt: time.Now().Add(-time.Hour),
+			},
+			err: models.ErrNoteBulkExpirationInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(
+				http.MethodPost,
+				"/api/v1/note/bulk",
+				e.jsonify(tt.inp),
+				toks.AccessToken,
+			)
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Panic() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	unread := e.createNoteAs(toks.AccessToken)
+	read := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+read, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodPost, "/api/v1/note/panic", nil, toks.AccessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var res apiv1NotesBulkResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &res)
+	e.Equal(1, res.Done)
+	e.Equal(map[string]string{unread: string(models.NoteBulkStatusDone)}, e.bulkStatuses(res))
+
+	dbNote := e.getNoteBySlug(unread)
+	e.Empty(dbNote.Content)
+	e.False(dbNote.ReadAt.IsZero())
+}
+
+func (e *AppTestSuite) bulkNotes(accessToken string, inp apiv1NotesBulkRequest) apiv1NotesBulkResponse {
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/bulk", e.jsonify(inp), accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NotesBulkResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
+
+func (e *AppTestSuite) bulkStatuses(res apiv1NotesBulkResponse) map[string]string {
+	statuses := make(map[string]string, len(res.Results))
+	for _, r := range res.Results {
+		statuses[r.Slug] = r.Status
+	}
+	return statuses
+}
@@ -0,0 +1,269 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type apiv1NoteUnlockRequest struct {
+	Code     string `json:"code"`
+	Password string `json:"password,omitempty"`
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote() {
+	email := e.randomEmail()
+	content := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    content,
+		BoundEmail: email,
+	})
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var meta apiv1NoteMetadataResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &meta)
+	e.True(meta.CodeRequired)
+
+	// the link alone isn't enough to read the note
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusForbidden, httpResp.Code)
+	var errBody errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &errBody)
+	e.Equal(models.ErrNoteCodeRequired.Error(), errBody.Message)
+
+	code := e.requestNoteCode(slug, email)
+
+	httpResp = e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}) //nolint:exhaustruct
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(content, body.Content)
+
+	httpResp = e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_codeUsedOnce() {
+	email := e.randomEmail()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:              e.uuid(),
+		BoundEmail:           email,
+		KeepBeforeExpiration: true,
+		ExpiresAt:            time.Now().Add(time.Hour),
+	})
+
+	code := e.requestNoteCode(slug, email)
+	e.Equal(http.StatusOK, e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}).Code) //nolint:exhaustruct
+
+	httpResp := e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}) //nolint:exhaustruct
+	e.Equal(http.StatusForbidden, httpResp.Code)
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteCodeInvalid.Error(), body.Message)
+
+	// the note is kept, so it can be read again with a new code
+	code = e.requestNoteCode(slug, email)
+	e.Equal(http.StatusOK, e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}).Code) //nolint:exhaustruct
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_previousCode() {
+	email := e.randomEmail()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		BoundEmail: email,
+	})
+
+	previous := e.requestNoteCode(slug, email)
+	code := e.requestNoteCode(slug, email)
+
+	// codes are random, so the new one could be the same
+	if previous != code {
+		httpResp := e.unlockNote(slug, apiv1NoteUnlockRequest{Code: previous}) //nolint:exhaustruct
+		e.Equal(http.StatusForbidden, httpResp.Code)
+	}
+
+	e.Equal(http.StatusOK, e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}).Code) //nolint:exhaustruct
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_withPassword() {
+	email := e.randomEmail()
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		Password:   passwd,
+		BoundEmail: email,
+	})
+
+	code := e.requestNoteCode(slug, email)
+
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: passwd}))
+	e.Equal(http.StatusForbidden, httpResp.Code)
+
+	// the code isn't used up by a wrong password
+	httpResp = e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code, Password: e.uuid()})
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code, Password: passwd})
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_attemptsExceeded() {
+	email := e.randomEmail()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		BoundEmail: email,
+	})
+
+	code := e.requestNoteCode(slug, email)
+	for range testNoteCodeMaxAttempts {
+		httpResp := e.unlockNote(slug, apiv1NoteUnlockRequest{Code: "not-a-code"}) //nolint:exhaustruct
+		e.Equal(http.StatusForbidden, httpResp.Code)
+	}
+
+	httpResp := e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}) //nolint:exhaustruct
+	e.Equal(http.StatusTooManyRequests, httpResp.Code)
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteCodeAttemptsExceeded.Error(), body.Message)
+
+	// new codes don't give more attempts
+	httpResp = e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/code", nil)
+	e.Equal(http.StatusTooManyRequests, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_attemptsExceededConcurrently() {
+	email := e.randomEmail()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		BoundEmail: email,
+	})
+
+	code := e.requestNoteCode(slug, email)
+
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		responses = make([]*httptest.ResponseRecorder, testNoteCodeMaxAttempts*4)
+	)
+
+	body := e.jsonify(apiv1NoteUnlockRequest{Code: "not-a-code"}) //nolint:exhaustruct
+	for i := range responses {
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/note/"+slug+"/unlock", bytes.NewReader(body))
+			resp := httptest.NewRecorder()
+
+			<-start
+			e.router.ServeHTTP(resp, req)
+			responses[i] = resp
+		})
+	}
+
+	close(start)
+	wg.Wait()
+
+	// only as many codes as allowed are compared, the rest are rejected
+	var forbidden int
+	for _, resp := range responses {
+		if resp.Code == http.StatusForbidden {
+			forbidden++
+			continue
+		}
+		e.Equal(http.StatusTooManyRequests, resp.Code)
+	}
+	e.Equal(testNoteCodeMaxAttempts, forbidden)
+
+	httpResp := e.unlockNote(slug, apiv1NoteUnlockRequest{Code: code}) //nolint:exhaustruct
+	e.Equal(http.StatusTooManyRequests, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_wrongCodeAudited() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	email := e.randomEmail()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		BoundEmail: email,
+	}, toks.AccessToken)
+
+	e.requestNoteCode(slug, email)
+	e.unlockNote(slug, apiv1NoteUnlockRequest{Code: "not-a-code"}) //nolint:exhaustruct
+
+	events := e.getNoteAccessEvents(toks.AccessToken, slug)
+	e.Require().Len(events, 1)
+	e.Equal("wrong_code", events[0].Event)
+}
+
+func (e *AppTestSuite) TestNoteV1_RequestCode_notBound() {
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/code", nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodPost, "/api/v1/note/"+e.uuid()+"/code", nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_BoundNote_invalid() {
+	tests := []struct {
+		name string
+		inp  apiv1NoteCreateRequest
+		err  error
+	}{
+		{
+			name: "invalid email",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				BoundEmail: "alice",
+			},
+			err: models.ErrNoteBoundEmailInvalid,
+		},
+		{
+			name: "email with display name",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				BoundEmail: "Bob <alice@example.com>",
+			},
+			err: models.ErrNoteBoundEmailInvalid,
+		},
+		{
+			name: "with recipients",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				BoundEmail: e.randomEmail(),
+				Recipients: 2,
+			},
+			err: models.ErrNoteBoundWithRecipients,
+		},
+	}
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp))
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+// requestNoteCode requests one-time code of the bound note, and returns the code emailed to the email
+func (e *AppTestSuite) requestNoteCode(slug, email string) string {
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/code", nil)
+	e.Require().Equal(http.StatusNoContent, httpResp.Code)
+
+	code := mockMailStore[email]
+	e.Require().Len(code, models.NoteCodeLength)
+
+	return code
+}
+
+func (e *AppTestSuite) unlockNote(slug string, req apiv1NoteUnlockRequest) *httptest.ResponseRecorder {
+	return e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/unlock", e.jsonify(req))
+}
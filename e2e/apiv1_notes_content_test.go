@@ -0,0 +1,184 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type (
+	apiv1NoteUpdateContentRequest struct {
+		Content string `json:"content"`
+		Version int    `json:"version,omitempty"`
+	}
+	apiv1NoteUpdateContentResponse struct {
+		Version int `json:"version"`
+	}
+)
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+
+	version := e.getNoteVersion(toks.AccessToken, slug)
+	content := e.uuid()
+
+	httpResp := e.updateNoteContent(toks.AccessToken, slug, content, `"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Equal(`"`+strconv.Itoa(version+1)+`"`, httpResp.Header().Get("ETag"))
+
+	var body apiv1NoteUpdateContentResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(version+1, body.Version)
+
+	// the version is already stale
+	httpResp = e.updateNoteContent(toks.AccessToken, slug, e.uuid(), `"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusPreconditionFailed, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var note apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &note)
+	e.Equal(content, note.Content)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_versionInBody() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/content",
+		e.jsonify(apiv1NoteUpdateContentRequest{
+			Content: e.uuid(),
+			Version: e.getNoteVersion(toks.AccessToken, slug),
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_versionIsRequired() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.updateNoteContent(toks.AccessToken, slug, e.uuid(), "")
+	e.Equal(http.StatusPreconditionRequired, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteVersionRequired.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_readNote() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+	version := e.getNoteVersion(toks.AccessToken, slug)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	httpResp = e.updateNoteContent(toks.AccessToken, slug, e.uuid(), `"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteCannotBeEdited.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_viewedKeptNote() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	content := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:              content,
+		KeepBeforeExpiration: true,
+		ExpiresAt:            time.Now().Add(time.Hour),
+	}, toks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	version := e.getNoteVersion(toks.AccessToken, slug)
+	httpResp = e.updateNoteContent(toks.AccessToken, slug, e.uuid(), `"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteCannotBeEdited.Error(), body.Message)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var note apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &note)
+	e.Equal(content, note.Content)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_expiredNote() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+	version := e.getNoteVersion(toks.AccessToken, slug)
+
+	e.expireNote(slug)
+
+	httpResp := e.updateNoteContent(toks.AccessToken, slug, e.uuid(), `"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusGone, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_notAuthor() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.updateNoteContent(otherToks.AccessToken, slug, e.uuid(), `"1"`)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateContent_emptyContent() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+	version := e.getNoteVersion(toks.AccessToken, slug)
+
+	httpResp := e.updateNoteContent(toks.AccessToken, slug, "", `W/"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+}
+
+func (e *AppTestSuite) updateNoteContent(
+	accessToken, slug, content, ifMatch string,
+) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/content",
+		bytes.NewBuffer(e.jsonify(apiv1NoteUpdateContentRequest{Content: content})), //nolint:exhaustruct
+	)
+	e.require.NoError(err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (e *AppTestSuite) getNoteVersion(accessToken, slug string) int {
+	page := e.listNotes(accessToken, url.Values{"limit": {"100"}})
+	for _, n := range page.Notes {
+		if n.Slug == slug {
+			return n.Version
+		}
+	}
+
+	e.FailNow("note not found in the list", slug)
+	return 0
+}
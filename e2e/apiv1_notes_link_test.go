@@ -0,0 +1,200 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type apiv1NoteLinkSendResponse struct {
+	RecipientEmail string    `json:"recipient_email"`
+	SentAt         time.Time `json:"sent_at"`
+}
+
+func (e *AppTestSuite) TestNoteV1_SendLink() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	recipient := e.randomEmail()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:        e.uuid(),
+		RecipientEmail: recipient,
+		Message:        "the password is the name of our first cat",
+	}, toks.AccessToken)
+
+	e.Equal("send_note_link:"+slug, mockMailStore[recipient])
+
+	sends := e.getNoteLinkSends(toks.AccessToken, slug)
+	e.Require().Len(sends, 1)
+	e.Equal(recipient, sends[0].RecipientEmail)
+	e.WithinDuration(time.Now(), sends[0].SentAt, time.Minute)
+}
+
+func (e *AppTestSuite) TestNoteV1_SendLink_rateLimited() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	for range testNoteLinkSendsPerHour {
+		e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:        e.uuid(),
+			RecipientEmail: e.randomEmail(),
+		}, toks.AccessToken)
+	}
+
+	recipient := e.randomEmail()
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:        e.uuid(),
+			RecipientEmail: recipient,
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusTooManyRequests, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteLinkSendsExceeded.Error(), body.Message)
+
+	// the note isn't created if its link cannot be sent
+	e.Empty(mockMailStore[recipient])
+	e.Equal(testNoteLinkSendsPerHour, e.listNotes(toks.AccessToken, url.Values{}).Total)
+
+	// notes without the link are still created
+	e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}, toks.AccessToken) //nolint:exhaustruct
+}
+
+func (e *AppTestSuite) TestNoteV1_SendLink_rateLimitedConcurrently() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		responses = make([]*httptest.ResponseRecorder, testNoteLinkSendsPerHour*4)
+	)
+
+	for i := range responses {
+		body := e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:        e.uuid(),
+			RecipientEmail: e.randomEmail(),
+		})
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/note", bytes.NewReader(body))
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+toks.AccessToken)
+			resp := httptest.NewRecorder()
+
+			<-start
+			e.router.ServeHTTP(resp, req)
+			responses[i] = resp
+		})
+	}
+
+	close(start)
+	wg.Wait()
+
+	// only as many links as allowed are sent, the rest of the notes aren't created
+	var created int
+	for _, resp := range responses {
+		if resp.Code == http.StatusCreated {
+			created++
+			continue
+		}
+		e.Equal(http.StatusTooManyRequests, resp.Code)
+	}
+	e.Equal(testNoteLinkSendsPerHour, created)
+}
+
+func (e *AppTestSuite) TestNoteV1_SendLink_invalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	tests := []struct {
+		name   string
+		inp    apiv1NoteCreateRequest
+		tokens []string
+		err    error
+	}{
+		{
+			name: "anonymous",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:        e.uuid(),
+				RecipientEmail: e.randomEmail(),
+			},
+			tokens: nil,
+			err:    models.ErrNoteLinkWithoutAuthor,
+		},
+		{
+			name: "recipient is not an email",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:        e.uuid(),
+				RecipientEmail: "not an email",
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteLinkRecipientInvalid,
+		},
+		{
+			name: "message without recipient",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content: e.uuid(),
+				Message: e.uuid(),
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteLinkRecipientInvalid,
+		},
+		{
+			name: "message is too long",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:        e.uuid(),
+				RecipientEmail: e.randomEmail(),
+				Message:        strings.Repeat("a", models.MaxNoteLinkMessageLength+1),
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteLinkMessageTooLong,
+		},
+		{
+			name: "with recipients",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:        e.uuid(),
+				Recipients:     2,
+				RecipientEmail: e.randomEmail(),
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteLinkWithRecipients,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp), tt.tokens...)
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_GetLinkSends_notAuthor() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:        e.uuid(),
+		RecipientEmail: e.randomEmail(),
+	}, toks.AccessToken)
+
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/sends", nil, otherToks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) getNoteLinkSends(accessToken, slug string) []apiv1NoteLinkSendResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug+"/sends", nil, accessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body []apiv1NoteLinkSendResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	return body
+}
@@ -0,0 +1,243 @@
+package e2e_test
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type apiv1NotesPageResponse struct {
+	Notes      []apiv1NoteGetAllResponse `json:"notes"`
+	Total      int                       `json:"total"`
+	NextCursor string                    `json:"next_cursor"`
+}
+
+func (e *AppTestSuite) TestNoteV1_List_paginated() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	created := make(map[string]bool)
+	for range 5 {
+		created[e.createNoteAs(toks.AccessToken)] = true
+	}
+
+	var (
+		seen   []string
+		cursor string
+		pages  int
+	)
+	for {
+		query := url.Values{"limit": {"2"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		page := e.listNotes(toks.AccessToken, query)
+		e.Equal(len(created), page.Total)
+		e.LessOrEqual(len(page.Notes), 2)
+
+		for _, n := range page.Notes {
+			seen = append(seen, n.Slug)
+		}
+
+		pages++
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	e.Equal(3, pages)
+	e.Len(seen, len(created))
+	for _, slug := range seen {
+		e.True(created[slug])
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_List_filterByStatus() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	unread := e.createNoteAs(toks.AccessToken)
+	read := e.createNoteAs(toks.AccessToken)
+	expired := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+read, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	e.expireNote(expired)
+
+	tests := []struct {
+		status string
+		slug   string
+	}{
+		{status: string(models.NoteStatusUnread), slug: unread},
+		{status: string(models.NoteStatusRead), slug: read},
+		{status: string(models.NoteStatusExpired), slug: expired},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.status, func() {
+			page := e.listNotes(toks.AccessToken, url.Values{"status": {tt.status}})
+			e.Equal(1, page.Total)
+			e.Require().Len(page.Notes, 1)
+			e.Equal(tt.slug, page.Notes[0].Slug)
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_List_filterByPasswordAndCreation() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:  e.uuid(),
+			Password: e.uuid(),
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var withPassword apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &withPassword)
+
+	withoutPassword := e.createNoteAs(toks.AccessToken)
+
+	page := e.listNotes(toks.AccessToken, url.Values{"has_password": {"true"}})
+	e.Require().Len(page.Notes, 1)
+	e.Equal(withPassword.Slug, page.Notes[0].Slug)
+	e.True(page.Notes[0].HasPassword)
+
+	page = e.listNotes(toks.AccessToken, url.Values{"has_password": {"false"}})
+	e.Require().Len(page.Notes, 1)
+	e.Equal(withoutPassword, page.Notes[0].Slug)
+
+	hourAgo := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	page = e.listNotes(toks.AccessToken, url.Values{"created_after": {hourAgo}})
+	e.Equal(2, page.Total)
+
+	page = e.listNotes(toks.AccessToken, url.Values{"created_before": {hourAgo}})
+	e.Zero(page.Total)
+	e.Empty(page.Notes)
+}
+
+func (e *AppTestSuite) TestNoteV1_List_sortByExpiresAt() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	slugs := make([]string, 3)
+	for i := range slugs {
+		httpResp := e.httpRequest(
+			http.MethodPost,
+			"/api/v1/note",
+			e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:   e.uuid(),
+				ExpiresAt: time.Now().Add(time.Duration(3-i) * time.Hour),
+			}),
+			toks.AccessToken,
+		)
+		e.Require().Equal(http.StatusCreated, httpResp.Code)
+
+		var body apiv1NoteCreateResponse
+		e.readBodyAndUnjsonify(httpResp.Body, &body)
+		slugs[i] = body.Slug
+	}
+
+	page := e.listNotes(toks.AccessToken, url.Values{"sort": {"expires_at"}, "order": {"asc"}})
+	e.Require().Len(page.Notes, 3)
+	e.Equal(slugs[2], page.Notes[0].Slug)
+	e.Equal(slugs[1], page.Notes[1].Slug)
+	e.Equal(slugs[0], page.Notes[2].Slug)
+
+	page = e.listNotes(toks.AccessToken, url.Values{"sort": {"expires_at"}, "order": {"desc"}})
+	e.Require().Len(page.Notes, 3)
+	e.Equal(slugs[0], page.Notes[0].Slug)
+	e.Equal(slugs[2], page.Notes[2].Slug)
+}
+
+func (e *AppTestSuite) TestNoteV1_List_cursorIsTiedToSorting() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.createNoteAs(toks.AccessToken)
+	e.createNoteAs(toks.AccessToken)
+
+	page := e.listNotes(toks.AccessToken, url.Values{"limit": {"1"}})
+	e.Require().NotEmpty(page.NextCursor)
+
+	httpResp := e.httpRequest(
+		http.MethodGet,
+		"/api/v1/note?"+url.Values{"cursor": {page.NextCursor}, "sort": {"read_at"}}.Encode(),
+		nil,
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteListCursorInvalid.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestNoteV1_List_invalidQuery() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	tests := []struct {
+		name  string
+		query url.Values
+		err   error
+	}{
+		{
+			name:  "unknown status",
+			query: url.Values{"status": {"burnt"}},
+			err:   models.ErrNoteListStatusInvalid,
+		},
+		{
+			name:  "unknown sort",
+			query: url.Values{"sort": {"content"}},
+			err:   models.ErrNoteListSortInvalid,
+		},
+		{
+			name:  "limit too big",
+			query: url.Values{"limit": {"1000"}},
+			err:   models.ErrNoteListLimitInvalid,
+		},
+		{
+			name:  "malformed cursor",
+			query: url.Values{"cursor": {"not a cursor"}},
+			err:   models.ErrNoteListCursorInvalid,
+		},
+		{
+			name: "inverted range",
+			query: url.Values{
+				"created_after":  {time.Now().UTC().Format(time.RFC3339)},
+				"created_before": {time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)},
+			},
+			err: models.ErrNoteListRangeInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(
+				http.MethodGet,
+				"/api/v1/note?"+tt.query.Encode(),
+				nil,
+				toks.AccessToken,
+			)
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+func (e *AppTestSuite) listNotes(accessToken string, query url.Values) apiv1NotesPageResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note?"+query.Encode(), nil, accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NotesPageResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
@@ -0,0 +1,198 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type (
+	apiv1ManagedNoteResponse struct {
+		Slug                 string    `json:"slug"`
+		KeepBeforeExpiration bool      `json:"keep_before_expiration"`
+		Views                int       `json:"views"`
+		ExpiresAt            time.Time `json:"expires_at"`
+		ReadAt               time.Time `json:"read_at"`
+	}
+	apiv1NoteClaimRequest struct {
+		ManagementTokens []string `json:"management_tokens"`
+	}
+	apiv1NoteClaimResponse struct {
+		Slugs []string `json:"slugs"`
+	}
+)
+
+func (e *AppTestSuite) TestNoteV1_Create_managementToken() {
+	body := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.NotEmpty(body.ManagementToken)
+
+	// notes with an author are managed by it
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: e.uuid()}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var authored apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &authored)
+	e.Empty(authored.ManagementToken)
+}
+
+func (e *AppTestSuite) TestNoteV1_Manage_get() {
+	note := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+
+	httpResp := e.manageNote(http.MethodGet, note.Slug, "", note.ManagementToken, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1ManagedNoteResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(note.Slug, body.Slug)
+	e.True(body.ReadAt.IsZero())
+
+	e.Equal(http.StatusOK, e.httpRequest(http.MethodGet, "/api/v1/note/"+note.Slug, nil).Code)
+
+	// the creator can see that the note has been read
+	httpResp = e.manageNote(http.MethodGet, note.Slug, "", note.ManagementToken, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.False(body.ReadAt.IsZero())
+	e.Equal(1, body.Views)
+}
+
+func (e *AppTestSuite) TestNoteV1_Manage_wrongToken() {
+	note := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()})  //nolint:exhaustruct
+	other := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+
+	e.Equal(http.StatusUnauthorized, e.manageNote(http.MethodGet, note.Slug, "", "", nil).Code)
+	e.Equal(http.StatusNotFound, e.manageNote(http.MethodGet, note.Slug, "", e.uuid(), nil).Code)
+	e.Equal(http.StatusNotFound, e.manageNote(http.MethodDelete, note.Slug, "", other.ManagementToken, nil).Code)
+
+	e.Equal(http.StatusOK, e.httpRequest(http.MethodGet, "/api/v1/note/"+note.Slug, nil).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Manage_updateExpiration() {
+	note := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	httpResp := e.manageNote(
+		http.MethodPatch,
+		note.Slug,
+		"/expires",
+		note.ManagementToken,
+		e.jsonify(apiV1NotePatchRequest{ExpiresAt: expiresAt, KeepBeforeExpiration: false}),
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	httpResp = e.manageNote(http.MethodGet, note.Slug, "", note.ManagementToken, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1ManagedNoteResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.True(expiresAt.Equal(body.ExpiresAt))
+}
+
+func (e *AppTestSuite) TestNoteV1_Manage_delete() {
+	note := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+
+	httpResp := e.manageNote(http.MethodDelete, note.Slug, "", note.ManagementToken, nil)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	e.Equal(http.StatusNotFound, e.httpRequest(http.MethodGet, "/api/v1/note/"+note.Slug, nil).Code)
+	e.Equal(http.StatusNotFound, e.manageNote(http.MethodGet, note.Slug, "", note.ManagementToken, nil).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Claim() {
+	note := e.createAnonymousNote(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+	withRecipients := e.createAnonymousNote(apiv1NoteCreateRequest{          //nolint:exhaustruct
+		Content:    e.uuid(),
+		Recipients: 2,
+	})
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slugs := e.claimNotes(toks.AccessToken, note.ManagementToken, withRecipients.ManagementToken, e.uuid())
+	e.ElementsMatch(
+		[]string{note.Slug, withRecipients.Recipients[0].Slug, withRecipients.Recipients[1].Slug},
+		slugs,
+	)
+
+	notes := e.listNotes(toks.AccessToken, url.Values{})
+	e.Equal(3, notes.Total)
+
+	// claimed notes are managed by the author, and cannot be claimed by anyone else
+	e.Equal(http.StatusNotFound, e.manageNote(http.MethodGet, note.Slug, "", note.ManagementToken, nil).Code)
+
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.Empty(e.claimNotes(otherToks.AccessToken, note.ManagementToken))
+
+	httpResp := e.httpRequest(http.MethodDelete, "/api/v1/note/"+note.Slug, nil, toks.AccessToken)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Claim_invalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	tokens := make([]string, models.MaxNoteClaimTokens+1)
+	for i := range tokens {
+		tokens[i] = e.uuid()
+	}
+
+	for _, tokens := range [][]string{nil, tokens} {
+		httpResp := e.httpRequest(
+			http.MethodPost,
+			"/api/v1/note/claim",
+			e.jsonify(apiv1NoteClaimRequest{ManagementTokens: tokens}),
+			toks.AccessToken,
+		)
+		e.Equal(http.StatusBadRequest, httpResp.Code)
+
+		var body errorResponse
+		e.readBodyAndUnjsonify(httpResp.Body, &body)
+		e.Equal(models.ErrNoteClaimTokensInvalid.Error(), body.Message)
+	}
+}
+
+func (e *AppTestSuite) createAnonymousNote(inp apiv1NoteCreateRequest) apiv1NoteCreateResponse {
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(inp))
+	e.Require().Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	return body
+}
+
+func (e *AppTestSuite) manageNote(method, slug, path, token string, body []byte) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, "/api/v1/note/"+slug+"/manage"+path, bytes.NewBuffer(body))
+	e.require.NoError(err)
+
+	req.Header.Set("Content-type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Management-Token", token)
+	}
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+func (e *AppTestSuite) claimNotes(accessToken string, tokens ...string) []string {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note/claim",
+		e.jsonify(apiv1NoteClaimRequest{ManagementTokens: tokens}),
+		accessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteClaimResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	return body.Slugs
+}
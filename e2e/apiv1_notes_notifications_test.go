@@ -0,0 +1,184 @@
+package e2e_test
+
+import (
+	"net/http"
+	"time"
+)
+
+type apiV1NoteSetNotificationsRequest struct {
+	NotifyAuthor bool `json:"notify_author"`
+}
+
+func (e *AppTestSuite) TestNoteV1_Get_notifiesAuthor() {
+	email := e.randomEmail()
+	_, toks := e.createAndSingIn(email, e.uuid())
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:      e.uuid(),
+			MaxViews:     2,
+			NotifyAuthor: true,
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	// the author is notified only when the note is burnt
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Empty(mockMailStore[email])
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Equal("note_read:"+body.Slug, mockMailStore[email])
+}
+
+func (e *AppTestSuite) TestNoteV1_Get_notifiesAuthorAboutKeptNote() {
+	email := e.randomEmail()
+	_, toks := e.createAndSingIn(email, e.uuid())
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:              e.uuid(),
+			KeepBeforeExpiration: true,
+			ExpiresAt:            time.Now().Add(time.Hour),
+			NotifyAuthor:         true,
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	// the author is notified only about the first view
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Equal("note_read:"+body.Slug, mockMailStore[email])
+
+	delete(mockMailStore, email)
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Empty(mockMailStore[email])
+
+	// and it isn't considered unread once expired
+	e.expireNote(body.Slug)
+	_, err := e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+	e.Empty(mockMailStore[email])
+}
+
+func (e *AppTestSuite) TestNoteV1_Get_doesNotNotifyAuthorByDefault() {
+	email := e.randomEmail()
+	_, toks := e.createAndSingIn(email, e.uuid())
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: e.uuid()}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Empty(mockMailStore[email])
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_notifyAuthorUnauthorized() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:      e.uuid(),
+			NotifyAuthor: true,
+		}),
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdateNotifications() {
+	email := e.randomEmail()
+	_, toks := e.createAndSingIn(email, e.uuid())
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: e.uuid()}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	// other users cannot change it
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	httpResp = e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+body.Slug+"/notifications",
+		e.jsonify(apiV1NoteSetNotificationsRequest{NotifyAuthor: true}),
+		otherToks.AccessToken,
+	)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+body.Slug+"/notifications",
+		e.jsonify(apiV1NoteSetNotificationsRequest{NotifyAuthor: true}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.Equal("note_read:"+body.Slug, mockMailStore[email])
+}
+
+func (e *AppTestSuite) TestReaper_Reap_notifiesAuthorAboutExpiredNote() {
+	email := e.randomEmail()
+	_, toks := e.createAndSingIn(email, e.uuid())
+
+	createNote := func(maxViews int) string {
+		httpResp := e.httpRequest(
+			http.MethodPost,
+			"/api/v1/note",
+			e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:      e.uuid(),
+				MaxViews:     maxViews,
+				NotifyAuthor: true,
+				ExpiresAt:    time.Now().Add(time.Hour),
+			}),
+			toks.AccessToken,
+		)
+		e.Equal(http.StatusCreated, httpResp.Code)
+
+		var body apiv1NoteCreateResponse
+		e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+		return body.Slug
+	}
+
+	// the note that was viewed isn't considered unread
+	viewedSlug := createNote(2)
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+viewedSlug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+	e.expireNote(viewedSlug)
+
+	_, err := e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+	e.Empty(mockMailStore[email])
+
+	unreadSlug := createNote(1)
+	e.expireNote(unreadSlug)
+
+	_, err = e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+	e.Equal("note_expired:"+unreadSlug, mockMailStore[email])
+}
@@ -0,0 +1,224 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/olexsmir/onasty/internal/hasher"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_saltedPerNote() {
+	passwd := e.uuid()
+	first := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: passwd,
+	})
+	second := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: passwd,
+	})
+
+	firstHash := e.getNoteBySlug(first).Password
+	secondHash := e.getNoteBySlug(second).Password
+	e.True(strings.HasPrefix(firstHash, "$argon2id$"))
+	e.NotEqual(firstHash, secondHash)
+
+	e.Equal(http.StatusOK, e.viewNote(first, passwd).Code)
+	e.Equal(http.StatusOK, e.viewNote(second, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_legacyHash() {
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: e.uuid(),
+	})
+
+	legacyHash, err := hasher.NewSHA256Hasher(e.getConfig().NotePasswordSalt).Hash(passwd)
+	e.require.NoError(err)
+
+	_, err = e.postgresDB.Exec(e.ctx, "update notes set password = $1 where slug = $2", legacyHash, slug)
+	e.require.NoError(err)
+
+	e.Equal(http.StatusOK, e.viewNote(slug, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_lockout() {
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: passwd,
+	})
+
+	for range testNotePasswordMaxAttempts - 1 {
+		e.Equal(http.StatusNotFound, e.viewNote(slug, e.uuid()).Code)
+	}
+
+	httpResp := e.viewNote(slug, e.uuid())
+	e.Equal(http.StatusLocked, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteLocked.Error(), body.Message)
+
+	// even the right password is rejected until the lockout passes
+	e.Equal(http.StatusLocked, e.viewNote(slug, passwd).Code)
+	e.Empty(e.getNoteBySlug(slug).ReadAt)
+
+	e.passNoteLockout(slug)
+	e.Equal(http.StatusOK, e.viewNote(slug, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_lockoutConcurrently() {
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: passwd,
+	})
+
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		responses = make([]*httptest.ResponseRecorder, testNotePasswordMaxAttempts*4)
+	)
+
+	for i := range responses {
+		body := e.jsonify(apiv1NoteGetWithPasswordRequest{Password: e.uuid()})
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/note/"+slug+"/view", bytes.NewReader(body))
+			resp := httptest.NewRecorder()
+
+			<-start
+			e.router.ServeHTTP(resp, req)
+			responses[i] = resp
+		})
+	}
+
+	close(start)
+	wg.Wait()
+
+	// only as many passwords as allowed are compared, the rest are rejected as the note is locked
+	var notFound int
+	for _, resp := range responses {
+		if resp.Code == http.StatusNotFound {
+			notFound++
+			continue
+		}
+		e.Equal(http.StatusLocked, resp.Code)
+	}
+	e.Equal(testNotePasswordMaxAttempts-1, notFound)
+
+	e.Equal(http.StatusLocked, e.viewNote(slug, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_rightPasswordResetsAttempts() {
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: passwd,
+		MaxViews: 2,
+	})
+
+	for range testNotePasswordMaxAttempts - 1 {
+		e.Equal(http.StatusNotFound, e.viewNote(slug, e.uuid()).Code)
+	}
+
+	e.Equal(http.StatusOK, e.viewNote(slug, passwd).Code)
+
+	for range testNotePasswordMaxAttempts - 1 {
+		e.Equal(http.StatusNotFound, e.viewNote(slug, e.uuid()).Code)
+	}
+
+	e.Equal(http.StatusOK, e.viewNote(slug, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_GetWithPassword_burnOnLockout() {
+	passwd := e.uuid()
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:       e.uuid(),
+		Password:      passwd,
+		BurnOnLockout: true,
+	})
+
+	for range testNotePasswordMaxAttempts {
+		e.Equal(http.StatusNotFound, e.viewNote(slug, e.uuid()).Code)
+	}
+
+	dbNote := e.getNoteBySlug(slug)
+	e.Empty(dbNote.Content)
+	e.False(dbNote.ReadAt.IsZero())
+
+	// the note is burnt, so even the right password doesn't reveal its content
+	httpResp := e.viewNote(slug, passwd)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	var body apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Empty(body.Content)
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_burnOnLockoutWithoutPassword() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:       e.uuid(),
+			BurnOnLockout: true,
+		}),
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteBurnOnLockoutWithoutPassword.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestNoteV1_UpdatePassword_unlocksNote() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:  e.uuid(),
+		Password: e.uuid(),
+	}, toks.AccessToken)
+
+	for range testNotePasswordMaxAttempts {
+		e.viewNote(slug, e.uuid())
+	}
+
+	passwd := e.uuid()
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/password",
+		e.jsonify(apiV1NoteSetPasswordRequest{Password: passwd}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	e.Equal(http.StatusOK, e.viewNote(slug, passwd).Code)
+}
+
+func (e *AppTestSuite) createNoteWithPassword(inp apiv1NoteCreateRequest, accessToken ...string) string {
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(inp), accessToken...)
+	e.Require().Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body.Slug
+}
+
+// passNoteLockout resets the wrong passwords counter, as if the lockout has passed
+func (e *AppTestSuite) passNoteLockout(slug string) {
+	e.require.NoError(e.redisDB.Del(e.ctx, "noteattempts:"+slug).Err())
+}
+
+func (e *AppTestSuite) viewNote(slug, passwd string) *httptest.ResponseRecorder {
+	return e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note/"+slug+"/view",
+		e.jsonify(apiv1NoteGetWithPasswordRequest{Password: passwd}),
+	)
+}
@@ -0,0 +1,182 @@
+package e2e_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+func (e *AppTestSuite) TestNoteV1_CreateWithRecipients() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	content := e.uuid()
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:         content,
+			RecipientLabels: []string{"alice", "bob"},
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Require().Len(body.Recipients, 2)
+	e.Equal(body.Recipients[0].Slug, body.Slug)
+	e.Equal("alice", body.Recipients[0].Label)
+	e.Equal("bob", body.Recipients[1].Label)
+	e.NotEqual(body.Recipients[0].Slug, body.Recipients[1].Slug)
+
+	alice, bob := body.Recipients[0].Slug, body.Recipients[1].Slug
+
+	// each link is burnt independently of the others
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+alice, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var note apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &note)
+	e.Equal(content, note.Content)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+alice, nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	notes := e.listNotes(toks.AccessToken, url.Values{})
+	e.Equal(2, notes.Total)
+
+	var groupID string
+	for _, n := range notes.Notes {
+		e.NotEmpty(n.GroupID)
+		groupID = n.GroupID
+
+		switch n.Slug {
+		case alice:
+			e.Equal("alice", n.RecipientLabel)
+			e.False(n.ReadAt.IsZero())
+		case bob:
+			e.Equal("bob", n.RecipientLabel)
+			e.True(n.ReadAt.IsZero())
+		default:
+			e.Failf("unexpected note", "slug: %s", n.Slug)
+		}
+	}
+
+	e.Equal(1, e.countNoteContents(groupID))
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+bob, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	e.readBodyAndUnjsonify(httpResp.Body, &note)
+	e.Equal(content, note.Content)
+
+	// the content is erased once all of the links are burnt
+	e.Zero(e.countNoteContents(groupID))
+}
+
+func (e *AppTestSuite) TestNoteV1_CreateWithRecipients_count() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:    e.uuid(),
+			Recipients: 3,
+		}),
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Len(body.Recipients, 3)
+
+	for _, r := range body.Recipients {
+		e.Empty(r.Label)
+		e.Equal(http.StatusOK, e.httpRequest(http.MethodGet, "/api/v1/note/"+r.Slug, nil).Code)
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_CreateWithRecipients_invalid() {
+	labels := make([]string, models.MaxNoteRecipients+1)
+	for i := range labels {
+		labels[i] = "recipient-" + strconv.Itoa(i)
+	}
+
+	tests := []struct {
+		name string
+		inp  apiv1NoteCreateRequest
+		err  error
+	}{
+		{
+			name: "too many recipients",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				Recipients: models.MaxNoteRecipients + 1,
+			},
+			err: models.ErrNoteRecipientsInvalid,
+		},
+		{
+			name: "too many labels",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:         e.uuid(),
+				RecipientLabels: labels,
+			},
+			err: models.ErrNoteRecipientsInvalid,
+		},
+		{
+			name: "labels don't match the count",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:         e.uuid(),
+				Recipients:      3,
+				RecipientLabels: []string{"alice", "bob"},
+			},
+			err: models.ErrNoteRecipientsInvalid,
+		},
+		{
+			name: "label is too long",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:         e.uuid(),
+				RecipientLabels: []string{strings.Repeat("a", 256)},
+			},
+			err: models.ErrNoteRecipientLabelTooLong,
+		},
+		{
+			name: "custom slug",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				Slug:       e.uuid(),
+				Recipients: 2,
+			},
+			err: models.ErrNoteRecipientsWithSlug,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp))
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_CreateWithRecipients_cannotBeEdited() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		Recipients: 2,
+	}, toks.AccessToken)
+
+	version := e.getNoteVersion(toks.AccessToken, slug)
+
+	httpResp := e.updateNoteContent(toks.AccessToken, slug, e.uuid(), `W/"`+strconv.Itoa(version)+`"`)
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteRecipientsCannotBeEdited.Error(), body.Message)
+}
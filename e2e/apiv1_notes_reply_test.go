@@ -0,0 +1,202 @@
+package e2e_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type apiv1NoteReplyRequest struct {
+	Content  string `json:"content"`
+	Password string `json:"password,omitempty"`
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		AllowReply: true,
+	}, toks.AccessToken)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var note apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &note)
+	e.True(note.ReplyAllowed)
+
+	// the note is already burnt, but its reader can still reply
+	replyContent := e.uuid()
+	httpResp = e.replyToNote(slug, note.ReadToken, apiv1NoteReplyRequest{Content: replyContent}) //nolint:exhaustruct
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	replySlug := e.getNoteBySlug(slug).ReplySlug
+	e.NotEmpty(replySlug)
+
+	// the reply is owned by the author
+	notes := e.listNotes(toks.AccessToken, url.Values{})
+	e.Equal(2, notes.Total)
+	for _, n := range notes.Notes {
+		if n.Slug == slug {
+			e.True(n.AllowReply)
+			e.Equal(replySlug, n.ReplySlug)
+		}
+	}
+
+	// and it's burnt on read
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+replySlug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var reply apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &reply)
+	e.Equal(replyContent, reply.Content)
+	e.False(reply.ReplyAllowed)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+replySlug, nil)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_withPassword() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		AllowReply: true,
+	}, toks.AccessToken)
+
+	passwd := e.uuid()
+	httpResp := e.replyToNote(slug, e.getReadToken(slug), apiv1NoteReplyRequest{Content: e.uuid(), Password: passwd})
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	replySlug := e.getNoteBySlug(slug).ReplySlug
+	e.Equal(http.StatusNotFound, e.viewNote(replySlug, e.uuid()).Code)
+	e.Equal(http.StatusOK, e.viewNote(replySlug, passwd).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_onlyOnce() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		AllowReply: true,
+	}, toks.AccessToken)
+
+	readToken := e.getReadToken(slug)
+	httpResp := e.replyToNote(slug, readToken, apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusCreated, httpResp.Code)
+	replySlug := e.getNoteBySlug(slug).ReplySlug
+
+	httpResp = e.replyToNote(slug, readToken, apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteAlreadyReplied.Error(), body.Message)
+
+	e.Equal(replySlug, e.getNoteBySlug(slug).ReplySlug)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_notAllowed() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteAs(toks.AccessToken)
+
+	// notes that cannot be replied to aren't given the token
+	httpResp := e.replyToNote(slug, e.getReadToken(slug), apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+	e.Empty(e.getNoteBySlug(slug).ReplySlug)
+
+	httpResp = e.replyToNote(e.uuid(), "", apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_expiredUnread() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		AllowReply: true,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	}, toks.AccessToken)
+	e.expireNote(slug)
+
+	httpResp := e.replyToNote(slug, e.getReadToken(slug), apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_emptyContent() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		AllowReply: true,
+	}, toks.AccessToken)
+
+	httpResp := e.replyToNote(slug, e.getReadToken(slug), apiv1NoteReplyRequest{}) //nolint:exhaustruct
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+	e.Empty(e.getNoteBySlug(slug).ReplySlug)
+}
+
+func (e *AppTestSuite) TestNoteV1_Reply_withoutReadToken() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		Password:   e.uuid(),
+		AllowReply: true,
+	}, toks.AccessToken)
+
+	// the password isn't given, so the note isn't read, and it cannot be replied to
+	httpResp := e.replyToNote(slug, "", apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	// tokens of other notes don't work either
+	other := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:    e.uuid(),
+		AllowReply: true,
+	}, toks.AccessToken)
+	otherToken := e.getReadToken(other)
+	e.require.NotEmpty(otherToken)
+
+	httpResp = e.replyToNote(slug, otherToken, apiv1NoteReplyRequest{Content: e.uuid()}) //nolint:exhaustruct
+	e.Equal(http.StatusNotFound, httpResp.Code)
+	e.Empty(e.getNoteBySlug(slug).ReplySlug)
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_allowReplyWithoutAuthor() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:    e.uuid(),
+			AllowReply: true,
+		}),
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteReplyWithoutAuthor.Error(), body.Message)
+}
+
+func (e *AppTestSuite) replyToNote(
+	slug, readToken string,
+	inp apiv1NoteReplyRequest,
+) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/note/"+slug+"/reply", bytes.NewReader(e.jsonify(inp)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Read-Token", readToken)
+
+	resp := httptest.NewRecorder()
+	e.router.ServeHTTP(resp, req)
+
+	return resp
+}
+
+// getReadToken reads the note, and returns the read token given along with its content
+func (e *AppTestSuite) getReadToken(slug string) string {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+
+	var note apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &note)
+
+	return note.ReadToken
+}
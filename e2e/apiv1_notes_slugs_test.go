@@ -0,0 +1,139 @@
+package e2e_test
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/sluggen"
+)
+
+func (e *AppTestSuite) TestNoteV1_Create_slugStrategies() {
+	tests := []struct {
+		name   string
+		inp    apiv1NoteCreateRequest
+		assert func(slug string)
+	}{
+		{
+			name: "default",
+			inp:  apiv1NoteCreateRequest{}, //nolint:exhaustruct
+			assert: func(slug string) {
+				_, err := uuid.FromString(slug)
+				e.require.NoError(err)
+			},
+		},
+		{
+			name: "base62 with length",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyBase62),
+				SlugLength:   20,
+			},
+			assert: func(slug string) {
+				e.Regexp("^[0-9A-Za-z]{20}$", slug)
+			},
+		},
+		{
+			name: "words",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyWords),
+			},
+			assert: func(slug string) {
+				e.Len(strings.Split(slug, "-"), e.getConfig().SlugWordsCount)
+			},
+		},
+		{
+			name: "words with length",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyWords),
+				SlugLength:   10,
+			},
+			assert: func(slug string) {
+				e.Len(strings.Split(slug, "-"), 10)
+			},
+		},
+		{
+			name: "uuid",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyUUID),
+			},
+			assert: func(slug string) {
+				_, err := uuid.FromString(slug)
+				e.require.NoError(err)
+			},
+		},
+		{
+			name: "custom slug ignores strategy",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Slug:         "custom-" + e.uuid(),
+				SlugStrategy: string(sluggen.StrategyWords),
+			},
+			assert: func(slug string) {
+				e.True(strings.HasPrefix(slug, "custom-"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			tt.inp.Content = e.uuid()
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp))
+			e.Equal(http.StatusCreated, httpResp.Code)
+
+			var body apiv1NoteCreateResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+			tt.assert(body.Slug)
+			e.NotEmpty(e.getNoteBySlug(body.Slug))
+		})
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_slugStrategyInvalid() {
+	tests := []struct {
+		name string
+		inp  apiv1NoteCreateRequest
+		err  error
+	}{
+		{
+			name: "unknown strategy",
+			inp:  apiv1NoteCreateRequest{SlugStrategy: "emoji"}, //nolint:exhaustruct
+			err:  sluggen.ErrStrategyUnknown,
+		},
+		{
+			name: "too short base62",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyBase62),
+				SlugLength:   4,
+			},
+			err: sluggen.ErrEntropyTooLow,
+		},
+		{
+			name: "too few words",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyWords),
+				SlugLength:   2,
+			},
+			err: sluggen.ErrEntropyTooLow,
+		},
+		{
+			name: "uuid with length",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				SlugStrategy: string(sluggen.StrategyUUID),
+				SlugLength:   8,
+			},
+			err: sluggen.ErrLengthInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			tt.inp.Content = e.uuid()
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp))
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Contains(body.Message, tt.err.Error())
+		})
+	}
+}
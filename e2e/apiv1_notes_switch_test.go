@@ -0,0 +1,240 @@
+package e2e_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type (
+	apiv1NoteSwitchRequest struct {
+		CheckInIntervalHours int      `json:"check_in_interval_hours"`
+		Recipients           []string `json:"recipients"`
+	}
+	apiv1NoteCheckInResponse struct {
+		ReleaseAt time.Time `json:"release_at"`
+	}
+)
+
+func (e *AppTestSuite) TestNoteV1_DeadManSwitch() {
+	email := e.randomEmail()
+	_, toks := e.createAndSingIn(email, e.uuid())
+	recipients := []string{e.randomEmail(), e.randomEmail()}
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content: e.uuid(),
+		DeadManSwitch: &apiv1NoteSwitchRequest{
+			CheckInIntervalHours: 24,
+			Recipients:           recipients,
+		},
+	}, toks.AccessToken)
+
+	// the note cannot be read before it's released
+	e.Equal(http.StatusTooEarly, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/check-in", nil, toks.AccessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1NoteCheckInResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.WithinDuration(time.Now().Add(24*time.Hour), body.ReleaseAt, time.Minute)
+
+	// the author is reminded before the note is released
+	e.moveNoteSwitchCheckIn(slug, 23*time.Hour)
+	_, err := e.deadman.Check(e.ctx)
+	e.require.NoError(err)
+
+	e.Equal("note_switch_reminder:"+slug, mockMailStore[email])
+	for _, r := range recipients {
+		e.Empty(mockMailStore[r])
+	}
+
+	// the note is released once the author misses the check-in
+	e.moveNoteSwitchCheckIn(slug, 25*time.Hour)
+	_, err = e.deadman.Check(e.ctx)
+	e.require.NoError(err)
+
+	for _, r := range recipients {
+		e.Equal("note_released:"+slug, mockMailStore[r])
+	}
+
+	// every recipient can read it
+	e.Equal(http.StatusOK, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+	e.Equal(http.StatusOK, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+	e.Equal(http.StatusNotFound, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_DeadManSwitch_checkInAfterRelease() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content: e.uuid(),
+		DeadManSwitch: &apiv1NoteSwitchRequest{
+			CheckInIntervalHours: 1,
+			Recipients:           []string{e.randomEmail()},
+		},
+	}, toks.AccessToken)
+
+	e.moveNoteSwitchCheckIn(slug, 2*time.Hour)
+	_, err := e.deadman.Check(e.ctx)
+	e.require.NoError(err)
+
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/check-in", nil, toks.AccessToken)
+	e.Equal(http.StatusConflict, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteSwitchReleased.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestNoteV1_DeadManSwitch_availableFromCannotBeChanged() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content: e.uuid(),
+		DeadManSwitch: &apiv1NoteSwitchRequest{
+			CheckInIntervalHours: 24,
+			Recipients:           []string{e.randomEmail()},
+		},
+	}, toks.AccessToken)
+
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/expires",
+		e.jsonify(apiv1NoteAvailabilityPatchRequest{AvailableFrom: time.Time{}}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrNoteSwitchWithAvailableFrom.Error(), body.Message)
+
+	// the note is still available only once the switch is released
+	e.Equal(http.StatusTooEarly, e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil).Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_DeadManSwitch_checkInWithoutSwitch() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}, toks.AccessToken) //nolint:exhaustruct
+
+	httpResp := e.httpRequest(http.MethodPost, "/api/v1/note/"+slug+"/check-in", nil, toks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestNoteV1_DeadManSwitch_invalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	tests := []struct {
+		name   string
+		inp    apiv1NoteCreateRequest
+		tokens []string
+		err    error
+	}{
+		{
+			name: "anonymous",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content: e.uuid(),
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			tokens: nil,
+			err:    models.ErrNoteSwitchWithoutAuthor,
+		},
+		{
+			name: "interval is too short",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content: e.uuid(),
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 0,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteSwitchIntervalInvalid,
+		},
+		{
+			name: "no recipients",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:       e.uuid(),
+				DeadManSwitch: &apiv1NoteSwitchRequest{CheckInIntervalHours: 24, Recipients: nil},
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteSwitchRecipientsInvalid,
+		},
+		{
+			name: "recipient is not an email",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content: e.uuid(),
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{"not an email"},
+				},
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteSwitchRecipientsInvalid,
+		},
+		{
+			name: "with available from",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:       e.uuid(),
+				AvailableFrom: time.Now().Add(time.Hour),
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteSwitchWithAvailableFrom,
+		},
+		{
+			name: "with recipients",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:    e.uuid(),
+				Recipients: 2,
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteSwitchWithRecipients,
+		},
+		{
+			name: "released after expiration",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:   e.uuid(),
+				ExpiresAt: time.Now().Add(time.Hour),
+				DeadManSwitch: &apiv1NoteSwitchRequest{
+					CheckInIntervalHours: 24,
+					Recipients:           []string{e.randomEmail()},
+				},
+			},
+			tokens: []string{toks.AccessToken},
+			err:    models.ErrNoteAvailabilityAfterExpiration,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp), tt.tokens...)
+			e.Equal(http.StatusBadRequest, httpResp.Code)
+
+			var body errorResponse
+			e.readBodyAndUnjsonify(httpResp.Body, &body)
+			e.Equal(tt.err.Error(), body.Message)
+		})
+	}
+}
+
+// moveNoteSwitchCheckIn moves the last check-in of note's dead man's switch to the past, as if ago has passed
+func (e *AppTestSuite) moveNoteSwitchCheckIn(slug string, ago time.Duration) {
+	_, err := e.postgresDB.Exec(e.ctx, `--sql
+update note_switches sw
+set last_check_in_at = $1
+from notes n
+where n.id = sw.note_id
+  and n.slug = $2`,
+		time.Now().Add(-ago), slug)
+	e.require.NoError(err)
+}
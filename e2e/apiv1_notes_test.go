@@ -1,26 +1,53 @@
 package e2e_test
 
 import (
+	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"testing/synctest"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/envelope"
 	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
+	"github.com/olexsmir/onasty/pkg/notecrypt"
 )
 
 type (
 	apiv1NoteCreateRequest struct {
-		Content              string    `json:"content"`
-		Slug                 string    `json:"slug"`
-		Password             string    `json:"password"`
-		KeepBeforeExpiration bool      `json:"keep_before_expiration"`
-		ExpiresAt            time.Time `json:"expires_at"`
+		Content              string                  `json:"content"`
+		Slug                 string                  `json:"slug"`
+		SlugStrategy         string                  `json:"slug_strategy,omitempty"`
+		SlugLength           int                     `json:"slug_length,omitempty"`
+		Password             string                  `json:"password"`
+		KeepBeforeExpiration bool                    `json:"keep_before_expiration"`
+		EncryptionScheme     string                  `json:"encryption_scheme,omitempty"`
+		EncryptionVersion    int                     `json:"encryption_version,omitempty"`
+		MaxViews             int                     `json:"max_views,omitempty"`
+		NotifyAuthor         bool                    `json:"notify_author,omitempty"`
+		BurnOnLockout        bool                    `json:"burn_on_lockout,omitempty"`
+		AllowReply           bool                    `json:"allow_reply,omitempty"`
+		Recipients           int                     `json:"recipients,omitempty"`
+		RecipientLabels      []string                `json:"recipient_labels,omitempty"`
+		ExpiresAt            time.Time               `json:"expires_at"`
+		AvailableFrom        time.Time               `json:"available_from,omitzero"`
+		DeadManSwitch        *apiv1NoteSwitchRequest `json:"dead_man_switch,omitempty"`
+		RecipientEmail       string                  `json:"recipient_email,omitempty"`
+		Message              string                  `json:"message,omitempty"`
+		BoundEmail           string                  `json:"bound_email,omitempty"`
+		InboxEmail           string                  `json:"inbox_email,omitempty"`
+		RequiresApproval     bool                    `json:"requires_approval,omitempty"`
 	}
 	apiv1NoteCreateResponse struct {
-		Slug string `json:"slug"`
+		Slug       string `json:"slug"`
+		Recipients []struct {
+			Label string `json:"label"`
+			Slug  string `json:"slug"`
+		} `json:"recipients"`
+		ManagementToken string `json:"management_token"`
 	}
 )
 
@@ -189,10 +216,15 @@ func (e *AppTestSuite) TestNoteV1_Create() {
 }
 
 type apiv1NoteGetResponse struct {
-	Content   string     `json:"content"`
-	ReadAt    *time.Time `json:"read_at"`
-	CreatedAt time.Time  `json:"created_at"`
-	ExpiresAt time.Time  `json:"expires_at"`
+	Content           string                        `json:"content"`
+	ReadAt            *time.Time                    `json:"read_at"`
+	EncryptionScheme  string                        `json:"encryption_scheme"`
+	EncryptionVersion int                           `json:"encryption_version"`
+	CreatedAt         time.Time                     `json:"created_at"`
+	ExpiresAt         time.Time                     `json:"expires_at"`
+	ReplyAllowed      bool                          `json:"reply_allowed"`
+	Attachments       []apiv1NoteAttachmentResponse `json:"attachments"`
+	ReadToken         string                        `json:"read_token"`
 }
 
 func (e *AppTestSuite) TestNoteV1_Get() {
@@ -266,6 +298,335 @@ func (e *AppTestSuite) TestNoteV1_Get_alreadyRead() {
 	e.Equal(dbNote2.ExpiresAt.Unix(), bodyRead2.ExpiresAt.Unix())
 }
 
+func (e *AppTestSuite) TestNoteV1_Get_concurrentReaders() {
+	// create note
+	content := e.uuid()
+	httpRespCreated := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: content}), //nolint:exhaustruct
+	)
+	e.Equal(http.StatusCreated, httpRespCreated.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpRespCreated.Body, &bodyCreated)
+
+	e.Equal(1, e.readNoteConcurrently(bodyCreated.Slug, content, 16))
+
+	dbNote := e.getNoteBySlug(bodyCreated.Slug)
+	e.Empty(dbNote.Content)
+	e.False(dbNote.ReadAt.IsZero())
+}
+
+// readNoteConcurrently reads the note by many readers at the same time,
+// and returns how many of them got its content.
+func (e *AppTestSuite) readNoteConcurrently(slug, content string, readers int) int {
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		responses = make([]*httptest.ResponseRecorder, readers)
+	)
+
+	for i := range readers {
+		wg.Go(func() {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+			resp := httptest.NewRecorder()
+
+			<-start
+			e.router.ServeHTTP(resp, req)
+			responses[i] = resp
+		})
+	}
+
+	close(start)
+	wg.Wait()
+
+	var gotContent int
+	for _, resp := range responses {
+		if resp.Code != http.StatusOK {
+			e.Equal(http.StatusNotFound, resp.Code)
+			continue
+		}
+
+		var body apiv1NoteGetResponse
+		e.readBodyAndUnjsonify(resp.Body, &body)
+		e.Equal(content, body.Content)
+		gotContent++
+	}
+
+	return gotContent
+}
+
+func (e *AppTestSuite) TestNoteV1_Get_maxViews() {
+	const maxViews = 3
+
+	content := e.uuid()
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:  content,
+			MaxViews: maxViews,
+		}),
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	for viewsLeft := maxViews; viewsLeft > 0; viewsLeft-- {
+		metaResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug+"/meta", nil)
+		e.Equal(http.StatusOK, metaResp.Code)
+
+		var metadata apiv1NoteMetadataResponse
+		e.readBodyAndUnjsonify(metaResp.Body, &metadata)
+		e.Equal(viewsLeft, metadata.ViewsLeft)
+
+		readResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+		e.Equal(http.StatusOK, readResp.Code)
+
+		var body apiv1NoteGetResponse
+		e.readBodyAndUnjsonify(readResp.Body, &body)
+		e.Equal(content, body.Content)
+	}
+
+	// all views are used
+	readResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+	e.Equal(http.StatusNotFound, readResp.Code)
+
+	dbNote := e.getNoteBySlug(bodyCreated.Slug)
+	e.Empty(dbNote.Content)
+	e.False(dbNote.ReadAt.IsZero())
+}
+
+func (e *AppTestSuite) TestNoteV1_Get_maxViewsConcurrentReaders() {
+	const maxViews = 3
+
+	content := e.uuid()
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:  content,
+			MaxViews: maxViews,
+		}),
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyCreated)
+
+	e.Equal(maxViews, e.readNoteConcurrently(bodyCreated.Slug, content, 16))
+
+	dbNote := e.getNoteBySlug(bodyCreated.Slug)
+	e.Empty(dbNote.Content)
+	e.False(dbNote.ReadAt.IsZero())
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_maxViewsInvalid() {
+	tests := []struct {
+		name string
+		inp  apiv1NoteCreateRequest
+		err  error
+	}{
+		{
+			name: "negative",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:  e.uuid(),
+				MaxViews: -1,
+			},
+			err: models.ErrNoteMaxViewsIsInvalid,
+		},
+		{
+			name: "kept before expiration",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:              e.uuid(),
+				MaxViews:             3,
+				KeepBeforeExpiration: true,
+				ExpiresAt:            time.Now().Add(time.Hour),
+			},
+			err: models.ErrNoteCannotBeKeptWithMaxViews,
+		},
+	}
+
+	for _, tt := range tests {
+		httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp))
+		e.Equal(http.StatusBadRequest, httpResp.Code, tt.name)
+
+		var body errorResponse
+		e.readBodyAndUnjsonify(httpResp.Body, &body)
+		e.Equal(tt.err.Error(), body.Message, tt.name)
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Get_encrypted() {
+	key, err := notecrypt.GenerateKey()
+	e.require.NoError(err)
+
+	content := e.uuid()
+	ciphertext, err := notecrypt.Encrypt(key, []byte(content))
+	e.require.NoError(err)
+
+	// create note
+	httpRespCreated := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:           ciphertext,
+			EncryptionScheme:  notecrypt.Scheme,
+			EncryptionVersion: notecrypt.Version,
+		}),
+	)
+	e.Equal(http.StatusCreated, httpRespCreated.Code)
+
+	var bodyCreated apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpRespCreated.Body, &bodyCreated)
+
+	dbNote := e.getNoteBySlug(bodyCreated.Slug)
+	e.NotContains(dbNote.Content, content)
+
+	// get metadata
+	metaResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug+"/meta", nil)
+	e.Equal(http.StatusOK, metaResp.Code)
+
+	var metadata apiv1NoteMetadataResponse
+	e.readBodyAndUnjsonify(metaResp.Body, &metadata)
+	e.Equal(notecrypt.Scheme, metadata.EncryptionScheme)
+
+	// read note
+	httpRespRead := e.httpRequest(http.MethodGet, "/api/v1/note/"+bodyCreated.Slug, nil)
+	e.Equal(http.StatusOK, httpRespRead.Code)
+
+	var bodyRead apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpRespRead.Body, &bodyRead)
+
+	e.Equal(notecrypt.Scheme, bodyRead.EncryptionScheme)
+	e.Equal(notecrypt.Version, bodyRead.EncryptionVersion)
+
+	decrypted, err := notecrypt.Decrypt(key, bodyRead.Content)
+	e.require.NoError(err)
+	e.Equal(content, string(decrypted))
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_encryptedInvalid() {
+	tests := []struct {
+		name string
+		inp  apiv1NoteCreateRequest
+		err  error
+	}{
+		{
+			name: "plaintext content",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:           "not encrypted content",
+				EncryptionScheme:  notecrypt.Scheme,
+				EncryptionVersion: notecrypt.Version,
+			},
+			err: models.ErrNoteContentIsNotCiphertext,
+		},
+		{
+			name: "unknown scheme",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:           e.uuid(),
+				EncryptionScheme:  "rot13",
+				EncryptionVersion: 1,
+			},
+			err: models.ErrNoteEncryptionSchemeUnsupported,
+		},
+		{
+			name: "unknown version",
+			inp: apiv1NoteCreateRequest{ //nolint:exhaustruct
+				Content:           e.uuid(),
+				EncryptionScheme:  notecrypt.Scheme,
+				EncryptionVersion: notecrypt.Version + 1,
+			},
+			err: models.ErrNoteEncryptionSchemeUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		httpResp := e.httpRequest(http.MethodPost, "/api/v1/note", e.jsonify(tt.inp))
+		e.Equal(http.StatusBadRequest, httpResp.Code, tt.name)
+
+		var body errorResponse
+		e.readBodyAndUnjsonify(httpResp.Body, &body)
+		e.Equal(tt.err.Error(), body.Message, tt.name)
+	}
+}
+
+func (e *AppTestSuite) TestNoteV1_Create_encryptedAtRest() {
+	content := e.uuid()
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content: content,
+		}),
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	contentAtRest, keyID := e.getNoteContentAtRestBySlug(body.Slug)
+	e.NotContains(contentAtRest, content)
+	e.Equal(testNoteKeyID, keyID)
+
+	// read note
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+body.Slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var bodyRead apiv1NoteGetResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &bodyRead)
+	e.Equal(content, bodyRead.Content)
+
+	contentAtRest, keyID = e.getNoteContentAtRestBySlug(body.Slug)
+	e.Empty(contentAtRest)
+	e.Empty(keyID)
+}
+
+func (e *AppTestSuite) TestNoteRepo_ReencryptContent() {
+	content := e.uuid()
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content: content,
+		}),
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	// note stored before encryption was enabled
+	legacyContent := e.uuid()
+	legacySlug := e.insertNote(legacyContent, time.Time{}, time.Time{})
+
+	rotatedKeys, err := envelope.NewKeyRing(testNoteRotatedKeyID, map[string][]byte{
+		testNoteKeyID:        bytes.Repeat([]byte{1}, envelope.KeySize),
+		testNoteRotatedKeyID: bytes.Repeat([]byte{2}, envelope.KeySize),
+	})
+	e.require.NoError(err)
+
+	repo := noterepo.New(e.postgresDB, envelope.New(rotatedKeys))
+	reencrypted, err := repo.ReencryptContent(e.ctx, 2)
+	e.require.NoError(err)
+	e.GreaterOrEqual(reencrypted, int64(2))
+
+	for slug, expectedContent := range map[string]string{body.Slug: content, legacySlug: legacyContent} {
+		contentAtRest, keyID := e.getNoteContentAtRestBySlug(slug)
+		e.Equal(testNoteRotatedKeyID, keyID)
+		e.NotContains(contentAtRest, expectedContent)
+
+		e.Equal(expectedContent, e.getNoteBySlug(slug).Content)
+	}
+
+	// nothing left to re-encrypt
+	reencrypted, err = repo.ReencryptContent(e.ctx, 2)
+	e.require.NoError(err)
+	e.Zero(reencrypted)
+}
+
 func (e *AppTestSuite) TestNoteV1_Get_ShouldNotBeKeptBeforeExpiration() {
 	// create note
 	content := e.uuid()
@@ -460,8 +821,12 @@ func (e *AppTestSuite) TestNoteV1_GetWithPassword_wrong() {
 }
 
 type apiv1NoteMetadataResponse struct {
-	CreatedAt   time.Time `json:"created_at"`
-	HasPassword bool      `json:"has_password"`
+	CreatedAt        time.Time `json:"created_at"`
+	HasPassword      bool      `json:"has_password"`
+	EncryptionScheme string    `json:"encryption_scheme"`
+	ViewsLeft        int       `json:"views_left"`
+	CodeRequired     bool      `json:"code_required"`
+	ApprovalRequired bool      `json:"approval_required"`
 }
 
 func (e *AppTestSuite) TestNoteV1_GetMetadata() {
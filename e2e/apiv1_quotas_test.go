@@ -0,0 +1,344 @@
+package e2e_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psql/quotarepo"
+)
+
+type getMeQuotaResponse struct {
+	Tier   string `json:"tier"`
+	Limits struct {
+		MaxContentBytes     int   `json:"max_content_bytes"`
+		NotesPerDay         int   `json:"notes_per_day"`
+		MaxActiveNotes      int   `json:"max_active_notes"`
+		MaxExpiresInSeconds int64 `json:"max_expires_in_seconds"`
+	} `json:"limits"`
+	Usage struct {
+		NotesToday  int       `json:"notes_today"`
+		ActiveNotes int       `json:"active_notes"`
+		ResetsAt    time.Time `json:"resets_at"`
+	} `json:"usage"`
+}
+
+func (e *AppTestSuite) TestQuota_anonymousContentTooLarge() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content: strings.Repeat("a", testQuotaAnonymousMaxContentSizeKb*1024+1),
+		}),
+	)
+	e.Equal(http.StatusRequestEntityTooLarge, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrQuotaContentTooLarge.Error(), body.Message)
+
+	httpResp = e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content: strings.Repeat("a", testQuotaAnonymousMaxContentSizeKb*1024),
+		}),
+	)
+	e.Equal(http.StatusCreated, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestQuota_customContentTooLarge() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{MaxContentBytes: ptr(8)}) //nolint:exhaustruct
+
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: "123456789"}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusRequestEntityTooLarge, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestQuota_notesPerDay() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{NotesPerDay: ptr(2)}) //nolint:exhaustruct
+
+	slug := e.createNoteAs(toks.AccessToken)
+	e.createNoteAs(toks.AccessToken)
+
+	// deleted notes are still counted
+	httpResp := e.httpRequest(http.MethodDelete, "/api/v1/note/"+slug, nil, toks.AccessToken)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	httpResp = e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: e.uuid()}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusTooManyRequests, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrQuotaDailyNotesExceeded.Error(), body.Message)
+}
+
+func (e *AppTestSuite) TestQuota_notesPerDay_rejectedNotesAreNotCounted() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{NotesPerDay: ptr(2)}) //nolint:exhaustruct
+
+	slug := e.createNoteAs(toks.AccessToken)
+
+	// the slug is already in use, so the note isn't created
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: e.uuid(), Slug: slug}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	me := e.getMeQuota(toks.AccessToken)
+	e.Equal(1, me.Usage.NotesToday)
+
+	e.createNoteAs(toks.AccessToken)
+}
+
+func (e *AppTestSuite) TestQuota_activeNotes() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{MaxActiveNotes: ptr(1)}) //nolint:exhaustruct
+
+	slug := e.createNoteAs(toks.AccessToken)
+
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{Content: e.uuid()}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusTooManyRequests, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrQuotaActiveNotesExceeded.Error(), body.Message)
+
+	// once the note is read, it's no longer active
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	e.createNoteAs(toks.AccessToken)
+}
+
+func (e *AppTestSuite) TestQuota_activeNotesConcurrentCreators() {
+	const (
+		maxActiveNotes = 2
+		creators       = 8
+	)
+
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{MaxActiveNotes: ptr(maxActiveNotes)}) //nolint:exhaustruct
+
+	var (
+		wg        sync.WaitGroup
+		start     = make(chan struct{})
+		responses = make([]*httptest.ResponseRecorder, creators)
+	)
+
+	for i := range creators {
+		body := e.jsonify(apiv1NoteCreateRequest{Content: e.uuid()}) //nolint:exhaustruct
+		wg.Go(func() {
+			<-start
+			responses[i] = e.httpRequest(http.MethodPost, "/api/v1/note", body, toks.AccessToken)
+		})
+	}
+
+	close(start)
+	wg.Wait()
+
+	var created int
+	for _, resp := range responses {
+		if resp.Code == http.StatusCreated {
+			created++
+			continue
+		}
+
+		e.Equal(http.StatusTooManyRequests, resp.Code)
+	}
+
+	e.Equal(maxActiveNotes, created)
+	e.Equal(maxActiveNotes, e.getMeQuota(toks.AccessToken).Usage.ActiveNotes)
+}
+
+func (e *AppTestSuite) TestQuota_maxExpiresIn() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{MaxExpiresInSeconds: ptr(int64(2 * 60 * 60))}) //nolint:exhaustruct
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		status    int
+		err       error
+	}{
+		{
+			name:      "without expiration",
+			expiresAt: time.Time{},
+			status:    http.StatusBadRequest,
+			err:       models.ErrQuotaExpirationIsRequired,
+		},
+		{
+			name:      "too far",
+			expiresAt: time.Now().Add(3 * time.Hour),
+			status:    http.StatusBadRequest,
+			err:       models.ErrQuotaExpirationTooFar,
+		},
+		{
+			name:      "within limit",
+			expiresAt: time.Now().Add(time.Hour),
+			status:    http.StatusCreated,
+			err:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		e.Run(tt.name, func() {
+			httpResp := e.httpRequest(
+				http.MethodPost,
+				"/api/v1/note",
+				e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+					Content:   e.uuid(),
+					ExpiresAt: tt.expiresAt,
+				}),
+				toks.AccessToken,
+			)
+			e.Equal(tt.status, httpResp.Code)
+
+			if tt.err != nil {
+				var body errorResponse
+				e.readBodyAndUnjsonify(httpResp.Body, &body)
+				e.Equal(tt.err.Error(), body.Message)
+			}
+		})
+	}
+}
+
+func (e *AppTestSuite) TestQuota_maxExpiresIn_update() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.overrideQuota(uid, quotaOverride{MaxExpiresInSeconds: ptr(int64(2 * 60 * 60))}) //nolint:exhaustruct
+
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{ //nolint:exhaustruct
+		Content:   e.uuid(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, toks.AccessToken)
+
+	// the note cannot be made to expire further than the quota allows once it's created
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/note/"+slug+"/expires",
+		e.jsonify(apiV1NotePatchRequest{ //nolint:exhaustruct
+			ExpiresAt: time.Now().Add(3 * time.Hour),
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	var body errorResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrQuotaExpirationTooFar.Error(), body.Message)
+
+	httpResp = e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note/bulk",
+		e.jsonify(apiv1NotesBulkRequest{ //nolint:exhaustruct
+			Action:    string(models.NoteBulkActionSetExpiration),
+			Slugs:     []string{slug},
+			ExpiresAt: time.Now().Add(3 * time.Hour),
+		}),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+	e.Equal(models.ErrQuotaExpirationTooFar.Error(), body.Message)
+
+	dbNote := e.getNoteBySlug(slug)
+	e.True(dbNote.ExpiresAt.Before(time.Now().Add(2 * time.Hour)))
+}
+
+func (e *AppTestSuite) TestQuota_getMe() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+
+	me := e.getMeQuota(toks.AccessToken)
+	e.Equal(string(models.QuotaTierRegistered), me.Tier)
+	e.Positive(me.Limits.MaxContentBytes)
+	e.Positive(me.Limits.NotesPerDay)
+	e.Positive(me.Limits.MaxActiveNotes)
+	e.Zero(me.Usage.NotesToday)
+	e.Zero(me.Usage.ActiveNotes)
+	e.True(me.Usage.ResetsAt.After(time.Now()))
+
+	slug := e.createNoteAs(toks.AccessToken)
+	e.createNoteAs(toks.AccessToken)
+	e.expireNote(slug)
+
+	e.overrideQuota(uid, quotaOverride{NotesPerDay: ptr(10)}) //nolint:exhaustruct
+
+	me = e.getMeQuota(toks.AccessToken)
+	e.Equal(string(models.QuotaTierCustom), me.Tier)
+	e.Equal(10, me.Limits.NotesPerDay)
+	e.Equal(2, me.Usage.NotesToday)
+	e.Equal(1, me.Usage.ActiveNotes)
+}
+
+func (e *AppTestSuite) TestQuota_setOverride() {
+	uid, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	quotarepo := quotarepo.New(e.postgresDB)
+
+	err := quotarepo.SetOverride(e.ctx, models.QuotaOverride{ //nolint:exhaustruct
+		UserID:      uid,
+		NotesPerDay: ptr(10),
+	})
+	e.require.NoError(err)
+
+	// the override is replaced, limits that aren't given are inherited again
+	err = quotarepo.SetOverride(e.ctx, models.QuotaOverride{ //nolint:exhaustruct
+		UserID:       uid,
+		MaxExpiresIn: ptr(time.Hour),
+	})
+	e.require.NoError(err)
+
+	me := e.getMeQuota(toks.AccessToken)
+	e.Equal(string(models.QuotaTierCustom), me.Tier)
+	e.Equal(int64(time.Hour.Seconds()), me.Limits.MaxExpiresInSeconds)
+	e.NotEqual(10, me.Limits.NotesPerDay)
+
+	e.require.NoError(quotarepo.DeleteOverrideByUserID(e.ctx, uid))
+	e.require.ErrorIs(quotarepo.DeleteOverrideByUserID(e.ctx, uid), models.ErrQuotaOverrideNotFound)
+
+	me = e.getMeQuota(toks.AccessToken)
+	e.Equal(string(models.QuotaTierRegistered), me.Tier)
+
+	err = quotarepo.SetOverride(e.ctx, models.QuotaOverride{ //nolint:exhaustruct
+		UserID:      uuid.Must(uuid.NewV4()),
+		NotesPerDay: ptr(10),
+	})
+	e.require.ErrorIs(err, models.ErrUserNotFound)
+}
+
+func (e *AppTestSuite) getMeQuota(accessToken string) getMeQuotaResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/me", nil, accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body struct {
+		Quota getMeQuotaResponse `json:"quota"`
+	}
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body.Quota
+}
+
+func ptr[T any](v T) *T { return &v }
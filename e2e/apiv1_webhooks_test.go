@@ -0,0 +1,466 @@
+package e2e_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	apiv1WebhookCreateRequest struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+	apiv1WebhookUpdateRequest struct {
+		URL    *string  `json:"url,omitempty"`
+		Events []string `json:"events,omitempty"`
+	}
+	apiv1WebhookResponse struct {
+		ID        uuid.UUID `json:"id"`
+		URL       string    `json:"url"`
+		Events    []string  `json:"events"`
+		Secret    string    `json:"secret"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	apiv1WebhookDeliveryResponse struct {
+		ID             uuid.UUID `json:"id"`
+		Event          string    `json:"event"`
+		Payload        string    `json:"payload"`
+		Status         string    `json:"status"`
+		Attempts       int       `json:"attempts"`
+		ResponseStatus int       `json:"response_status"`
+		Error          string    `json:"error"`
+		NextAttemptAt  time.Time `json:"next_attempt_at"`
+	}
+)
+
+func (e *AppTestSuite) TestWebhookV1_Create() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	created := e.createWebhook(toks.AccessToken, "https://example.com/hooks", "note.read", "note.deleted")
+
+	e.NotEmpty(created.ID)
+	e.True(strings.HasPrefix(created.Secret, "whsec_"))
+	e.Equal("https://example.com/hooks", created.URL)
+	e.Equal([]string{"note.read", "note.deleted"}, created.Events)
+
+	// the secret is returned only once
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/webhooks", nil, toks.AccessToken)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	var webhooks []apiv1WebhookResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &webhooks)
+	e.Require().Len(webhooks, 1)
+	e.Equal(created.ID, webhooks[0].ID)
+	e.Empty(webhooks[0].Secret)
+
+	webhook := e.getWebhook(toks.AccessToken, created.ID)
+	e.Equal(created.URL, webhook.URL)
+	e.Empty(webhook.Secret)
+}
+
+func (e *AppTestSuite) TestWebhookV1_Create_invalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	tests := []struct {
+		name   string
+		url    string
+		events []string
+	}{
+		{name: "invalid url", url: "example.com/hooks", events: []string{"note.read"}},
+		{name: "unsupported scheme", url: "ftp://example.com/hooks", events: []string{"note.read"}},
+		{name: "no events", url: "https://example.com/hooks", events: nil},
+		{name: "unknown event", url: "https://example.com/hooks", events: []string{"note.burnt"}},
+	}
+	for _, tt := range tests {
+		httpResp := e.httpRequest(
+			http.MethodPost,
+			"/api/v1/webhooks",
+			e.jsonify(apiv1WebhookCreateRequest{URL: tt.url, Events: tt.events}),
+			toks.AccessToken,
+		)
+		e.Equal(http.StatusBadRequest, httpResp.Code, tt.name)
+	}
+}
+
+func (e *AppTestSuite) TestWebhookV1_Create_unauthorized() {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/webhooks",
+		e.jsonify(apiv1WebhookCreateRequest{URL: "https://example.com", Events: []string{"note.read"}}),
+	)
+	e.Equal(http.StatusUnauthorized, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestWebhookV1_Update() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	created := e.createWebhook(toks.AccessToken, "https://example.com/hooks", "note.read")
+
+	newURL := "https://example.com/hooks/v2"
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/webhooks/"+created.ID.String(),
+		e.jsonify(apiv1WebhookUpdateRequest{URL: &newURL}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	webhook := e.getWebhook(toks.AccessToken, created.ID)
+	e.Equal(newURL, webhook.URL)
+	e.Equal([]string{"note.read"}, webhook.Events)
+
+	httpResp = e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/webhooks/"+created.ID.String(),
+		e.jsonify(apiv1WebhookUpdateRequest{Events: []string{"note.created", "note.expired"}}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	webhook = e.getWebhook(toks.AccessToken, created.ID)
+	e.Equal(newURL, webhook.URL)
+	e.Equal([]string{"note.created", "note.expired"}, webhook.Events)
+}
+
+func (e *AppTestSuite) TestWebhookV1_Update_invalid() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	created := e.createWebhook(toks.AccessToken, "https://example.com/hooks", "note.read")
+
+	invalidURL := "not a url"
+	httpResp := e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/webhooks/"+created.ID.String(),
+		e.jsonify(apiv1WebhookUpdateRequest{URL: &invalidURL}), //nolint:exhaustruct
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	httpResp = e.httpRequest(
+		http.MethodPatch,
+		"/api/v1/webhooks/"+created.ID.String(),
+		[]byte(`{"events": []}`),
+		toks.AccessToken,
+	)
+	e.Equal(http.StatusBadRequest, httpResp.Code)
+
+	e.Equal([]string{"note.read"}, e.getWebhook(toks.AccessToken, created.ID).Events)
+}
+
+func (e *AppTestSuite) TestWebhookV1_Delete() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	created := e.createWebhook(toks.AccessToken, "https://example.com/hooks", "note.read")
+
+	httpResp := e.httpRequest(http.MethodDelete, "/api/v1/webhooks/"+created.ID.String(), nil, toks.AccessToken)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodGet, "/api/v1/webhooks/"+created.ID.String(), nil, toks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+
+	httpResp = e.httpRequest(http.MethodDelete, "/api/v1/webhooks/"+created.ID.String(), nil, toks.AccessToken)
+	e.Equal(http.StatusNotFound, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestWebhookV1_otherUsersWebhook() {
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	created := e.createWebhook(toks.AccessToken, "https://example.com/hooks", "note.read")
+
+	_, otherToks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	for _, tt := range []struct {
+		method string
+		path   string
+		body   []byte
+	}{
+		{method: http.MethodGet, path: ""},
+		{method: http.MethodGet, path: "/deliveries"},
+		{method: http.MethodPatch, path: "", body: []byte(`{"url": "https://example.com/stolen"}`)},
+		{method: http.MethodDelete, path: ""},
+	} {
+		httpResp := e.httpRequest(
+			tt.method,
+			"/api/v1/webhooks/"+created.ID.String()+tt.path,
+			tt.body,
+			otherToks.AccessToken,
+		)
+		e.Equal(http.StatusNotFound, httpResp.Code, tt.method+" "+tt.path)
+	}
+
+	e.Equal(created.URL, e.getWebhook(toks.AccessToken, created.ID).URL)
+}
+
+func (e *AppTestSuite) TestDispatcher_Dispatch_deliversSignedEvent() {
+	receiver := newWebhookReceiver(http.StatusOK)
+	defer receiver.Close()
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	webhook := e.createWebhook(toks.AccessToken, receiver.URL, "note.read")
+	slug := e.createNoteAs(toks.AccessToken)
+
+	// the note isn't read yet, and the webhook isn't subscribed to its creation
+	_, err := e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+	e.Empty(receiver.Requests())
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusOK, httpResp.Code)
+
+	_, err = e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+
+	requests := receiver.Requests()
+	e.Require().Len(requests, 1)
+	req := requests[0]
+	e.Equal("note.read", req.header.Get("X-Onasty-Event"))
+	e.Equal("application/json", req.header.Get("Content-Type"))
+	e.verifyWebhookSignature(webhook.Secret, req)
+
+	var payload struct {
+		Event string `json:"event"`
+		Note  struct {
+			Slug string `json:"slug"`
+		} `json:"note"`
+	}
+	e.require.NoError(json.Unmarshal(req.body, &payload))
+	e.Equal("note.read", payload.Event)
+	e.Equal(slug, payload.Note.Slug)
+
+	deliveries := e.getWebhookDeliveries(toks.AccessToken, webhook.ID)
+	e.Require().Len(deliveries, 1)
+	e.Equal(req.header.Get("X-Onasty-Delivery"), deliveries[0].ID.String())
+	e.Equal("succeeded", deliveries[0].Status)
+	e.Equal(1, deliveries[0].Attempts)
+	e.Equal(http.StatusOK, deliveries[0].ResponseStatus)
+	e.Equal(string(req.body), deliveries[0].Payload)
+}
+
+func (e *AppTestSuite) TestDispatcher_Dispatch_deletedAndExpiredNotes() {
+	receiver := newWebhookReceiver(http.StatusNoContent)
+	defer receiver.Close()
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	e.createWebhook(toks.AccessToken, receiver.URL, "note.deleted", "note.expired")
+
+	deletedSlug := e.createNoteAs(toks.AccessToken)
+	httpResp := e.httpRequest(http.MethodDelete, "/api/v1/note/"+deletedSlug, nil, toks.AccessToken)
+	e.Equal(http.StatusNoContent, httpResp.Code)
+
+	expiredSlug := e.createNoteAs(toks.AccessToken)
+	e.expireNote(expiredSlug)
+	_, err := e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+
+	_, err = e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+
+	events := make(map[string]string)
+	for _, req := range receiver.Requests() {
+		var payload struct {
+			Note struct {
+				Slug string `json:"slug"`
+			} `json:"note"`
+		}
+		e.require.NoError(json.Unmarshal(req.body, &payload))
+		events[req.header.Get("X-Onasty-Event")] = payload.Note.Slug
+	}
+
+	e.Equal(map[string]string{
+		"note.deleted": deletedSlug,
+		"note.expired": expiredSlug,
+	}, events)
+}
+
+func (e *AppTestSuite) TestDispatcher_Dispatch_retriesFailedDelivery() {
+	receiver := newWebhookReceiver(http.StatusServiceUnavailable, http.StatusOK)
+	defer receiver.Close()
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	webhook := e.createWebhook(toks.AccessToken, receiver.URL, "note.created")
+	e.createNoteAs(toks.AccessToken)
+
+	_, err := e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+
+	deliveries := e.getWebhookDeliveries(toks.AccessToken, webhook.ID)
+	e.Require().Len(deliveries, 1)
+	e.Equal("pending", deliveries[0].Status)
+	e.Equal(1, deliveries[0].Attempts)
+	e.Equal(http.StatusServiceUnavailable, deliveries[0].ResponseStatus)
+	e.True(deliveries[0].NextAttemptAt.After(time.Now()))
+
+	// the retry isn't due yet
+	_, err = e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+	e.Len(receiver.Requests(), 1)
+
+	e.makeWebhookDeliveriesDue(webhook.ID)
+	_, err = e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+
+	requests := receiver.Requests()
+	e.Require().Len(requests, 2)
+	e.Equal(requests[0].header.Get("X-Onasty-Delivery"), requests[1].header.Get("X-Onasty-Delivery"))
+
+	deliveries = e.getWebhookDeliveries(toks.AccessToken, webhook.ID)
+	e.Require().Len(deliveries, 1)
+	e.Equal("succeeded", deliveries[0].Status)
+	e.Equal(2, deliveries[0].Attempts)
+	e.Equal(http.StatusOK, deliveries[0].ResponseStatus)
+}
+
+func (e *AppTestSuite) TestDispatcher_Dispatch_givesUpAfterMaxAttempts() {
+	receiver := newWebhookReceiver(http.StatusInternalServerError)
+	defer receiver.Close()
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	webhook := e.createWebhook(toks.AccessToken, receiver.URL, "note.created")
+	e.createNoteAs(toks.AccessToken)
+
+	for range testWebhooksMaxAttempts + 1 {
+		_, err := e.dispatcher.Dispatch(e.ctx)
+		e.require.NoError(err)
+		e.makeWebhookDeliveriesDue(webhook.ID)
+	}
+
+	e.Len(receiver.Requests(), testWebhooksMaxAttempts)
+
+	deliveries := e.getWebhookDeliveries(toks.AccessToken, webhook.ID)
+	e.Require().Len(deliveries, 1)
+	e.Equal("failed", deliveries[0].Status)
+	e.Equal(testWebhooksMaxAttempts, deliveries[0].Attempts)
+	e.Zero(deliveries[0].NextAttemptAt)
+}
+
+func (e *AppTestSuite) TestDispatcher_Dispatch_unreachableEndpoint() {
+	receiver := newWebhookReceiver(http.StatusOK)
+	receiver.Close()
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	webhook := e.createWebhook(toks.AccessToken, receiver.URL, "note.created")
+	e.createNoteAs(toks.AccessToken)
+
+	_, err := e.dispatcher.Dispatch(e.ctx)
+	e.require.NoError(err)
+
+	deliveries := e.getWebhookDeliveries(toks.AccessToken, webhook.ID)
+	e.Require().Len(deliveries, 1)
+	e.Equal("pending", deliveries[0].Status)
+	e.Zero(deliveries[0].ResponseStatus)
+	e.NotEmpty(deliveries[0].Error)
+}
+
+func (e *AppTestSuite) createWebhook(accessToken, url string, events ...string) apiv1WebhookResponse {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/webhooks",
+		e.jsonify(apiv1WebhookCreateRequest{URL: url, Events: events}),
+		accessToken,
+	)
+	e.Require().Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1WebhookResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
+
+func (e *AppTestSuite) getWebhook(accessToken string, id uuid.UUID) apiv1WebhookResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/webhooks/"+id.String(), nil, accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body apiv1WebhookResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
+
+func (e *AppTestSuite) getWebhookDeliveries(accessToken string, id uuid.UUID) []apiv1WebhookDeliveryResponse {
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/webhooks/"+id.String()+"/deliveries", nil, accessToken)
+	e.Require().Equal(http.StatusOK, httpResp.Code)
+
+	var body []apiv1WebhookDeliveryResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body
+}
+
+func (e *AppTestSuite) createNoteAs(accessToken string) string {
+	httpResp := e.httpRequest(
+		http.MethodPost,
+		"/api/v1/note",
+		e.jsonify(apiv1NoteCreateRequest{ //nolint:exhaustruct
+			Content:   e.uuid(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}),
+		accessToken,
+	)
+	e.Require().Equal(http.StatusCreated, httpResp.Code)
+
+	var body apiv1NoteCreateResponse
+	e.readBodyAndUnjsonify(httpResp.Body, &body)
+
+	return body.Slug
+}
+
+// verifyWebhookSignature verifies the signature the same way receivers are expected to
+func (e *AppTestSuite) verifyWebhookSignature(secret string, req receivedWebhookRequest) {
+	timestamp, signature, ok := strings.Cut(req.header.Get("X-Onasty-Signature"), ",")
+	e.Require().True(ok)
+
+	timestamp, ok = strings.CutPrefix(timestamp, "t=")
+	e.Require().True(ok)
+
+	signature, ok = strings.CutPrefix(signature, "v1=")
+	e.Require().True(ok)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(req.body)))
+	e.Equal(hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+type receivedWebhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver is an endpoint that records requests it gets,
+// and responds with statuses in order they're provided, the last one is repeated.
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhookRequest
+}
+
+func newWebhookReceiver(statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses} //nolint:exhaustruct
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		status := r.statuses[min(len(r.requests), len(r.statuses)-1)]
+		r.requests = append(r.requests, receivedWebhookRequest{
+			header: req.Header.Clone(),
+			body:   body,
+		})
+
+		w.WriteHeader(status)
+	}))
+
+	return r
+}
+
+func (r *webhookReceiver) Requests() []receivedWebhookRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]receivedWebhookRequest(nil), r.requests...)
+}
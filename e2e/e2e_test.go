@@ -1,9 +1,11 @@
 package e2e_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"testing"
 	"time"
 
@@ -12,28 +14,46 @@ import (
 	"github.com/golang-migrate/migrate/v4/database/pgx"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/olexsmir/onasty/internal/config"
+	"github.com/olexsmir/onasty/internal/envelope"
 	"github.com/olexsmir/onasty/internal/hasher"
 	"github.com/olexsmir/onasty/internal/jwtutil"
 	"github.com/olexsmir/onasty/internal/logger"
+	"github.com/olexsmir/onasty/internal/models"
 	"github.com/olexsmir/onasty/internal/service/authsrv"
 	"github.com/olexsmir/onasty/internal/service/notesrv"
+	"github.com/olexsmir/onasty/internal/service/quotasrv"
 	"github.com/olexsmir/onasty/internal/service/usersrv"
+	"github.com/olexsmir/onasty/internal/service/webhooksrv"
+	"github.com/olexsmir/onasty/internal/sluggen"
+	"github.com/olexsmir/onasty/internal/store/blob/s3blob"
+	"github.com/olexsmir/onasty/internal/store/psql/attachmentrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/changeemailrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
 	"github.com/olexsmir/onasty/internal/store/psql/passwordtokrepo"
+	"github.com/olexsmir/onasty/internal/store/psql/quotarepo"
 	"github.com/olexsmir/onasty/internal/store/psql/sessionrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/userepo"
 	"github.com/olexsmir/onasty/internal/store/psql/vertokrepo"
+	"github.com/olexsmir/onasty/internal/store/psql/webhookrepo"
 	"github.com/olexsmir/onasty/internal/store/psqlutil"
 	"github.com/olexsmir/onasty/internal/store/rdb"
+	"github.com/olexsmir/onasty/internal/store/rdb/noteattempts"
 	"github.com/olexsmir/onasty/internal/store/rdb/notecache"
+	"github.com/olexsmir/onasty/internal/store/rdb/notecodes"
+	"github.com/olexsmir/onasty/internal/store/rdb/notecounter"
+	"github.com/olexsmir/onasty/internal/store/rdb/notelinksends"
+	"github.com/olexsmir/onasty/internal/store/rdb/notereads"
 	"github.com/olexsmir/onasty/internal/store/rdb/usercache"
 	httptransport "github.com/olexsmir/onasty/internal/transport/http"
 	"github.com/olexsmir/onasty/internal/transport/http/ratelimit"
+	"github.com/olexsmir/onasty/internal/worker/deadman"
+	"github.com/olexsmir/onasty/internal/worker/dispatcher"
+	"github.com/olexsmir/onasty/internal/worker/reaper"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/testcontainers/testcontainers-go"
+	tcminio "github.com/testcontainers/testcontainers-go/modules/minio"
 	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
 	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -41,6 +61,34 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
+const (
+	testNoteKeyID        = "test-key"
+	testNoteRotatedKeyID = "test-key-rotated"
+
+	testAttachmentMaxSizeKb = 16
+	testAttachmentsMaxCount = 3
+
+	testWebhooksMaxAttempts = 2
+
+	testNotePasswordMaxAttempts = 3
+
+	testNoteCodeMaxAttempts = 2
+
+	testNoteLinkSendsPerHour = 2
+
+	// anonymous notes in tests come from the same ip, so only content size is limited for them
+	testQuotaAnonymousMaxContentSizeKb = 4
+)
+
+// testArgon2idParams are cheap, so tests that sign users in don't take long.
+var testArgon2idParams = hasher.Argon2idParams{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
 type (
 	stopFunc     func()
 	AppTestSuite struct {
@@ -55,9 +103,17 @@ type (
 		redisDB   *rdb.DB
 		stopRedis stopFunc
 
-		router       http.Handler
-		hasher       hasher.Hasher
-		jwtTokenizer jwtutil.JWTTokenizer
+		blobStore *s3blob.Store
+		stopMinio stopFunc
+
+		router        http.Handler
+		hasher        hasher.Rehasher
+		noteHasher    hasher.Hasher
+		jwtTokenizer  jwtutil.JWTTokenizer
+		noteEncryptor *envelope.Envelope
+		reaper        *reaper.Reaper
+		deadman       *deadman.Deadman
+		dispatcher    *dispatcher.Dispatcher
 	}
 	errorResponse struct {
 		Message string `json:"message"`
@@ -81,6 +137,7 @@ func (e *AppTestSuite) SetupSuite() {
 
 	e.postgresDB, e.stopPostgres = e.prepPostgres()
 	e.redisDB, e.stopRedis = e.prepRedis()
+	e.blobStore, e.stopMinio = e.prepMinio()
 
 	e.initDeps()
 }
@@ -88,6 +145,7 @@ func (e *AppTestSuite) SetupSuite() {
 func (e *AppTestSuite) TearDownSuite() {
 	e.stopPostgres()
 	e.stopRedis()
+	e.stopMinio()
 }
 
 // initDeps initializes the dependencies for the app
@@ -98,9 +156,30 @@ func (e *AppTestSuite) initDeps() {
 	err := logger.SetDefault(cfg.LogLevel, cfg.LogFormat, cfg.LogShowLine)
 	e.require.NoError(err)
 
-	e.hasher = hasher.NewSHA256Hasher(cfg.PasswordSalt)
+	e.hasher, err = hasher.NewPasswordHasher(hasher.PasswordConfig{
+		Algorithm:  cfg.PasswordHasher,
+		Argon2id:   testArgon2idParams,
+		BcryptCost: cfg.PasswordBcryptCost,
+		LegacySalt: cfg.PasswordSalt,
+	})
+	e.require.NoError(err)
+	e.noteHasher, err = hasher.NewPasswordHasher(hasher.PasswordConfig{
+		Algorithm:  cfg.PasswordHasher,
+		Argon2id:   testArgon2idParams,
+		BcryptCost: cfg.PasswordBcryptCost,
+		LegacySalt: cfg.NotePasswordSalt,
+	})
+	e.require.NoError(err)
 	e.jwtTokenizer = jwtutil.NewJWTUtil(cfg.JwtSigningKey, time.Hour)
 
+	// the second key is used to test the key rotation
+	noteKeys, err := envelope.NewKeyRing(testNoteKeyID, map[string][]byte{
+		testNoteKeyID:        bytes.Repeat([]byte{1}, envelope.KeySize),
+		testNoteRotatedKeyID: bytes.Repeat([]byte{2}, envelope.KeySize),
+	})
+	e.require.NoError(err)
+	e.noteEncryptor = envelope.New(noteKeys)
+
 	sessionrepo := sessionrepo.New(e.postgresDB)
 	vertokrepo := vertokrepo.New(e.postgresDB)
 	pwdtokrepo := passwordtokrepo.NewPasswordResetTokenRepo(e.postgresDB)
@@ -109,9 +188,81 @@ func (e *AppTestSuite) initDeps() {
 	stubOAuthProvider := newOauthProviderStub()
 	mailerMockService := newMailerMockService()
 
+	webhookrepo := webhookrepo.New(e.postgresDB, e.noteEncryptor)
+	webhooksrv := webhooksrv.New(webhookrepo, webhooksrv.Config{
+		Timeout:              cfg.WebhooksTimeout,
+		MaxAttempts:          cfg.WebhooksMaxAttempts,
+		Backoff:              cfg.WebhooksBackoff,
+		DeliveryLogRetention: cfg.WebhooksDeliveryLogRetention,
+		AllowInternalAddrs:   cfg.WebhooksAllowInternalAddrs,
+	})
+	e.dispatcher = dispatcher.New(webhooksrv, dispatcher.Config{Interval: time.Hour})
+
 	notecache := notecache.New(e.redisDB, cfg.CacheUsersTTL)
-	noterepo := noterepo.New(e.postgresDB)
-	notesrv := notesrv.New(noterepo, e.hasher, notecache)
+	noterepo := noterepo.New(e.postgresDB, e.noteEncryptor)
+	attachmentrepo := attachmentrepo.New(e.postgresDB)
+	quotasrv := quotasrv.New(
+		quotarepo.New(e.postgresDB),
+		noterepo,
+		notecounter.New(e.redisDB),
+		quotasrv.Config{
+			Anonymous: models.Quota{
+				Tier:            models.QuotaTierAnonymous,
+				MaxContentBytes: cfg.QuotaAnonymousMaxContentSizeKb * 1024,
+				NotesPerDay:     cfg.QuotaAnonymousNotesPerDay,
+				MaxActiveNotes:  cfg.QuotaAnonymousMaxActiveNotes,
+				MaxExpiresIn:    cfg.QuotaAnonymousMaxExpiresIn,
+			},
+			Registered: models.Quota{
+				Tier:            models.QuotaTierRegistered,
+				MaxContentBytes: cfg.QuotaRegisteredMaxContentSizeKb * 1024,
+				NotesPerDay:     cfg.QuotaRegisteredNotesPerDay,
+				MaxActiveNotes:  cfg.QuotaRegisteredMaxActiveNotes,
+				MaxExpiresIn:    cfg.QuotaRegisteredMaxExpiresIn,
+			},
+			IPHashKey: cfg.QuotaIPHashKey,
+		},
+	)
+	slugPolicy, err := sluggen.NewPolicy(sluggen.Config{
+		Strategy:     sluggen.Strategy(cfg.SlugStrategy),
+		Base62Length: cfg.SlugBase62Length,
+		WordsCount:   cfg.SlugWordsCount,
+		MinEntropy:   float64(cfg.SlugMinEntropyBits),
+	})
+	e.require.NoError(err)
+
+	notesrv := notesrv.New(noterepo, e.noteHasher, notecache, notesrv.Deps{
+		AttachmentRepo: attachmentrepo,
+		Blobs:          e.blobStore,
+		BlobEnc:        e.noteEncryptor,
+		Slugs:          slugPolicy,
+		Attempts:       noteattempts.New(e.redisDB, cfg.NotePasswordLockout),
+		Codes:          notecodes.New(e.redisDB, cfg.NoteCodeTTL),
+		Reads:          notereads.New(e.redisDB, cfg.NoteReadTokenTTL),
+		LinkSends:      notelinksends.New(e.redisDB),
+		Mailer:         mailerMockService,
+		Webhooks:       webhooksrv,
+		Quotas:         quotasrv,
+	}, notesrv.Config{
+		Attachments: notesrv.AttachmentsConfig{
+			MaxSize:        int64(cfg.AttachmentMaxSizeKb) * 1024,
+			MaxCount:       cfg.AttachmentsMaxCount,
+			DownloadWindow: cfg.AttachmentDownloadWindow,
+		},
+		MaxPasswordAttempts: cfg.NotePasswordMaxAttempts,
+		MaxCodeAttempts:     cfg.NoteCodeMaxAttempts,
+		MaxLinkSendsPerHour: cfg.NoteLinkSendsPerHour,
+		AccessRequestTTL:    cfg.NoteAccessRequestTTL,
+	})
+	e.reaper = reaper.New(e.postgresDB, notesrv, reaper.Config{
+		Interval:       time.Hour,
+		Retention:      cfg.ReaperRetention,
+		AuditRetention: cfg.ReaperAuditRetention,
+	})
+	e.deadman = deadman.New(e.postgresDB, notesrv, deadman.Config{
+		Interval:     time.Hour,
+		RemindBefore: cfg.DeadmanRemindBefore,
+	})
 
 	userepo := userepo.New(e.postgresDB)
 	usercache := usercache.New(e.redisDB, cfg.CacheUsersTTL)
@@ -121,6 +272,7 @@ func (e *AppTestSuite) initDeps() {
 		pwdtokrepo,
 		changeemailrepo,
 		noterepo,
+		quotasrv,
 		e.hasher,
 		mailerMockService,
 		cfg.VerificationTokenTTL,
@@ -153,6 +305,7 @@ func (e *AppTestSuite) initDeps() {
 		authsrv,
 		usersrv,
 		notesrv,
+		webhooksrv,
 		cfg.AppEnv,
 		cfg.AppURL,
 		cfg.FrontendURL,
@@ -228,6 +381,28 @@ func (e *AppTestSuite) prepRedis() (*rdb.DB, stopFunc) {
 	return redis, stop
 }
 
+func (e *AppTestSuite) prepMinio() (*s3blob.Store, stopFunc) {
+	minioContainer, err := tcminio.Run(e.ctx, "minio/minio:RELEASE.2024-01-16T16-07-38Z")
+	e.require.NoError(err)
+
+	stop := func() { e.require.NoError(minioContainer.Terminate(e.ctx)) }
+
+	endpoint, err := minioContainer.ConnectionString(e.ctx)
+	e.require.NoError(err)
+
+	store, err := s3blob.New(e.ctx, s3blob.Config{
+		Endpoint:  endpoint,
+		Region:    "",
+		Bucket:    "testing",
+		AccessKey: minioContainer.Username,
+		SecretKey: minioContainer.Password,
+		UseSSL:    false,
+	})
+	e.require.NoError(err)
+
+	return store, stop
+}
+
 func (e *AppTestSuite) getConfig() *config.Config {
 	e.T().Setenv("APP_ENV", "test")
 	e.T().Setenv("APP_URL", "localhost")
@@ -237,6 +412,19 @@ func (e *AppTestSuite) getConfig() *config.Config {
 	e.T().Setenv("LOG_SHOW_LINE", "true")
 	e.T().Setenv("LOG_FORMAT", "text")
 	e.T().Setenv("LOG_LEVEL", "debug")
+	e.T().Setenv("ATTACHMENT_MAX_SIZE_KB", strconv.Itoa(testAttachmentMaxSizeKb))
+	e.T().Setenv("ATTACHMENTS_MAX_COUNT", strconv.Itoa(testAttachmentsMaxCount))
+	e.T().Setenv("WEBHOOKS_MAX_ATTEMPTS", strconv.Itoa(testWebhooksMaxAttempts))
+	e.T().Setenv("WEBHOOKS_TIMEOUT", "2s")
+	// test endpoints listen on loopback
+	e.T().Setenv("WEBHOOKS_ALLOW_INTERNAL_ADDRS", "true")
+	e.T().Setenv("NOTE_PASSWORD_MAX_ATTEMPTS", strconv.Itoa(testNotePasswordMaxAttempts))
+	e.T().Setenv("NOTE_CODE_MAX_ATTEMPTS", strconv.Itoa(testNoteCodeMaxAttempts))
+	e.T().Setenv("NOTE_LINK_SENDS_PER_HOUR", strconv.Itoa(testNoteLinkSendsPerHour))
+	e.T().Setenv("QUOTA_IP_HASH_KEY", "ip-hash-key")
+	e.T().Setenv("QUOTA_ANONYMOUS_MAX_CONTENT_SIZE_KB", strconv.Itoa(testQuotaAnonymousMaxContentSizeKb))
+	e.T().Setenv("QUOTA_ANONYMOUS_NOTES_PER_DAY", "0")
+	e.T().Setenv("QUOTA_ANONYMOUS_MAX_ACTIVE_NOTES", "0")
 
 	return config.NewConfig()
 }
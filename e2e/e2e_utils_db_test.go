@@ -54,6 +54,20 @@ func (e *AppTestSuite) insertUser(email, passwd string, activated ...bool) uuid.
 	return id
 }
 
+// deleteUser deletes user from db, along with their sessions and tokens
+func (e *AppTestSuite) deleteUser(uid uuid.UUID) {
+	for _, query := range []string{
+		"delete from sessions where user_id = $1",
+		"delete from verification_tokens where user_id = $1",
+		"delete from password_reset_tokens where user_id = $1",
+		"delete from change_email_tokens where user_id = $1",
+		"delete from users where id = $1",
+	} {
+		_, err := e.postgresDB.Exec(e.ctx, query, uid)
+		e.require.NoError(err)
+	}
+}
+
 // getLastSessionByUserID gets last inserted [models.Session] for particular user
 func (e *AppTestSuite) getLastSessionByUserID(uid uuid.UUID) models.Session {
 	query, args, err := pgq.
@@ -104,12 +118,15 @@ func (e *AppTestSuite) getNoteBySlug(slug string) models.Note {
 		Select(
 			"id",
 			"content",
+			"content_key_id",
 			"slug",
 			"keep_before_expiration",
 			"password",
 			"read_at",
 			"created_at",
 			"expires_at",
+			"allow_reply",
+			"coalesce(reply_slug, '')",
 		).
 		From("notes").
 		Where(pgq.Eq{"slug": slug}).
@@ -118,18 +135,107 @@ func (e *AppTestSuite) getNoteBySlug(slug string) models.Note {
 
 	var readAt sql.NullTime
 	var note models.Note
+	var contentKeyID string
 	err = e.postgresDB.QueryRow(e.ctx, query, args...).
-		Scan(&note.ID, &note.Content, &note.Slug, &note.KeepBeforeExpiration, &note.Password, &readAt, &note.CreatedAt, &note.ExpiresAt)
+		Scan(&note.ID, &note.Content, &contentKeyID, &note.Slug, &note.KeepBeforeExpiration, &note.Password, &readAt, &note.CreatedAt, &note.ExpiresAt,
+			&note.AllowReply, &note.ReplySlug)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return models.Note{} //nolint:exhaustruct
 	}
 
-	note.ReadAt = psqlutil.NullTimeToTime(readAt)
+	e.require.NoError(err)
 
+	note.ReadAt = psqlutil.NullTimeToTime(readAt)
+	note.Content, err = e.noteEncryptor.Decrypt(e.ctx, note.Content, contentKeyID)
 	e.require.NoError(err)
+
 	return note
 }
 
+// getNoteContentAtRestBySlug returns note's content as it's stored in db, and id of the key it's encrypted with
+func (e *AppTestSuite) getNoteContentAtRestBySlug(slug string) (string, string) {
+	var content, keyID string
+	err := e.postgresDB.QueryRow(e.ctx, "select content, content_key_id from notes where slug = $1", slug).
+		Scan(&content, &keyID)
+	e.require.NoError(err)
+
+	return content, keyID
+}
+
+// countNoteContents returns how many contents, shared by notes with recipients, are stored with the id
+func (e *AppTestSuite) countNoteContents(id string) int {
+	var count int
+	err := e.postgresDB.QueryRow(e.ctx, "select count(*) from note_contents where id = $1", id).
+		Scan(&count)
+	e.require.NoError(err)
+
+	return count
+}
+
+// insertNote inserts note directly into db, zero readAt means the note is unread
+func (e *AppTestSuite) insertNote(content string, readAt, expiresAt time.Time) string {
+	slug := e.uuid()
+	_, err := e.postgresDB.Exec(e.ctx,
+		"insert into notes (content, slug, read_at, expires_at) values ($1, $2, $3, $4)",
+		content, slug, sql.NullTime{Time: readAt, Valid: !readAt.IsZero()}, expiresAt)
+	e.require.NoError(err)
+
+	return slug
+}
+
+// getNoteAttachmentNoteID returns id of the note the attachment is linked to,
+// it's invalid if the attachment is detached, and false is returned if there's no such attachment
+func (e *AppTestSuite) getNoteAttachmentNoteID(id uuid.UUID) (uuid.NullUUID, bool) {
+	var noteID uuid.NullUUID
+	err := e.postgresDB.QueryRow(e.ctx, "select note_id from note_attachments where id = $1", id).
+		Scan(&noteID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.NullUUID{}, false //nolint:exhaustruct
+	}
+
+	e.require.NoError(err)
+
+	return noteID, true
+}
+
+// getNoteAttachmentIDsBySlug returns ids of attachments linked to the note
+func (e *AppTestSuite) getNoteAttachmentIDsBySlug(slug string) []uuid.UUID {
+	rows, err := e.postgresDB.Query(e.ctx, `--sql
+select a.id from note_attachments a
+inner join notes n on n.id = a.note_id
+where n.slug = $1`, slug)
+	e.require.NoError(err)
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	e.require.NoError(err)
+
+	return ids
+}
+
+// expireNote sets note's expiration time to the past
+func (e *AppTestSuite) expireNote(slug string) {
+	_, err := e.postgresDB.Exec(e.ctx,
+		"update notes set expires_at = $1 where slug = $2",
+		time.Now().Add(-time.Minute), slug)
+	e.require.NoError(err)
+}
+
+// expireNoteAccessRequest sets access request's expiration time to the past
+func (e *AppTestSuite) expireNoteAccessRequest(id string) {
+	_, err := e.postgresDB.Exec(e.ctx,
+		"update note_access_requests set expires_at = $1 where id = $2",
+		time.Now().Add(-time.Minute), id)
+	e.require.NoError(err)
+}
+
+// makeWebhookDeliveriesDue makes pending deliveries of the webhook due to be attempted right away
+func (e *AppTestSuite) makeWebhookDeliveriesDue(webhookID uuid.UUID) {
+	_, err := e.postgresDB.Exec(e.ctx,
+		"update webhook_deliveries set next_attempt_at = now() where webhook_id = $1 and status = 'pending'",
+		webhookID)
+	e.require.NoError(err)
+}
+
 type noteAuthorModel struct {
 	noteID uuid.UUID
 	userID uuid.UUID
@@ -203,3 +309,24 @@ func (e *AppTestSuite) getChangeEmailTokenByUserID(u uuid.UUID) userVerification
 	e.require.NoError(err)
 	return r
 }
+
+// quotaOverride is a row of user_quotas, nil limits are inherited from the tier
+type quotaOverride struct {
+	MaxContentBytes     *int
+	NotesPerDay         *int
+	MaxActiveNotes      *int
+	MaxExpiresInSeconds *int64
+}
+
+// overrideQuota overrides quota of the user
+func (e *AppTestSuite) overrideQuota(uid uuid.UUID, o quotaOverride) {
+	query, args, err := pgq.
+		Insert("user_quotas").
+		Columns("user_id", "max_content_bytes", "notes_per_day", "max_active_notes", "max_expires_in_seconds").
+		Values(uid, o.MaxContentBytes, o.NotesPerDay, o.MaxActiveNotes, o.MaxExpiresInSeconds).
+		SQL()
+	e.require.NoError(err)
+
+	_, err = e.postgresDB.Exec(e.ctx, query, args...)
+	e.require.NoError(err)
+}
@@ -39,3 +39,59 @@ func (m *mailerMockService) SendChangeEmailConfirmation(
 	mockMailStore[i.Receiver] = i.Token
 	return nil
 }
+
+func (m *mailerMockService) SendNoteReadNotice(
+	_ context.Context,
+	i mailermq.SendNoteReadNoticeRequest,
+) error {
+	mockMailStore[i.Receiver] = "note_read:" + i.Slug
+	return nil
+}
+
+func (m *mailerMockService) SendNoteExpiredNotice(
+	_ context.Context,
+	i mailermq.SendNoteExpiredNoticeRequest,
+) error {
+	mockMailStore[i.Receiver] = "note_expired:" + i.Slug
+	return nil
+}
+
+func (m *mailerMockService) SendNoteSwitchReminder(
+	_ context.Context,
+	i mailermq.SendNoteSwitchReminderRequest,
+) error {
+	mockMailStore[i.Receiver] = "note_switch_reminder:" + i.Slug
+	return nil
+}
+
+func (m *mailerMockService) SendNoteReleased(
+	_ context.Context,
+	i mailermq.SendNoteReleasedRequest,
+) error {
+	mockMailStore[i.Receiver] = "note_released:" + i.Slug
+	return nil
+}
+
+func (m *mailerMockService) SendNoteLink(
+	_ context.Context,
+	i mailermq.SendNoteLinkRequest,
+) error {
+	mockMailStore[i.Receiver] = "send_note_link:" + i.Slug
+	return nil
+}
+
+func (m *mailerMockService) SendNoteCode(
+	_ context.Context,
+	i mailermq.SendNoteCodeRequest,
+) error {
+	mockMailStore[i.Receiver] = i.Code
+	return nil
+}
+
+func (m *mailerMockService) SendNoteAccessRequest(
+	_ context.Context,
+	i mailermq.SendNoteAccessRequestRequest,
+) error {
+	mockMailStore[i.Receiver] = "note_access_request:" + i.Slug
+	return nil
+}
@@ -0,0 +1,92 @@
+package e2e_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/config"
+)
+
+func (e *AppTestSuite) TestReaper_Reap() {
+	retention := config.NewConfig().ReaperRetention
+
+	unreadSlug := e.insertNote(e.uuid(), time.Time{}, time.Time{})
+	expiredSlug := e.insertNote(e.uuid(), time.Time{}, time.Now().Add(-time.Minute))
+	recentlyReadSlug := e.insertNote("", time.Now().Add(-time.Minute), time.Time{})
+	staleReadSlug := e.insertNote("", time.Now().Add(-retention-time.Hour), time.Time{})
+	staleExpiredSlug := e.insertNote(e.uuid(), time.Time{}, time.Now().Add(-retention-time.Hour))
+
+	reaped, err := e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+	e.True(reaped)
+
+	e.NotEmpty(e.getNoteBySlug(unreadSlug).Content)
+
+	expired := e.getNoteBySlug(expiredSlug)
+	e.NotEmpty(expired.Slug)
+	e.Empty(expired.Content)
+
+	e.NotEmpty(e.getNoteBySlug(recentlyReadSlug).Slug)
+	e.Empty(e.getNoteBySlug(staleReadSlug).Slug)
+	e.Empty(e.getNoteBySlug(staleExpiredSlug).Slug)
+}
+
+func (e *AppTestSuite) TestReaper_Reap_expiredNoteStillGone() {
+	slug := e.insertNote(e.uuid(), time.Time{}, time.Now().Add(-time.Minute))
+
+	_, err := e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+
+	httpResp := e.httpRequest(http.MethodGet, "/api/v1/note/"+slug, nil)
+	e.Equal(http.StatusGone, httpResp.Code)
+}
+
+func (e *AppTestSuite) TestReaper_Reap_oldAccessEvents() {
+	retention := config.NewConfig().ReaperAuditRetention
+
+	_, toks := e.createAndSingIn(e.randomEmail(), e.uuid())
+	slug := e.createNoteWithPassword(apiv1NoteCreateRequest{Content: e.uuid()}, toks.AccessToken) //nolint:exhaustruct
+	noteID := e.getNoteBySlug(slug).ID
+
+	e.Equal(http.StatusOK, e.readerRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil).Code)
+
+	_, err := e.postgresDB.Exec(e.ctx,
+		"update note_access_events set created_at = $1 where note_id = $2",
+		time.Now().Add(-retention-time.Hour), noteID)
+	e.require.NoError(err)
+
+	e.Equal(http.StatusOK, e.readerRequest(http.MethodGet, "/api/v1/note/"+slug+"/meta", nil).Code)
+
+	_, err = e.reaper.Reap(e.ctx)
+	e.require.NoError(err)
+
+	events := e.getNoteAccessEvents(toks.AccessToken, slug)
+	e.Require().Len(events, 1)
+	e.WithinDuration(time.Now(), events[0].CreatedAt, time.Minute)
+}
+
+func (e *AppTestSuite) TestPsqlutil_WithTryAdvisoryLock() {
+	const key = 42
+
+	var called bool
+	locked, err := e.postgresDB.WithTryAdvisoryLock(e.ctx, key, func(ctx context.Context) error {
+		// other session shouldn't be able to get the lock while it's held
+		lockedByOther, err := e.postgresDB.WithTryAdvisoryLock(ctx, key, func(context.Context) error {
+			called = true
+			return nil
+		})
+		e.require.NoError(err)
+		e.False(lockedByOther)
+
+		return nil
+	})
+	e.require.NoError(err)
+	e.True(locked)
+	e.False(called)
+
+	// the lock is released after the function returns
+	locked, err = e.postgresDB.WithTryAdvisoryLock(e.ctx, key, func(context.Context) error { return nil })
+	e.require.NoError(err)
+	e.True(locked)
+}
@@ -39,6 +39,43 @@ type Config struct {
 	PasswordSalt     string
 	NotePasswordSalt string
 
+	PasswordHasher              string
+	PasswordArgon2idMemoryKb    int
+	PasswordArgon2idIterations  int
+	PasswordArgon2idParallelism int
+	PasswordBcryptCost          int
+
+	NotePasswordMaxAttempts int
+	NotePasswordLockout     time.Duration
+
+	NoteCodeMaxAttempts int
+	NoteCodeTTL         time.Duration
+
+	NoteAccessRequestTTL time.Duration
+
+	NoteReadTokenTTL time.Duration
+
+	NoteLinkSendsPerHour int
+
+	SlugStrategy       string
+	SlugBase62Length   int
+	SlugWordsCount     int
+	SlugMinEntropyBits int
+
+	NoteEncryptionKeyfile string
+
+	AttachmentsStorage       string
+	AttachmentsFSDir         string
+	AttachmentsS3Endpoint    string
+	AttachmentsS3Region      string
+	AttachmentsS3Bucket      string
+	AttachmentsS3AccessKey   string
+	AttachmentsS3SecretKey   string
+	AttachmentsS3UseSSL      bool
+	AttachmentMaxSizeKb      int
+	AttachmentsMaxCount      int
+	AttachmentDownloadWindow time.Duration
+
 	RedisAddr     string
 	RedisPassword string
 	RedisDB       int
@@ -65,6 +102,33 @@ type Config struct {
 	MetricsEnabled bool
 	MetricsPort    int
 
+	ReaperEnabled        bool
+	ReaperInterval       time.Duration
+	ReaperRetention      time.Duration
+	ReaperAuditRetention time.Duration
+
+	DeadmanEnabled      bool
+	DeadmanInterval     time.Duration
+	DeadmanRemindBefore time.Duration
+
+	WebhooksDispatcherEnabled    bool
+	WebhooksDispatchInterval     time.Duration
+	WebhooksTimeout              time.Duration
+	WebhooksMaxAttempts          int
+	WebhooksBackoff              time.Duration
+	WebhooksDeliveryLogRetention time.Duration
+	WebhooksAllowInternalAddrs   bool
+
+	QuotaIPHashKey                  string
+	QuotaAnonymousMaxContentSizeKb  int
+	QuotaAnonymousNotesPerDay       int
+	QuotaAnonymousMaxActiveNotes    int
+	QuotaAnonymousMaxExpiresIn      time.Duration
+	QuotaRegisteredMaxContentSizeKb int
+	QuotaRegisteredNotesPerDay      int
+	QuotaRegisteredMaxActiveNotes   int
+	QuotaRegisteredMaxExpiresIn     time.Duration
+
 	LogLevel    string
 	LogFormat   string
 	LogShowLine bool
@@ -97,6 +161,45 @@ func NewConfig() *Config {
 			PasswordSalt:     getenvOrDefault("PASSWORD_SALT", ""),
 			NotePasswordSalt: getenvOrDefault("NOTE_PASSWORD_SALT", ""),
 
+			PasswordHasher:              getenvOrDefault("PASSWORD_HASHER", "argon2id"),
+			PasswordArgon2idMemoryKb:    mustGetenvOrDefaultInt("PASSWORD_ARGON2ID_MEMORY_KB", 19*1024),
+			PasswordArgon2idIterations:  mustGetenvOrDefaultInt("PASSWORD_ARGON2ID_ITERATIONS", 2),
+			PasswordArgon2idParallelism: mustGetenvOrDefaultInt("PASSWORD_ARGON2ID_PARALLELISM", 1),
+			PasswordBcryptCost:          mustGetenvOrDefaultInt("PASSWORD_BCRYPT_COST", 12),
+
+			NotePasswordMaxAttempts: mustGetenvOrDefaultInt("NOTE_PASSWORD_MAX_ATTEMPTS", 5),
+			NotePasswordLockout:     mustParseDuration(getenvOrDefault("NOTE_PASSWORD_LOCKOUT", "1h")),
+
+			NoteCodeMaxAttempts: mustGetenvOrDefaultInt("NOTE_CODE_MAX_ATTEMPTS", 5),
+			NoteCodeTTL:         mustParseDuration(getenvOrDefault("NOTE_CODE_TTL", "10m")),
+
+			NoteAccessRequestTTL: mustParseDuration(getenvOrDefault("NOTE_ACCESS_REQUEST_TTL", "1h")),
+
+			NoteReadTokenTTL: mustParseDuration(getenvOrDefault("NOTE_READ_TOKEN_TTL", "1h")),
+
+			NoteLinkSendsPerHour: mustGetenvOrDefaultInt("NOTE_LINK_SENDS_PER_HOUR", 20),
+
+			SlugStrategy:       getenvOrDefault("SLUG_STRATEGY", "uuid"),
+			SlugBase62Length:   mustGetenvOrDefaultInt("SLUG_BASE62_LENGTH", 17),
+			SlugWordsCount:     mustGetenvOrDefaultInt("SLUG_WORDS_COUNT", 9),
+			SlugMinEntropyBits: mustGetenvOrDefaultInt("SLUG_MIN_ENTROPY_BITS", 96),
+
+			NoteEncryptionKeyfile: getenvOrDefault("NOTE_ENCRYPTION_KEYFILE", ""),
+
+			AttachmentsStorage:     getenvOrDefault("ATTACHMENTS_STORAGE", "fs"),
+			AttachmentsFSDir:       getenvOrDefault("ATTACHMENTS_FS_DIR", "attachments"),
+			AttachmentsS3Endpoint:  getenvOrDefault("ATTACHMENTS_S3_ENDPOINT", ""),
+			AttachmentsS3Region:    getenvOrDefault("ATTACHMENTS_S3_REGION", ""),
+			AttachmentsS3Bucket:    getenvOrDefault("ATTACHMENTS_S3_BUCKET", "onasty-attachments"),
+			AttachmentsS3AccessKey: getenvOrDefault("ATTACHMENTS_S3_ACCESS_KEY", ""),
+			AttachmentsS3SecretKey: getenvOrDefault("ATTACHMENTS_S3_SECRET_KEY", ""),
+			AttachmentsS3UseSSL:    getenvOrDefault("ATTACHMENTS_S3_USE_SSL", "true") == "true",
+			AttachmentMaxSizeKb:    mustGetenvOrDefaultInt("ATTACHMENT_MAX_SIZE_KB", 1024),
+			AttachmentsMaxCount:    mustGetenvOrDefaultInt("ATTACHMENTS_MAX_COUNT", 5),
+			AttachmentDownloadWindow: mustParseDuration(
+				getenvOrDefault("ATTACHMENT_DOWNLOAD_WINDOW", "1h"),
+			),
+
 			RedisAddr:     getenvOrDefault("REDIS_ADDR", ""),
 			RedisPassword: getenvOrDefault("REDIS_PASSWORD", ""),
 			RedisDB:       mustGetenvOrDefaultInt(getenvOrDefault("REDIS_DB", "0"), 0),
@@ -133,6 +236,44 @@ func NewConfig() *Config {
 			MetricsPort:    mustGetenvOrDefaultInt("METRICS_PORT", 3001),
 			MetricsEnabled: getenvOrDefault("METRICS_ENABLED", "true") == "true",
 
+			ReaperEnabled:        getenvOrDefault("REAPER_ENABLED", "true") == "true",
+			ReaperInterval:       mustParseDuration(getenvOrDefault("REAPER_INTERVAL", "5m")),
+			ReaperRetention:      mustParseDuration(getenvOrDefault("REAPER_RETENTION", "720h")),
+			ReaperAuditRetention: mustParseDuration(getenvOrDefault("REAPER_AUDIT_RETENTION", "720h")),
+
+			DeadmanEnabled:      getenvOrDefault("DEADMAN_ENABLED", "true") == "true",
+			DeadmanInterval:     mustParseDuration(getenvOrDefault("DEADMAN_INTERVAL", "1m")),
+			DeadmanRemindBefore: mustParseDuration(getenvOrDefault("DEADMAN_REMIND_BEFORE", "24h")),
+
+			WebhooksDispatcherEnabled: getenvOrDefault("WEBHOOKS_DISPATCHER_ENABLED", "true") == "true",
+			WebhooksDispatchInterval: mustParseDuration(
+				getenvOrDefault("WEBHOOKS_DISPATCH_INTERVAL", "5s"),
+			),
+			WebhooksTimeout:     mustParseDuration(getenvOrDefault("WEBHOOKS_TIMEOUT", "10s")),
+			WebhooksMaxAttempts: mustGetenvOrDefaultInt("WEBHOOKS_MAX_ATTEMPTS", 10),
+			WebhooksBackoff:     mustParseDuration(getenvOrDefault("WEBHOOKS_BACKOFF", "30s")),
+			WebhooksDeliveryLogRetention: mustParseDuration(
+				getenvOrDefault("WEBHOOKS_DELIVERY_LOG_RETENTION", "168h"),
+			),
+			WebhooksAllowInternalAddrs: getenvOrDefault("WEBHOOKS_ALLOW_INTERNAL_ADDRS", "false") == "true",
+
+			QuotaIPHashKey:                 getenvOrDefault("QUOTA_IP_HASH_KEY", ""),
+			QuotaAnonymousMaxContentSizeKb: mustGetenvOrDefaultInt("QUOTA_ANONYMOUS_MAX_CONTENT_SIZE_KB", 64),
+			QuotaAnonymousNotesPerDay:      mustGetenvOrDefaultInt("QUOTA_ANONYMOUS_NOTES_PER_DAY", 50),
+			QuotaAnonymousMaxActiveNotes:   mustGetenvOrDefaultInt("QUOTA_ANONYMOUS_MAX_ACTIVE_NOTES", 50),
+			QuotaAnonymousMaxExpiresIn: mustParseDuration(
+				getenvOrDefault("QUOTA_ANONYMOUS_MAX_EXPIRES_IN", "0s"),
+			),
+			QuotaRegisteredMaxContentSizeKb: mustGetenvOrDefaultInt(
+				"QUOTA_REGISTERED_MAX_CONTENT_SIZE_KB",
+				512,
+			),
+			QuotaRegisteredNotesPerDay:    mustGetenvOrDefaultInt("QUOTA_REGISTERED_NOTES_PER_DAY", 500),
+			QuotaRegisteredMaxActiveNotes: mustGetenvOrDefaultInt("QUOTA_REGISTERED_MAX_ACTIVE_NOTES", 500),
+			QuotaRegisteredMaxExpiresIn: mustParseDuration(
+				getenvOrDefault("QUOTA_REGISTERED_MAX_EXPIRES_IN", "0s"),
+			),
+
 			LogLevel:    getenvOrDefault("LOG_LEVEL", "debug"),
 			LogFormat:   getenvOrDefault("LOG_FORMAT", "json"),
 			LogShowLine: getenvOrDefault("LOG_SHOW_LINE", "true") == "true",
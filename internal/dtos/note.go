@@ -1,6 +1,8 @@
 package dtos
 
 import (
+	"io"
+	"iter"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
@@ -11,14 +13,32 @@ type NoteSlug = string
 type GetNote struct {
 	Content              string
 	KeepBeforeExpiration bool
+	EncryptionScheme     string
+	EncryptionVersion    int
 	ReadAt               time.Time
 	CreatedAt            time.Time
 	ExpiresAt            time.Time
+	Attachments          []NoteAttachment
+
+	// ReadToken is issued to the reader along with attachments, or if the note can be replied to,
+	// attachments are downloaded, and replies are made with it.
+	ReadToken string
+
+	// ReplyAllowed is whether the reader can still reply to the note.
+	ReplyAllowed bool
 }
 
 type NoteMetadata struct {
-	HasPassword bool
-	CreatedAt   time.Time
+	HasPassword      bool
+	EncryptionScheme string
+	ViewsLeft        int
+	CreatedAt        time.Time
+
+	// CodeRequired is whether the note is bound to an email, and is released only with the one-time code.
+	CodeRequired bool
+
+	// ApprovalRequired is whether the note is released only once its author approves the reader's access request.
+	ApprovalRequired bool
 }
 
 type CreateNote struct {
@@ -27,21 +47,265 @@ type CreateNote struct {
 	Slug                 NoteSlug
 	KeepBeforeExpiration bool
 	Password             string
+	EncryptionScheme     string
+	EncryptionVersion    int
+	MaxViews             int
+	NotifyAuthor         bool
+	BurnOnLockout        bool
+	AllowReply           bool
 	CreatedAt            time.Time
 	ExpiresAt            time.Time
+	AvailableFrom        time.Time
+
+	// SlugStrategy and SlugLength are how the slug is generated, if it's not set.
+	// Zero values mean the instance's defaults.
+	SlugStrategy string
+	SlugLength   int
+
+	// RecipientsCount and RecipientLabels are who the note is sent to,
+	// used only when it's created with recipients, see [models.NewNoteRecipients].
+	RecipientsCount int
+	RecipientLabels []string
+
+	// Switch is the note's dead man's switch, nil if it has none.
+	Switch *CreateNoteSwitch
+
+	// Link is who the link to the note is emailed to, once it's created, nil if it's not emailed.
+	Link *CreateNoteLink
+
+	// BoundEmail is the email the note is bound to, empty if it's not bound, see [models.Note.BoundEmail].
+	BoundEmail string
+
+	// InboxEmail is email of the registered user the note is sent to, empty if it's not sent to an inbox,
+	// see [models.Note.InboxUserID].
+	InboxEmail string
+
+	// RequiresApproval is whether the note is released only to readers approved by its author,
+	// see [models.Note.RequiresApproval].
+	RequiresApproval bool
+
+	// CreatorIP is the ip the note is created from, notes without an author are counted toward its quota.
+	CreatorIP string
+
+	// Attachments are consumed one by one, the content of each should be read before getting the next one.
+	// Could be nil if there's no attachments.
+	Attachments iter.Seq2[CreateNoteAttachment, error]
 }
 
-type NoteDetailed struct {
-	Content              string
+// CreateNoteSwitch is the dead man's switch of the note,
+// the note is released to Recipients if its author doesn't check in within CheckInInterval.
+type CreateNoteSwitch struct {
+	CheckInInterval time.Duration
+	Recipients      []string
+}
+
+// CreateNoteLink is the email with the link to the note, sent to RecipientEmail on behalf of the author.
+type CreateNoteLink struct {
+	RecipientEmail string
+	Message        string
+}
+
+// NoteSwitchReminder reminds the author to check in, before the note is released at ReleaseAt.
+type NoteSwitchReminder struct {
+	Slug        NoteSlug
+	AuthorEmail string
+	ReleaseAt   time.Time
+}
+
+// DueNoteSwitch is the dead man's switch which author has missed the check-in,
+// so the note should be released to its recipients.
+type DueNoteSwitch struct {
+	NoteID      uuid.UUID
+	Slug        NoteSlug
+	AuthorID    uuid.UUID
+	AuthorEmail string
+
+	// Recipients are who the note hasn't been sent to yet.
+	Recipients []string
+}
+
+// CreatedNote is the note that has been created.
+type CreatedNote struct {
+	Slug NoteSlug
+
+	// Recipients are links to the note, if it's created with recipients, Slug is the first one of them.
+	Recipients []NoteRecipient
+
+	// ManagementToken lets the creator manage the note without an account,
+	// it's set only for notes created without an author.
+	ManagementToken string
+}
+
+// ManagedNote is the note created without an author, as it's seen by its creator with the management token.
+type ManagedNote struct {
 	Slug                 NoteSlug
 	KeepBeforeExpiration bool
-	HasPassword          bool
+	MaxViews             int
+	Views                int
 	CreatedAt            time.Time
 	ExpiresAt            time.Time
+	AvailableFrom        time.Time
 	ReadAt               time.Time
 }
 
+// NoteRecipient is one of recipients of the note, with their own link to it.
+type NoteRecipient struct {
+	Label string
+	Slug  NoteSlug
+}
+
+// CreateNoteReply is the reply to a note, it's burnt on read like any other note.
+type CreateNoteReply struct {
+	// ReadToken is the token given to the reader along with the note's content.
+	ReadToken string
+
+	Content           string
+	Password          string
+	EncryptionScheme  string
+	EncryptionVersion int
+	CreatedAt         time.Time
+}
+
+type CreateNoteAttachment struct {
+	Filename    string
+	ContentType string
+	Content     io.Reader
+}
+
+type NoteAttachment struct {
+	ID          uuid.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+type NoteDetailed struct {
+	Content              string
+	Slug                 NoteSlug
+	KeepBeforeExpiration bool
+	HasPassword          bool
+	EncryptionScheme     string
+	EncryptionVersion    int
+	MaxViews             int
+	Views                int
+	NotifyAuthor         bool
+	BurnOnLockout        bool
+	AllowReply           bool
+	ReplySlug            NoteSlug
+	Version              int
+
+	// GroupID is shared by notes sent to several recipients at once, [uuid.Nil] for the rest.
+	GroupID        uuid.UUID
+	RecipientLabel string
+
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	AvailableFrom time.Time
+	ReadAt        time.Time
+
+	// InboxEmail is email of the user the note is sent to, empty if it's not sent to an inbox,
+	// and DeliveredAt is when the note has been listed in the user's inbox.
+	// InboxRecipientDeleted is whether the user has deleted their account since.
+	InboxEmail            string
+	DeliveredAt           time.Time
+	InboxRecipientDeleted bool
+}
+
+// CreatedNoteAccessRequest is the reader's access request, the token is returned only once,
+// and the note is read with it once the request is approved.
+type CreatedNoteAccessRequest struct {
+	ID        uuid.UUID
+	Token     string
+	ExpiresAt time.Time
+
+	// TokenExpiresAt is the latest time the token could be used,
+	// i.e. if the request is approved right before it expires.
+	TokenExpiresAt time.Time
+}
+
+// InboxNote is the note in the user's inbox, sent by another user.
+type InboxNote struct {
+	Slug          NoteSlug
+	SenderEmail   string
+	HasPassword   bool
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	AvailableFrom time.Time
+}
+
+// NotesFilter filters the author's notes, zero fields are not applied.
+type NotesFilter struct {
+	Status        string
+	HasPassword   *bool
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+}
+
+// ListNotes is a query of a page of the author's notes, zero fields are not applied,
+// or set to their defaults.
+type ListNotes struct {
+	NotesFilter
+
+	SortBy string
+	Order  string
+	Limit  int
+
+	// Cursor is the [NotesPage.NextCursor] of the previous page, empty for the first one.
+	Cursor string
+}
+
+type NotesPage struct {
+	Notes []NoteDetailed
+
+	// Total is how many notes match the query, on all pages.
+	Total int
+
+	// NextCursor is empty if it's the last page.
+	NextCursor string
+}
+
 type PatchNote struct {
 	ExpiresAt            *time.Time
 	KeepBeforeExpiration *bool
+
+	// AvailableFrom is set to zero time to make the note available right away.
+	AvailableFrom *time.Time
+}
+
+// BulkNotes is an action applied to many of the author's notes,
+// selected either by Slugs or by Filter.
+type BulkNotes struct {
+	Action string
+	Slugs  []NoteSlug
+	Filter *NotesFilter
+
+	// ExpiresAt is required by the set_expiration action.
+	ExpiresAt time.Time
+
+	// Password is required by the set_password action.
+	Password string
+}
+
+type BulkNoteResult struct {
+	Slug   NoteSlug
+	Status string
+}
+
+type BulkNotesResult struct {
+	Results []BulkNoteResult
+
+	// Done is how many notes the action was applied to.
+	Done int
+}
+
+// ExpiredNote is a note which content was purged after expiration,
+// AuthorID is [uuid.Nil] if the note has no author,
+// AuthorEmail is set if the author should be notified that it expired unread.
+type ExpiredNote struct {
+	Slug        NoteSlug
+	AuthorID    uuid.UUID
+	AuthorEmail string
+	ExpiresAt   time.Time
 }
@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+// QuotaUsage is the user's quota, and how much of it is used. Zero limit means there's no limit.
+type QuotaUsage struct {
+	Tier            string
+	MaxContentBytes int
+	NotesPerDay     int
+	MaxActiveNotes  int
+	MaxExpiresIn    time.Duration
+
+	NotesToday  int
+	ActiveNotes int
+
+	// ResetsAt is when the daily limit resets.
+	ResetsAt time.Time
+}
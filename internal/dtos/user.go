@@ -49,6 +49,7 @@ type UserInfo struct {
 	CreatedAt    time.Time
 	LastLoginAt  time.Time
 	NotesCreated int
+	Quota        QuotaUsage
 }
 
 type ChangeEmail struct {
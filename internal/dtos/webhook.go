@@ -0,0 +1,49 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type CreateWebhook struct {
+	URL    string
+	Events []string
+}
+
+// PatchWebhook is the webhook's update, nil fields are left as is.
+type PatchWebhook struct {
+	URL    *string
+	Events []string
+}
+
+type Webhook struct {
+	ID     uuid.UUID
+	URL    string
+	Events []string
+
+	// Secret is returned only when the webhook is created.
+	Secret    string
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	Error          string
+	CreatedAt      time.Time
+	NextAttemptAt  time.Time
+	LastAttemptAt  time.Time
+}
+
+// WebhookDeliveryAttempt is the outcome of a single delivery attempt.
+type WebhookDeliveryAttempt struct {
+	// Status is the status of delivery after the attempt,
+	// if it's still pending, the delivery is going to be retried.
+	Status   string
+	Duration time.Duration
+}
@@ -0,0 +1,231 @@
+// Package envelope implements envelope encryption of data at rest.
+//
+// Every value is encrypted with its own random data key, which is then encrypted(wrapped)
+// with a key encryption key provided by [KeyProvider]. The wrapped data key is stored
+// alongside the value, and the ID of the key encryption key is stored next to it, so keys
+// can be rotated without downtime: old values are still decryptable with the old key,
+// while the new ones are encrypted with the current one.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of key encryption keys, and data keys in bytes.
+const KeySize = 32
+
+const formatVersion byte = 1
+
+var (
+	ErrKeyNotFound       = errors.New("envelope: key not found")
+	ErrInvalidKey        = errors.New("envelope: invalid key")
+	ErrInvalidCiphertext = errors.New("envelope: invalid ciphertext")
+)
+
+var (
+	dataKeyAdditionalData = []byte("onasty:envelope:dek:v1")
+	contentAdditionalData = []byte("onasty:envelope:content:v1")
+)
+
+type Encryptor interface {
+	// Encrypt encrypts the plaintext with the current key.
+	// Returns the ciphertext, and ID of the key it was encrypted with.
+	// Empty plaintext is returned as is, with an empty key ID.
+	Encrypt(ctx context.Context, plaintext string) (string, string, error)
+
+	// Decrypt decrypts the ciphertext produced by [Encryptor.Encrypt].
+	// Values with empty keyID are considered to be stored before the encryption was enabled,
+	// and returned as is.
+	//
+	// Returns [ErrKeyNotFound] if the key is not known.
+	Decrypt(ctx context.Context, ciphertext, keyID string) (string, error)
+
+	// CurrentKeyID returns ID of the key new values are encrypted with.
+	// Returns empty string if encryption is disabled.
+	CurrentKeyID(ctx context.Context) (string, error)
+}
+
+var _ Encryptor = (*Envelope)(nil)
+
+type Envelope struct {
+	keys KeyProvider
+}
+
+// New creates an [Envelope] that uses the keys.
+// If keys is nil, the encryption is disabled: values are stored as is,
+// and only the ones without key ID can be decrypted.
+func New(keys KeyProvider) *Envelope {
+	return &Envelope{keys: keys}
+}
+
+// NewFromKeyfile creates an [Envelope] with keys loaded from the keyfile, see [LoadKeyRing].
+// If path is empty, the encryption is disabled.
+func NewFromKeyfile(path string) (*Envelope, error) {
+	if path == "" {
+		return New(nil), nil
+	}
+
+	keys, err := LoadKeyRing(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(keys), nil
+}
+
+func (e *Envelope) Encrypt(ctx context.Context, plaintext string) (string, string, error) {
+	if e.keys == nil || plaintext == "" {
+		return plaintext, "", nil
+	}
+
+	kek, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	dek := make([]byte, KeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", "", err
+	}
+
+	wrappedDEK, err := seal(kek.Material, dek, dataKeyAdditionalData)
+	if err != nil {
+		return "", "", err
+	}
+
+	sealed, err := seal(dek, []byte(plaintext), contentAdditionalData)
+	if err != nil {
+		return "", "", err
+	}
+
+	out := make([]byte, 0, 1+len(wrappedDEK)+len(sealed))
+	out = append(out, formatVersion)
+	out = append(out, wrappedDEK...)
+	out = append(out, sealed...)
+
+	return base64.StdEncoding.EncodeToString(out), kek.ID, nil
+}
+
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext, keyID string) (string, error) {
+	if keyID == "" || ciphertext == "" {
+		return ciphertext, nil
+	}
+
+	if e.keys == nil {
+		return "", ErrKeyNotFound
+	}
+
+	kek, err := e.keys.Key(ctx, keyID)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(raw) < 1+wrappedDEKSize || raw[0] != formatVersion {
+		return "", ErrInvalidCiphertext
+	}
+
+	dek, err := open(kek.Material, raw[1:1+wrappedDEKSize], dataKeyAdditionalData)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dek, raw[1+wrappedDEKSize:], contentAdditionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func (e *Envelope) CurrentKeyID(ctx context.Context) (string, error) {
+	if e.keys == nil {
+		return "", nil
+	}
+
+	kek, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return kek.ID, nil
+}
+
+// CheckKeys checks that every key, values are encrypted with, is known, empty IDs are ignored.
+// Returns [ErrKeyNotFound] along with ID of the first unknown key.
+func (e *Envelope) CheckKeys(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+
+		if e.keys == nil {
+			return fmt.Errorf("%w: %q", ErrKeyNotFound, id)
+		}
+
+		if _, err := e.keys.Key(ctx, id); err != nil {
+			return fmt.Errorf("%w: %q", err, id)
+		}
+	}
+
+	return nil
+}
+
+const (
+	nonceSize = 12
+	tagSize   = 16
+
+	// wrappedDEKSize is the size of sealed data key: nonce, the key itself, and authentication tag.
+	wrappedDEKSize = nonceSize + KeySize + tagSize
+)
+
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize, nonceSize+len(plaintext)+tagSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < nonceSize+tagSize {
+		return nil, ErrInvalidCiphertext
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
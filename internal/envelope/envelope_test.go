@@ -0,0 +1,239 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyRing(t *testing.T, current string, ids ...string) *KeyRing {
+	t.Helper()
+
+	keys := make(map[string][]byte, len(ids))
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, KeySize)
+	}
+
+	kr, err := NewKeyRing(current, keys)
+	require.NoError(t, err)
+
+	return kr
+}
+
+func TestEnvelope_EncryptDecrypt(t *testing.T) {
+	ctx := t.Context()
+	plaintext := "the secret content"
+
+	t.Run("round trip", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k1", "k1"))
+
+		ciphertext, keyID, err := env.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		require.Equal(t, "k1", keyID)
+		require.NotContains(t, ciphertext, plaintext)
+
+		decrypted, err := env.Decrypt(ctx, ciphertext, keyID)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("rotated key", func(t *testing.T) {
+		old := New(newTestKeyRing(t, "k1", "k1"))
+		ciphertext, keyID, err := old.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+
+		rotated := New(newTestKeyRing(t, "k2", "k1", "k2"))
+		decrypted, err := rotated.Decrypt(ctx, ciphertext, keyID)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+
+		_, newKeyID, err := rotated.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		require.Equal(t, "k2", newKeyID)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k1", "k1"))
+		ciphertext, _, err := env.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+
+		_, err = env.Decrypt(ctx, ciphertext, "k2")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k1", "k1"))
+		ciphertext, keyID, err := env.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+
+		tampered := []byte(ciphertext)
+		tampered[len(tampered)-4] ^= 'a' ^ 'b'
+
+		_, err = env.Decrypt(ctx, string(tampered), keyID)
+		require.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("legacy plaintext", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k1", "k1"))
+
+		decrypted, err := env.Decrypt(ctx, plaintext, "")
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("empty plaintext", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k1", "k1"))
+
+		ciphertext, keyID, err := env.Encrypt(ctx, "")
+		require.NoError(t, err)
+		require.Empty(t, ciphertext)
+		require.Empty(t, keyID)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		env := New(nil)
+
+		ciphertext, keyID, err := env.Encrypt(ctx, plaintext)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, ciphertext)
+		require.Empty(t, keyID)
+
+		_, err = env.Decrypt(ctx, "whatever", "k1")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
+
+func TestEnvelope_CheckKeys(t *testing.T) {
+	ctx := t.Context()
+
+	t.Run("known keys", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k2", "k1", "k2"))
+		require.NoError(t, env.CheckKeys(ctx, []string{"k1", "k2", ""}))
+	})
+
+	t.Run("retired key", func(t *testing.T) {
+		env := New(newTestKeyRing(t, "k2", "k2"))
+		err := env.CheckKeys(ctx, []string{"k2", "k1"})
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		require.ErrorContains(t, err, "k1")
+	})
+
+	t.Run("disabled encryption", func(t *testing.T) {
+		env := New(nil)
+		require.NoError(t, env.CheckKeys(ctx, []string{""}))
+		require.ErrorIs(t, env.CheckKeys(ctx, []string{"k1"}), ErrKeyNotFound)
+	})
+}
+
+func TestNewKeyRing(t *testing.T) {
+	t.Run("current key is missing", func(t *testing.T) {
+		_, err := NewKeyRing("k2", map[string][]byte{"k1": make([]byte, KeySize)})
+		require.ErrorIs(t, err, ErrNoCurrentKey)
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := NewKeyRing("k1", map[string][]byte{"k1": make([]byte, 16)})
+		require.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	keyfile := `{
+  "current": "k2",
+  "keys": {
+    "k1": "` + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)) + `",
+    "k2": "` + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, KeySize)) + `"
+  }
+}`
+	require.NoError(t, os.WriteFile(path, []byte(keyfile), 0o600))
+
+	kr, err := LoadKeyRing(path)
+	require.NoError(t, err)
+
+	current, err := kr.CurrentKey(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "k2", current.ID)
+
+	old, err := kr.Key(t.Context(), "k1")
+	require.NoError(t, err)
+	require.Equal(t, bytes.Repeat([]byte{1}, KeySize), old.Material)
+}
+
+func TestEnvelope_EncryptDecryptStream(t *testing.T) {
+	ctx := t.Context()
+	env := New(newTestKeyRing(t, "k1", "k1"))
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 42} {
+		t.Run("round trip "+strconv.Itoa(size), func(t *testing.T) {
+			plaintext := make([]byte, size)
+			_, err := rand.Read(plaintext)
+			require.NoError(t, err)
+
+			r, keyID, err := env.EncryptStream(ctx, bytes.NewReader(plaintext))
+			require.NoError(t, err)
+			require.Equal(t, "k1", keyID)
+
+			ciphertext, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			r, err = env.DecryptStream(ctx, bytes.NewReader(ciphertext), keyID)
+			require.NoError(t, err)
+
+			decrypted, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, plaintext, decrypted)
+		})
+	}
+
+	encrypt := func(t *testing.T, plaintext []byte) []byte {
+		t.Helper()
+
+		r, _, err := env.EncryptStream(ctx, bytes.NewReader(plaintext))
+		require.NoError(t, err)
+
+		ciphertext, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return ciphertext
+	}
+
+	decrypt := func(ciphertext []byte) error {
+		r, err := env.DecryptStream(ctx, bytes.NewReader(ciphertext), "k1")
+		if err != nil {
+			return err
+		}
+
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		ciphertext := encrypt(t, make([]byte, 2*chunkSize))
+		require.ErrorIs(t, decrypt(ciphertext[:streamHeaderSize+sealedChunkSize]), ErrInvalidCiphertext)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		ciphertext := encrypt(t, make([]byte, chunkSize+1))
+		ciphertext[len(ciphertext)-1] ^= 1
+		require.ErrorIs(t, decrypt(ciphertext), ErrInvalidCiphertext)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		plaintext := []byte("the content")
+		r, keyID, err := New(nil).EncryptStream(ctx, bytes.NewReader(plaintext))
+		require.NoError(t, err)
+		require.Empty(t, keyID)
+
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, got)
+	})
+}
@@ -0,0 +1,111 @@
+package envelope
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// Key is a key encryption key.
+type Key struct {
+	ID       string
+	Material []byte
+}
+
+type KeyProvider interface {
+	// CurrentKey returns the key new values should be encrypted with.
+	CurrentKey(ctx context.Context) (Key, error)
+
+	// Key returns the key by its ID.
+	// Returns [ErrKeyNotFound] if there's no such key.
+	Key(ctx context.Context, id string) (Key, error)
+}
+
+var _ KeyProvider = (*KeyRing)(nil)
+
+var ErrNoCurrentKey = errors.New("envelope: current key is not in the key ring")
+
+// KeyRing is a static set of keys, one of which is the current one.
+type KeyRing struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewKeyRing creates a key ring, keys is a map of key IDs to the key material.
+func NewKeyRing(current string, keys map[string][]byte) (*KeyRing, error) {
+	for id, key := range keys {
+		if id == "" || len(key) != KeySize {
+			return nil, ErrInvalidKey
+		}
+	}
+
+	if _, ok := keys[current]; !ok {
+		return nil, ErrNoCurrentKey
+	}
+
+	return &KeyRing{
+		current: current,
+		keys:    keys,
+	}, nil
+}
+
+type keyfile struct {
+	Current string            `json:"current"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// LoadKeyRing loads the key ring from the json keyfile, that looks like:
+//
+//	{
+//	  "current": "2026-10",
+//	  "keys": {
+//	    "2026-10": "<base64 encoded 32 bytes>",
+//	    "2025-01": "<base64 encoded 32 bytes>"
+//	  }
+//	}
+//
+// To rotate the key, add a new one, and make it current; the old key should be kept
+// until all values are re-encrypted with the new one.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf keyfile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string][]byte, len(kf.Keys))
+	for id, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		keys[id] = key
+	}
+
+	return NewKeyRing(kf.Current, keys)
+}
+
+func (k *KeyRing) CurrentKey(_ context.Context) (Key, error) {
+	return Key{
+		ID:       k.current,
+		Material: k.keys[k.current],
+	}, nil
+}
+
+func (k *KeyRing) Key(_ context.Context, id string) (Key, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return Key{}, ErrKeyNotFound
+	}
+
+	return Key{
+		ID:       id,
+		Material: key,
+	}, nil
+}
@@ -0,0 +1,230 @@
+package envelope
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+type StreamEncryptor interface {
+	// EncryptStream returns reader of r's content encrypted with the current key,
+	// and ID of the key it's encrypted with.
+	// If encryption is disabled, r is returned as is, with an empty key ID.
+	EncryptStream(ctx context.Context, r io.Reader) (io.Reader, string, error)
+
+	// DecryptStream returns reader of r's content decrypted, r should be produced by
+	// [StreamEncryptor.EncryptStream]. Empty keyID means that the content is not encrypted.
+	//
+	// Returns [ErrKeyNotFound] if the key is not known,
+	// the reader returns [ErrInvalidCiphertext] if the content was tampered with.
+	DecryptStream(ctx context.Context, r io.Reader, keyID string) (io.Reader, error)
+}
+
+var _ StreamEncryptor = (*Envelope)(nil)
+
+// The stream is split into chunks, each of them is sealed separately, so it can be
+// encrypted and decrypted without loading the whole content into memory.
+// Nonce of each chunk consists of random prefix, chunk counter, and flag that marks the last chunk,
+// so chunks cannot be reordered, dropped, or truncated unnoticed.
+const (
+	chunkSize       = 64 * 1024
+	sealedChunkSize = chunkSize + tagSize
+	noncePrefixSize = nonceSize - 4 - 1
+
+	streamHeaderSize = 1 + wrappedDEKSize + noncePrefixSize
+)
+
+var streamAdditionalData = []byte("onasty:envelope:stream:v1")
+
+func (e *Envelope) EncryptStream(ctx context.Context, r io.Reader) (io.Reader, string, error) {
+	if e.keys == nil {
+		return r, "", nil
+	}
+
+	kek, err := e.keys.CurrentKey(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dek := make([]byte, KeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, "", err
+	}
+
+	wrappedDEK, err := seal(kek.Material, dek, dataKeyAdditionalData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, "", err
+	}
+
+	header := make([]byte, 0, streamHeaderSize)
+	header = append(header, formatVersion)
+	header = append(header, wrappedDEK...)
+	header = append(header, make([]byte, noncePrefixSize)...)
+	if _, err := rand.Read(header[1+wrappedDEKSize:]); err != nil {
+		return nil, "", err
+	}
+
+	return &encryptingReader{
+		src:         r,
+		aead:        aead,
+		noncePrefix: header[1+wrappedDEKSize:],
+		plaintext:   make([]byte, chunkSize),
+		sealed:      make([]byte, 0, sealedChunkSize),
+		out:         header,
+	}, kek.ID, nil
+}
+
+func (e *Envelope) DecryptStream(ctx context.Context, r io.Reader, keyID string) (io.Reader, error) {
+	if keyID == "" {
+		return r, nil
+	}
+
+	if e.keys == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	kek, err := e.keys.Key(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil || header[0] != formatVersion {
+		return nil, ErrInvalidCiphertext
+	}
+
+	dek, err := open(kek.Material, header[1:1+wrappedDEKSize], dataKeyAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptingReader{
+		src:         r,
+		aead:        aead,
+		noncePrefix: header[1+wrappedDEKSize:],
+		sealed:      make([]byte, sealedChunkSize),
+		plaintext:   make([]byte, 0, chunkSize),
+	}, nil
+}
+
+type encryptingReader struct {
+	src         io.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	plaintext   []byte
+	sealed      []byte
+	out         []byte
+	done        bool
+}
+
+func (e *encryptingReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+
+		if err := e.sealNextChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+
+	return n, nil
+}
+
+// sealNextChunk reads the next chunk from the source, and seals it.
+// Chunk that is shorter than [chunkSize] is the last one, if the content's size is multiple of [chunkSize],
+// the last chunk is empty.
+func (e *encryptingReader) sealNextChunk() error {
+	n, err := io.ReadFull(e.src, e.plaintext)
+	last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !last {
+		return err
+	}
+
+	nonce := chunkNonce(e.noncePrefix, e.counter, last)
+	e.out = e.aead.Seal(e.sealed[:0], nonce, e.plaintext[:n], streamAdditionalData)
+	e.counter++
+	e.done = last
+
+	return nil
+}
+
+type decryptingReader struct {
+	src         io.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	sealed      []byte
+	plaintext   []byte
+	out         []byte
+	done        bool
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+
+		if err := d.openNextChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+
+	return n, nil
+}
+
+func (d *decryptingReader) openNextChunk() error {
+	n, err := io.ReadFull(d.src, d.sealed)
+	last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	if err != nil && !last {
+		return err
+	}
+
+	if n < tagSize {
+		return ErrInvalidCiphertext
+	}
+
+	nonce := chunkNonce(d.noncePrefix, d.counter, last)
+	plaintext, err := d.aead.Open(d.plaintext[:0], nonce, d.sealed[:n], streamAdditionalData)
+	if err != nil {
+		return ErrInvalidCiphertext
+	}
+
+	d.out = plaintext
+	d.counter++
+	d.done = last
+
+	return nil
+}
+
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, nonceSize)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
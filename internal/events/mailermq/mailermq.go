@@ -3,6 +3,7 @@ package mailermq
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/olexsmir/onasty/internal/events"
@@ -20,6 +21,27 @@ type Mailer interface {
 
 	// SendChangeEmailVerification sends an email with a change email verification token to the user.
 	SendChangeEmailConfirmation(ctx context.Context, inp SendChangeEmailConfirmationRequest) error
+
+	// SendNoteReadNotice notifies the note's author that the note has been read.
+	SendNoteReadNotice(ctx context.Context, inp SendNoteReadNoticeRequest) error
+
+	// SendNoteExpiredNotice notifies the note's author that the note has expired unread.
+	SendNoteExpiredNotice(ctx context.Context, inp SendNoteExpiredNoticeRequest) error
+
+	// SendNoteSwitchReminder reminds the note's author to check in, before the note is released.
+	SendNoteSwitchReminder(ctx context.Context, inp SendNoteSwitchReminderRequest) error
+
+	// SendNoteReleased sends the link to the note to its recipient, once the author has missed the check-in.
+	SendNoteReleased(ctx context.Context, inp SendNoteReleasedRequest) error
+
+	// SendNoteLink sends the link to the note to the recipient, on behalf of the note's author.
+	SendNoteLink(ctx context.Context, inp SendNoteLinkRequest) error
+
+	// SendNoteCode sends the one-time code, the note is released with, to the email the note is bound to.
+	SendNoteCode(ctx context.Context, inp SendNoteCodeRequest) error
+
+	// SendNoteAccessRequest notifies the note's author that a reader has requested access to the note.
+	SendNoteAccessRequest(ctx context.Context, inp SendNoteAccessRequestRequest) error
 }
 
 type MailerMQ struct {
@@ -127,3 +149,203 @@ func (m MailerMQ) SendChangeEmailConfirmation(
 
 	return events.CheckRespForError(resp)
 }
+
+type SendNoteReadNoticeRequest struct {
+	Receiver string
+	Slug     string
+	ReadAt   time.Time
+}
+
+func (m MailerMQ) SendNoteReadNotice(ctx context.Context, inp SendNoteReadNoticeRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "note_read",
+		Options: map[string]string{
+			"slug":    inp.Slug,
+			"read_at": inp.ReadAt.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
+
+type SendNoteExpiredNoticeRequest struct {
+	Receiver  string
+	Slug      string
+	ExpiresAt time.Time
+}
+
+func (m MailerMQ) SendNoteExpiredNotice(ctx context.Context, inp SendNoteExpiredNoticeRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "note_expired",
+		Options: map[string]string{
+			"slug":       inp.Slug,
+			"expired_at": inp.ExpiresAt.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
+
+type SendNoteSwitchReminderRequest struct {
+	Receiver  string
+	Slug      string
+	ReleaseAt time.Time
+}
+
+func (m MailerMQ) SendNoteSwitchReminder(ctx context.Context, inp SendNoteSwitchReminderRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "note_switch_reminder",
+		Options: map[string]string{
+			"slug":       inp.Slug,
+			"release_at": inp.ReleaseAt.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
+
+type SendNoteReleasedRequest struct {
+	Receiver    string
+	Slug        string
+	AuthorEmail string
+}
+
+func (m MailerMQ) SendNoteReleased(ctx context.Context, inp SendNoteReleasedRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "note_released",
+		Options: map[string]string{
+			"slug":   inp.Slug,
+			"author": inp.AuthorEmail,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
+
+type SendNoteLinkRequest struct {
+	Receiver    string
+	Slug        string
+	SenderEmail string
+	Message     string
+}
+
+func (m MailerMQ) SendNoteLink(ctx context.Context, inp SendNoteLinkRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "send_note_link",
+		Options: map[string]string{
+			"slug":    inp.Slug,
+			"sender":  inp.SenderEmail,
+			"message": inp.Message,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
+
+type SendNoteCodeRequest struct {
+	Receiver string
+	Slug     string
+	Code     string
+}
+
+func (m MailerMQ) SendNoteCode(ctx context.Context, inp SendNoteCodeRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "note_code",
+		Options: map[string]string{
+			"slug": inp.Slug,
+			"code": inp.Code,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
+
+type SendNoteAccessRequestRequest struct {
+	Receiver  string
+	Slug      string
+	Reason    string
+	ExpiresAt time.Time
+}
+
+func (m MailerMQ) SendNoteAccessRequest(ctx context.Context, inp SendNoteAccessRequestRequest) error {
+	req, err := json.Marshal(sendRequest{
+		RequestID:    reqid.GetContext(ctx),
+		Receiver:     inp.Receiver,
+		TemplateName: "note_access_request",
+		Options: map[string]string{
+			"slug":       inp.Slug,
+			"reason":     inp.Reason,
+			"expires_at": inp.ExpiresAt.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := m.nc.RequestWithContext(ctx, sendTopic, req)
+	if err != nil {
+		return err
+	}
+
+	return events.CheckRespForError(resp)
+}
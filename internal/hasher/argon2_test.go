@@ -0,0 +1,68 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2idParams = Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2idHasher_Hash(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	hashed, err := hasher.Hash("qwerty123")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	require.True(t, hasher.Recognizes(hashed))
+
+	// every hash has its own salt
+	again, err := hasher.Hash("qwerty123")
+	require.NoError(t, err)
+	require.NotEqual(t, hashed, again)
+}
+
+func TestArgon2idHasher_Compare(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+	input := "qwerty123"
+
+	hashed, err := hasher.Hash(input)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, hasher.Compare(hashed, input))
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		require.ErrorIs(t, hasher.Compare(hashed, input+"4"), ErrMismatchedHashes)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		for _, hash := range []string{
+			"",
+			"$argon2id$v=19$m=1024,t=1,p=1$salt",
+			"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+		} {
+			require.ErrorIs(t, hasher.Compare(hash, input), ErrHashMalformed, hash)
+		}
+	})
+}
+
+func TestArgon2idHasher_NeedsRehash(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	hashed, err := hasher.Hash("qwerty123")
+	require.NoError(t, err)
+	require.False(t, hasher.NeedsRehash(hashed))
+
+	stronger := testArgon2idParams
+	stronger.Iterations++
+	require.True(t, NewArgon2idHasher(stronger).NeedsRehash(hashed))
+}
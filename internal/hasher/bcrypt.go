@@ -0,0 +1,45 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var _ Verifier = (*BcryptHasher)(nil)
+
+// BcryptHasher hashes with bcrypt, its hashes are salted, and in the modular crypt format.
+// Only first 72 bytes of input are used by bcrypt, so longer inputs are rejected.
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(inp string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(inp), h.cost)
+	return string(hash), err
+}
+
+func (h *BcryptHasher) Compare(hash, plain string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedHashes
+	}
+	return err
+}
+
+func (h *BcryptHasher) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") ||
+		strings.HasPrefix(hash, "$2b$") ||
+		strings.HasPrefix(hash, "$2y$")
+}
+
+// NeedsRehash reports whether the hash is made with other cost than the hasher's.
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
@@ -0,0 +1,33 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBcryptHasher_Compare(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+	input := "qwerty123"
+
+	hashed, err := hasher.Hash(input)
+	require.NoError(t, err)
+	require.True(t, hasher.Recognizes(hashed))
+
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, hasher.Compare(hashed, input))
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		require.ErrorIs(t, hasher.Compare(hashed, input+"4"), ErrMismatchedHashes)
+	})
+}
+
+func TestBcryptHasher_NeedsRehash(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	hashed, err := hasher.Hash("qwerty123")
+	require.NoError(t, err)
+	require.False(t, hasher.NeedsRehash(hashed))
+	require.True(t, NewBcryptHasher(bcrypt.MinCost+1).NeedsRehash(hashed))
+}
@@ -2,7 +2,11 @@ package hasher
 
 import "errors"
 
-var ErrMismatchedHashes = errors.New("hashes are mismatched")
+var (
+	ErrMismatchedHashes = errors.New("hashes are mismatched")
+	ErrHashUnrecognized = errors.New("hash is not made by any of known hashers")
+	ErrHashMalformed    = errors.New("hash is malformed")
+)
 
 type Hasher interface {
 	// Hash takes a string as input and returns its hash
@@ -12,3 +16,20 @@ type Hasher interface {
 	// in case of mismatch returns [ErrMismatchedHashes]
 	Compare(hash, plain string) error
 }
+
+// Verifier is a [Hasher] that recognizes hashes made by it.
+type Verifier interface {
+	Hasher
+
+	// Recognizes reports whether the hash is made by the hasher, it doesn't verify the hash.
+	Recognizes(hash string) bool
+}
+
+// Rehasher is a [Hasher] that can tell when a hash should be replaced with a new one.
+type Rehasher interface {
+	Hasher
+
+	// NeedsRehash reports whether the hash is made by another algorithm,
+	// or with outdated parameters, so it should be hashed again once the plain value is known.
+	NeedsRehash(hash string) bool
+}
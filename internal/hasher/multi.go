@@ -0,0 +1,49 @@
+package hasher
+
+var _ Rehasher = (*MultiHasher)(nil)
+
+// MultiHasher hashes with the primary hasher, and verifies hashes made by any of the known hashers,
+// so hashes made by the legacy ones are accepted until they're rehashed with the primary one.
+type MultiHasher struct {
+	primary Verifier
+	legacy  []Verifier
+}
+
+func NewMultiHasher(primary Verifier, legacy ...Verifier) *MultiHasher {
+	return &MultiHasher{
+		primary: primary,
+		legacy:  legacy,
+	}
+}
+
+func (h *MultiHasher) Hash(inp string) (string, error) {
+	return h.primary.Hash(inp)
+}
+
+// Compare compares the hash with the hasher that made it.
+// Returns [ErrHashUnrecognized] if it's made by none of them.
+func (h *MultiHasher) Compare(hash, plain string) error {
+	if h.primary.Recognizes(hash) {
+		return h.primary.Compare(hash, plain)
+	}
+
+	for _, l := range h.legacy {
+		if l.Recognizes(hash) {
+			return l.Compare(hash, plain)
+		}
+	}
+
+	return ErrHashUnrecognized
+}
+
+func (h *MultiHasher) NeedsRehash(hash string) bool {
+	if !h.primary.Recognizes(hash) {
+		return true
+	}
+
+	if r, ok := h.primary.(Rehasher); ok {
+		return r.NeedsRehash(hash)
+	}
+
+	return false
+}
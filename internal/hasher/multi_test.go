@@ -0,0 +1,35 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMultiHasher(t *testing.T) {
+	legacy := NewSHA256Hasher("salt")
+	bcryptHasher := NewBcryptHasher(bcrypt.MinCost)
+	hasher := NewMultiHasher(NewArgon2idHasher(testArgon2idParams), legacy, bcryptHasher)
+	input := "qwerty123"
+
+	t.Run("hashes with the primary hasher", func(t *testing.T) {
+		hashed, err := hasher.Hash(input)
+		require.NoError(t, err)
+		require.NoError(t, hasher.Compare(hashed, input))
+		require.False(t, hasher.NeedsRehash(hashed))
+	})
+	t.Run("verifies legacy hashes", func(t *testing.T) {
+		for _, l := range []Hasher{legacy, bcryptHasher} {
+			hashed, err := l.Hash(input)
+			require.NoError(t, err)
+
+			require.NoError(t, hasher.Compare(hashed, input))
+			require.ErrorIs(t, hasher.Compare(hashed, input+"4"), ErrMismatchedHashes)
+			require.True(t, hasher.NeedsRehash(hashed))
+		}
+	})
+	t.Run("rejects unknown hashes", func(t *testing.T) {
+		require.ErrorIs(t, hasher.Compare("$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5", input), ErrHashUnrecognized)
+	})
+}
@@ -0,0 +1,37 @@
+package hasher
+
+import "errors"
+
+var ErrAlgorithmUnknown = errors.New("hashing algorithm should be one of: argon2id, bcrypt")
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+type PasswordConfig struct {
+	// Algorithm is the one new hashes are made with.
+	Algorithm  string
+	Argon2id   Argon2idParams
+	BcryptCost int
+
+	// LegacySalt is the salt of sha256 hashes, that are still accepted until rehashed.
+	LegacySalt string
+}
+
+// NewPasswordHasher returns a hasher for user passwords, that hashes with the configured algorithm,
+// and verifies hashes made by any of the supported ones, including the legacy sha256.
+func NewPasswordHasher(cfg PasswordConfig) (*MultiHasher, error) {
+	argon2id := NewArgon2idHasher(cfg.Argon2id)
+	bcrypt := NewBcryptHasher(cfg.BcryptCost)
+	legacy := NewSHA256Hasher(cfg.LegacySalt)
+
+	switch cfg.Algorithm {
+	case AlgorithmArgon2id:
+		return NewMultiHasher(argon2id, bcrypt, legacy), nil
+	case AlgorithmBcrypt:
+		return NewMultiHasher(bcrypt, argon2id, legacy), nil
+	default:
+		return nil, ErrAlgorithmUnknown
+	}
+}
@@ -0,0 +1,36 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestNewPasswordHasher(t *testing.T) {
+	cfg := PasswordConfig{
+		Algorithm:  AlgorithmBcrypt,
+		Argon2id:   testArgon2idParams,
+		BcryptCost: bcrypt.MinCost,
+		LegacySalt: "salt",
+	}
+
+	t.Run("hashes with the configured algorithm", func(t *testing.T) {
+		hasher, err := NewPasswordHasher(cfg)
+		require.NoError(t, err)
+
+		hashed, err := hasher.Hash("qwerty123")
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(hashed, "$2a$"))
+
+		legacy, err := NewSHA256Hasher(cfg.LegacySalt).Hash("qwerty123")
+		require.NoError(t, err)
+		require.NoError(t, hasher.Compare(legacy, "qwerty123"))
+	})
+	t.Run("should fail if algorithm is unknown", func(t *testing.T) {
+		cfg.Algorithm = "md5"
+		_, err := NewPasswordHasher(cfg)
+		require.ErrorIs(t, err, ErrAlgorithmUnknown)
+	})
+}
@@ -3,8 +3,11 @@ package hasher
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 )
 
+var _ Verifier = (*SHA256Hasher)(nil)
+
 type SHA256Hasher struct {
 	salt string
 }
@@ -32,3 +35,9 @@ func (h *SHA256Hasher) Compare(hash, plain string) error {
 	}
 	return nil
 }
+
+// Recognizes reports whether the hash could be made by the hasher,
+// its hashes are hex encoded, unlike ones in PHC or modular crypt format.
+func (h *SHA256Hasher) Recognizes(hash string) bool {
+	return hash != "" && !strings.HasPrefix(hash, "$")
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	deadmanRemindersSent = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "deadman_reminders_sent_total",
+		Help: "the total number of reminders to check in sent to authors of notes with a dead man's switch",
+	})
+
+	deadmanNotesReleased = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "deadman_notes_released_total",
+		Help: "the total number of notes released to recipients, because their authors missed the check-in",
+	})
+
+	deadmanRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "deadman_runs_total",
+		Help: "the total number of dead man's switch worker runs",
+	}, []string{"status"})
+)
+
+func RecordDeadmanRemindersSentMetric(count int64) {
+	go deadmanRemindersSent.Add(float64(count))
+}
+
+func RecordDeadmanNotesReleasedMetric(count int64) {
+	go deadmanNotesReleased.Add(float64(count))
+}
+
+// RecordDeadmanRunMetric records dead man's switch worker run with status,
+// which is one of "success", "failure", or "skipped"(when other replica holds the lock).
+func RecordDeadmanRunMetric(status string) {
+	go deadmanRuns.With(prometheus.Labels{"status": status}).Inc()
+}
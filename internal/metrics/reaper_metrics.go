@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	reaperNotesContentPurged = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reaper_notes_content_purged_total",
+		Help: "the total number of expired notes which content was purged by the reaper",
+	})
+
+	reaperNotesDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reaper_notes_deleted_total",
+		Help: "the total number of read or expired notes deleted by the reaper after the retention period",
+	})
+
+	reaperAttachmentsDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reaper_attachments_deleted_total",
+		Help: "the total number of attachments of deleted, expired, or burnt notes deleted by the reaper",
+	})
+
+	reaperAccessEventsDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "reaper_note_access_events_deleted_total",
+		Help: "the total number of accesses to notes deleted by the reaper after the retention period",
+	})
+
+	reaperRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "reaper_runs_total",
+		Help: "the total number of reaper runs",
+	}, []string{"status"})
+)
+
+func RecordReaperNotesContentPurgedMetric(count int64) {
+	go reaperNotesContentPurged.Add(float64(count))
+}
+
+func RecordReaperNotesDeletedMetric(count int64) {
+	go reaperNotesDeleted.Add(float64(count))
+}
+
+func RecordReaperAttachmentsDeletedMetric(count int64) {
+	go reaperAttachmentsDeleted.Add(float64(count))
+}
+
+func RecordReaperAccessEventsDeletedMetric(count int64) {
+	go reaperAccessEventsDeleted.Add(float64(count))
+}
+
+// RecordReaperRunMetric records reaper run with status,
+// which is one of "success", "failure", or "skipped"(when other replica holds the lock).
+func RecordReaperRunMetric(status string) {
+	go reaperRuns.With(prometheus.Labels{"status": status}).Inc()
+}
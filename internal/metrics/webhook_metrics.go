@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	webhookDeliveryAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_delivery_attempts_total",
+		Help: "the total number of webhook delivery attempts",
+	}, []string{"status"})
+
+	webhookDeliveryLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "webhook_delivery_latency_seconds",
+		Help:    "the latency of webhook delivery attempts in seconds",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	webhookDeliveriesDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_deliveries_deleted_total",
+		Help: "the total number of webhook deliveries deleted after the delivery log retention period",
+	})
+
+	webhookDispatcherRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_dispatcher_runs_total",
+		Help: "the total number of webhook dispatcher runs",
+	}, []string{"status"})
+)
+
+// RecordWebhookDeliveryAttemptMetric records webhook delivery attempt with status of the delivery after it,
+// which is one of "succeeded", "pending"(it's going to be retried), or "failed"(ran out of attempts).
+func RecordWebhookDeliveryAttemptMetric(status string, latency time.Duration) {
+	go func() {
+		webhookDeliveryAttempts.With(prometheus.Labels{"status": status}).Inc()
+		webhookDeliveryLatency.Observe(latency.Seconds())
+	}()
+}
+
+func RecordWebhookDeliveriesDeletedMetric(count int64) {
+	go webhookDeliveriesDeleted.Add(float64(count))
+}
+
+// RecordWebhookDispatcherRunMetric records webhook dispatcher run with status,
+// which is either "success", or "failure".
+func RecordWebhookDispatcherRunMetric(status string) {
+	go webhookDispatcherRuns.With(prometheus.Labels{"status": status}).Inc()
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/pkg/notecrypt"
 )
 
 // read and unread are not allowed because those slugs might and will be interpreted as api routes
@@ -21,10 +22,52 @@ var (
 	ErrNoteCannotBeKept       = errors.New(
 		"note: cannot be kept before expiration if expiration time is not provided",
 	)
-	ErrNoteExpired  = errors.New("note: expired")
-	ErrNoteNotFound = errors.New("note: not found")
+	ErrNoteExpired                     = errors.New("note: expired")
+	ErrNoteNotFound                    = errors.New("note: not found")
+	ErrNoteEncryptionSchemeUnsupported = errors.New("note: encryption scheme is not supported")
+	ErrNoteContentIsNotCiphertext      = errors.New(
+		"note: content is expected to be encrypted, but it's not",
+	)
+	ErrNoteMaxViewsIsInvalid        = errors.New("note: max views should be a positive number")
+	ErrNoteCannotBeKeptWithMaxViews = errors.New(
+		"note: cannot be kept before expiration and have max views at the same time",
+	)
+	ErrNoteNotifyAuthorWithoutAuthor    = errors.New("note: only notes with an author can notify it")
+	ErrNoteCannotBeEdited               = errors.New("note: only notes that haven't been viewed can be edited")
+	ErrNoteVersionRequired              = errors.New("note: version is required to edit the note")
+	ErrNoteVersionMismatch              = errors.New("note: has been changed since the version")
+	ErrNoteBurnOnLockoutWithoutPassword = errors.New(
+		"note: only notes with a password can be burnt on lockout",
+	)
+	ErrNoteLocked                      = errors.New("note: locked after too many wrong passwords, try again later")
+	ErrNoteReplyWithoutAuthor          = errors.New("note: only notes with an author can be replied to")
+	ErrNoteAlreadyReplied              = errors.New("note: has already been replied to")
+	ErrNoteNotAvailableYet             = errors.New("note: is not available yet")
+	ErrNoteAvailabilityAfterExpiration = errors.New(
+		"note: should become available before it expires",
+	)
+	ErrNoteClaimTokensInvalid = errors.New(
+		"note: between 1 and 100 management tokens can be claimed at once",
+	)
 )
 
+// MaxNoteClaimTokens is how many management tokens of anonymous notes can be claimed at once.
+const MaxNoteClaimTokens = 100
+
+// NoteNotAvailableYetError is returned for notes that are read before [Note.AvailableFrom],
+// it's [ErrNoteNotAvailableYet] that carries the time the note becomes available.
+type NoteNotAvailableYetError struct {
+	AvailableFrom time.Time
+}
+
+func (e NoteNotAvailableYetError) Error() string { return ErrNoteNotAvailableYet.Error() }
+func (e NoteNotAvailableYetError) Unwrap() error { return ErrNoteNotAvailableYet }
+
+// supportedEncryptionSchemes maps client-side encryption schemes to their latest supported version.
+var supportedEncryptionSchemes = map[string]int{
+	notecrypt.Scheme: notecrypt.Version,
+}
+
 type Note struct {
 	ID                   uuid.UUID
 	Content              string
@@ -34,6 +77,63 @@ type Note struct {
 	ReadAt               time.Time
 	CreatedAt            time.Time
 	ExpiresAt            time.Time
+
+	// AvailableFrom is the time before which the note cannot be read, zero if it's available right away.
+	AvailableFrom time.Time
+
+	// EncryptionScheme is the scheme the content was encrypted with on the client side,
+	// empty if the content is plaintext.
+	EncryptionScheme  string
+	EncryptionVersion int
+
+	// MaxViews is how many times the note can be read before it's burnt,
+	// and ViewsLeft is how many reads are left.
+	// Both are zero for notes that are kept before expiration, since they aren't burnt on read.
+	MaxViews  int
+	ViewsLeft int
+
+	// NotifyAuthor is whether the author is emailed when the note is read or expires unread.
+	NotifyAuthor bool
+
+	// BurnOnLockout is whether the note is burnt, instead of being locked, after too many wrong passwords.
+	BurnOnLockout bool
+
+	// AllowReply is whether the reader can reply to the note once, the reply is a note owned by the author,
+	// and ReplySlug is its slug, empty if there's no reply yet.
+	AllowReply bool
+	ReplySlug  string
+
+	// GroupID is shared by notes sent to several recipients at once, their content is stored once,
+	// and RecipientLabel tells them apart. GroupID is [uuid.Nil] for notes without recipients.
+	GroupID        uuid.UUID
+	RecipientLabel string
+
+	// BoundEmail is the email the note is bound to, its content is released only to readers
+	// who give the one-time code sent to it, empty if the note isn't bound.
+	BoundEmail string
+
+	// InboxUserID is the registered user the note is sent to, only the user can read it,
+	// [uuid.Nil] if the note can be read by anyone with its link.
+	// InboxEmail is the user's email, and DeliveredAt is when the note has been listed in the user's inbox,
+	// they're set only for the note's author.
+	InboxUserID uuid.UUID
+	InboxEmail  string
+	DeliveredAt time.Time
+
+	// InboxRecipientDeleted is whether the user the note has been sent to has deleted their account,
+	// such note cannot be read by anyone.
+	InboxRecipientDeleted bool
+
+	// ViewedAt is when the note that's kept before expiration has been read for the first time,
+	// zero if nobody has read it yet.
+	ViewedAt time.Time
+
+	// RequiresApproval is whether the note's content is released only to readers,
+	// whose access request has been approved by the note's author.
+	RequiresApproval bool
+
+	// Version is bumped whenever the content is edited, or the note is viewed.
+	Version int
 }
 
 var slugPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
@@ -59,6 +159,65 @@ func (n Note) Validate() error {
 		return ErrNoteSlugIsAlreadyInUse
 	}
 
+	if n.MaxViews < 0 {
+		return ErrNoteMaxViewsIsInvalid
+	}
+
+	if n.KeepBeforeExpiration && n.MaxViews > 1 {
+		return ErrNoteCannotBeKeptWithMaxViews
+	}
+
+	if n.BurnOnLockout && n.Password == "" {
+		return ErrNoteBurnOnLockoutWithoutPassword
+	}
+
+	if n.BoundEmail != "" && !isBareEmail(n.BoundEmail) {
+		return ErrNoteBoundEmailInvalid
+	}
+
+	if err := n.ValidateAvailability(); err != nil {
+		return err
+	}
+
+	return n.validateEncryption()
+}
+
+// ValidateAvailability validates that the note becomes available before it expires.
+func (n Note) ValidateAvailability() error {
+	if !n.AvailableFrom.IsZero() && !n.ExpiresAt.IsZero() &&
+		!n.AvailableFrom.Before(n.ExpiresAt) {
+		return ErrNoteAvailabilityAfterExpiration
+	}
+
+	return nil
+}
+
+// ValidateContent validates only the content, against the note's encryption scheme.
+func (n Note) ValidateContent() error {
+	if n.Content == "" {
+		return ErrNoteContentIsEmpty
+	}
+
+	return n.validateEncryption()
+}
+
+func (n Note) validateEncryption() error {
+	if !n.IsEncrypted() {
+		if n.EncryptionVersion != 0 {
+			return ErrNoteEncryptionSchemeUnsupported
+		}
+		return nil
+	}
+
+	latest, ok := supportedEncryptionSchemes[n.EncryptionScheme]
+	if !ok || n.EncryptionVersion < 1 || n.EncryptionVersion > latest {
+		return ErrNoteEncryptionSchemeUnsupported
+	}
+
+	if !notecrypt.IsCiphertext(n.Content) {
+		return ErrNoteContentIsNotCiphertext
+	}
+
 	return nil
 }
 
@@ -67,6 +226,12 @@ func (n Note) IsExpired() bool {
 		n.ExpiresAt.Before(time.Now())
 }
 
+// IsAvailable reports whether the note can already be read.
+func (n Note) IsAvailable() bool {
+	return n.AvailableFrom.IsZero() ||
+		!n.AvailableFrom.After(time.Now())
+}
+
 func (n Note) ShouldPreserveOnRead() bool {
 	return !n.ExpiresAt.IsZero() &&
 		n.KeepBeforeExpiration
@@ -75,3 +240,44 @@ func (n Note) ShouldPreserveOnRead() bool {
 func (n Note) IsRead() bool {
 	return !n.ReadAt.IsZero()
 }
+
+// CanBeRepliedTo reports whether the reader can still reply to the note.
+func (n Note) CanBeRepliedTo() bool {
+	return n.AllowReply && n.ReplySlug == ""
+}
+
+// CanBeReadBy reports whether the user can read the note, [uuid.Nil] is a reader that isn't signed in.
+func (n Note) CanBeReadBy(userID uuid.UUID) bool {
+	if n.InboxRecipientDeleted {
+		return false
+	}
+
+	return n.InboxUserID.IsNil() || n.InboxUserID == userID
+}
+
+// HasRecipients reports whether the note is one of notes sent to several recipients at once.
+func (n Note) HasRecipients() bool {
+	return !n.GroupID.IsNil()
+}
+
+// IsBound reports whether the note is released only with the one-time code sent to [Note.BoundEmail].
+func (n Note) IsBound() bool {
+	return n.BoundEmail != ""
+}
+
+// IsEditable reports whether the note's content can be edited,
+// that is when nobody has viewed it yet, and it's not expired.
+func (n Note) IsEditable() bool {
+	return !n.IsRead() && n.Views() == 0 && n.ViewedAt.IsZero() && !n.IsExpired()
+}
+
+// Views returns how many times the note has been read.
+func (n Note) Views() int {
+	return max(n.MaxViews-n.ViewsLeft, 0)
+}
+
+// IsEncrypted reports whether the content is encrypted on the client side,
+// in which case the server never sees it as plaintext.
+func (n Note) IsEncrypted() bool {
+	return n.EncryptionScheme != ""
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"net/netip"
+	"time"
+)
+
+type NoteAccessKind string
+
+const (
+	// NoteAccessRead is a read of the note's content.
+	NoteAccessRead NoteAccessKind = "read"
+
+	// NoteAccessWrongPassword is an attempt to read the note with a wrong password.
+	NoteAccessWrongPassword NoteAccessKind = "wrong_password"
+
+	// NoteAccessWrongCode is an attempt to read the bound note with a wrong one-time code.
+	NoteAccessWrongCode NoteAccessKind = "wrong_code"
+
+	// NoteAccessMetadata is a request of the note's metadata.
+	NoteAccessMetadata NoteAccessKind = "metadata"
+
+	// NoteAccessRequested, NoteAccessApproved, and NoteAccessDenied are a reader's request to read the note
+	// that requires approval, and the author's decision on it, they're recorded with the requester's ip.
+	NoteAccessRequested NoteAccessKind = "access_requested"
+	NoteAccessApproved  NoteAccessKind = "access_approved"
+	NoteAccessDenied    NoteAccessKind = "access_denied"
+)
+
+const MaxNoteAccessUserAgentLength = 512
+
+// NoteAccessEvent is an access to the note by a reader, shown to the note's author.
+type NoteAccessEvent struct {
+	Kind      NoteAccessKind
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// NewNoteAccessEvent creates the event of the reader's access,
+// ip is truncated to its network, and user agent to [MaxNoteAccessUserAgentLength] characters.
+func NewNoteAccessEvent(kind NoteAccessKind, ip, userAgent string, now time.Time) NoteAccessEvent {
+	if ua := []rune(userAgent); len(ua) > MaxNoteAccessUserAgentLength {
+		userAgent = string(ua[:MaxNoteAccessUserAgentLength])
+	}
+
+	return NoteAccessEvent{
+		Kind:      kind,
+		IP:        TruncateIP(ip),
+		UserAgent: userAgent,
+		CreatedAt: now,
+	}
+}
+
+// TruncateIP returns network of the ip, /24 for ipv4 and /48 for ipv6 addresses,
+// which is enough to tell readers apart, but not to identify them.
+// Returns empty string if ip is invalid.
+func TruncateIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	addr = addr.Unmap().WithZone("")
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+
+	return prefix.String()
+}
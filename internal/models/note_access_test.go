@@ -0,0 +1,47 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestTruncateIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "ipv4", ip: "203.0.113.42", want: "203.0.113.0/24"},
+		{name: "ipv6", ip: "2001:db8:abcd:12::1", want: "2001:db8:abcd::/48"},
+		{name: "ipv4 mapped to ipv6", ip: "::ffff:203.0.113.42", want: "203.0.113.0/24"},
+		{name: "ipv6 with zone", ip: "fe80::1%eth0", want: "fe80::/48"},
+		{name: "empty", ip: "", want: ""},
+		{name: "invalid", ip: "localhost", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TruncateIP(tt.ip))
+		})
+	}
+}
+
+func TestNewNoteAccessEvent(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should truncate ip", func(t *testing.T) {
+		e := NewNoteAccessEvent(NoteAccessRead, "203.0.113.42", "curl/8.0", now)
+		assert.Equal(t, NoteAccessEvent{
+			Kind:      NoteAccessRead,
+			IP:        "203.0.113.0/24",
+			UserAgent: "curl/8.0",
+			CreatedAt: now,
+		}, e)
+	})
+	t.Run("should truncate user agent", func(t *testing.T) {
+		e := NewNoteAccessEvent(NoteAccessMetadata, "", strings.Repeat("ы", MaxNoteAccessUserAgentLength+1), now)
+		assert.Equal(t, strings.Repeat("ы", MaxNoteAccessUserAgentLength), e.UserAgent)
+	})
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrNoteApprovalWithoutAuthor = errors.New("note: only notes with an author can require approval")
+	ErrNoteApprovalWithSwitch    = errors.New("note: note with a dead man's switch cannot require approval")
+	ErrNoteApprovalWithInbox     = errors.New("note: note sent to an inbox cannot require approval")
+	ErrNoteApprovalWithBound     = errors.New("note: note bound to an email cannot require approval")
+	ErrNoteApprovalNotRequired   = errors.New("note: the note doesn't require approval")
+	ErrNoteApprovalRequired      = errors.New("note: the note is released only once its author approves your access request")
+
+	ErrNoteAccessRequestReasonInvalid = errors.New("note: reason of the access request should be 1 to 500 characters")
+	ErrNoteAccessRequestNotFound      = errors.New("note: access request not found")
+	ErrNoteAccessRequestNotPending    = errors.New("note: access request has already been decided or has expired")
+)
+
+const MaxNoteAccessRequestReasonLength = 500
+
+type NoteAccessRequestStatus string
+
+const (
+	NoteAccessRequestPending  NoteAccessRequestStatus = "pending"
+	NoteAccessRequestApproved NoteAccessRequestStatus = "approved"
+	NoteAccessRequestDenied   NoteAccessRequestStatus = "denied"
+
+	// NoteAccessRequestExpired is a request that hasn't been decided, or used, before it has expired.
+	// It's never stored, see [NoteAccessRequest.StatusAt].
+	NoteAccessRequestExpired NoteAccessRequestStatus = "expired"
+
+	// NoteAccessRequestUsed is an approved request, the note has been read with.
+	// It's never stored, see [NoteAccessRequest.StatusAt].
+	NoteAccessRequestUsed NoteAccessRequestStatus = "used"
+)
+
+// NoteAccessRequest is a reader's request to read the note that requires approval of its author.
+// Each approved request releases the note once, and only to the reader who has made it.
+type NoteAccessRequest struct {
+	ID     uuid.UUID
+	Reason string
+	Status NoteAccessRequestStatus
+
+	// IP and UserAgent are of the reader who has made the request, ip is truncated the same way as in [NoteAccessEvent].
+	IP        string
+	UserAgent string
+
+	CreatedAt time.Time
+	DecidedAt time.Time
+	UsedAt    time.Time
+
+	// ExpiresAt is when the request expires, it's postponed once the request is approved,
+	// so the reader has time to read the note.
+	ExpiresAt time.Time
+}
+
+// StatusAt returns the request's status at the given time, taking its expiration into account.
+func (r NoteAccessRequest) StatusAt(now time.Time) NoteAccessRequestStatus {
+	switch {
+	case !r.UsedAt.IsZero():
+		return NoteAccessRequestUsed
+	case r.Status == NoteAccessRequestDenied:
+		return NoteAccessRequestDenied
+	case !r.ExpiresAt.After(now):
+		return NoteAccessRequestExpired
+	default:
+		return r.Status
+	}
+}
+
+func ValidateNoteAccessRequestReason(reason string) error {
+	if strings.TrimSpace(reason) == "" || utf8.RuneCountInString(reason) > MaxNoteAccessRequestReasonLength {
+		return ErrNoteAccessRequestReasonInvalid
+	}
+
+	return nil
+}
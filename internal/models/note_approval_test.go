@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestNoteAccessRequest_StatusAt(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		req  NoteAccessRequest
+		want NoteAccessRequestStatus
+	}{
+		{
+			name: "pending",
+			req:  NoteAccessRequest{Status: NoteAccessRequestPending, ExpiresAt: now.Add(time.Minute)},
+			want: NoteAccessRequestPending,
+		},
+		{
+			name: "pending, but expired",
+			req:  NoteAccessRequest{Status: NoteAccessRequestPending, ExpiresAt: now.Add(-time.Minute)},
+			want: NoteAccessRequestExpired,
+		},
+		{
+			name: "approved",
+			req:  NoteAccessRequest{Status: NoteAccessRequestApproved, ExpiresAt: now.Add(time.Minute)},
+			want: NoteAccessRequestApproved,
+		},
+		{
+			name: "approved, but expired",
+			req:  NoteAccessRequest{Status: NoteAccessRequestApproved, ExpiresAt: now.Add(-time.Minute)},
+			want: NoteAccessRequestExpired,
+		},
+		{
+			name: "approved and used",
+			req: NoteAccessRequest{
+				Status:    NoteAccessRequestApproved,
+				ExpiresAt: now.Add(-time.Minute),
+				UsedAt:    now.Add(-2 * time.Minute),
+			},
+			want: NoteAccessRequestUsed,
+		},
+		{
+			name: "denied stays denied after expiration",
+			req:  NoteAccessRequest{Status: NoteAccessRequestDenied, ExpiresAt: now.Add(-time.Minute)},
+			want: NoteAccessRequestDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.req.StatusAt(now))
+		})
+	}
+}
+
+func TestValidateNoteAccessRequestReason(t *testing.T) {
+	assert.NoError(t, ValidateNoteAccessRequestReason("deploying the hotfix"))
+	assert.NoError(t, ValidateNoteAccessRequestReason(strings.Repeat("ы", MaxNoteAccessRequestReasonLength)))
+
+	assert.ErrorIs(t, ValidateNoteAccessRequestReason(""), ErrNoteAccessRequestReasonInvalid)
+	assert.ErrorIs(t, ValidateNoteAccessRequestReason("  \n"), ErrNoteAccessRequestReasonInvalid)
+	assert.ErrorIs(t,
+		ValidateNoteAccessRequestReason(strings.Repeat("a", MaxNoteAccessRequestReasonLength+1)),
+		ErrNoteAccessRequestReasonInvalid)
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrNoteAttachmentNotFound        = errors.New("note: attachment not found")
+	ErrNoteAttachmentTooLarge        = errors.New("note: attachment is too large")
+	ErrNoteTooManyAttachments        = errors.New("note: too many attachments")
+	ErrNoteAttachmentFilenameInvalid = errors.New("note: attachment filename is invalid")
+)
+
+const maxAttachmentFilenameLength = 255
+
+type NoteAttachment struct {
+	ID          uuid.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+
+	// ContentKeyID is ID of the key the blob is encrypted with at rest,
+	// empty if it's stored as is.
+	ContentKeyID string
+	CreatedAt    time.Time
+}
+
+func (a NoteAttachment) Validate() error {
+	if a.Filename == "" ||
+		len(a.Filename) > maxAttachmentFilenameLength ||
+		a.Filename == "." || a.Filename == ".." ||
+		strings.ContainsAny(a.Filename, "/\\\x00") {
+		return ErrNoteAttachmentFilenameInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestNoteAttachment_Validate(t *testing.T) {
+	t.Run("should pass with a regular filename", func(t *testing.T) {
+		assert.NoError(t, NoteAttachment{Filename: "id_ed25519.pub"}.Validate())
+	})
+	t.Run("should pass with a dotfile", func(t *testing.T) {
+		assert.NoError(t, NoteAttachment{Filename: ".env"}.Validate())
+	})
+	t.Run("should fail if filename is invalid", func(t *testing.T) {
+		for _, filename := range []string{
+			"", ".", "..", "../.env", "certs/ca.pem", `certs\ca.pem`, "a\x00b", strings.Repeat("a", 256),
+		} {
+			assert.ErrorIs(t, NoteAttachment{Filename: filename}.Validate(), ErrNoteAttachmentFilenameInvalid)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoteBulkActionInvalid = errors.New(
+		"note: bulk action should be one of: delete, expire, set_expiration, set_password, burn",
+	)
+	ErrNoteBulkSelectorInvalid   = errors.New("note: notes should be selected either by slugs or by filter")
+	ErrNoteBulkTooManySlugs      = errors.New("note: up to 100 notes can be selected by slugs")
+	ErrNoteBulkExpirationInvalid = errors.New("note: expiration time should be in the future")
+)
+
+const MaxNoteBulkSlugs = 100
+
+type NoteBulkAction string
+
+const (
+	NoteBulkActionDelete        NoteBulkAction = "delete"
+	NoteBulkActionExpire        NoteBulkAction = "expire"
+	NoteBulkActionSetExpiration NoteBulkAction = "set_expiration"
+	NoteBulkActionSetPassword   NoteBulkAction = "set_password"
+	NoteBulkActionBurn          NoteBulkAction = "burn"
+)
+
+// NoteBulkStatus is the outcome of a bulk action for a single note.
+type NoteBulkStatus string
+
+const (
+	NoteBulkStatusDone NoteBulkStatus = "done"
+
+	// NoteBulkStatusSkipped is set for notes the action cannot be applied to,
+	// e.g. read or expired notes cannot be expired or burnt,
+	// and notes cannot be set to expire before they're available.
+	NoteBulkStatusSkipped NoteBulkStatus = "skipped"
+
+	// NoteBulkStatusNotFound is set for slugs that don't match any of the author's notes.
+	NoteBulkStatusNotFound NoteBulkStatus = "not_found"
+)
+
+// NoteBulkQuery is an action applied to the author's notes,
+// selected either by slugs or by filter.
+type NoteBulkQuery struct {
+	Action NoteBulkAction
+	Slugs  []string
+	Filter *NoteListFilter
+
+	// ExpiresAt is set only for [NoteBulkActionSetExpiration].
+	ExpiresAt time.Time
+
+	// Password is hashed, and set only for [NoteBulkActionSetPassword].
+	Password string
+}
+
+type NoteBulkResult struct {
+	Slug   string
+	Status NoteBulkStatus
+}
+
+func (q NoteBulkQuery) Validate(now time.Time) error {
+	switch q.Action {
+	case NoteBulkActionDelete, NoteBulkActionExpire, NoteBulkActionSetExpiration,
+		NoteBulkActionSetPassword, NoteBulkActionBurn:
+	default:
+		return ErrNoteBulkActionInvalid
+	}
+
+	if (len(q.Slugs) == 0) == (q.Filter == nil) {
+		return ErrNoteBulkSelectorInvalid
+	}
+
+	if len(q.Slugs) > MaxNoteBulkSlugs {
+		return ErrNoteBulkTooManySlugs
+	}
+
+	if q.Filter != nil {
+		if err := q.Filter.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if q.Action == NoteBulkActionSetExpiration && !q.ExpiresAt.After(now) {
+		return ErrNoteBulkExpirationInvalid
+	}
+
+	return nil
+}
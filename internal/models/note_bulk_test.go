@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestNoteBulkQuery_Validate(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should pass", func(t *testing.T) {
+		assert.NoError(t, NoteBulkQuery{
+			Action: NoteBulkActionDelete,
+			Slugs:  []string{"a", "b"},
+		}.Validate(now))
+		assert.NoError(t, NoteBulkQuery{
+			Action: NoteBulkActionBurn,
+			Filter: &NoteListFilter{},
+		}.Validate(now))
+		assert.NoError(t, NoteBulkQuery{
+			Action:    NoteBulkActionSetExpiration,
+			Filter:    &NoteListFilter{Status: NoteStatusUnread},
+			ExpiresAt: now.Add(time.Hour),
+		}.Validate(now))
+	})
+	t.Run("should fail if action is unknown", func(t *testing.T) {
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action: "read",
+			Slugs:  []string{"a"},
+		}.Validate(now), ErrNoteBulkActionInvalid)
+	})
+	t.Run("should fail if notes are selected by both or none", func(t *testing.T) {
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action: NoteBulkActionDelete,
+		}.Validate(now), ErrNoteBulkSelectorInvalid)
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action: NoteBulkActionDelete,
+			Slugs:  []string{"a"},
+			Filter: &NoteListFilter{},
+		}.Validate(now), ErrNoteBulkSelectorInvalid)
+	})
+	t.Run("should fail if too many slugs", func(t *testing.T) {
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action: NoteBulkActionDelete,
+			Slugs:  make([]string, MaxNoteBulkSlugs+1),
+		}.Validate(now), ErrNoteBulkTooManySlugs)
+	})
+	t.Run("should fail if filter is invalid", func(t *testing.T) {
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action: NoteBulkActionDelete,
+			Filter: &NoteListFilter{Status: "burnt"},
+		}.Validate(now), ErrNoteListStatusInvalid)
+	})
+	t.Run("should fail if expiration time is not in the future", func(t *testing.T) {
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action: NoteBulkActionSetExpiration,
+			Slugs:  []string{"a"},
+		}.Validate(now), ErrNoteBulkExpirationInvalid)
+		assert.ErrorIs(t, NoteBulkQuery{
+			Action:    NoteBulkActionSetExpiration,
+			Slugs:     []string{"a"},
+			ExpiresAt: now.Add(-time.Minute),
+		}.Validate(now), ErrNoteBulkExpirationInvalid)
+	})
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrNoteBoundEmailInvalid    = errors.New("note: email the note is bound to is invalid")
+	ErrNoteBoundWithRecipients  = errors.New("note: note sent to several recipients cannot be bound to an email")
+	ErrNoteCodeRequired         = errors.New("note: one-time code sent to the recipient's email is required")
+	ErrNoteCodeInvalid          = errors.New("note: one-time code is invalid or expired")
+	ErrNoteCodeAttemptsExceeded = errors.New("note: too many wrong one-time codes, try again later")
+)
+
+// NoteCodeLength is how many digits one-time codes of bound notes have.
+const NoteCodeLength = 6
+
+var noteCodeMax = big.NewInt(1_000_000) // 10^NoteCodeLength
+
+// NewNoteCode generates a random one-time code, the bound note is released with.
+func NewNoteCode() (string, error) {
+	n, err := rand.Int(rand.Reader, noteCodeMax)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", NoteCodeLength, n.Int64()), nil
+}
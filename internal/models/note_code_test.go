@@ -0,0 +1,17 @@
+package models
+
+import (
+	"regexp"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewNoteCode(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9]{6}$`)
+	for range 100 {
+		code, err := NewNoteCode()
+		assert.NoError(t, err)
+		assert.Regexp(t, pattern, code)
+	}
+}
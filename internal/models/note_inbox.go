@@ -0,0 +1,10 @@
+package models
+
+import "errors"
+
+var (
+	ErrNoteInboxRecipientNotFound = errors.New("note: recipient doesn't have an account")
+	ErrNoteInboxWithoutAuthor     = errors.New("note: only notes with an author can be sent to an inbox")
+	ErrNoteInboxWithRecipients    = errors.New("note: note sent to several recipients cannot be sent to an inbox")
+	ErrNoteInboxWithSwitch        = errors.New("note: note with a dead man's switch cannot be sent to an inbox")
+)
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrNoteLinkRecipientInvalid = errors.New("note: recipient email is invalid")
+	ErrNoteLinkMessageTooLong   = errors.New("note: message to the recipient is too long")
+	ErrNoteLinkWithoutAuthor    = errors.New("note: only notes with an author can be emailed to the recipient")
+	ErrNoteLinkWithRecipients   = errors.New("note: note sent to several recipients cannot be emailed")
+	ErrNoteLinkSendsExceeded    = errors.New("note: too many notes have been emailed recently, try again later")
+)
+
+const MaxNoteLinkMessageLength = 1000
+
+// NoteLinkSend is the link to a note emailed to the recipient by the note's author.
+// The message is only included into the email, it's never stored.
+type NoteLinkSend struct {
+	RecipientEmail string
+	Message        string
+	SentAt         time.Time
+}
+
+func (s NoteLinkSend) Validate() error {
+	if !isBareEmail(s.RecipientEmail) {
+		return ErrNoteLinkRecipientInvalid
+	}
+
+	if utf8.RuneCountInString(s.Message) > MaxNoteLinkMessageLength {
+		return ErrNoteLinkMessageTooLong
+	}
+
+	return nil
+}
+
+// isBareEmail reports whether s is an email address without a display name,
+// display names could be used to make the email look like it's from someone else.
+func isBareEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestNoteLinkSend_Validate(t *testing.T) {
+	t.Run("should pass", func(t *testing.T) {
+		s := NoteLinkSend{RecipientEmail: "alice@example.com", Message: "here's the password"}
+		assert.NoError(t, s.Validate())
+	})
+	t.Run("should pass without message", func(t *testing.T) {
+		s := NoteLinkSend{RecipientEmail: "alice@example.com"}
+		assert.NoError(t, s.Validate())
+	})
+	t.Run("should fail if recipient is not an email", func(t *testing.T) {
+		s := NoteLinkSend{RecipientEmail: "alice"}
+		assert.EqualError(t, s.Validate(), ErrNoteLinkRecipientInvalid.Error())
+	})
+	t.Run("should fail if recipient has a display name", func(t *testing.T) {
+		s := NoteLinkSend{RecipientEmail: "Bob <alice@example.com>"}
+		assert.EqualError(t, s.Validate(), ErrNoteLinkRecipientInvalid.Error())
+	})
+	t.Run("should fail if message is too long", func(t *testing.T) {
+		s := NoteLinkSend{
+			RecipientEmail: "alice@example.com",
+			Message:        strings.Repeat("ы", MaxNoteLinkMessageLength+1),
+		}
+		assert.EqualError(t, s.Validate(), ErrNoteLinkMessageTooLong.Error())
+	})
+	t.Run("should count characters, not bytes", func(t *testing.T) {
+		s := NoteLinkSend{
+			RecipientEmail: "alice@example.com",
+			Message:        strings.Repeat("ы", MaxNoteLinkMessageLength),
+		}
+		assert.NoError(t, s.Validate())
+	})
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrNoteListStatusInvalid = errors.New("note: list status should be one of: read, unread, expired")
+	ErrNoteListSortInvalid   = errors.New(
+		"note: list can be sorted only by created_at, expires_at or read_at, in asc or desc order",
+	)
+	ErrNoteListLimitInvalid  = errors.New("note: list limit should be between 1 and 100")
+	ErrNoteListRangeInvalid  = errors.New("note: list range should start before it ends")
+	ErrNoteListCursorInvalid = errors.New("note: list cursor is invalid")
+)
+
+const (
+	DefaultNoteListLimit = 50
+	MaxNoteListLimit     = 100
+)
+
+type NoteStatus string
+
+const (
+	NoteStatusRead    NoteStatus = "read"
+	NoteStatusUnread  NoteStatus = "unread"
+	NoteStatusExpired NoteStatus = "expired"
+)
+
+type NoteSortField string
+
+const (
+	NoteSortByCreatedAt NoteSortField = "created_at"
+	NoteSortByExpiresAt NoteSortField = "expires_at"
+	NoteSortByReadAt    NoteSortField = "read_at"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+// NoteListFilter filters the author's notes, zero fields don't filter anything.
+type NoteListFilter struct {
+	Status      NoteStatus
+	HasPassword *bool
+
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+
+	// ExpiresAfter and ExpiresBefore match only notes that have an expiration time.
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+}
+
+// NoteListCursor points at the last note of the previous page.
+type NoteListCursor struct {
+	// SortValue is the value of the field notes are sorted by,
+	// zero time if the note has no expiration time, or isn't read.
+	SortValue time.Time
+	ID        uuid.UUID
+}
+
+// NoteListQuery is a query of a page of the author's notes.
+type NoteListQuery struct {
+	Filter NoteListFilter
+	SortBy NoteSortField
+	Order  SortOrder
+	Limit  int
+
+	// After is nil for the first page.
+	After *NoteListCursor
+}
+
+func (f NoteListFilter) Validate() error {
+	switch f.Status {
+	case "", NoteStatusRead, NoteStatusUnread, NoteStatusExpired:
+	default:
+		return ErrNoteListStatusInvalid
+	}
+
+	if isRangeInvalid(f.CreatedAfter, f.CreatedBefore) ||
+		isRangeInvalid(f.ExpiresAfter, f.ExpiresBefore) {
+		return ErrNoteListRangeInvalid
+	}
+
+	return nil
+}
+
+func (q NoteListQuery) Validate() error {
+	if err := q.Filter.Validate(); err != nil {
+		return err
+	}
+
+	switch q.SortBy {
+	case NoteSortByCreatedAt, NoteSortByExpiresAt, NoteSortByReadAt:
+	default:
+		return ErrNoteListSortInvalid
+	}
+
+	if q.Order != SortOrderAsc && q.Order != SortOrderDesc {
+		return ErrNoteListSortInvalid
+	}
+
+	if q.Limit < 1 || q.Limit > MaxNoteListLimit {
+		return ErrNoteListLimitInvalid
+	}
+
+	return nil
+}
+
+// SortValue returns value of the field the note is sorted by.
+func (n Note) SortValue(field NoteSortField) time.Time {
+	switch field {
+	case NoteSortByExpiresAt:
+		return n.ExpiresAt
+	case NoteSortByReadAt:
+		return n.ReadAt
+	default:
+		return n.CreatedAt
+	}
+}
+
+func isRangeInvalid(after, before time.Time) bool {
+	return !after.IsZero() && !before.IsZero() && !after.Before(before)
+}
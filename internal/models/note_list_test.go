@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestNoteListQuery_Validate(t *testing.T) {
+	now := time.Now()
+	valid := NoteListQuery{
+		SortBy: NoteSortByCreatedAt,
+		Order:  SortOrderDesc,
+		Limit:  DefaultNoteListLimit,
+	}
+
+	t.Run("should pass", func(t *testing.T) {
+		assert.NoError(t, valid.Validate())
+
+		q := valid
+		q.Filter = NoteListFilter{
+			Status:        NoteStatusExpired,
+			CreatedAfter:  now.Add(-time.Hour),
+			CreatedBefore: now,
+			ExpiresAfter:  now,
+		}
+		assert.NoError(t, q.Validate())
+	})
+	t.Run("should fail if status is unknown", func(t *testing.T) {
+		q := valid
+		q.Filter.Status = "burnt"
+		assert.ErrorIs(t, q.Validate(), ErrNoteListStatusInvalid)
+	})
+	t.Run("should fail if sort is invalid", func(t *testing.T) {
+		q := valid
+		q.SortBy = "content"
+		assert.ErrorIs(t, q.Validate(), ErrNoteListSortInvalid)
+
+		q = valid
+		q.Order = "random"
+		assert.ErrorIs(t, q.Validate(), ErrNoteListSortInvalid)
+	})
+	t.Run("should fail if limit is out of range", func(t *testing.T) {
+		for _, limit := range []int{-1, 0, MaxNoteListLimit + 1} {
+			q := valid
+			q.Limit = limit
+			assert.ErrorIs(t, q.Validate(), ErrNoteListLimitInvalid)
+		}
+	})
+	t.Run("should fail if range ends before it starts", func(t *testing.T) {
+		q := valid
+		q.Filter.CreatedAfter = now
+		q.Filter.CreatedBefore = now.Add(-time.Hour)
+		assert.ErrorIs(t, q.Validate(), ErrNoteListRangeInvalid)
+
+		q = valid
+		q.Filter.ExpiresAfter = now
+		q.Filter.ExpiresBefore = now
+		assert.ErrorIs(t, q.Validate(), ErrNoteListRangeInvalid)
+	})
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+var (
+	ErrNoteRecipientsInvalid = errors.New(
+		"note: recipients should be between 1 and 50, and labels, if given, one for each of them",
+	)
+	ErrNoteRecipientLabelTooLong     = errors.New("note: recipient label should be up to 255 characters")
+	ErrNoteRecipientsWithSlug        = errors.New("note: slug cannot be set for note with recipients")
+	ErrNoteRecipientsWithAttachments = errors.New("note: attachments cannot be sent to recipients")
+	ErrNoteRecipientsCannotBeEdited  = errors.New("note: content of note with recipients cannot be edited")
+)
+
+const (
+	MaxNoteRecipients         = 50
+	maxNoteRecipientLabelSize = 255
+)
+
+// NoteRecipient is one of recipients of a note, every one of them gets their own link,
+// that is burnt independently of the others.
+type NoteRecipient struct {
+	Label string
+	Slug  string
+}
+
+type NoteRecipients []NoteRecipient
+
+// NewNoteRecipients returns recipients either labeled, or count of unlabeled ones,
+// if both are given, there should be a label for every recipient.
+func NewNoteRecipients(count int, labels []string) (NoteRecipients, error) {
+	if len(labels) != 0 && count != 0 && count != len(labels) {
+		return nil, ErrNoteRecipientsInvalid
+	}
+
+	if len(labels) == 0 {
+		labels = make([]string, max(count, 0))
+	}
+
+	recipients := make(NoteRecipients, len(labels))
+	for i, label := range labels {
+		recipients[i] = NoteRecipient{Label: label, Slug: ""}
+	}
+
+	return recipients, recipients.Validate()
+}
+
+func (r NoteRecipients) Validate() error {
+	if len(r) == 0 || len(r) > MaxNoteRecipients {
+		return ErrNoteRecipientsInvalid
+	}
+
+	for _, recipient := range r {
+		if utf8.RuneCountInString(recipient.Label) > maxNoteRecipientLabelSize {
+			return ErrNoteRecipientLabelTooLong
+		}
+	}
+
+	return nil
+}
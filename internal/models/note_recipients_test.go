@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestNewNoteRecipients(t *testing.T) {
+	t.Run("should create unlabeled recipients", func(t *testing.T) {
+		r, err := NewNoteRecipients(3, nil)
+		assert.NoError(t, err)
+		assert.Len(t, r, 3)
+		for _, recipient := range r {
+			assert.Empty(t, recipient.Label)
+		}
+	})
+	t.Run("should create labeled recipients", func(t *testing.T) {
+		r, err := NewNoteRecipients(0, []string{"alice", "bob"})
+		assert.NoError(t, err)
+		assert.Equal(t, NoteRecipients{{Label: "alice"}, {Label: "bob"}}, r)
+	})
+	t.Run("should pass if count matches labels", func(t *testing.T) {
+		r, err := NewNoteRecipients(2, []string{"alice", "bob"})
+		assert.NoError(t, err)
+		assert.Len(t, r, 2)
+	})
+	t.Run("should fail if count doesn't match labels", func(t *testing.T) {
+		_, err := NewNoteRecipients(3, []string{"alice", "bob"})
+		assert.ErrorIs(t, err, ErrNoteRecipientsInvalid)
+	})
+	t.Run("should fail if there's no recipients", func(t *testing.T) {
+		_, err := NewNoteRecipients(0, nil)
+		assert.ErrorIs(t, err, ErrNoteRecipientsInvalid)
+
+		_, err = NewNoteRecipients(-1, nil)
+		assert.ErrorIs(t, err, ErrNoteRecipientsInvalid)
+	})
+	t.Run("should fail if there's too many recipients", func(t *testing.T) {
+		_, err := NewNoteRecipients(MaxNoteRecipients+1, nil)
+		assert.ErrorIs(t, err, ErrNoteRecipientsInvalid)
+	})
+	t.Run("should fail if label is too long", func(t *testing.T) {
+		_, err := NewNoteRecipients(0, []string{strings.Repeat("a", maxNoteRecipientLabelSize+1)})
+		assert.ErrorIs(t, err, ErrNoteRecipientLabelTooLong)
+	})
+}
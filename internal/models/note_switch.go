@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrNoteSwitchIntervalInvalid = errors.New(
+		"note: check-in interval should be between an hour and a year",
+	)
+	ErrNoteSwitchRecipientsInvalid = errors.New(
+		"note: dead man's switch should have between 1 and 10 recipients, each of them a valid email",
+	)
+	ErrNoteSwitchWithoutAuthor = errors.New(
+		"note: only notes with an author can have a dead man's switch",
+	)
+	ErrNoteSwitchWithAvailableFrom = errors.New(
+		"note: availability of note with a dead man's switch is set by check-ins",
+	)
+	ErrNoteSwitchWithRecipients = errors.New(
+		"note: note with a dead man's switch cannot be sent to several recipients",
+	)
+	ErrNoteSwitchNotFound = errors.New("note: has no dead man's switch")
+	ErrNoteSwitchReleased = errors.New("note: dead man's switch has already been released")
+)
+
+const (
+	MinNoteSwitchInterval   = time.Hour
+	MaxNoteSwitchInterval   = 365 * 24 * time.Hour
+	MaxNoteSwitchRecipients = 10
+)
+
+// NoteSwitch is the dead man's switch of a note: the note is released to the recipients,
+// if its author doesn't check in within the interval since the last check-in.
+// The note isn't available until then.
+type NoteSwitch struct {
+	NoteID          uuid.UUID
+	CheckInInterval time.Duration
+	Recipients      []string
+	LastCheckInAt   time.Time
+
+	// RemindedAt is when the author was reminded to check in, zero if they weren't since the last check-in.
+	RemindedAt time.Time
+
+	// ReleasedAt is when the note was released to the recipients, zero if it wasn't yet.
+	ReleasedAt time.Time
+}
+
+func (s NoteSwitch) Validate() error {
+	if s.CheckInInterval < MinNoteSwitchInterval || s.CheckInInterval > MaxNoteSwitchInterval {
+		return ErrNoteSwitchIntervalInvalid
+	}
+
+	if len(s.Recipients) == 0 || len(s.Recipients) > MaxNoteSwitchRecipients {
+		return ErrNoteSwitchRecipientsInvalid
+	}
+
+	for _, r := range s.Recipients {
+		if _, err := mail.ParseAddress(r); err != nil {
+			return ErrNoteSwitchRecipientsInvalid
+		}
+	}
+
+	return nil
+}
+
+// ReleaseAt returns the time the note is released at, unless the author checks in before it.
+func (s NoteSwitch) ReleaseAt() time.Time {
+	return s.LastCheckInAt.Add(s.CheckInInterval)
+}
+
+func (s NoteSwitch) IsReleased() bool {
+	return !s.ReleasedAt.IsZero()
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestNoteSwitch_Validate(t *testing.T) {
+	t.Run("should pass", func(t *testing.T) {
+		s := NoteSwitch{CheckInInterval: 24 * time.Hour, Recipients: []string{"ops@example.com"}}
+		assert.NoError(t, s.Validate())
+	})
+	t.Run("should fail if interval is too short", func(t *testing.T) {
+		s := NoteSwitch{CheckInInterval: time.Minute, Recipients: []string{"ops@example.com"}}
+		assert.EqualError(t, s.Validate(), ErrNoteSwitchIntervalInvalid.Error())
+	})
+	t.Run("should fail if interval is too long", func(t *testing.T) {
+		s := NoteSwitch{
+			CheckInInterval: MaxNoteSwitchInterval + time.Hour,
+			Recipients:      []string{"ops@example.com"},
+		}
+		assert.EqualError(t, s.Validate(), ErrNoteSwitchIntervalInvalid.Error())
+	})
+	t.Run("should fail if there's no recipients", func(t *testing.T) {
+		s := NoteSwitch{CheckInInterval: time.Hour}
+		assert.EqualError(t, s.Validate(), ErrNoteSwitchRecipientsInvalid.Error())
+	})
+	t.Run("should fail if there's too many recipients", func(t *testing.T) {
+		s := NoteSwitch{CheckInInterval: time.Hour}
+		for range MaxNoteSwitchRecipients + 1 {
+			s.Recipients = append(s.Recipients, "ops@example.com")
+		}
+		assert.EqualError(t, s.Validate(), ErrNoteSwitchRecipientsInvalid.Error())
+	})
+	t.Run("should fail if recipient is not an email", func(t *testing.T) {
+		s := NoteSwitch{CheckInInterval: time.Hour, Recipients: []string{"ops@example.com", "ops"}}
+		assert.EqualError(t, s.Validate(), ErrNoteSwitchRecipientsInvalid.Error())
+	})
+}
+
+//nolint:exhaustruct
+func TestNoteSwitch_ReleaseAt(t *testing.T) {
+	now := time.Now()
+	s := NoteSwitch{CheckInInterval: time.Hour, LastCheckInAt: now}
+	assert.Equal(t, now.Add(time.Hour), s.ReleaseAt())
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/pkg/notecrypt"
 	assert "github.com/stretchr/testify/require"
 )
 
@@ -71,6 +73,104 @@ func TestNote_Validate(t *testing.T) {
 			assert.EqualError(t, n.Validate(), ErrNoteSlugIsAlreadyInUse.Error())
 		}
 	})
+	t.Run("should pass if content is encrypted with supported scheme", func(t *testing.T) {
+		key, err := notecrypt.GenerateKey()
+		assert.NoError(t, err)
+
+		ciphertext, err := notecrypt.Encrypt(key, []byte("the content"))
+		assert.NoError(t, err)
+
+		n := Note{
+			Content:           ciphertext,
+			EncryptionScheme:  notecrypt.Scheme,
+			EncryptionVersion: notecrypt.Version,
+		}
+		assert.NoError(t, n.Validate())
+	})
+	t.Run("should fail if encryption scheme is unknown", func(t *testing.T) {
+		n := Note{Content: "the content", EncryptionScheme: "rot13", EncryptionVersion: 1}
+		assert.EqualError(t, n.Validate(), ErrNoteEncryptionSchemeUnsupported.Error())
+	})
+	t.Run("should fail if encryption version is unknown", func(t *testing.T) {
+		n := Note{Content: "the content", EncryptionScheme: notecrypt.Scheme, EncryptionVersion: 0}
+		assert.EqualError(t, n.Validate(), ErrNoteEncryptionSchemeUnsupported.Error())
+	})
+	t.Run("should fail if version is set without scheme", func(t *testing.T) {
+		n := Note{Content: "the content", EncryptionVersion: 1}
+		assert.EqualError(t, n.Validate(), ErrNoteEncryptionSchemeUnsupported.Error())
+	})
+	t.Run("should fail if encrypted note has plaintext content", func(t *testing.T) {
+		n := Note{
+			Content:           "the content",
+			EncryptionScheme:  notecrypt.Scheme,
+			EncryptionVersion: notecrypt.Version,
+		}
+		assert.EqualError(t, n.Validate(), ErrNoteContentIsNotCiphertext.Error())
+	})
+	t.Run("should pass if max views is set", func(t *testing.T) {
+		n := Note{Content: "the content", MaxViews: 3}
+		assert.NoError(t, n.Validate())
+	})
+	t.Run("should fail if max views is negative", func(t *testing.T) {
+		n := Note{Content: "the content", MaxViews: -1}
+		assert.EqualError(t, n.Validate(), ErrNoteMaxViewsIsInvalid.Error())
+	})
+	t.Run("should fail if note is kept before expiration and has max views", func(t *testing.T) {
+		n := Note{
+			Content:              "the content",
+			KeepBeforeExpiration: true,
+			ExpiresAt:            time.Now().Add(time.Hour),
+			MaxViews:             3,
+		}
+		assert.EqualError(t, n.Validate(), ErrNoteCannotBeKeptWithMaxViews.Error())
+	})
+	t.Run("should fail if note is burnt on lockout without password", func(t *testing.T) {
+		n := Note{Content: "the content", BurnOnLockout: true}
+		assert.EqualError(t, n.Validate(), ErrNoteBurnOnLockoutWithoutPassword.Error())
+
+		n.Password = "hashed"
+		assert.NoError(t, n.Validate())
+	})
+	t.Run("should pass if note becomes available before it expires", func(t *testing.T) {
+		n := Note{
+			Content:       "the content",
+			AvailableFrom: time.Now().Add(time.Hour),
+			ExpiresAt:     time.Now().Add(2 * time.Hour),
+		}
+		assert.NoError(t, n.Validate())
+	})
+	t.Run("should fail if note becomes available after it expires", func(t *testing.T) {
+		n := Note{
+			Content:       "the content",
+			AvailableFrom: time.Now().Add(2 * time.Hour),
+			ExpiresAt:     time.Now().Add(time.Hour),
+		}
+		assert.EqualError(t, n.Validate(), ErrNoteAvailabilityAfterExpiration.Error())
+	})
+	t.Run("should pass if note is bound to an email", func(t *testing.T) {
+		n := Note{Content: "the content", BoundEmail: "alice@example.com"}
+		assert.NoError(t, n.Validate())
+	})
+	t.Run("should fail if note is bound to an invalid email", func(t *testing.T) {
+		n := Note{Content: "the content", BoundEmail: "Bob <alice@example.com>"}
+		assert.EqualError(t, n.Validate(), ErrNoteBoundEmailInvalid.Error())
+	})
+}
+
+//nolint:exhaustruct
+func TestNote_IsAvailable(t *testing.T) {
+	t.Run("should be available when [AvailableFrom] is zero", func(t *testing.T) {
+		n := Note{AvailableFrom: time.Time{}}
+		assert.True(t, n.IsAvailable())
+	})
+	t.Run("should be available", func(t *testing.T) {
+		n := Note{AvailableFrom: time.Now().Add(-time.Hour)}
+		assert.True(t, n.IsAvailable())
+	})
+	t.Run("should not be available yet", func(t *testing.T) {
+		n := Note{AvailableFrom: time.Now().Add(time.Hour)}
+		assert.False(t, n.IsAvailable())
+	})
 }
 
 //nolint:exhaustruct
@@ -125,3 +225,96 @@ func TestNote_IsRead(t *testing.T) {
 		assert.True(t, n.IsRead())
 	})
 }
+
+//nolint:exhaustruct
+func TestNote_Views(t *testing.T) {
+	t.Run("unread", func(t *testing.T) {
+		n := Note{MaxViews: 3, ViewsLeft: 3}
+		assert.Zero(t, n.Views())
+	})
+	t.Run("read few times", func(t *testing.T) {
+		n := Note{MaxViews: 3, ViewsLeft: 1}
+		assert.Equal(t, 2, n.Views())
+	})
+	t.Run("kept before expiration", func(t *testing.T) {
+		n := Note{KeepBeforeExpiration: true}
+		assert.Zero(t, n.Views())
+	})
+}
+
+//nolint:exhaustruct
+func TestNote_CanBeRepliedTo(t *testing.T) {
+	t.Run("should be repliable", func(t *testing.T) {
+		n := Note{AllowReply: true}
+		assert.True(t, n.CanBeRepliedTo())
+	})
+	t.Run("should not be repliable if not allowed", func(t *testing.T) {
+		n := Note{AllowReply: false}
+		assert.False(t, n.CanBeRepliedTo())
+	})
+	t.Run("should not be repliable once replied", func(t *testing.T) {
+		n := Note{AllowReply: true, ReplySlug: "reply"}
+		assert.False(t, n.CanBeRepliedTo())
+	})
+}
+
+//nolint:exhaustruct
+func TestNote_IsEditable(t *testing.T) {
+	t.Run("should be editable", func(t *testing.T) {
+		n := Note{MaxViews: 3, ViewsLeft: 3, ExpiresAt: time.Now().Add(time.Hour)}
+		assert.True(t, n.IsEditable())
+	})
+	t.Run("should not be editable once viewed", func(t *testing.T) {
+		n := Note{MaxViews: 3, ViewsLeft: 2}
+		assert.False(t, n.IsEditable())
+	})
+	t.Run("should not be editable once kept note is viewed", func(t *testing.T) {
+		n := Note{KeepBeforeExpiration: true, ViewedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+		assert.False(t, n.IsEditable())
+	})
+	t.Run("should not be editable once read", func(t *testing.T) {
+		n := Note{MaxViews: 1, ViewsLeft: 0, ReadAt: time.Now()}
+		assert.False(t, n.IsEditable())
+	})
+	t.Run("should not be editable once expired", func(t *testing.T) {
+		n := Note{MaxViews: 1, ViewsLeft: 1, ExpiresAt: time.Now().Add(-time.Hour)}
+		assert.False(t, n.IsEditable())
+	})
+}
+
+//nolint:exhaustruct
+func TestNote_ValidateContent(t *testing.T) {
+	assert.ErrorIs(t, Note{}.ValidateContent(), ErrNoteContentIsEmpty)
+	assert.NoError(t, Note{Content: "content"}.ValidateContent())
+	assert.ErrorIs(t, Note{
+		Content:           "not a ciphertext",
+		EncryptionScheme:  notecrypt.Scheme,
+		EncryptionVersion: notecrypt.Version,
+	}.ValidateContent(), ErrNoteContentIsNotCiphertext)
+}
+
+//nolint:exhaustruct
+func TestNote_IsBound(t *testing.T) {
+	assert.False(t, Note{}.IsBound())
+	assert.True(t, Note{BoundEmail: "alice@example.com"}.IsBound())
+}
+
+//nolint:exhaustruct
+func TestNote_CanBeReadBy(t *testing.T) {
+	userID := uuid.Must(uuid.NewV4())
+	assert.True(t, Note{}.CanBeReadBy(uuid.Nil))
+	assert.True(t, Note{}.CanBeReadBy(userID))
+
+	n := Note{InboxUserID: userID}
+	assert.True(t, n.CanBeReadBy(userID))
+	assert.False(t, n.CanBeReadBy(uuid.Nil))
+	assert.False(t, n.CanBeReadBy(uuid.Must(uuid.NewV4())))
+
+	assert.False(t, Note{InboxRecipientDeleted: true}.CanBeReadBy(uuid.Nil))
+}
+
+//nolint:exhaustruct
+func TestNote_HasRecipients(t *testing.T) {
+	assert.False(t, Note{}.HasRecipients())
+	assert.True(t, Note{GroupID: uuid.Must(uuid.NewV4())}.HasRecipients())
+}
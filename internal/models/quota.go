@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrQuotaContentTooLarge      = errors.New("quota: note content is too large")
+	ErrQuotaDailyNotesExceeded   = errors.New("quota: daily notes limit is reached")
+	ErrQuotaActiveNotesExceeded  = errors.New("quota: active notes limit is reached")
+	ErrQuotaExpirationTooFar     = errors.New("quota: note should expire sooner")
+	ErrQuotaExpirationIsRequired = errors.New("quota: note should have an expiration time")
+	ErrQuotaOverrideNotFound     = errors.New("quota: override not found")
+)
+
+type QuotaTier string
+
+const (
+	QuotaTierAnonymous  QuotaTier = "anonymous"
+	QuotaTierRegistered QuotaTier = "registered"
+
+	// QuotaTierCustom is the registered tier, with some of its limits overridden for the user.
+	QuotaTierCustom QuotaTier = "custom"
+)
+
+// Quota limits notes that can be created, zero limit means there's no limit.
+type Quota struct {
+	Tier            QuotaTier
+	MaxContentBytes int
+	NotesPerDay     int
+
+	// MaxActiveNotes is how many notes can be unread and not expired at the same time.
+	MaxActiveNotes int
+
+	// MaxExpiresIn is how far in the future notes can expire,
+	// notes without an expiration time cannot be created if it's set.
+	MaxExpiresIn time.Duration
+}
+
+// QuotaOverride overrides limits of the user's tier, nil limits are inherited from the tier.
+type QuotaOverride struct {
+	UserID          uuid.UUID
+	MaxContentBytes *int
+	NotesPerDay     *int
+	MaxActiveNotes  *int
+	MaxExpiresIn    *time.Duration
+}
+
+// WithOverride returns the quota with limits set by the override.
+func (q Quota) WithOverride(o QuotaOverride) Quota {
+	q.Tier = QuotaTierCustom
+	if o.MaxContentBytes != nil {
+		q.MaxContentBytes = *o.MaxContentBytes
+	}
+	if o.NotesPerDay != nil {
+		q.NotesPerDay = *o.NotesPerDay
+	}
+	if o.MaxActiveNotes != nil {
+		q.MaxActiveNotes = *o.MaxActiveNotes
+	}
+	if o.MaxExpiresIn != nil {
+		q.MaxExpiresIn = *o.MaxExpiresIn
+	}
+	return q
+}
+
+// CheckContent checks whether the note's content fits into the quota.
+func (q Quota) CheckContent(content string) error {
+	if q.MaxContentBytes > 0 && len(content) > q.MaxContentBytes {
+		return ErrQuotaContentTooLarge
+	}
+	return nil
+}
+
+// CheckNote checks whether the note fits into the limits that don't depend on the usage.
+func (q Quota) CheckNote(note Note, now time.Time) error {
+	if err := q.CheckContent(note.Content); err != nil {
+		return err
+	}
+
+	return q.CheckExpiration(note.ExpiresAt, now)
+}
+
+// CheckExpiration checks whether the note's expiration time fits into the quota.
+func (q Quota) CheckExpiration(expiresAt, now time.Time) error {
+	if q.MaxExpiresIn > 0 {
+		if expiresAt.IsZero() {
+			return ErrQuotaExpirationIsRequired
+		}
+
+		if expiresAt.After(now.Add(q.MaxExpiresIn)) {
+			return ErrQuotaExpirationTooFar
+		}
+	}
+
+	return nil
+}
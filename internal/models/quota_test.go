@@ -0,0 +1,75 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+//nolint:exhaustruct
+func TestQuota_CheckNote(t *testing.T) {
+	now := time.Now()
+	quota := Quota{
+		MaxContentBytes: 8,
+		MaxExpiresIn:    time.Hour,
+	}
+
+	t.Run("should pass if note fits", func(t *testing.T) {
+		assert.NoError(t, quota.CheckNote(Note{
+			Content:   strings.Repeat("a", 8),
+			ExpiresAt: now.Add(time.Hour),
+		}, now))
+	})
+	t.Run("should pass if there's no limits", func(t *testing.T) {
+		assert.NoError(t, Quota{}.CheckNote(Note{Content: strings.Repeat("a", 1024)}, now))
+	})
+	t.Run("should fail if content is too large", func(t *testing.T) {
+		assert.ErrorIs(t, quota.CheckNote(Note{
+			Content:   strings.Repeat("a", 9),
+			ExpiresAt: now.Add(time.Minute),
+		}, now), ErrQuotaContentTooLarge)
+	})
+	t.Run("should fail if note expires too late", func(t *testing.T) {
+		assert.ErrorIs(t, quota.CheckNote(Note{
+			Content:   "a",
+			ExpiresAt: now.Add(time.Hour + time.Second),
+		}, now), ErrQuotaExpirationTooFar)
+	})
+	t.Run("should fail if note never expires", func(t *testing.T) {
+		assert.ErrorIs(t, quota.CheckNote(Note{Content: "a"}, now), ErrQuotaExpirationIsRequired)
+	})
+}
+
+func TestQuota_CheckExpiration(t *testing.T) {
+	now := time.Now()
+	quota := Quota{MaxExpiresIn: time.Hour} //nolint:exhaustruct
+
+	assert.NoError(t, quota.CheckExpiration(now.Add(time.Hour), now))
+	assert.NoError(t, Quota{}.CheckExpiration(time.Time{}, now)) //nolint:exhaustruct
+	assert.ErrorIs(t, quota.CheckExpiration(now.Add(2*time.Hour), now), ErrQuotaExpirationTooFar)
+	assert.ErrorIs(t, quota.CheckExpiration(time.Time{}, now), ErrQuotaExpirationIsRequired)
+}
+
+func TestQuota_WithOverride(t *testing.T) {
+	notesPerDay, maxExpiresIn := 0, 24*time.Hour
+	quota := Quota{
+		Tier:            QuotaTierRegistered,
+		MaxContentBytes: 1024,
+		NotesPerDay:     10,
+		MaxActiveNotes:  5,
+		MaxExpiresIn:    time.Hour,
+	}.WithOverride(QuotaOverride{ //nolint:exhaustruct
+		NotesPerDay:  &notesPerDay,
+		MaxExpiresIn: &maxExpiresIn,
+	})
+
+	assert.Equal(t, Quota{
+		Tier:            QuotaTierCustom,
+		MaxContentBytes: 1024,
+		NotesPerDay:     0,
+		MaxActiveNotes:  5,
+		MaxExpiresIn:    24 * time.Hour,
+	}, quota)
+}
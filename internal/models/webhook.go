@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook: not found")
+	ErrWebhookURLInvalid   = errors.New("webhook: url should be an absolute http or https url")
+	ErrWebhookNoEvents     = errors.New("webhook: should be subscribed to at least one event")
+	ErrWebhookEventUnknown = errors.New("webhook: unknown event")
+)
+
+const maxWebhookURLLength = 2048
+
+// WebhookEvent is an event of note's life cycle, webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	WebhookEventNoteCreated         WebhookEvent = "note.created"
+	WebhookEventNoteRead            WebhookEvent = "note.read"
+	WebhookEventNoteExpired         WebhookEvent = "note.expired"
+	WebhookEventNoteDeleted         WebhookEvent = "note.deleted"
+	WebhookEventNoteReleased        WebhookEvent = "note.released"
+	WebhookEventNoteAccessRequested WebhookEvent = "note.access_requested"
+)
+
+var webhookEvents = map[WebhookEvent]struct{}{
+	WebhookEventNoteCreated:         {},
+	WebhookEventNoteRead:            {},
+	WebhookEventNoteExpired:         {},
+	WebhookEventNoteDeleted:         {},
+	WebhookEventNoteReleased:        {},
+	WebhookEventNoteAccessRequested: {},
+}
+
+type Webhook struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+	URL    string
+	Events []WebhookEvent
+
+	// Secret is used to sign deliveries, so the receiver can verify they're sent by us.
+	Secret    string
+	CreatedAt time.Time
+}
+
+func (w Webhook) Validate() error {
+	if err := ValidateWebhookURL(w.URL); err != nil {
+		return err
+	}
+
+	return ValidateWebhookEvents(w.Events)
+}
+
+func ValidateWebhookURL(rawURL string) error {
+	if len(rawURL) > maxWebhookURLLength {
+		return ErrWebhookURLInvalid
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil ||
+		(u.Scheme != "http" && u.Scheme != "https") ||
+		u.Host == "" || u.User != nil {
+		return ErrWebhookURLInvalid
+	}
+
+	return nil
+}
+
+func ValidateWebhookEvents(events []WebhookEvent) error {
+	if len(events) == 0 {
+		return ErrWebhookNoEvents
+	}
+
+	for _, e := range events {
+		if _, ok := webhookEvents[e]; !ok {
+			return ErrWebhookEventUnknown
+		}
+	}
+
+	return nil
+}
+
+// WebhookDeliveryStatus is the status of webhook delivery,
+// pending deliveries are (re)tried until they succeed, or run out of attempts.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	ID        uuid.UUID
+	WebhookID uuid.UUID
+	Event     WebhookEvent
+	Payload   string
+	Status    WebhookDeliveryStatus
+	Attempts  int
+
+	// ResponseStatus is the http status of the last attempt,
+	// zero if the endpoint didn't respond, then Error is set.
+	ResponseStatus int
+	Error          string
+
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+	LastAttemptAt time.Time
+}
@@ -0,0 +1,61 @@
+// Package netguard keeps outgoing connections, made on behalf of users, away from internal networks.
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+var ErrAddrNotAllowed = errors.New("netguard: address is not allowed")
+
+// blockedPrefixes are ranges that aren't covered by [netip.Addr] methods.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+}
+
+// IsAllowed reports whether connections to the ip are allowed,
+// that is when it's a public address, not a loopback, private, link-local, or unspecified one.
+func IsAllowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() {
+		return false
+	}
+
+	for _, p := range blockedPrefixes {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Control is [net.Dialer.Control] that refuses connections to addresses that aren't allowed.
+// It's called with already resolved address, so the check cannot be bypassed by DNS rebinding.
+func Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !IsAllowed(ip) {
+		return ErrAddrNotAllowed
+	}
+
+	return nil
+}
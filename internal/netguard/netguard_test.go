@@ -0,0 +1,66 @@
+package netguard
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAllowed(t *testing.T) {
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"1.1.1.1", true},
+		{"93.184.216.34", true},
+		{"2606:4700:4700::1111", true},
+		{"100.63.255.255", true},
+		{"100.128.0.0", true},
+
+		// loopback
+		{"127.0.0.1", false},
+		{"127.1.2.3", false},
+		{"::1", false},
+		// private
+		{"10.0.0.1", false},
+		{"172.16.0.1", false},
+		{"172.31.255.255", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		// link-local, including cloud metadata
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		// carrier-grade NAT
+		{"100.64.0.1", false},
+		{"100.127.255.255", false},
+		// unspecified
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"::", false},
+		// ipv4-mapped ipv6
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		// multicast
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			require.Equal(t, tt.allowed, IsAllowed(netip.MustParseAddr(tt.ip)))
+		})
+	}
+}
+
+func TestControl(t *testing.T) {
+	require.NoError(t, Control("tcp4", "1.1.1.1:443", nil))
+	require.NoError(t, Control("tcp6", "[2606:4700:4700::1111]:443", nil))
+
+	require.ErrorIs(t, Control("tcp4", "127.0.0.1:80", nil), ErrAddrNotAllowed)
+	require.ErrorIs(t, Control("tcp4", "169.254.169.254:80", nil), ErrAddrNotAllowed)
+	require.ErrorIs(t, Control("tcp6", "[::1]:80", nil), ErrAddrNotAllowed)
+	require.ErrorIs(t, Control("tcp6", "[::ffff:10.0.0.1]:80", nil), ErrAddrNotAllowed)
+
+	require.Error(t, Control("tcp4", "not-an-address", nil))
+}
@@ -81,7 +81,7 @@ type AuthSrv struct {
 	vertokrepo   vertokrepo.VerificationTokenStorer
 	cache        usercache.UserCacheer
 
-	hasher       hasher.Hasher
+	hasher       hasher.Rehasher
 	jwtTokenizer jwtutil.JWTTokenizer
 	mailermq     mailermq.Mailer
 
@@ -97,7 +97,7 @@ func New(
 	sessionstore sessionrepo.SessionStorer,
 	vertokrepo vertokrepo.VerificationTokenStorer,
 	cache usercache.UserCacheer,
-	hasher hasher.Hasher,
+	hasher hasher.Rehasher,
 	jwtTokenizer jwtutil.JWTTokenizer,
 	mailermq mailermq.Mailer,
 	googleOauth, githubOauth oauth.Provider,
@@ -170,7 +170,8 @@ func (a *AuthSrv) SignIn(ctx context.Context, inp dtos.SignIn) (dtos.Tokens, err
 	}
 
 	if err = a.hasher.Compare(user.Password, inp.Password); err != nil {
-		if errors.Is(err, hasher.ErrMismatchedHashes) {
+		if errors.Is(err, hasher.ErrMismatchedHashes) ||
+			errors.Is(err, hasher.ErrHashUnrecognized) {
 			return dtos.Tokens{}, models.ErrUserNotFound
 		}
 		return dtos.Tokens{}, err
@@ -180,9 +181,29 @@ func (a *AuthSrv) SignIn(ctx context.Context, inp dtos.SignIn) (dtos.Tokens, err
 		return dtos.Tokens{}, models.ErrUserIsNotActivated
 	}
 
+	a.rehashPassword(ctx, user, inp.Password)
+
 	return a.issueTokens(ctx, user.ID)
 }
 
+// rehashPassword rehashes the password if it's hashed by outdated algorithm or params,
+// it's done on the best-effort basis, so failing to rehash doesn't fail the login.
+func (a *AuthSrv) rehashPassword(ctx context.Context, user models.User, password string) {
+	if !a.hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := a.hasher.Hash(password)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to rehash password", "user_id", user.ID, "err", err)
+		return
+	}
+
+	if err := a.userstore.RehashPassword(ctx, user.ID, user.Password, hashedPassword); err != nil {
+		slog.ErrorContext(ctx, "failed to update rehashed password", "user_id", user.ID, "err", err)
+	}
+}
+
 func (a *AuthSrv) RefreshTokens(ctx context.Context, rtoken string) (dtos.Tokens, error) {
 	userID, err := a.sessionstore.GetUserIDByRefreshToken(ctx, rtoken)
 	if err != nil {
@@ -0,0 +1,49 @@
+package notesrv
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// MaxAccessEvents is how many latest accesses to a note are returned to its author.
+const MaxAccessEvents = 100
+
+// recordAccess records the reader's access to the note.
+// It's recorded after the access is already done, so failures are only logged.
+func (n *NoteSrv) recordAccess(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	kind models.NoteAccessKind,
+	reader Reader,
+) {
+	n.recordAccessEvent(ctx, slug, models.NewNoteAccessEvent(kind, reader.IP, reader.UserAgent, time.Now()))
+}
+
+// recordAccessEvent records the event as is, failures are only logged.
+func (n *NoteSrv) recordAccessEvent(ctx context.Context, slug dtos.NoteSlug, event models.NoteAccessEvent) {
+	if err := n.noterepo.CreateAccessEvent(ctx, slug, event); err != nil {
+		slog.ErrorContext(ctx, "failed to record note access", "slug", slug, "kind", event.Kind, "err", err)
+	}
+}
+
+func (n *NoteSrv) GetAccessEvents(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+) ([]models.NoteAccessEvent, error) {
+	note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.noterepo.GetAccessEventsByNoteID(ctx, note.ID, MaxAccessEvents)
+}
+
+func (n *NoteSrv) DeleteOldAccessEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	return n.noterepo.DeleteAccessEventsBefore(ctx, time.Now().Add(-retention))
+}
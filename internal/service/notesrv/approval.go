@@ -0,0 +1,204 @@
+package notesrv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/events/mailermq"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// MaxAccessRequests is how many latest access requests to a note are returned to its author.
+const MaxAccessRequests = 100
+
+const (
+	accessRequestTokenPrefix = "nar_"
+	accessRequestTokenSize   = 32
+)
+
+// checkNoteApproval checks that the note that's being created can require approval.
+func checkNoteApproval(inp dtos.CreateNote, userID uuid.UUID) error {
+	// the author is the one who approves reads
+	if userID.IsNil() {
+		return models.ErrNoteApprovalWithoutAuthor
+	}
+
+	// released notes are read when the author is gone, so nobody could approve them
+	if inp.Switch != nil {
+		return models.ErrNoteApprovalWithSwitch
+	}
+
+	// notes in an inbox are already read only by one user
+	if inp.InboxEmail != "" {
+		return models.ErrNoteApprovalWithInbox
+	}
+
+	// the one-time code would be used up by readers whose access request isn't approved yet
+	if inp.BoundEmail != "" {
+		return models.ErrNoteApprovalWithBound
+	}
+
+	return nil
+}
+
+func (n *NoteSrv) RequestAccess(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	reason string,
+	reader Reader,
+) (dtos.CreatedNoteAccessRequest, error) {
+	if err := models.ValidateNoteAccessRequestReason(reason); err != nil {
+		return dtos.CreatedNoteAccessRequest{}, err
+	}
+
+	requiresApproval, err := n.noterepo.GetRequiresApprovalBySlug(ctx, slug)
+	if err != nil {
+		return dtos.CreatedNoteAccessRequest{}, err
+	}
+
+	if !requiresApproval {
+		return dtos.CreatedNoteAccessRequest{}, models.ErrNoteApprovalNotRequired
+	}
+
+	token, err := generateAccessRequestToken()
+	if err != nil {
+		return dtos.CreatedNoteAccessRequest{}, err
+	}
+
+	now := time.Now()
+	event := models.NewNoteAccessEvent(models.NoteAccessRequested, reader.IP, reader.UserAgent, now)
+
+	//nolint:exhaustruct // ID is generated by the database, and the request isn't decided or used yet
+	req := models.NoteAccessRequest{
+		Reason:    reason,
+		Status:    models.NoteAccessRequestPending,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		CreatedAt: now,
+		ExpiresAt: now.Add(n.cfg.AccessRequestTTL),
+	}
+
+	req.ID, err = n.noterepo.CreateAccessRequest(ctx, slug, hashAccessRequestToken(token), req)
+	if err != nil {
+		return dtos.CreatedNoteAccessRequest{}, err
+	}
+
+	n.recordAccessEvent(ctx, slug, event)
+	n.notifyAuthorAccessRequested(ctx, slug, req)
+
+	return dtos.CreatedNoteAccessRequest{
+		ID:             req.ID,
+		Token:          token,
+		ExpiresAt:      req.ExpiresAt,
+		TokenExpiresAt: req.ExpiresAt.Add(n.cfg.AccessRequestTTL),
+	}, nil
+}
+
+func (n *NoteSrv) GetAccessRequests(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+) ([]models.NoteAccessRequest, error) {
+	note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.noterepo.GetAccessRequestsByNoteID(ctx, note.ID, MaxAccessRequests)
+}
+
+func (n *NoteSrv) DecideAccessRequest(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+	requestID uuid.UUID,
+	approve bool,
+) error {
+	note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+	if err != nil {
+		return err
+	}
+
+	// approved requests are given the whole ttl to be used, denied ones end right away
+	now := time.Now()
+	status, kind, expiresAt := models.NoteAccessRequestDenied, models.NoteAccessDenied, now
+	if approve {
+		status, kind, expiresAt = models.NoteAccessRequestApproved, models.NoteAccessApproved, now.Add(n.cfg.AccessRequestTTL)
+	}
+
+	req, err := n.noterepo.DecideAccessRequest(ctx, note.ID, requestID, status, now, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	// the decision is shown along with the requester's accesses, so it's recorded with their ip
+	n.recordAccessEvent(ctx, slug, models.NoteAccessEvent{
+		Kind:      kind,
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		CreatedAt: now,
+	})
+
+	return nil
+}
+
+// useAccessRequest uses the reader's approved access request to read the note that's kept before expiration,
+// each request is used once. Requests to notes that are burnt on read are used along with consuming the note.
+func (n *NoteSrv) useAccessRequest(ctx context.Context, inp GetNoteBySlugInput) error {
+	return n.noterepo.UseAccessRequest(ctx, inp.Slug, hashAccessRequestToken(inp.AccessToken), time.Now())
+}
+
+// notifyAuthorAccessRequested emails, and emits [models.WebhookEventNoteAccessRequested] to the note's author.
+// The request is already made at this point, so failures are only logged.
+func (n *NoteSrv) notifyAuthorAccessRequested(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	req models.NoteAccessRequest,
+) {
+	email, err := n.noterepo.GetAuthorEmailBySlug(ctx, slug)
+	if err == nil {
+		err = n.mailermq.SendNoteAccessRequest(ctx, mailermq.SendNoteAccessRequestRequest{
+			Receiver:  email,
+			Slug:      slug,
+			Reason:    req.Reason,
+			ExpiresAt: req.ExpiresAt,
+		})
+	}
+	if err != nil && !errors.Is(err, models.ErrNoteNotFound) {
+		slog.ErrorContext(ctx, "failed to send note access request", "slug", slug, "err", err)
+	}
+
+	authorID, err := n.noterepo.GetAuthorIDBySlug(ctx, slug)
+	if err != nil {
+		if !errors.Is(err, models.ErrNoteNotFound) {
+			slog.ErrorContext(ctx, "failed to get note's author", "slug", slug, "err", err)
+		}
+		return
+	}
+
+	n.emit(ctx, authorID, models.WebhookEventNoteAccessRequested, slug)
+}
+
+func generateAccessRequestToken() (string, error) {
+	token := make([]byte, accessRequestTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return accessRequestTokenPrefix + base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashAccessRequestToken hashes the token, so it could be looked up by the hash,
+// the token is random enough not to be salted or stretched.
+func hashAccessRequestToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,263 @@
+package notesrv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/blob"
+)
+
+type AttachmentsConfig struct {
+	// MaxSize is the maximum size of a single attachment in bytes.
+	MaxSize int64
+
+	// MaxCount is the maximum number of attachments per note.
+	MaxCount int
+
+	// DownloadWindow is for how long attachments of a burnt note can be downloaded,
+	// each of them only once.
+	DownloadWindow time.Duration
+}
+
+const (
+	defaultAttachmentContentType = "application/octet-stream"
+	purgeAttachmentsBatchSize    = 100
+)
+
+func (n *NoteSrv) GetAttachment(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	id uuid.UUID,
+	readToken string,
+) (dtos.NoteAttachment, io.ReadCloser, error) {
+	// the token is issued only once the note is read, so the note's checks cannot be skipped
+	ok, err := n.isReadTokenValid(ctx, slug, readToken)
+	if err != nil {
+		return dtos.NoteAttachment{}, nil, err
+	}
+	if !ok {
+		return dtos.NoteAttachment{}, nil, models.ErrNoteAttachmentNotFound
+	}
+
+	now := time.Now()
+	attachment, isNoteRead, err := n.attachmentrepo.GetDownloadable(
+		ctx, slug, id, now.Add(-n.cfg.Attachments.DownloadWindow), now)
+	if err != nil {
+		return dtos.NoteAttachment{}, nil, err
+	}
+
+	// attachments of burnt notes are downloaded only once, so the one who detaches it, downloads it
+	if isNoteRead {
+		if err := n.attachmentrepo.Detach(ctx, id); err != nil {
+			return dtos.NoteAttachment{}, nil, err
+		}
+	}
+
+	stored, err := n.blobs.Get(ctx, id.String())
+	if errors.Is(err, blob.ErrNotFound) {
+		return dtos.NoteAttachment{}, nil, models.ErrNoteAttachmentNotFound
+	}
+	if err != nil {
+		return dtos.NoteAttachment{}, nil, err
+	}
+
+	content, err := n.blobEnc.DecryptStream(ctx, stored, attachment.ContentKeyID)
+	if err != nil {
+		return dtos.NoteAttachment{}, nil, errors.Join(err, stored.Close())
+	}
+
+	closeFn := stored.Close
+	if isNoteRead {
+		closeFn = func() error {
+			err := stored.Close()
+			if derr := n.deleteAttachment(context.WithoutCancel(ctx), id); derr != nil {
+				// the attachment is detached, so the reaper deletes it later
+				slog.ErrorContext(ctx, "failed to delete downloaded attachment", "err", derr)
+			}
+			return err
+		}
+	}
+
+	return mapAttachmentModelToDto(attachment), attachmentReader{content, closeFn}, nil
+}
+
+func (n *NoteSrv) PurgeAttachments(ctx context.Context) (int64, error) {
+	now := time.Now()
+	if _, err := n.attachmentrepo.DetachStale(ctx, now, now.Add(-n.cfg.Attachments.DownloadWindow)); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for {
+		ids, err := n.attachmentrepo.GetDetached(ctx, purgeAttachmentsBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, id := range ids {
+			if err := n.deleteAttachment(ctx, id); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(ids) < purgeAttachmentsBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// storeAttachments stores blobs of all attachments, if any of them fails, the stored ones are deleted.
+func (n *NoteSrv) storeAttachments(
+	ctx context.Context,
+	attachments iter.Seq2[dtos.CreateNoteAttachment, error],
+) ([]models.NoteAttachment, error) {
+	if attachments == nil {
+		return nil, nil
+	}
+
+	var stored []models.NoteAttachment
+	for inp, err := range attachments {
+		if err == nil && len(stored) >= n.cfg.Attachments.MaxCount {
+			err = models.ErrNoteTooManyAttachments
+		}
+
+		var attachment models.NoteAttachment
+		if err == nil {
+			attachment, err = n.storeAttachment(ctx, inp)
+		}
+
+		if err != nil {
+			n.deleteAttachmentBlobs(ctx, stored)
+			return nil, err
+		}
+
+		stored = append(stored, attachment)
+	}
+
+	return stored, nil
+}
+
+func (n *NoteSrv) storeAttachment(
+	ctx context.Context,
+	inp dtos.CreateNoteAttachment,
+) (models.NoteAttachment, error) {
+	//nolint:exhaustruct // Size and ContentKeyID are known only after the content is stored
+	attachment := models.NoteAttachment{
+		ID:          uuid.Must(uuid.NewV4()),
+		Filename:    inp.Filename,
+		ContentType: inp.ContentType,
+		CreatedAt:   time.Now(),
+	}
+	if err := attachment.Validate(); err != nil {
+		return models.NoteAttachment{}, err
+	}
+
+	if attachment.ContentType == "" {
+		attachment.ContentType = defaultAttachmentContentType
+	}
+
+	content := &sizeLimitedReader{r: inp.Content, limit: n.cfg.Attachments.MaxSize}
+	encrypted, keyID, err := n.blobEnc.EncryptStream(ctx, content)
+	if err != nil {
+		return models.NoteAttachment{}, err
+	}
+
+	if err := n.blobs.Put(ctx, attachment.ID.String(), encrypted); err != nil {
+		// blob store might wrap the error, or not return it at all
+		if content.exceeded {
+			err = models.ErrNoteAttachmentTooLarge
+		}
+
+		return models.NoteAttachment{}, errors.Join(err, n.blobs.Delete(ctx, attachment.ID.String()))
+	}
+
+	attachment.Size = content.read
+	attachment.ContentKeyID = keyID
+
+	return attachment, nil
+}
+
+func (n *NoteSrv) getAttachments(ctx context.Context, slug dtos.NoteSlug) ([]dtos.NoteAttachment, error) {
+	attachments, err := n.attachmentrepo.GetAllByNoteSlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dtos.NoteAttachment, 0, len(attachments))
+	for _, a := range attachments {
+		res = append(res, mapAttachmentModelToDto(a))
+	}
+
+	return res, nil
+}
+
+// deleteAttachment deletes the attachment's blob, and then the attachment itself.
+func (n *NoteSrv) deleteAttachment(ctx context.Context, id uuid.UUID) error {
+	if err := n.blobs.Delete(ctx, id.String()); err != nil {
+		return err
+	}
+
+	return n.attachmentrepo.Delete(ctx, id)
+}
+
+// deleteAttachmentBlobs deletes blobs of attachments that aren't saved yet.
+func (n *NoteSrv) deleteAttachmentBlobs(ctx context.Context, attachments []models.NoteAttachment) {
+	ctx = context.WithoutCancel(ctx)
+	for _, a := range attachments {
+		if err := n.blobs.Delete(ctx, a.ID.String()); err != nil {
+			slog.ErrorContext(ctx, "failed to delete attachment blob", "id", a.ID, "err", err)
+		}
+	}
+}
+
+func mapAttachmentModelToDto(a models.NoteAttachment) dtos.NoteAttachment {
+	return dtos.NoteAttachment{
+		ID:          a.ID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+	}
+}
+
+type attachmentReader struct {
+	io.Reader
+	close func() error
+}
+
+func (a attachmentReader) Close() error { return a.close() }
+
+// sizeLimitedReader fails with [models.ErrNoteAttachmentTooLarge], once more than limit bytes are read.
+type sizeLimitedReader struct {
+	r        io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (s *sizeLimitedReader) Read(p []byte) (int, error) {
+	if s.exceeded {
+		return 0, models.ErrNoteAttachmentTooLarge
+	}
+
+	// one extra byte is read to find out whether the limit is exceeded
+	if left := s.limit - s.read + 1; int64(len(p)) > left {
+		p = p[:left]
+	}
+
+	n, err := s.r.Read(p)
+	s.read += int64(n)
+	if s.read > s.limit {
+		s.exceeded = true
+		return 0, models.ErrNoteAttachmentTooLarge
+	}
+
+	return n, err
+}
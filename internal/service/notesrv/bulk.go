@@ -0,0 +1,114 @@
+package notesrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+func (n *NoteSrv) BulkUpdate(
+	ctx context.Context,
+	authorID uuid.UUID,
+	inp dtos.BulkNotes,
+) (dtos.BulkNotesResult, error) {
+	query := models.NoteBulkQuery{
+		Action:    models.NoteBulkAction(inp.Action),
+		Slugs:     inp.Slugs,
+		Filter:    nil,
+		ExpiresAt: inp.ExpiresAt,
+		Password:  "",
+	}
+	if inp.Filter != nil {
+		filter := mapNotesFilterDtoToModel(*inp.Filter)
+		query.Filter = &filter
+	}
+
+	now := time.Now()
+	if err := query.Validate(now); err != nil {
+		return dtos.BulkNotesResult{}, err
+	}
+
+	if query.Action == models.NoteBulkActionSetExpiration {
+		if err := n.quotas.CheckExpiration(ctx, authorID, query.ExpiresAt); err != nil {
+			return dtos.BulkNotesResult{}, err
+		}
+	}
+
+	if query.Action == models.NoteBulkActionSetPassword {
+		if inp.Password == "" {
+			return dtos.BulkNotesResult{}, ErrNotePasswordNotProvided
+		}
+
+		// the notes share the password, so they share its hash as well,
+		// hashing it for each of them would only take longer
+		hashedPassword, err := n.hasher.Hash(inp.Password)
+		if err != nil {
+			return dtos.BulkNotesResult{}, err
+		}
+		query.Password = hashedPassword
+	}
+
+	return n.bulkUpdate(ctx, authorID, query, now)
+}
+
+func (n *NoteSrv) BurnAllUnread(ctx context.Context, authorID uuid.UUID) (dtos.BulkNotesResult, error) {
+	// expired notes cannot be read anyway, and their content is purged by the reaper
+	//nolint:exhaustruct
+	return n.bulkUpdate(ctx, authorID, models.NoteBulkQuery{
+		Action: models.NoteBulkActionBurn,
+		Filter: &models.NoteListFilter{Status: models.NoteStatusUnread},
+	}, time.Now())
+}
+
+func (n *NoteSrv) bulkUpdate(
+	ctx context.Context,
+	authorID uuid.UUID,
+	query models.NoteBulkQuery,
+	now time.Time,
+) (dtos.BulkNotesResult, error) {
+	selected, err := n.noterepo.BulkUpdateByAuthorID(ctx, authorID, query, now)
+	if err != nil {
+		return dtos.BulkNotesResult{}, err
+	}
+
+	res := dtos.BulkNotesResult{
+		Results: make([]dtos.BulkNoteResult, 0, len(selected)),
+		Done:    0,
+	}
+	for _, r := range selected {
+		res.Results = append(res.Results, dtos.BulkNoteResult{Slug: r.Slug, Status: string(r.Status)})
+		if r.Status != models.NoteBulkStatusDone {
+			continue
+		}
+
+		res.Done++
+		switch query.Action { //nolint:exhaustive // other actions have no side effects
+		case models.NoteBulkActionDelete:
+			n.emit(ctx, authorID, models.WebhookEventNoteDeleted, r.Slug)
+		case models.NoteBulkActionSetPassword:
+			n.resetPasswordAttempts(ctx, r.Slug)
+		}
+	}
+
+	// slugs that were asked for, but not selected, are reported in the order they were asked for
+	reported := make(map[dtos.NoteSlug]struct{}, len(selected))
+	for _, r := range selected {
+		reported[r.Slug] = struct{}{}
+	}
+	for _, slug := range query.Slugs {
+		if _, ok := reported[slug]; ok {
+			continue
+		}
+
+		reported[slug] = struct{}{}
+		res.Results = append(res.Results, dtos.BulkNoteResult{
+			Slug:   slug,
+			Status: string(models.NoteBulkStatusNotFound),
+		})
+	}
+
+	return res, nil
+}
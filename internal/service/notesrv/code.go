@@ -0,0 +1,106 @@
+package notesrv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/events/mailermq"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+func (n *NoteSrv) RequestCode(ctx context.Context, slug dtos.NoteSlug) error {
+	email, err := n.noterepo.GetBoundEmailBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	// new codes don't give more attempts, so they cannot be used to guess the code
+	if err := n.checkCodeAttempts(ctx, slug); err != nil {
+		return err
+	}
+
+	code, err := models.NewNoteCode()
+	if err != nil {
+		return err
+	}
+
+	if err := n.codes.Set(ctx, slug, hashNoteCode(slug, code)); err != nil {
+		return err
+	}
+
+	return n.mailermq.SendNoteCode(ctx, mailermq.SendNoteCodeRequest{
+		Receiver: email,
+		Slug:     slug,
+		Code:     code,
+	})
+}
+
+// checkNoteCode checks the one-time code given to the bound note, the code can be used only once,
+// it's deleted with [NoteSrv.deleteNoteCode] once the note is read, so the reader can retry if reading fails.
+func (n *NoteSrv) checkNoteCode(ctx context.Context, inp GetNoteBySlugInput) error {
+	if inp.Code == "" {
+		if err := n.checkCodeAttempts(ctx, inp.Slug); err != nil {
+			return err
+		}
+		return models.ErrNoteCodeRequired
+	}
+
+	// the attempt is counted before the code is compared,
+	// so concurrent attempts cannot get past the limit
+	attempts, err := n.codes.IncrementAttempts(ctx, inp.Slug)
+	if err != nil {
+		return err
+	}
+
+	if n.cfg.MaxCodeAttempts != 0 && attempts > int64(n.cfg.MaxCodeAttempts) {
+		return models.ErrNoteCodeAttemptsExceeded
+	}
+
+	codeHash, err := n.codes.Get(ctx, inp.Slug)
+	if err != nil {
+		return err
+	}
+
+	if codeHash == "" || !hmac.Equal([]byte(codeHash), []byte(hashNoteCode(inp.Slug, inp.Code))) {
+		n.recordAccess(ctx, inp.Slug, models.NoteAccessWrongCode, inp.Reader)
+		return models.ErrNoteCodeInvalid
+	}
+
+	return nil
+}
+
+// deleteNoteCode deletes the one-time code of the bound note, that's been read with it.
+// The note is already read at this point, so failures are only logged.
+func (n *NoteSrv) deleteNoteCode(ctx context.Context, slug dtos.NoteSlug) {
+	if err := n.codes.Delete(ctx, slug); err != nil {
+		slog.ErrorContext(ctx, "failed to delete note code", "slug", slug, "err", err)
+	}
+}
+
+func (n *NoteSrv) checkCodeAttempts(ctx context.Context, slug dtos.NoteSlug) error {
+	if n.cfg.MaxCodeAttempts == 0 {
+		return nil
+	}
+
+	attempts, err := n.codes.GetAttempts(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if attempts >= int64(n.cfg.MaxCodeAttempts) {
+		return models.ErrNoteCodeAttemptsExceeded
+	}
+
+	return nil
+}
+
+// hashNoteCode hashes the code, so it isn't stored as is,
+// the slug is mixed in, so the same codes of different notes have different hashes.
+func hashNoteCode(slug dtos.NoteSlug, code string) string {
+	sum := sha256.Sum256([]byte(slug + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,29 @@
+package notesrv
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// getInboxUserID returns id of the user, the note that's being created is sent to.
+func (n *NoteSrv) getInboxUserID(ctx context.Context, inp dtos.CreateNote, userID uuid.UUID) (uuid.UUID, error) {
+	// the recipient sees who sent the note, so it cannot be sent anonymously
+	if userID.IsNil() {
+		return uuid.Nil, models.ErrNoteInboxWithoutAuthor
+	}
+
+	// released notes are read by recipients of the switch, who couldn't read notes from someone's inbox
+	if inp.Switch != nil {
+		return uuid.Nil, models.ErrNoteInboxWithSwitch
+	}
+
+	return n.noterepo.GetInboxUserIDByEmail(ctx, inp.InboxEmail)
+}
+
+func (n *NoteSrv) GetInbox(ctx context.Context, userID uuid.UUID) ([]dtos.InboxNote, error) {
+	return n.noterepo.GetInboxByUserID(ctx, userID, time.Now())
+}
@@ -1,6 +1,9 @@
 package notesrv
 
-import "github.com/olexsmir/onasty/internal/dtos"
+import (
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+)
 
 const EmptyPassword = ""
 
@@ -12,8 +15,32 @@ type GetNoteBySlugInput struct {
 	// Password is a note's password.
 	// Leave it `""` if note has no password.
 	Password string
+
+	// Code is the one-time code sent to the email the note is bound to.
+	// Leave it `""` if note isn't bound.
+	Code string
+
+	// AccessToken is the token of the reader's access request, returned by [NoteSrv.RequestAccess].
+	// Leave it `""` if note doesn't require approval.
+	AccessToken string
+
+	// UserID is the signed in reader, notes sent to a user's inbox can be read only by the user.
+	// Leave it [uuid.Nil] if reader isn't signed in.
+	UserID uuid.UUID
+
+	// Reader is who reads the note, the access is recorded for the note's author.
+	Reader Reader
 }
 
 func (i GetNoteBySlugInput) HasPassword() bool {
 	return i.Password != EmptyPassword
 }
+
+// Reader is the client accessing a note.
+type Reader struct {
+	// IP is the reader's ip address, it's stored truncated to its network.
+	IP string
+
+	// UserAgent is the reader's User-Agent header.
+	UserAgent string
+}
@@ -0,0 +1,103 @@
+package notesrv
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/events/mailermq"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// checkNoteLink checks whether the link to the note that's being created can be emailed by the user,
+// before the note is created, the send is counted toward the limit with [NoteSrv.reserveLinkSend].
+func (n *NoteSrv) checkNoteLink(ctx context.Context, link dtos.CreateNoteLink, userID uuid.UUID) error {
+	// only authorized users are activated, so the sender's email is verified
+	if userID.IsNil() {
+		return models.ErrNoteLinkWithoutAuthor
+	}
+
+	send := models.NoteLinkSend{ //nolint:exhaustruct // it's not sent yet
+		RecipientEmail: link.RecipientEmail,
+		Message:        link.Message,
+	}
+	return send.Validate()
+}
+
+// reserveLinkSend counts the link the user is going to email toward the hourly limit.
+// The send is counted before it's compared to the limit, so concurrent ones cannot get past it,
+// it should be released with [NoteSrv.releaseLinkSend] if the note isn't created.
+func (n *NoteSrv) reserveLinkSend(ctx context.Context, userID uuid.UUID) error {
+	if n.cfg.MaxLinkSendsPerHour == 0 {
+		return nil
+	}
+
+	sent, err := n.linkSends.Increment(ctx, userID.String())
+	if err != nil {
+		return err
+	}
+
+	if sent > int64(n.cfg.MaxLinkSendsPerHour) {
+		return models.ErrNoteLinkSendsExceeded
+	}
+
+	return nil
+}
+
+// releaseLinkSend gives back the send reserved by [NoteSrv.reserveLinkSend], if the note has a link to send.
+func (n *NoteSrv) releaseLinkSend(ctx context.Context, link *dtos.CreateNoteLink, userID uuid.UUID) {
+	if link == nil || n.cfg.MaxLinkSendsPerHour == 0 {
+		return
+	}
+
+	if err := n.linkSends.Decrement(ctx, userID.String()); err != nil {
+		slog.ErrorContext(ctx, "failed to release link send", "err", err)
+	}
+}
+
+// sendNoteLink emails the link to the created note to the recipient, and records it.
+// The note is already created at this point, so failures are only logged, and the email isn't recorded,
+// the author can see that it wasn't sent with [NoteSrv.GetLinkSends].
+func (n *NoteSrv) sendNoteLink(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+	link dtos.CreateNoteLink,
+) {
+	sender, err := n.noterepo.GetAuthorEmailBySlug(ctx, slug)
+	if err == nil {
+		err = n.mailermq.SendNoteLink(ctx, mailermq.SendNoteLinkRequest{
+			Receiver:    link.RecipientEmail,
+			Slug:        slug,
+			SenderEmail: sender,
+			Message:     link.Message,
+		})
+	}
+
+	if err == nil {
+		err = n.noterepo.CreateLinkSend(ctx, slug, userID, models.NoteLinkSend{
+			RecipientEmail: link.RecipientEmail,
+			Message:        "",
+			SentAt:         time.Now(),
+		})
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send note link", "slug", slug, "err", err)
+	}
+}
+
+func (n *NoteSrv) GetLinkSends(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+) ([]models.NoteLinkSend, error) {
+	note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.noterepo.GetLinkSendsByNoteID(ctx, note.ID)
+}
@@ -0,0 +1,143 @@
+package notesrv
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+func (n *NoteSrv) ListByAuthorID(
+	ctx context.Context,
+	authorID uuid.UUID,
+	inp dtos.ListNotes,
+) (dtos.NotesPage, error) {
+	query := models.NoteListQuery{
+		Filter: mapNotesFilterDtoToModel(inp.NotesFilter),
+		SortBy: models.NoteSortField(inp.SortBy),
+		Order:  models.SortOrder(inp.Order),
+		Limit:  inp.Limit,
+		After:  nil,
+	}
+	if query.SortBy == "" {
+		query.SortBy = models.NoteSortByCreatedAt
+	}
+	if query.Order == "" {
+		query.Order = models.SortOrderDesc
+	}
+	if query.Limit == 0 {
+		query.Limit = models.DefaultNoteListLimit
+	}
+
+	if err := query.Validate(); err != nil {
+		return dtos.NotesPage{}, err
+	}
+
+	if inp.Cursor != "" {
+		after, err := decodeCursor(inp.Cursor, query.SortBy, query.Order)
+		if err != nil {
+			return dtos.NotesPage{}, err
+		}
+		query.After = &after
+	}
+
+	now := time.Now()
+	total, err := n.noterepo.GetCountByAuthorID(ctx, authorID, query.Filter, now)
+	if err != nil {
+		return dtos.NotesPage{}, err
+	}
+
+	// one more note is fetched to know whether there's the next page
+	pageQuery := query
+	pageQuery.Limit++
+	notes, err := n.noterepo.ListByAuthorID(ctx, authorID, pageQuery, now)
+	if err != nil {
+		return dtos.NotesPage{}, err
+	}
+
+	var nextCursor string
+	if len(notes) > query.Limit {
+		notes = notes[:query.Limit]
+		last := notes[len(notes)-1]
+		nextCursor, err = encodeCursor(query.SortBy, query.Order, models.NoteListCursor{
+			SortValue: last.SortValue(query.SortBy),
+			ID:        last.ID,
+		})
+		if err != nil {
+			return dtos.NotesPage{}, err
+		}
+	}
+
+	return dtos.NotesPage{
+		Notes:      n.mapNoteModelToDto(notes),
+		Total:      int(total),
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func mapNotesFilterDtoToModel(f dtos.NotesFilter) models.NoteListFilter {
+	return models.NoteListFilter{
+		Status:        models.NoteStatus(f.Status),
+		HasPassword:   f.HasPassword,
+		CreatedAfter:  f.CreatedAfter,
+		CreatedBefore: f.CreatedBefore,
+		ExpiresAfter:  f.ExpiresAfter,
+		ExpiresBefore: f.ExpiresBefore,
+	}
+}
+
+// cursor is an opaque, to the client, pointer to the last note of the page.
+// It's tied to the sorting it's made for, so it cannot be used with another one.
+type cursor struct {
+	SortBy    models.NoteSortField `json:"s"`
+	Order     models.SortOrder     `json:"o"`
+	SortValue time.Time            `json:"v"`
+	ID        uuid.UUID            `json:"i"`
+}
+
+func encodeCursor(
+	sortBy models.NoteSortField,
+	order models.SortOrder,
+	after models.NoteListCursor,
+) (string, error) {
+	raw, err := json.Marshal(cursor{
+		SortBy:    sortBy,
+		Order:     order,
+		SortValue: after.SortValue,
+		ID:        after.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(
+	s string,
+	sortBy models.NoteSortField,
+	order models.SortOrder,
+) (models.NoteListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return models.NoteListCursor{}, models.ErrNoteListCursorInvalid
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return models.NoteListCursor{}, models.ErrNoteListCursorInvalid
+	}
+
+	if c.SortBy != sortBy || c.Order != order || c.ID.IsNil() {
+		return models.NoteListCursor{}, models.ErrNoteListCursorInvalid
+	}
+
+	return models.NoteListCursor{
+		SortValue: c.SortValue,
+		ID:        c.ID,
+	}, nil
+}
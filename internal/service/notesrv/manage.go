@@ -0,0 +1,137 @@
+package notesrv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+const (
+	managementTokenPrefix = "ntm_"
+	managementTokenSize   = 32
+)
+
+func (n *NoteSrv) GetByManagementToken(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	token string,
+) (dtos.ManagedNote, error) {
+	if token == "" {
+		return dtos.ManagedNote{}, models.ErrNoteNotFound
+	}
+
+	note, err := n.noterepo.GetByManagementTokenHash(ctx, slug, hashManagementToken(token))
+	if err != nil {
+		return dtos.ManagedNote{}, err
+	}
+
+	return dtos.ManagedNote{
+		Slug:                 note.Slug,
+		KeepBeforeExpiration: note.KeepBeforeExpiration,
+		MaxViews:             note.MaxViews,
+		Views:                note.Views(),
+		CreatedAt:            note.CreatedAt,
+		ExpiresAt:            note.ExpiresAt,
+		AvailableFrom:        note.AvailableFrom,
+		ReadAt:               note.ReadAt,
+	}, nil
+}
+
+func (n *NoteSrv) UpdateExpirationTimeSettingsByManagementToken(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	token string,
+	patchData dtos.PatchNote,
+) error {
+	if token == "" {
+		return models.ErrNoteNotFound
+	}
+
+	tokenHash := hashManagementToken(token)
+	if patchData.AvailableFrom != nil || patchData.ExpiresAt != nil {
+		note, err := n.noterepo.GetByManagementTokenHash(ctx, slug, tokenHash)
+		if err != nil {
+			return err
+		}
+
+		if patchData.AvailableFrom != nil {
+			note.AvailableFrom = *patchData.AvailableFrom
+		}
+		if patchData.ExpiresAt != nil {
+			note.ExpiresAt = *patchData.ExpiresAt
+			// the note has no author, so it's within quota of anonymous notes
+			if err := n.quotas.CheckExpiration(ctx, uuid.Nil, note.ExpiresAt); err != nil {
+				return err
+			}
+		}
+
+		if err := note.ValidateAvailability(); err != nil {
+			return err
+		}
+	}
+
+	return n.noterepo.UpdateExpirationTimeSettingsByManagementTokenHash(ctx, slug, tokenHash, patchData)
+}
+
+func (n *NoteSrv) DeleteByManagementToken(ctx context.Context, slug dtos.NoteSlug, token string) error {
+	if token == "" {
+		return models.ErrNoteNotFound
+	}
+
+	attachments, err := n.attachmentrepo.GetAllByNoteSlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if err := n.noterepo.DeleteByManagementTokenHash(ctx, slug, hashManagementToken(token)); err != nil {
+		return err
+	}
+
+	n.deleteNoteAttachments(ctx, attachments)
+
+	return nil
+}
+
+func (n *NoteSrv) ClaimNotes(ctx context.Context, userID uuid.UUID, tokens []string) ([]dtos.NoteSlug, error) {
+	if len(tokens) == 0 || len(tokens) > models.MaxNoteClaimTokens {
+		return nil, models.ErrNoteClaimTokensInvalid
+	}
+
+	hashes := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		hashes = append(hashes, hashManagementToken(token))
+	}
+
+	slugs, err := n.noterepo.ClaimByManagementTokenHashes(ctx, userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, slug := range slugs {
+		n.emit(ctx, userID, models.WebhookEventNoteCreated, slug)
+	}
+
+	return slugs, nil
+}
+
+func generateManagementToken() (string, error) {
+	token := make([]byte, managementTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return managementTokenPrefix + base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// hashManagementToken hashes the token, so it could be looked up by the hash,
+// the token is random enough not to be salted or stretched.
+func hashManagementToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
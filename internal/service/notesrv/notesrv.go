@@ -3,47 +3,124 @@ package notesrv
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/envelope"
+	"github.com/olexsmir/onasty/internal/events/mailermq"
 	"github.com/olexsmir/onasty/internal/hasher"
 	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/service/quotasrv"
+	"github.com/olexsmir/onasty/internal/service/webhooksrv"
+	"github.com/olexsmir/onasty/internal/sluggen"
+	"github.com/olexsmir/onasty/internal/store/blob"
+	"github.com/olexsmir/onasty/internal/store/psql/attachmentrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
+	"github.com/olexsmir/onasty/internal/store/rdb/noteattempts"
 	"github.com/olexsmir/onasty/internal/store/rdb/notecache"
+	"github.com/olexsmir/onasty/internal/store/rdb/notecodes"
+	"github.com/olexsmir/onasty/internal/store/rdb/notelinksends"
+	"github.com/olexsmir/onasty/internal/store/rdb/notereads"
 )
 
 var ErrNotePasswordNotProvided = errors.New("note: password was not provided")
 
+// maxSlugAttempts is how many slugs are generated for a note, before giving up on collisions.
+const maxSlugAttempts = 3
+
 type NoteServicer interface {
 	// Create creates note
-	// if slug is empty it will be generated, otherwise used as is
+	// if slug is empty it will be generated with the chosen strategy, otherwise used as is
+	// returns [sluggen.ErrEntropyTooLow] if slugs of the strategy would be too easy to guess
 	// if userID is empty it means user isn't authorized so it will be used
-	Create(ctx context.Context, note dtos.CreateNote, userID uuid.UUID) (dtos.NoteSlug, error)
+	// the note should fit into quota of the user, or of the creator's ip if user isn't authorized
+	// if link is set, it's emailed to the recipient once the note is created,
+	// returns [models.ErrNoteLinkSendsExceeded] if the user has emailed too many links recently
+	// notes created without an author get the management token, see [NoteServicer.GetByManagementToken]
+	Create(ctx context.Context, note dtos.CreateNote, userID uuid.UUID) (dtos.CreatedNote, error)
+
+	// CreateWithRecipients creates the note for several recipients at once, every one of them
+	// gets their own link, that is burnt independently, while the content is stored once
+	// and deleted when every link is burnt or expired.
+	// The note is counted as one toward quota of the user, the same way as [NoteServicer.Create] does.
+	// Returns [models.ErrNoteRecipientsInvalid] if there's no recipients or too many of them,
+	// [models.ErrNoteRecipientsWithSlug] if the slug is set,
+	// and [models.ErrNoteRecipientsWithAttachments] if there's any attachments.
+	// Every link of the note created without an author is managed with the same management token.
+	CreateWithRecipients(
+		ctx context.Context,
+		inp dtos.CreateNote,
+		userID uuid.UUID,
+	) (dtos.CreatedNote, error)
+
+	// Reply creates the reply to the note, it's owned by the note's author and burnt on read.
+	// Note can be replied to only once, and only if its author allows it.
+	// Only readers of the note can reply, with the read token given along with its content.
+	// Returns [models.ErrNoteNotFound] if there's no such note, it's expired unread, or the token is invalid,
+	// and [models.ErrNoteAlreadyReplied] if it's been replied to.
+	Reply(ctx context.Context, slug dtos.NoteSlug, inp dtos.CreateNoteReply) error
 
 	// GetBySlugAndRemoveIfNeeded returns note by slug, and removes if if needed.
-	// If note is not found returns [models.ErrNoteNotFound].
+	// Reads and wrong passwords are recorded as the reader's accesses.
+	// If note is not found, or the password doesn't match returns [models.ErrNoteNotFound].
+	// Returns [models.ErrNoteLocked] if too many wrong passwords were given to the note,
+	// and burns it instead, if the author asked for it.
+	// Returns [models.NoteNotAvailableYetError] if the note cannot be read yet.
+	// Notes sent to a user's inbox are read only by the user, for the rest [models.ErrNoteNotFound] is returned.
+	// Notes bound to an email are released only with the one-time code sent by [NoteServicer.RequestCode],
+	// otherwise [models.ErrNoteCodeRequired] or [models.ErrNoteCodeInvalid] is returned,
+	// and [models.ErrNoteCodeAttemptsExceeded] after too many wrong codes.
+	// Notes that require approval are released once per access request approved by the author,
+	// and only with the request's token, otherwise [models.ErrNoteApprovalRequired] is returned.
 	GetBySlugAndRemoveIfNeeded(
 		ctx context.Context,
 		input GetNoteBySlugInput,
 	) (dtos.GetNote, error)
 
-	// GetNoteMetadataBySlug returns note metadata by slug.
-	// If note is not found returns [models.ErrNoteNotFound].
-	GetNoteMetadataBySlug(ctx context.Context, slug dtos.NoteSlug) (dtos.NoteMetadata, error)
-
-	// GetAllByAuthorID returns all notes by author id.
-	GetAllByAuthorID(ctx context.Context, authorID uuid.UUID) ([]dtos.NoteDetailed, error)
+	// GetInbox returns notes sent to the user's inbox, that are neither read nor expired, newest first.
+	// Senders see the notes as delivered once they're returned.
+	// The notes are read with [NoteServicer.GetBySlugAndRemoveIfNeeded] as the user.
+	GetInbox(ctx context.Context, userID uuid.UUID) ([]dtos.InboxNote, error)
+
+	// RequestCode emails the one-time code, the note is released with, to the email the note is bound to.
+	// The previous code of the note cannot be used after that.
+	// Returns [models.ErrNoteNotFound] if note is not found, is read, or isn't bound,
+	// and [models.ErrNoteCodeAttemptsExceeded] if too many wrong codes were given to it.
+	RequestCode(ctx context.Context, slug dtos.NoteSlug) error
+
+	// RequestAccess requests access to the note that requires approval, and notifies its author.
+	// The returned token is given to [NoteServicer.GetBySlugAndRemoveIfNeeded], once the author approves the request.
+	// Returns [models.ErrNoteNotFound] if note is not found, or is read,
+	// [models.ErrNoteApprovalNotRequired] if the note doesn't require approval,
+	// and [models.ErrNoteAccessRequestReasonInvalid] if the reason is empty or too long.
+	RequestAccess(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		reason string,
+		reader Reader,
+	) (dtos.CreatedNoteAccessRequest, error)
 
-	// GetAllReadByAuthorID returns all notes that ARE READ and authored by author id.
-	GetAllReadByAuthorID(ctx context.Context, authorID uuid.UUID) ([]dtos.NoteDetailed, error)
+	// GetNoteMetadataBySlug returns note metadata by slug, and records the reader's access.
+	// If note is not found, or it was sent to an inbox that isn't userID's, returns [models.ErrNoteNotFound].
+	GetNoteMetadataBySlug(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		userID uuid.UUID,
+		reader Reader,
+	) (dtos.NoteMetadata, error)
 
-	// GetAllUnreadByAuthorID returns all notes that ARE UNREAD and authored by author id.
-	GetAllUnreadByAuthorID(ctx context.Context, authorID uuid.UUID) ([]dtos.NoteDetailed, error)
+	// ListByAuthorID returns a page of notes by author id, that match the query.
+	// Returns [models.ErrNoteListCursorInvalid] if the cursor is not returned by the previous page
+	// of the same sorting, or other [models.NoteListQuery.Validate] errors if the query is invalid.
+	ListByAuthorID(ctx context.Context, authorID uuid.UUID, inp dtos.ListNotes) (dtos.NotesPage, error)
 
-	// UpdateExpirationTimeSettings updates expiresAt and keepBeforeExpiration.
+	// UpdateExpirationTimeSettings updates expiresAt, keepBeforeExpiration, and availableFrom.
 	// If notes is not found returns [models.ErrNoteNotFound].
+	// Returns [models.ErrNoteAvailabilityAfterExpiration] if the note would become available after it expires,
+	// and [models.ErrNoteSwitchWithAvailableFrom] if availableFrom is changed on a note with a dead man's switch.
 	UpdateExpirationTimeSettings(
 		ctx context.Context,
 		patchData dtos.PatchNote,
@@ -51,27 +128,225 @@ type NoteServicer interface {
 		userID uuid.UUID,
 	) error
 
-	// UpdatePassword sets or updates notes password.
+	// UpdateNotifyAuthor sets whether the author is emailed when the note is read or expires unread.
+	// If notes is not found returns [models.ErrNoteNotFound].
+	UpdateNotifyAuthor(ctx context.Context, slug dtos.NoteSlug, notify bool, userID uuid.UUID) error
+
+	// UpdateContent replaces content of the note, that nobody has viewed yet.
+	// The version should be the current version of the note, the new one is returned.
+	// If notes is not found returns [models.ErrNoteNotFound].
+	// Returns [models.ErrNoteVersionRequired] if the version is not provided,
+	// [models.ErrNoteVersionMismatch] if the note has been changed since the version,
+	// [models.ErrNoteCannotBeEdited] if it has been viewed,
+	// and [models.ErrNoteRecipientsCannotBeEdited] if it's one of notes sent to several recipients.
+	UpdateContent(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		content string,
+		version int,
+		userID uuid.UUID,
+	) (int, error)
+
+	// UpdatePassword sets or updates notes password, the note is unlocked if it was.
 	// If notes is not found returns [models.ErrNoteNotFound].
 	UpdatePassword(ctx context.Context, slug dtos.NoteSlug, passwd string, userID uuid.UUID) error
 
-	// DeleteBySlug deletes note by slug
+	// DeleteBySlug deletes note by slug, and its attachments.
 	DeleteBySlug(ctx context.Context, slug dtos.NoteSlug, userID uuid.UUID) error
+
+	// BulkUpdate applies the action to the author's notes, at once.
+	// Returns the result for every selected note, and for every slug that doesn't match any note.
+	// Returns [models.NoteBulkQuery.Validate] errors if the input is invalid,
+	// and [ErrNotePasswordNotProvided] if the password is not provided for the set_password action.
+	BulkUpdate(ctx context.Context, authorID uuid.UUID, inp dtos.BulkNotes) (dtos.BulkNotesResult, error)
+
+	// BurnAllUnread burns every unread note of the author, as if they were read.
+	BurnAllUnread(ctx context.Context, authorID uuid.UUID) (dtos.BulkNotesResult, error)
+
+	// GetAttachment returns note's attachment, and its content, that should be closed by the caller.
+	// Attachments are downloaded with the read token, that's given to the reader along with the note's content.
+	// Attachments of burnt notes can be downloaded only once, within [AttachmentsConfig.DownloadWindow].
+	// Returns [models.ErrNoteAttachmentNotFound] if the attachment is not found, or cannot be downloaded.
+	GetAttachment(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		id uuid.UUID,
+		readToken string,
+	) (dtos.NoteAttachment, io.ReadCloser, error)
+
+	// CheckIn checks the author in, so the note's dead man's switch is released only after another interval.
+	// Returns the time the switch is released at, unless the author checks in again.
+	// Returns [models.ErrNoteSwitchNotFound] if the note has no switch, [models.ErrNoteSwitchReleased] if it's
+	// already released, and [models.ErrNoteAvailabilityAfterExpiration] if the note would expire before then.
+	CheckIn(ctx context.Context, slug dtos.NoteSlug, userID uuid.UUID) (time.Time, error)
+
+	// GetByManagementToken returns the note created without an author, e.g. to check whether it's been read.
+	// Returns [models.ErrNoteNotFound] if note is not found, or the token doesn't match.
+	GetByManagementToken(ctx context.Context, slug dtos.NoteSlug, token string) (dtos.ManagedNote, error)
+
+	// UpdateExpirationTimeSettingsByManagementToken is [NoteServicer.UpdateExpirationTimeSettings]
+	// for notes created without an author.
+	// Returns [models.ErrNoteNotFound] if note is not found, or the token doesn't match.
+	UpdateExpirationTimeSettingsByManagementToken(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		token string,
+		patchData dtos.PatchNote,
+	) error
+
+	// DeleteByManagementToken deletes the note created without an author.
+	// Returns [models.ErrNoteNotFound] if note is not found, or the token doesn't match.
+	DeleteByManagementToken(ctx context.Context, slug dtos.NoteSlug, token string) error
+
+	// ClaimNotes makes the user author of notes created without an author, managed with any of the tokens,
+	// the tokens cannot be used after that. Tokens that don't match any note are ignored.
+	// Returns slugs of the claimed notes, and [models.ErrNoteClaimTokensInvalid] if there's no tokens,
+	// or too many of them.
+	ClaimNotes(ctx context.Context, userID uuid.UUID, tokens []string) ([]dtos.NoteSlug, error)
+
+	// GetLinkSends returns emails with the link to the author's note, sent when it was created.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	GetLinkSends(ctx context.Context, slug dtos.NoteSlug, userID uuid.UUID) ([]models.NoteLinkSend, error)
+
+	// GetAccessEvents returns up to [MaxAccessEvents] latest accesses to the author's note, newest first.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	GetAccessEvents(ctx context.Context, slug dtos.NoteSlug, userID uuid.UUID) ([]models.NoteAccessEvent, error)
+
+	// GetAccessRequests returns up to [MaxAccessRequests] latest access requests to the author's note, newest first.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	GetAccessRequests(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		userID uuid.UUID,
+	) ([]models.NoteAccessRequest, error)
+
+	// DecideAccessRequest approves, or denies, the access request to the author's note.
+	// The decision is recorded as the requester's access.
+	// Returns [models.ErrNoteNotFound] if note is not found, [models.ErrNoteAccessRequestNotFound]
+	// if the request is not found, and [models.ErrNoteAccessRequestNotPending] if it's decided or expired.
+	DecideAccessRequest(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		userID uuid.UUID,
+		requestID uuid.UUID,
+		approve bool,
+	) error
+
+	// RemindSwitches reminds authors to check in, if their notes are released within remindBefore.
+	// Every author is reminded once between check-ins.
+	// Returns number of sent reminders.
+	RemindSwitches(ctx context.Context, remindBefore time.Duration) (int64, error)
+
+	// ReleaseSwitches sends links to notes, which authors have missed the check-in, to their recipients.
+	// Returns number of released notes.
+	ReleaseSwitches(ctx context.Context) (int64, error)
+
+	// PurgeExpired deletes content of all expired notes, their metadata is kept.
+	// Authors who asked for it are notified about notes that expired unread.
+	// Returns number of purged notes.
+	PurgeExpired(ctx context.Context) (int64, error)
+
+	// DeleteStale deletes notes that were read or expired more than retention ago.
+	// Returns number of deleted notes.
+	DeleteStale(ctx context.Context, retention time.Duration) (int64, error)
+
+	// DeleteOldAccessEvents deletes accesses to notes recorded more than retention ago.
+	// Returns number of deleted accesses.
+	DeleteOldAccessEvents(ctx context.Context, retention time.Duration) (int64, error)
+
+	// PurgeAttachments deletes attachments of deleted and expired notes, and of notes that were burnt
+	// more than [AttachmentsConfig.DownloadWindow] ago.
+	// Returns number of deleted attachments.
+	PurgeAttachments(ctx context.Context) (int64, error)
 }
 
 var _ NoteServicer = (*NoteSrv)(nil)
 
 type NoteSrv struct {
-	noterepo noterepo.NoteStorer
-	hasher   hasher.Hasher
-	cache    notecache.NoteCacher
+	noterepo       noterepo.NoteStorer
+	attachmentrepo attachmentrepo.AttachmentStorer
+	blobs          blob.Storer
+	blobEnc        envelope.StreamEncryptor
+	hasher         hasher.Hasher
+	slugs          *sluggen.Policy
+	cache          notecache.NoteCacher
+	attempts       noteattempts.NoteAttempter
+	codes          notecodes.NoteCoder
+	reads          notereads.NoteReader
+	linkSends      notelinksends.NoteLinkSender
+	mailermq       mailermq.Mailer
+	webhooks       webhooksrv.Emitter
+	quotas         quotasrv.QuotaServicer
+	cfg            Config
 }
 
-func New(noterepo noterepo.NoteStorer, hasher hasher.Hasher, cache notecache.NoteCacher) *NoteSrv {
+// Deps are dependencies of [NoteSrv], besides the ones every note is stored and read with.
+type Deps struct {
+	// AttachmentRepo, and Blobs store note attachments, their content is encrypted at rest with BlobEnc.
+	AttachmentRepo attachmentrepo.AttachmentStorer
+	Blobs          blob.Storer
+	BlobEnc        envelope.StreamEncryptor
+
+	// Slugs is the policy slugs of notes are generated with.
+	Slugs *sluggen.Policy
+
+	// Attempts count wrong passwords, Codes keep one-time codes of bound notes,
+	// Reads keep tokens issued on read, and LinkSends count links emailed by users.
+	Attempts  noteattempts.NoteAttempter
+	Codes     notecodes.NoteCoder
+	Reads     notereads.NoteReader
+	LinkSends notelinksends.NoteLinkSender
+
+	// Mailer emails notices, and links to notes, Webhooks get events of notes' life cycle.
+	Mailer   mailermq.Mailer
+	Webhooks webhooksrv.Emitter
+
+	// Quotas are the quotas notes are created within.
+	Quotas quotasrv.QuotaServicer
+}
+
+type Config struct {
+	Attachments AttachmentsConfig
+
+	// MaxPasswordAttempts is how many wrong passwords lock a note, zero means unlimited.
+	MaxPasswordAttempts int
+
+	// MaxCodeAttempts is how many wrong one-time codes lock a bound note, zero means unlimited.
+	MaxCodeAttempts int
+
+	// MaxLinkSendsPerHour is how many links to notes a user can email per hour, zero means unlimited.
+	MaxLinkSendsPerHour int
+
+	// AccessRequestTTL is how long access requests wait for the author's decision,
+	// and how long approved ones can be used.
+	AccessRequestTTL time.Duration
+}
+
+// New creates a [NoteSrv], notes are stored in noterepo, their passwords are hashed with hasher,
+// and read ones are cached in cache.
+func New(
+	noterepo noterepo.NoteStorer,
+	hasher hasher.Hasher,
+	cache notecache.NoteCacher,
+	deps Deps,
+	cfg Config,
+) *NoteSrv {
 	return &NoteSrv{
-		noterepo: noterepo,
-		hasher:   hasher,
-		cache:    cache,
+		noterepo:       noterepo,
+		attachmentrepo: deps.AttachmentRepo,
+		blobs:          deps.Blobs,
+		blobEnc:        deps.BlobEnc,
+		hasher:         hasher,
+		slugs:          deps.Slugs,
+		cache:          cache,
+		attempts:       deps.Attempts,
+		codes:          deps.Codes,
+		reads:          deps.Reads,
+		linkSends:      deps.LinkSends,
+		mailermq:       deps.Mailer,
+		webhooks:       deps.Webhooks,
+		quotas:         deps.Quotas,
+		cfg:            cfg,
 	}
 }
 
@@ -79,22 +354,134 @@ func (n *NoteSrv) Create(
 	ctx context.Context,
 	inp dtos.CreateNote,
 	userID uuid.UUID,
-) (dtos.NoteSlug, error) {
-	slog.DebugContext(ctx, "creating", "inp", inp)
-
+) (dtos.CreatedNote, error) {
+	// the input isn't logged as is, it has the note's content and password
+	slog.DebugContext(ctx, "creating",
+		"slug", inp.Slug,
+		"expires_at", inp.ExpiresAt,
+		"user_id", userID)
+
+	// the generator is kept, so the slug is regenerated if it collides with an existing one
+	var slugGen sluggen.Generator
 	if inp.Slug == "" {
-		inp.Slug = uuid.Must(uuid.NewV4()).String()
+		var err error
+		if slugGen, err = n.slugs.Generator(sluggen.Strategy(inp.SlugStrategy), inp.SlugLength); err != nil {
+			return dtos.CreatedNote{}, err
+		}
+
+		if inp.Slug, err = slugGen.Generate(); err != nil {
+			return dtos.CreatedNote{}, err
+		}
+	}
+
+	// the note isn't available until its dead man's switch is released,
+	// and every recipient can read it, unless told otherwise
+	var noteSwitch *models.NoteSwitch
+	if inp.Switch != nil {
+		sw, err := newNoteSwitch(inp, userID)
+		if err != nil {
+			return dtos.CreatedNote{}, err
+		}
+
+		inp.AvailableFrom = sw.ReleaseAt()
+		if inp.MaxViews == 0 && !inp.KeepBeforeExpiration {
+			inp.MaxViews = len(sw.Recipients)
+		}
+		noteSwitch = &sw
+	}
+
+	if inp.Link != nil {
+		if err := n.checkNoteLink(ctx, *inp.Link, userID); err != nil {
+			return dtos.CreatedNote{}, err
+		}
+	}
+
+	var inboxUserID uuid.UUID
+	if inp.InboxEmail != "" {
+		var err error
+		if inboxUserID, err = n.getInboxUserID(ctx, inp, userID); err != nil {
+			return dtos.CreatedNote{}, err
+		}
+	}
+
+	note, err := n.newNote(inp, userID)
+	if err != nil {
+		return dtos.CreatedNote{}, err
+	}
+	note.InboxUserID = inboxUserID
+
+	// only hash of the token is stored, so it's returned only once
+	var managementToken, managementTokenHash string
+	if userID.IsNil() {
+		if managementToken, err = generateManagementToken(); err != nil {
+			return dtos.CreatedNote{}, err
+		}
+		managementTokenHash = hashManagementToken(managementToken)
+	}
+
+	reservation, err := n.quotas.Reserve(ctx, userID, inp.CreatorIP, note)
+	if err != nil {
+		return dtos.CreatedNote{}, err
+	}
+
+	if inp.Link != nil {
+		if err := n.reserveLinkSend(ctx, userID); err != nil {
+			n.quotas.Release(ctx, reservation)
+			return dtos.CreatedNote{}, err
+		}
+	}
+
+	attachments, err := n.storeAttachments(ctx, inp.Attachments)
+	if err != nil {
+		n.quotas.Release(ctx, reservation)
+		n.releaseLinkSend(ctx, inp.Link, userID)
+		return dtos.CreatedNote{}, err
+	}
+
+	if err := n.createNote(ctx, &note, slugGen, func(ctx context.Context, note models.Note) error {
+		return n.noterepo.Create(ctx, noterepo.NewNote{
+			Note:                note,
+			Attachments:         attachments,
+			AuthorID:            userID,
+			AnonymousIPHash:     n.quotas.HashIP(inp.CreatorIP),
+			Switch:              noteSwitch,
+			ManagementTokenHash: managementTokenHash,
+			MaxActiveNotes:      reservation.MaxActiveNotes,
+		})
+	}); err != nil {
+		n.quotas.Release(ctx, reservation)
+		n.releaseLinkSend(ctx, inp.Link, userID)
+		n.deleteAttachmentBlobs(ctx, attachments)
+		return dtos.CreatedNote{}, err
+	}
+
+	if !userID.IsNil() {
+		n.emit(ctx, userID, models.WebhookEventNoteCreated, note.Slug)
+	}
+
+	// the link is emailed only once the note is created with everything it's linked to
+	if inp.Link != nil {
+		n.sendNoteLink(ctx, note.Slug, userID, *inp.Link)
 	}
 
+	return dtos.CreatedNote{
+		Slug:            note.Slug,
+		Recipients:      nil,
+		ManagementToken: managementToken,
+	}, nil
+}
+
+// newNote returns the note that's going to be created, its password is hashed.
+func (n *NoteSrv) newNote(inp dtos.CreateNote, userID uuid.UUID) (models.Note, error) {
 	if inp.Password != "" {
 		hashedPassword, err := n.hasher.Hash(inp.Password)
 		if err != nil {
-			return "", err
+			return models.Note{}, err
 		}
 		inp.Password = hashedPassword
 	}
 
-	//nolint:exhaustruct // ID - cannot be predicted, and ReadAt will be set on read
+	//nolint:exhaustruct // ID - cannot be predicted, ReadAt will be set on read, and ViewsLeft is set below
 	note := models.Note{
 		Content:              inp.Content,
 		Slug:                 inp.Slug,
@@ -102,22 +489,70 @@ func (n *NoteSrv) Create(
 		KeepBeforeExpiration: inp.KeepBeforeExpiration,
 		CreatedAt:            inp.CreatedAt,
 		ExpiresAt:            inp.ExpiresAt,
+		AvailableFrom:        inp.AvailableFrom,
+		EncryptionScheme:     inp.EncryptionScheme,
+		EncryptionVersion:    inp.EncryptionVersion,
+		MaxViews:             inp.MaxViews,
+		NotifyAuthor:         inp.NotifyAuthor,
+		BurnOnLockout:        inp.BurnOnLockout,
+		AllowReply:           inp.AllowReply,
+		BoundEmail:           inp.BoundEmail,
+		RequiresApproval:     inp.RequiresApproval,
 	}
 	if err := note.Validate(); err != nil {
-		return "", err
+		return models.Note{}, err
 	}
 
-	if err := n.noterepo.Create(ctx, note); err != nil {
-		return "", err
+	if note.NotifyAuthor && userID.IsNil() {
+		return models.Note{}, models.ErrNoteNotifyAuthorWithoutAuthor
 	}
 
-	if !userID.IsNil() {
-		if err := n.noterepo.SetAuthorIDBySlug(ctx, inp.Slug, userID); err != nil {
-			return "", err
+	if note.AllowReply && userID.IsNil() {
+		return models.Note{}, models.ErrNoteReplyWithoutAuthor
+	}
+
+	if note.RequiresApproval {
+		if err := checkNoteApproval(inp, userID); err != nil {
+			return models.Note{}, err
 		}
 	}
 
-	return inp.Slug, nil
+	// notes that are kept before expiration aren't burnt on read, so their views aren't counted,
+	// the rest are burnt on the first read, unless told otherwise
+	switch {
+	case note.KeepBeforeExpiration:
+		note.MaxViews = 0
+	case note.MaxViews == 0:
+		note.MaxViews = 1
+	}
+	note.ViewsLeft = note.MaxViews
+
+	return note, nil
+}
+
+// createNote creates the note with create, if its slug is generated by slugGen,
+// it's regenerated when it collides with an existing one, up to [maxSlugAttempts] times.
+func (n *NoteSrv) createNote(
+	ctx context.Context,
+	note *models.Note,
+	slugGen sluggen.Generator,
+	create func(context.Context, models.Note) error,
+) error {
+	err := create(ctx, *note)
+	for attempt := 1; slugGen != nil && attempt < maxSlugAttempts; attempt++ {
+		if !errors.Is(err, models.ErrNoteSlugIsAlreadyInUse) {
+			return err
+		}
+
+		slog.WarnContext(ctx, "generated slug collided, regenerating", "attempt", attempt)
+		if note.Slug, err = slugGen.Generate(); err != nil {
+			return err
+		}
+
+		err = create(ctx, *note)
+	}
+
+	return err
 }
 
 func (n *NoteSrv) GetBySlugAndRemoveIfNeeded(
@@ -129,69 +564,102 @@ func (n *NoteSrv) GetBySlugAndRemoveIfNeeded(
 		return dtos.GetNote{}, err
 	}
 
+	if !note.CanBeReadBy(inp.UserID) {
+		return dtos.GetNote{}, models.ErrNoteNotFound
+	}
+
 	if note.IsExpired() {
 		return dtos.GetNote{}, models.ErrNoteExpired
 	}
 
-	respNote := dtos.GetNote{
-		Content:              note.Content,
-		KeepBeforeExpiration: note.KeepBeforeExpiration,
-		ReadAt:               note.ReadAt,
-		CreatedAt:            note.CreatedAt,
-		ExpiresAt:            note.ExpiresAt,
+	if !note.IsAvailable() {
+		return dtos.GetNote{}, models.NoteNotAvailableYetError{AvailableFrom: note.AvailableFrom}
+	}
+
+	// attachments are not returned for notes that are already read
+	if note.IsRead() {
+		return n.mapNoteModelToGetDto(note), nil
+	}
+
+	if note.IsBound() {
+		if err := n.checkNoteCode(ctx, inp); err != nil {
+			return dtos.GetNote{}, err
+		}
+	}
+
+	if note.RequiresApproval && inp.AccessToken == "" {
+		return dtos.GetNote{}, models.ErrNoteApprovalRequired
 	}
 
 	// since not every note should be burn before expiration
 	// we return early if it's not
 	if note.ShouldPreserveOnRead() {
-		return respNote, nil
+		if note.RequiresApproval {
+			if err := n.useAccessRequest(ctx, inp); err != nil {
+				return dtos.GetNote{}, err
+			}
+		}
+
+		n.recordAccess(ctx, inp.Slug, models.NoteAccessRead, inp.Reader)
+		n.markNoteViewed(ctx, inp.Slug)
+		if note.IsBound() {
+			n.deleteNoteCode(ctx, inp.Slug)
+		}
+		return n.mapNoteModelWithAttachmentsToGetDto(ctx, note)
 	}
 
-	return respNote, n.noterepo.RemoveBySlug(ctx, inp.Slug, time.Now())
-}
+	// the access request is used only if the note is consumed, so it's not lost if consuming fails
+	var accessTokenHash string
+	if note.RequiresApproval {
+		accessTokenHash = hashAccessRequestToken(inp.AccessToken)
+	}
 
-func (n *NoteSrv) GetNoteMetadataBySlug(
-	ctx context.Context,
-	slug dtos.NoteSlug,
-) (dtos.NoteMetadata, error) {
-	note, err := n.noterepo.GetMetadataBySlug(ctx, slug)
-	return note, err
-}
+	// the password is already verified, so the note is consumed only if it still has the same one
+	readAt := time.Now()
+	consumed, err := n.noterepo.ConsumeBySlug(ctx, inp.Slug, note.Password, accessTokenHash, readAt)
+	if errors.Is(err, models.ErrNoteNotFound) {
+		// the note has been consumed by concurrent reader since we've fetched it,
+		// so the caller gets the same response as if the note was already read
+		note, err = n.getNote(ctx, inp)
+		if err != nil {
+			return dtos.GetNote{}, err
+		}
 
-func (n *NoteSrv) GetAllByAuthorID(
-	ctx context.Context,
-	authorID uuid.UUID,
-) ([]dtos.NoteDetailed, error) {
-	notes, err := n.noterepo.GetAllByAuthorID(ctx, authorID)
+		return n.mapNoteModelToGetDto(note), nil
+	}
 	if err != nil {
-		return nil, err
+		return dtos.GetNote{}, err
 	}
 
-	return n.mapNoteModelToDto(notes), nil
-}
+	n.recordAccess(ctx, inp.Slug, models.NoteAccessRead, inp.Reader)
 
-func (n *NoteSrv) GetAllReadByAuthorID(
-	ctx context.Context,
-	authorID uuid.UUID,
-) ([]dtos.NoteDetailed, error) {
-	notes, err := n.noterepo.GetAllReadByAuthorID(ctx, authorID)
-	if err != nil {
-		return nil, err
+	// the code is used only once the note is read, so it's not lost if reading fails
+	if note.IsBound() {
+		n.deleteNoteCode(ctx, inp.Slug)
+	}
+
+	if consumed.ViewsLeft == 0 {
+		n.notifyAuthorNoteRead(ctx, inp.Slug, readAt)
+		n.emitNoteRead(ctx, inp.Slug)
 	}
 
-	return n.mapNoteModelToDto(notes), nil
+	return n.mapNoteModelWithAttachmentsToGetDto(ctx, consumed)
 }
 
-func (n *NoteSrv) GetAllUnreadByAuthorID(
+func (n *NoteSrv) GetNoteMetadataBySlug(
 	ctx context.Context,
-	authorID uuid.UUID,
-) ([]dtos.NoteDetailed, error) {
-	notes, err := n.noterepo.GetAllUnreadByAuthorID(ctx, authorID)
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+	reader Reader,
+) (dtos.NoteMetadata, error) {
+	note, err := n.noterepo.GetMetadataBySlug(ctx, slug, userID)
 	if err != nil {
-		return nil, err
+		return dtos.NoteMetadata{}, err
 	}
 
-	return n.mapNoteModelToDto(notes), nil
+	n.recordAccess(ctx, slug, models.NoteAccessMetadata, reader)
+
+	return note, nil
 }
 
 func (n *NoteSrv) UpdateExpirationTimeSettings(
@@ -200,9 +668,97 @@ func (n *NoteSrv) UpdateExpirationTimeSettings(
 	slug dtos.NoteSlug,
 	userID uuid.UUID,
 ) error {
+	if patchData.AvailableFrom != nil || patchData.ExpiresAt != nil {
+		note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+		if err != nil {
+			return err
+		}
+
+		if patchData.AvailableFrom != nil {
+			if err := n.checkNoteHasNoSwitch(ctx, note.ID); err != nil {
+				return err
+			}
+			note.AvailableFrom = *patchData.AvailableFrom
+		}
+		if patchData.ExpiresAt != nil {
+			note.ExpiresAt = *patchData.ExpiresAt
+			if err := n.quotas.CheckExpiration(ctx, userID, note.ExpiresAt); err != nil {
+				return err
+			}
+		}
+
+		if err := note.ValidateAvailability(); err != nil {
+			return err
+		}
+	}
+
 	return n.noterepo.UpdateExpirationTimeSettingsBySlug(ctx, slug, patchData, userID)
 }
 
+func (n *NoteSrv) UpdateNotifyAuthor(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	notify bool,
+	userID uuid.UUID,
+) error {
+	return n.noterepo.UpdateNotifyAuthorBySlug(ctx, slug, userID, notify)
+}
+
+func (n *NoteSrv) UpdateContent(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	content string,
+	version int,
+	userID uuid.UUID,
+) (int, error) {
+	if version == 0 {
+		return 0, models.ErrNoteVersionRequired
+	}
+
+	note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	if note.IsExpired() {
+		return 0, models.ErrNoteExpired
+	}
+
+	if !note.IsEditable() {
+		return 0, models.ErrNoteCannotBeEdited
+	}
+
+	// the content is shared by all recipients, some of whom could've already read it
+	if note.HasRecipients() {
+		return 0, models.ErrNoteRecipientsCannotBeEdited
+	}
+
+	if note.Version != version {
+		return 0, models.ErrNoteVersionMismatch
+	}
+
+	// the encryption scheme is kept, so the new content should be encrypted the same way
+	note.Content = content
+	if err := note.ValidateContent(); err != nil {
+		return 0, err
+	}
+
+	if err := n.quotas.CheckContent(ctx, userID, content); err != nil {
+		return 0, err
+	}
+
+	newVersion, err := n.noterepo.UpdateContentBySlug(ctx, slug, userID, content, version, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	if err := n.cache.DeleteNote(ctx, slug); err != nil {
+		slog.ErrorContext(ctx, "notecache", "err", err)
+	}
+
+	return newVersion, nil
+}
+
 func (n *NoteSrv) UpdatePassword(
 	ctx context.Context,
 	slug dtos.NoteSlug,
@@ -218,7 +774,13 @@ func (n *NoteSrv) UpdatePassword(
 		return err
 	}
 
-	return n.noterepo.UpdatePasswordBySlug(ctx, slug, userID, hashedPassword)
+	if err := n.noterepo.UpdatePasswordBySlug(ctx, slug, userID, hashedPassword); err != nil {
+		return err
+	}
+
+	n.resetPasswordAttempts(ctx, slug)
+
+	return nil
 }
 
 func (n *NoteSrv) DeleteBySlug(
@@ -226,15 +788,140 @@ func (n *NoteSrv) DeleteBySlug(
 	slug dtos.NoteSlug,
 	authorID uuid.UUID,
 ) error {
-	return n.noterepo.DeleteNoteBySlug(ctx, slug, authorID)
+	// make sure the note belongs to the author before touching its attachments
+	if _, err := n.noterepo.GetByAuthorIDAndSlug(ctx, authorID, slug); err != nil {
+		return err
+	}
+
+	attachments, err := n.attachmentrepo.GetAllByNoteSlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	if err := n.noterepo.DeleteNoteBySlug(ctx, slug, authorID); err != nil {
+		return err
+	}
+
+	n.emit(ctx, authorID, models.WebhookEventNoteDeleted, slug)
+	n.deleteNoteAttachments(ctx, attachments)
+
+	return nil
+}
+
+// deleteNoteAttachments deletes attachments of the deleted note,
+// they're already detached from it, so if any of them fails to be deleted, it will be deleted by the reaper.
+func (n *NoteSrv) deleteNoteAttachments(ctx context.Context, attachments []models.NoteAttachment) {
+	for _, a := range attachments {
+		if err := n.deleteAttachment(ctx, a.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to delete attachment", "id", a.ID, "err", err)
+		}
+	}
+}
+
+func (n *NoteSrv) PurgeExpired(ctx context.Context) (int64, error) {
+	purged, err := n.noterepo.PurgeExpiredContent(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, note := range purged {
+		if !note.AuthorID.IsNil() {
+			n.emit(ctx, note.AuthorID, models.WebhookEventNoteExpired, note.Slug)
+		}
+
+		if note.AuthorEmail == "" {
+			continue
+		}
+
+		// the content is already purged, so the notice is not retried if it fails
+		if err := n.mailermq.SendNoteExpiredNotice(ctx, mailermq.SendNoteExpiredNoticeRequest{
+			Receiver:  note.AuthorEmail,
+			Slug:      note.Slug,
+			ExpiresAt: note.ExpiresAt,
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to send note expired notice", "slug", note.Slug, "err", err)
+		}
+	}
+
+	return int64(len(purged)), nil
+}
+
+func (n *NoteSrv) DeleteStale(ctx context.Context, retention time.Duration) (int64, error) {
+	return n.noterepo.DeleteStale(ctx, time.Now().Add(-retention))
+}
+
+// notifyAuthorNoteRead sends the read receipt to the note's author, if they asked for it.
+// The note is already read at this point, so failures are only logged.
+func (n *NoteSrv) notifyAuthorNoteRead(ctx context.Context, slug dtos.NoteSlug, readAt time.Time) {
+	email, err := n.noterepo.GetNotifiableAuthorEmailBySlug(ctx, slug)
+	if errors.Is(err, models.ErrNoteNotFound) {
+		return
+	}
+
+	if err == nil {
+		err = n.mailermq.SendNoteReadNotice(ctx, mailermq.SendNoteReadNoticeRequest{
+			Receiver: email,
+			Slug:     slug,
+			ReadAt:   readAt,
+		})
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to send note read notice", "slug", slug, "err", err)
+	}
 }
 
+// markNoteViewed marks the note that's kept before expiration as viewed,
+// its author is notified only about the first view, as if the note was burnt.
+// The note is already read at this point, so failures are only logged.
+func (n *NoteSrv) markNoteViewed(ctx context.Context, slug dtos.NoteSlug) {
+	viewedAt := time.Now()
+	first, err := n.noterepo.MarkViewedBySlug(ctx, slug, viewedAt)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to mark note as viewed", "slug", slug, "err", err)
+		return
+	}
+
+	if first {
+		n.notifyAuthorNoteRead(ctx, slug, viewedAt)
+		n.emitNoteRead(ctx, slug)
+	}
+}
+
+// emitNoteRead emits [models.WebhookEventNoteRead] to the note's author, if it has one.
+func (n *NoteSrv) emitNoteRead(ctx context.Context, slug dtos.NoteSlug) {
+	authorID, err := n.noterepo.GetAuthorIDBySlug(ctx, slug)
+	if errors.Is(err, models.ErrNoteNotFound) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get note's author", "slug", slug, "err", err)
+		return
+	}
+
+	n.emit(ctx, authorID, models.WebhookEventNoteRead, slug)
+}
+
+// emit emits the note's event to the author's webhooks.
+// The event has already happened at this point, so failures are only logged.
+func (n *NoteSrv) emit(
+	ctx context.Context,
+	authorID uuid.UUID,
+	event models.WebhookEvent,
+	slug dtos.NoteSlug,
+) {
+	if err := n.webhooks.Emit(ctx, authorID, event, slug); err != nil {
+		slog.ErrorContext(ctx, "failed to emit webhook event", "event", event, "slug", slug, "err", err)
+	}
+}
+
+// getNote returns note by slug and password(empty if note has no password).
 func (n *NoteSrv) getNote(ctx context.Context, inp GetNoteBySlugInput) (models.Note, error) {
 	if note, err := n.cache.GetNote(ctx, inp.Slug); err == nil {
 		return note, nil
 	}
 
-	note, err := n.getNoteFromDBasedOnInput(ctx, inp)
+	note, err := n.getNoteFromDBasedOnPassword(ctx, inp)
 	if err != nil {
 		return models.Note{}, err
 	}
@@ -248,32 +935,78 @@ func (n *NoteSrv) getNote(ctx context.Context, inp GetNoteBySlugInput) (models.N
 	return note, err
 }
 
-func (n *NoteSrv) getNoteFromDBasedOnInput(
+func (n *NoteSrv) getNoteFromDBasedOnPassword(
 	ctx context.Context,
 	inp GetNoteBySlugInput,
 ) (models.Note, error) {
 	if inp.HasPassword() {
-		hashedPassword, err := n.hasher.Hash(inp.Password)
-		if err != nil {
-			return models.Note{}, err
-		}
-
-		return n.noterepo.GetBySlugAndPassword(ctx, inp.Slug, hashedPassword)
+		return n.getNoteByPassword(ctx, inp)
 	}
 	return n.noterepo.GetBySlug(ctx, inp.Slug)
 }
 
+func (n *NoteSrv) mapNoteModelToGetDto(note models.Note) dtos.GetNote {
+	//nolint:exhaustruct // attachments are set only when note's content is returned
+	return dtos.GetNote{
+		Content:              note.Content,
+		KeepBeforeExpiration: note.KeepBeforeExpiration,
+		EncryptionScheme:     note.EncryptionScheme,
+		EncryptionVersion:    note.EncryptionVersion,
+		ReadAt:               note.ReadAt,
+		CreatedAt:            note.CreatedAt,
+		ExpiresAt:            note.ExpiresAt,
+		ReplyAllowed:         note.CanBeRepliedTo(),
+	}
+}
+
+func (n *NoteSrv) mapNoteModelWithAttachmentsToGetDto(
+	ctx context.Context,
+	note models.Note,
+) (dtos.GetNote, error) {
+	attachments, err := n.getAttachments(ctx, note.Slug)
+	if err != nil {
+		return dtos.GetNote{}, err
+	}
+
+	res := n.mapNoteModelToGetDto(note)
+	res.Attachments = attachments
+
+	// attachments are downloaded, and replies are made only by readers of the note
+	if len(attachments) != 0 || note.CanBeRepliedTo() {
+		if res.ReadToken, err = n.issueReadToken(ctx, note.Slug); err != nil {
+			return dtos.GetNote{}, err
+		}
+	}
+
+	return res, nil
+}
+
 func (n *NoteSrv) mapNoteModelToDto(notes []models.Note) []dtos.NoteDetailed {
 	var resNotes []dtos.NoteDetailed
 	for _, note := range notes {
 		resNotes = append(resNotes, dtos.NoteDetailed{
-			Content:              note.Content,
-			Slug:                 note.Slug,
-			KeepBeforeExpiration: note.KeepBeforeExpiration,
-			HasPassword:          note.Password != "",
-			CreatedAt:            note.CreatedAt,
-			ExpiresAt:            note.ExpiresAt,
-			ReadAt:               note.ReadAt,
+			Content:               note.Content,
+			Slug:                  note.Slug,
+			KeepBeforeExpiration:  note.KeepBeforeExpiration,
+			HasPassword:           note.Password != "",
+			EncryptionScheme:      note.EncryptionScheme,
+			EncryptionVersion:     note.EncryptionVersion,
+			MaxViews:              note.MaxViews,
+			Views:                 note.Views(),
+			NotifyAuthor:          note.NotifyAuthor,
+			BurnOnLockout:         note.BurnOnLockout,
+			AllowReply:            note.AllowReply,
+			ReplySlug:             note.ReplySlug,
+			Version:               note.Version,
+			GroupID:               note.GroupID,
+			RecipientLabel:        note.RecipientLabel,
+			CreatedAt:             note.CreatedAt,
+			ExpiresAt:             note.ExpiresAt,
+			AvailableFrom:         note.AvailableFrom,
+			ReadAt:                note.ReadAt,
+			InboxEmail:            note.InboxEmail,
+			DeliveredAt:           note.DeliveredAt,
+			InboxRecipientDeleted: note.InboxRecipientDeleted,
 		})
 	}
 
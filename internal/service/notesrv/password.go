@@ -0,0 +1,92 @@
+package notesrv
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/hasher"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// getNoteByPassword returns the note if the password matches its hash.
+// Wrong passwords are counted, and once there's too many of them the note is locked,
+// or burnt if the author asked for it.
+func (n *NoteSrv) getNoteByPassword(ctx context.Context, inp GetNoteBySlugInput) (models.Note, error) {
+	slug := inp.Slug
+	note, err := n.noterepo.GetBySlugWithPassword(ctx, slug)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	// others cannot lock, or burn, notes sent to a user's inbox
+	if !note.CanBeReadBy(inp.UserID) {
+		return models.Note{}, models.ErrNoteNotFound
+	}
+
+	// passwords aren't checked before the note is available, so they cannot be guessed in the meantime
+	if !note.IsAvailable() {
+		return models.Note{}, models.NoteNotAvailableYetError{AvailableFrom: note.AvailableFrom}
+	}
+
+	// every attempt is counted before the password is compared,
+	// so concurrent attempts cannot get more of them than allowed
+	attempts, err := n.attempts.Increment(ctx, slug)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	if n.isLockedOut(attempts - 1) {
+		return models.Note{}, models.ErrNoteLocked
+	}
+
+	err = n.hasher.Compare(note.Password, inp.Password)
+	if errors.Is(err, hasher.ErrMismatchedHashes) || errors.Is(err, hasher.ErrHashUnrecognized) {
+		n.recordAccess(ctx, slug, models.NoteAccessWrongPassword, inp.Reader)
+		return models.Note{}, n.handleWrongPassword(ctx, note, attempts)
+	}
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	n.resetPasswordAttempts(ctx, slug)
+
+	return note, nil
+}
+
+// handleWrongPassword returns the error the reader gets for the wrong password given to the note,
+// attempts is how many passwords, including this one, have been given to it.
+func (n *NoteSrv) handleWrongPassword(ctx context.Context, note models.Note, attempts int64) error {
+	if !n.isLockedOut(attempts) {
+		return models.ErrNoteNotFound
+	}
+
+	if !note.BurnOnLockout || note.IsRead() {
+		return models.ErrNoteLocked
+	}
+
+	// the note is burnt as if it was read, so it's not locked anymore,
+	// and readers get the same response as for any other read note
+	if err := n.noterepo.RemoveBySlug(ctx, note.Slug, time.Now()); err != nil {
+		return err
+	}
+
+	n.resetPasswordAttempts(ctx, note.Slug)
+
+	return models.ErrNoteNotFound
+}
+
+func (n *NoteSrv) isLockedOut(attempts int64) bool {
+	return n.cfg.MaxPasswordAttempts > 0 &&
+		attempts >= int64(n.cfg.MaxPasswordAttempts)
+}
+
+// resetPasswordAttempts unlocks the note, if it was locked.
+// Failures are only logged, since the note is unlocked once the lockout passes anyway.
+func (n *NoteSrv) resetPasswordAttempts(ctx context.Context, slug dtos.NoteSlug) {
+	if err := n.attempts.Reset(ctx, slug); err != nil {
+		slog.ErrorContext(ctx, "failed to reset note password attempts", "slug", slug, "err", err)
+	}
+}
@@ -0,0 +1,53 @@
+package notesrv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/olexsmir/onasty/internal/dtos"
+)
+
+const (
+	readTokenPrefix = "nrt_"
+	readTokenSize   = 32
+)
+
+// issueReadToken issues the read token to the reader of the note,
+// with it they prove that they've passed all checks the note is read with.
+func (n *NoteSrv) issueReadToken(ctx context.Context, slug dtos.NoteSlug) (string, error) {
+	token := make([]byte, readTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	readToken := readTokenPrefix + base64.RawURLEncoding.EncodeToString(token)
+	if err := n.reads.Set(ctx, hashReadToken(readToken), slug); err != nil {
+		return "", err
+	}
+
+	return readToken, nil
+}
+
+// isReadTokenValid reports whether the read token is issued to a reader of the note, and isn't expired yet.
+func (n *NoteSrv) isReadTokenValid(ctx context.Context, slug dtos.NoteSlug, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	readSlug, err := n.reads.Get(ctx, hashReadToken(token))
+	if err != nil {
+		return false, err
+	}
+
+	return readSlug != "" && readSlug == slug, nil
+}
+
+// hashReadToken hashes the token, so it could be looked up by the hash,
+// the token is random enough not to be salted or stretched.
+func hashReadToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
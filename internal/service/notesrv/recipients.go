@@ -0,0 +1,139 @@
+package notesrv
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/sluggen"
+	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
+)
+
+func (n *NoteSrv) CreateWithRecipients(
+	ctx context.Context,
+	inp dtos.CreateNote,
+	userID uuid.UUID,
+) (dtos.CreatedNote, error) {
+	// the input isn't logged as is, it has the note's content and password
+	slog.DebugContext(ctx, "creating with recipients",
+		"recipients_count", inp.RecipientsCount,
+		"expires_at", inp.ExpiresAt,
+		"user_id", userID)
+
+	if inp.Slug != "" {
+		return dtos.CreatedNote{}, models.ErrNoteRecipientsWithSlug
+	}
+
+	if inp.Switch != nil {
+		return dtos.CreatedNote{}, models.ErrNoteSwitchWithRecipients
+	}
+
+	if inp.Link != nil {
+		return dtos.CreatedNote{}, models.ErrNoteLinkWithRecipients
+	}
+
+	if inp.BoundEmail != "" {
+		return dtos.CreatedNote{}, models.ErrNoteBoundWithRecipients
+	}
+
+	if inp.InboxEmail != "" {
+		return dtos.CreatedNote{}, models.ErrNoteInboxWithRecipients
+	}
+
+	for _, err := range inp.Attachments {
+		if err != nil {
+			return dtos.CreatedNote{}, err
+		}
+		return dtos.CreatedNote{}, models.ErrNoteRecipientsWithAttachments
+	}
+
+	recipients, err := models.NewNoteRecipients(inp.RecipientsCount, inp.RecipientLabels)
+	if err != nil {
+		return dtos.CreatedNote{}, err
+	}
+
+	slugGen, err := n.slugs.Generator(sluggen.Strategy(inp.SlugStrategy), inp.SlugLength)
+	if err != nil {
+		return dtos.CreatedNote{}, err
+	}
+
+	note, err := n.newNote(inp, userID)
+	if err != nil {
+		return dtos.CreatedNote{}, err
+	}
+
+	// only hash of the token is stored, so it's returned only once, every link is managed with it
+	var managementToken, managementTokenHash string
+	if userID.IsNil() {
+		if managementToken, err = generateManagementToken(); err != nil {
+			return dtos.CreatedNote{}, err
+		}
+		managementTokenHash = hashManagementToken(managementToken)
+	}
+
+	reservation, err := n.quotas.Reserve(ctx, userID, inp.CreatorIP, note)
+	if err != nil {
+		return dtos.CreatedNote{}, err
+	}
+
+	//nolint:exhaustruct // notes with recipients have neither attachments, nor a switch
+	if err := n.createNoteWithRecipients(ctx, recipients, slugGen, noterepo.NewNote{
+		Note:                note,
+		AuthorID:            userID,
+		AnonymousIPHash:     n.quotas.HashIP(inp.CreatorIP),
+		ManagementTokenHash: managementTokenHash,
+		MaxActiveNotes:      reservation.MaxActiveNotes,
+	}); err != nil {
+		n.quotas.Release(ctx, reservation)
+		return dtos.CreatedNote{}, err
+	}
+
+	if !userID.IsNil() {
+		for _, r := range recipients {
+			n.emit(ctx, userID, models.WebhookEventNoteCreated, r.Slug)
+		}
+	}
+
+	res := make([]dtos.NoteRecipient, 0, len(recipients))
+	for _, r := range recipients {
+		res = append(res, dtos.NoteRecipient{Label: r.Label, Slug: r.Slug})
+	}
+
+	return dtos.CreatedNote{
+		Slug:            res[0].Slug,
+		Recipients:      res,
+		ManagementToken: managementToken,
+	}, nil
+}
+
+// createNoteWithRecipients generates slugs for every recipient, and creates the note for them,
+// slugs are regenerated if any of them collides with an existing one, up to [maxSlugAttempts] times.
+func (n *NoteSrv) createNoteWithRecipients(
+	ctx context.Context,
+	recipients models.NoteRecipients,
+	slugGen sluggen.Generator,
+	note noterepo.NewNote,
+) error {
+	var err error
+	for attempt := range maxSlugAttempts {
+		if attempt != 0 {
+			slog.WarnContext(ctx, "generated slug collided, regenerating", "attempt", attempt)
+		}
+
+		for i := range recipients {
+			if recipients[i].Slug, err = slugGen.Generate(); err != nil {
+				return err
+			}
+		}
+
+		err = n.noterepo.CreateWithRecipients(ctx, note, recipients)
+		if !errors.Is(err, models.ErrNoteSlugIsAlreadyInUse) {
+			return err
+		}
+	}
+
+	return err
+}
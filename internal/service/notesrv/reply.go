@@ -0,0 +1,78 @@
+package notesrv
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+func (n *NoteSrv) Reply(ctx context.Context, slug dtos.NoteSlug, inp dtos.CreateNoteReply) error {
+	// the token is issued only once the note is read, so the note's checks cannot be skipped
+	ok, err := n.isReadTokenValid(ctx, slug, inp.ReadToken)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return models.ErrNoteNotFound
+	}
+
+	authorID, err := n.noterepo.GetAuthorIDBySlug(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	// the reply is owned by the author, so it should fit into their quota,
+	// but it isn't counted toward the daily limit, since every note can be replied to only once
+	if err := n.quotas.CheckContent(ctx, authorID, inp.Content); err != nil {
+		return err
+	}
+
+	if inp.Password != "" {
+		if inp.Password, err = n.hasher.Hash(inp.Password); err != nil {
+			return err
+		}
+	}
+
+	slugGen, err := n.slugs.Generator("", 0)
+	if err != nil {
+		return err
+	}
+
+	replySlug, err := slugGen.Generate()
+	if err != nil {
+		return err
+	}
+
+	//nolint:exhaustruct // replies are burnt on the first read, and cannot be replied to themselves
+	reply := models.Note{
+		Content:           inp.Content,
+		Slug:              replySlug,
+		Password:          inp.Password,
+		CreatedAt:         inp.CreatedAt,
+		EncryptionScheme:  inp.EncryptionScheme,
+		EncryptionVersion: inp.EncryptionVersion,
+		MaxViews:          1,
+		ViewsLeft:         1,
+	}
+	if err := reply.Validate(); err != nil {
+		return err
+	}
+
+	if err := n.createNote(ctx, &reply, slugGen, func(ctx context.Context, reply models.Note) error {
+		return n.noterepo.CreateReply(ctx, slug, reply, time.Now())
+	}); err != nil {
+		return err
+	}
+
+	// read notes are cached, and the cached one would still allow replies
+	if err := n.cache.DeleteNote(ctx, slug); err != nil {
+		slog.ErrorContext(ctx, "notecache", "err", err)
+	}
+
+	n.emit(ctx, authorID, models.WebhookEventNoteCreated, reply.Slug)
+
+	return nil
+}
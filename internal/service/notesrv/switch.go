@@ -0,0 +1,142 @@
+package notesrv
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/events/mailermq"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// newNoteSwitch returns dead man's switch of the note that is being created,
+// the note isn't available until the switch is released.
+func newNoteSwitch(inp dtos.CreateNote, userID uuid.UUID) (models.NoteSwitch, error) {
+	if userID.IsNil() {
+		return models.NoteSwitch{}, models.ErrNoteSwitchWithoutAuthor
+	}
+
+	if !inp.AvailableFrom.IsZero() {
+		return models.NoteSwitch{}, models.ErrNoteSwitchWithAvailableFrom
+	}
+
+	sw := models.NoteSwitch{ //nolint:exhaustruct // note id is set once it's created
+		CheckInInterval: inp.Switch.CheckInInterval,
+		Recipients:      inp.Switch.Recipients,
+		LastCheckInAt:   inp.CreatedAt,
+	}
+
+	return sw, sw.Validate()
+}
+
+// checkNoteHasNoSwitch returns [models.ErrNoteSwitchWithAvailableFrom] if the note has a dead man's switch,
+// availability of such note is changed only by check-ins, otherwise the switch could be bypassed.
+func (n *NoteSrv) checkNoteHasNoSwitch(ctx context.Context, noteID uuid.UUID) error {
+	_, err := n.noterepo.GetSwitchByNoteID(ctx, noteID)
+	if errors.Is(err, models.ErrNoteSwitchNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return models.ErrNoteSwitchWithAvailableFrom
+}
+
+func (n *NoteSrv) CheckIn(ctx context.Context, slug dtos.NoteSlug, userID uuid.UUID) (time.Time, error) {
+	note, err := n.noterepo.GetByAuthorIDAndSlug(ctx, userID, slug)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	sw, err := n.noterepo.GetSwitchByNoteID(ctx, note.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if sw.IsReleased() {
+		return time.Time{}, models.ErrNoteSwitchReleased
+	}
+
+	// the note should still be available before it expires, after it's postponed
+	now := time.Now()
+	sw.LastCheckInAt = now
+	note.AvailableFrom = sw.ReleaseAt()
+	if err := note.ValidateAvailability(); err != nil {
+		return time.Time{}, err
+	}
+
+	return n.noterepo.CheckInSwitch(ctx, note.ID, now)
+}
+
+func (n *NoteSrv) RemindSwitches(ctx context.Context, remindBefore time.Duration) (int64, error) {
+	reminders, err := n.noterepo.ClaimSwitchReminders(ctx, time.Now(), remindBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	// the reminders are already claimed, so they're not retried if sending fails
+	for _, r := range reminders {
+		if err := n.mailermq.SendNoteSwitchReminder(ctx, mailermq.SendNoteSwitchReminderRequest{
+			Receiver:  r.AuthorEmail,
+			Slug:      r.Slug,
+			ReleaseAt: r.ReleaseAt,
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to send note switch reminder", "slug", r.Slug, "err", err)
+		}
+	}
+
+	return int64(len(reminders)), nil
+}
+
+func (n *NoteSrv) ReleaseSwitches(ctx context.Context) (int64, error) {
+	now := time.Now()
+	due, err := n.noterepo.GetDueSwitches(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var released int64
+	for _, sw := range due {
+		if !n.sendNoteReleased(ctx, sw) {
+			continue
+		}
+
+		if err := n.noterepo.ReleaseSwitch(ctx, sw.NoteID, now); err != nil {
+			return released, err
+		}
+
+		n.emit(ctx, sw.AuthorID, models.WebhookEventNoteReleased, sw.Slug)
+		released++
+	}
+
+	return released, nil
+}
+
+// sendNoteReleased sends the note's link to the switch's recipients it hasn't been sent to yet.
+// Returns false if it failed for any of them, so the switch isn't released,
+// and it's retried on the next run, only for those it failed for.
+func (n *NoteSrv) sendNoteReleased(ctx context.Context, sw dtos.DueNoteSwitch) bool {
+	sent := true
+	for _, recipient := range sw.Recipients {
+		if err := n.mailermq.SendNoteReleased(ctx, mailermq.SendNoteReleasedRequest{
+			Receiver:    recipient,
+			Slug:        sw.Slug,
+			AuthorEmail: sw.AuthorEmail,
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to send released note", "slug", sw.Slug, "err", err)
+			sent = false
+			continue
+		}
+
+		if err := n.noterepo.MarkSwitchSentTo(ctx, sw.NoteID, recipient); err != nil {
+			slog.ErrorContext(ctx, "failed to record released note send", "slug", sw.Slug, "err", err)
+			sent = false
+		}
+	}
+
+	return sent
+}
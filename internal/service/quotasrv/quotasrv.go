@@ -0,0 +1,240 @@
+package quotasrv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
+	"github.com/olexsmir/onasty/internal/store/psql/quotarepo"
+	"github.com/olexsmir/onasty/internal/store/rdb/notecounter"
+)
+
+type QuotaServicer interface {
+	// Reserve checks whether the note can be created within quota of its creator,
+	// the user, or the ip if userID is empty, and reserves a note of the daily limit for it.
+	// The reservation should be released with [QuotaServicer.Release] if the note isn't created.
+	//
+	// Returns [models.ErrQuotaContentTooLarge], [models.ErrQuotaExpirationTooFar],
+	// [models.ErrQuotaExpirationIsRequired], [models.ErrQuotaActiveNotesExceeded],
+	// or [models.ErrQuotaDailyNotesExceeded] if the note doesn't fit into the quota.
+	Reserve(ctx context.Context, userID uuid.UUID, ip string, note models.Note) (Reservation, error)
+
+	// CheckContent checks whether the content of the user's note fits into their quota,
+	// e.g. when the content is edited.
+	// Returns [models.ErrQuotaContentTooLarge] if it doesn't.
+	CheckContent(ctx context.Context, userID uuid.UUID, content string) error
+
+	// CheckExpiration checks whether the expiration time of the user's note fits into their quota,
+	// e.g. when it's changed, userID is empty for notes without an author.
+	// Returns [models.ErrQuotaExpirationTooFar], or [models.ErrQuotaExpirationIsRequired] if it doesn't.
+	CheckExpiration(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error
+
+	// Release gives back the note reserved by [QuotaServicer.Reserve],
+	// to the day it was reserved on, even if the day has changed since.
+	Release(ctx context.Context, reservation Reservation)
+
+	// HashIP returns hash of the ip, notes without an author are counted toward its quota by,
+	// e.g. when they're created along with it, see [noterepo.NewNote.AnonymousIPHash].
+	HashIP(ip string) string
+
+	// GetUsage returns the user's quota, and how much of it is used.
+	GetUsage(ctx context.Context, userID uuid.UUID) (dtos.QuotaUsage, error)
+}
+
+var _ QuotaServicer = (*QuotaSrv)(nil)
+
+// Reservation is a note reserved of the daily limit by [QuotaServicer.Reserve].
+type Reservation struct {
+	// MaxActiveNotes is how many active notes the creator can have, 0 if unlimited.
+	// Notes can be created concurrently, so the note should be created within it,
+	// see [noterepo.NewNote.MaxActiveNotes].
+	MaxActiveNotes int
+
+	subject string
+	day     time.Time
+}
+
+type Config struct {
+	// Anonymous is the quota of notes created without an author, it's applied per ip.
+	Anonymous models.Quota
+
+	// Registered is the quota of every user, unless it's overridden for them.
+	Registered models.Quota
+
+	// IPHashKey is the key ips are hashed with, before they're stored.
+	IPHashKey string
+}
+
+type QuotaSrv struct {
+	quotarepo quotarepo.QuotaStorer
+	noterepo  noterepo.NoteStorer
+	counter   notecounter.NoteCounterer
+	cfg       Config
+}
+
+func New(
+	quotarepo quotarepo.QuotaStorer,
+	noterepo noterepo.NoteStorer,
+	counter notecounter.NoteCounterer,
+	cfg Config,
+) *QuotaSrv {
+	return &QuotaSrv{
+		quotarepo: quotarepo,
+		noterepo:  noterepo,
+		counter:   counter,
+		cfg:       cfg,
+	}
+}
+
+func (q *QuotaSrv) Reserve(
+	ctx context.Context,
+	userID uuid.UUID,
+	ip string,
+	note models.Note,
+) (Reservation, error) {
+	quota, err := q.getQuota(ctx, userID)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	now := time.Now()
+	if err := quota.CheckNote(note, now); err != nil {
+		return Reservation{}, err
+	}
+
+	// the note is checked against the limit once more when it's created, this only fails early
+	if quota.MaxActiveNotes > 0 {
+		active, err := q.getActiveNotesCount(ctx, userID, ip, now)
+		if err != nil {
+			return Reservation{}, err
+		}
+
+		if active >= int64(quota.MaxActiveNotes) {
+			return Reservation{}, models.ErrQuotaActiveNotesExceeded
+		}
+	}
+
+	// the note is counted first, so concurrent requests cannot go over the limit
+	reservation := Reservation{
+		MaxActiveNotes: quota.MaxActiveNotes,
+		subject:        q.getSubject(userID, ip),
+		day:            now,
+	}
+
+	count, err := q.counter.Increment(ctx, reservation.subject, reservation.day)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	if quota.NotesPerDay > 0 && count > int64(quota.NotesPerDay) {
+		q.Release(ctx, reservation)
+		return Reservation{}, models.ErrQuotaDailyNotesExceeded
+	}
+
+	return reservation, nil
+}
+
+func (q *QuotaSrv) CheckContent(ctx context.Context, userID uuid.UUID, content string) error {
+	quota, err := q.getQuota(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return quota.CheckContent(content)
+}
+
+func (q *QuotaSrv) CheckExpiration(ctx context.Context, userID uuid.UUID, expiresAt time.Time) error {
+	quota, err := q.getQuota(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return quota.CheckExpiration(expiresAt, time.Now())
+}
+
+func (q *QuotaSrv) Release(ctx context.Context, reservation Reservation) {
+	if err := q.counter.Decrement(ctx, reservation.subject, reservation.day); err != nil {
+		slog.ErrorContext(ctx, "failed to release daily note", "err", err)
+	}
+}
+
+func (q *QuotaSrv) GetUsage(ctx context.Context, userID uuid.UUID) (dtos.QuotaUsage, error) {
+	quota, err := q.getQuota(ctx, userID)
+	if err != nil {
+		return dtos.QuotaUsage{}, err
+	}
+
+	now := time.Now()
+	today, err := q.counter.Get(ctx, q.getSubject(userID, ""), now)
+	if err != nil {
+		return dtos.QuotaUsage{}, err
+	}
+
+	active, err := q.noterepo.GetCountOfActiveNotesByAuthorID(ctx, userID, now)
+	if err != nil {
+		return dtos.QuotaUsage{}, err
+	}
+
+	return dtos.QuotaUsage{
+		Tier:            string(quota.Tier),
+		MaxContentBytes: quota.MaxContentBytes,
+		NotesPerDay:     quota.NotesPerDay,
+		MaxActiveNotes:  quota.MaxActiveNotes,
+		MaxExpiresIn:    quota.MaxExpiresIn,
+		NotesToday:      int(today),
+		ActiveNotes:     int(active),
+		ResetsAt:        now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour),
+	}, nil
+}
+
+func (q *QuotaSrv) getQuota(ctx context.Context, userID uuid.UUID) (models.Quota, error) {
+	if userID.IsNil() {
+		return q.cfg.Anonymous, nil
+	}
+
+	override, err := q.quotarepo.GetOverrideByUserID(ctx, userID)
+	if errors.Is(err, models.ErrQuotaOverrideNotFound) {
+		return q.cfg.Registered, nil
+	}
+	if err != nil {
+		return models.Quota{}, err
+	}
+
+	return q.cfg.Registered.WithOverride(override), nil
+}
+
+func (q *QuotaSrv) getActiveNotesCount(
+	ctx context.Context,
+	userID uuid.UUID,
+	ip string,
+	now time.Time,
+) (int64, error) {
+	if userID.IsNil() {
+		return q.noterepo.GetCountOfActiveNotesByAnonymousAuthor(ctx, q.HashIP(ip), now)
+	}
+
+	return q.noterepo.GetCountOfActiveNotesByAuthorID(ctx, userID, now)
+}
+
+// getSubject returns the one the daily notes are counted for.
+func (q *QuotaSrv) getSubject(userID uuid.UUID, ip string) string {
+	if userID.IsNil() {
+		return "ip:" + q.HashIP(ip)
+	}
+
+	return "user:" + userID.String()
+}
+
+func (q *QuotaSrv) HashIP(ip string) string {
+	mac := hmac.New(sha256.New, []byte(q.cfg.IPHashKey))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
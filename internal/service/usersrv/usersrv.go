@@ -9,6 +9,7 @@ import (
 	"github.com/olexsmir/onasty/internal/events/mailermq"
 	"github.com/olexsmir/onasty/internal/hasher"
 	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/service/quotasrv"
 	"github.com/olexsmir/onasty/internal/store/psql/changeemailrepo"
 	"github.com/olexsmir/onasty/internal/store/psql/noterepo"
 	"github.com/olexsmir/onasty/internal/store/psql/passwordtokrepo"
@@ -17,7 +18,7 @@ import (
 )
 
 type UserServicer interface {
-	// GetUserInfo retrieves user information by user ID.
+	// GetUserInfo retrieves user information by user ID, including usage of their quota.
 	GetUserInfo(ctx context.Context, userID uuid.UUID) (dtos.UserInfo, error)
 
 	// ChangePassword changes the user's password.
@@ -48,6 +49,7 @@ type UserSrv struct {
 	pwdtokrepo      passwordtokrepo.PasswordResetTokenStorer
 	changeemailrepo changeemailrepo.ChangeEmailStorer
 	notestore       noterepo.NoteStorer
+	quotasrv        quotasrv.QuotaServicer
 
 	hasher   hasher.Hasher
 	mailermq mailermq.Mailer
@@ -63,6 +65,7 @@ func New(
 	pwdtokrepo passwordtokrepo.PasswordResetTokenStorer,
 	changeemailrepo changeemailrepo.ChangeEmailStorer,
 	notestore noterepo.NoteStorer,
+	quotasrv quotasrv.QuotaServicer,
 	hasher hasher.Hasher,
 	mailermq mailermq.Mailer,
 	verificationTokenTTL, resetPasswordTokenTTL, changeEmailTokenTTL time.Duration,
@@ -73,6 +76,7 @@ func New(
 		pwdtokrepo:            pwdtokrepo,
 		changeemailrepo:       changeemailrepo,
 		notestore:             notestore,
+		quotasrv:              quotasrv,
 		hasher:                hasher,
 		mailermq:              mailermq,
 		verificationTokenTTL:  verificationTokenTTL,
@@ -92,11 +96,17 @@ func (u *UserSrv) GetUserInfo(ctx context.Context, userID uuid.UUID) (dtos.UserI
 		return dtos.UserInfo{}, err
 	}
 
+	quota, err := u.quotasrv.GetUsage(ctx, userID)
+	if err != nil {
+		return dtos.UserInfo{}, err
+	}
+
 	return dtos.UserInfo{
 		Email:        user.Email,
 		CreatedAt:    user.CreatedAt,
 		LastLoginAt:  user.LastLoginAt,
 		NotesCreated: int(count),
+		Quota:        quota,
 	}, nil
 }
 
@@ -0,0 +1,162 @@
+package webhooksrv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psql/webhookrepo"
+)
+
+// Every delivery is a POST request with the json payload, and the following headers.
+// The signature looks like "t=<unix timestamp>,v1=<hex encoded hmac>", where hmac is
+// HMAC-SHA256 of "<unix timestamp>.<payload>" keyed with the webhook's secret.
+// Deliveries are made at least once, so the receiver should use the delivery ID to skip duplicates.
+const (
+	HeaderEvent     = "X-Onasty-Event"
+	HeaderDelivery  = "X-Onasty-Delivery"
+	HeaderSignature = "X-Onasty-Signature"
+)
+
+const (
+	// deliveryBatchSize is how many deliveries are attempted at once.
+	deliveryBatchSize = 50
+
+	// deliveryLease is how long, on top of [Config.Timeout], the claimed delivery waits
+	// for its result to be recorded, before it's attempted again.
+	deliveryLease = time.Minute
+
+	maxBackoff = 12 * time.Hour
+
+	// maxErrorLength is how much of the error message is kept in the delivery log.
+	maxErrorLength = 255
+
+	// maxResponseBodySize is how much of the response body is read, so the connection can be reused.
+	maxResponseBodySize = 64 * 1024
+)
+
+func (w *WebhookSrv) DeliverDue(ctx context.Context) ([]dtos.WebhookDeliveryAttempt, error) {
+	now := time.Now()
+	due, err := w.webhookrepo.ClaimDueDeliveries(
+		ctx,
+		now,
+		now.Add(w.cfg.Timeout+deliveryLease),
+		deliveryBatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := make([]dtos.WebhookDeliveryAttempt, len(due))
+
+	var wg sync.WaitGroup
+	for i, d := range due {
+		wg.Go(func() { attempts[i] = w.deliver(ctx, d) })
+	}
+	wg.Wait()
+
+	return attempts, nil
+}
+
+// deliver attempts the delivery, and records its result.
+// If the result cannot be recorded, the delivery is attempted again once its lease runs out.
+func (w *WebhookSrv) deliver(ctx context.Context, due webhookrepo.DueDelivery) dtos.WebhookDeliveryAttempt {
+	startedAt := time.Now()
+	status, err := w.send(ctx, due)
+
+	d := due.Delivery
+	d.LastAttemptAt = startedAt
+	d.ResponseStatus = status
+	d.Error = ""
+	if err != nil {
+		d.Error = truncate(err.Error(), maxErrorLength)
+	}
+
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		d.Status = models.WebhookDeliveryStatusSucceeded
+	case d.Attempts >= w.cfg.MaxAttempts:
+		d.Status = models.WebhookDeliveryStatusFailed
+	default:
+		d.Status = models.WebhookDeliveryStatusPending
+		d.NextAttemptAt = time.Now().Add(w.backoff(d.Attempts))
+	}
+
+	if err := w.webhookrepo.UpdateDelivery(ctx, d); err != nil {
+		slog.ErrorContext(ctx, "failed to record webhook delivery", "id", d.ID, "err", err)
+	}
+
+	return dtos.WebhookDeliveryAttempt{
+		Status:   string(d.Status),
+		Duration: time.Since(startedAt),
+	}
+}
+
+// send sends the delivery, and returns the response's status.
+func (w *WebhookSrv) send(ctx context.Context, due webhookrepo.DueDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		due.URL,
+		strings.NewReader(due.Delivery.Payload),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "onasty-webhooks")
+	req.Header.Set(HeaderEvent, string(due.Delivery.Event))
+	req.Header.Set(HeaderDelivery, due.Delivery.ID.String())
+	req.Header.Set(HeaderSignature, sign(due.Secret, time.Now(), due.Delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodySize))
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt, after the specified number of attempts.
+func (w *WebhookSrv) backoff(attempts int) time.Duration {
+	delay := w.cfg.Backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
+
+func sign(secret string, timestamp time.Time, payload string) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "." + payload))
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return strings.ToValidUTF8(s[:n], "")
+}
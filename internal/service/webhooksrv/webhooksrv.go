@@ -0,0 +1,302 @@
+package webhooksrv
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/netguard"
+	"github.com/olexsmir/onasty/internal/store/psql/webhookrepo"
+)
+
+type WebhookServicer interface {
+	Emitter
+
+	// Create creates a webhook, and generates secret its deliveries are signed with.
+	// The secret is returned only here.
+	Create(ctx context.Context, userID uuid.UUID, inp dtos.CreateWebhook) (dtos.Webhook, error)
+
+	// GetAll returns all webhooks of the user.
+	GetAll(ctx context.Context, userID uuid.UUID) ([]dtos.Webhook, error)
+
+	// GetByID returns the user's webhook.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	GetByID(ctx context.Context, id, userID uuid.UUID) (dtos.Webhook, error)
+
+	// Update updates webhook's url and events.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	Update(ctx context.Context, id, userID uuid.UUID, patch dtos.PatchWebhook) error
+
+	// Delete deletes the webhook, its pending deliveries are not going to be attempted.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	Delete(ctx context.Context, id, userID uuid.UUID) error
+
+	// GetDeliveries returns latest deliveries of the user's webhook, newest first.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	GetDeliveries(ctx context.Context, id, userID uuid.UUID) ([]dtos.WebhookDelivery, error)
+
+	// DeliverDue attempts deliveries that are due, failed ones are retried with exponential backoff,
+	// until they run out of [Config.MaxAttempts].
+	// Returns outcomes of the attempts.
+	DeliverDue(ctx context.Context) ([]dtos.WebhookDeliveryAttempt, error)
+
+	// DeleteOldDeliveries deletes succeeded and failed deliveries older than [Config.DeliveryLogRetention].
+	// Returns number of deleted deliveries.
+	DeleteOldDeliveries(ctx context.Context) (int64, error)
+}
+
+type Emitter interface {
+	// Emit queues delivery of the note's event to the user's webhooks that are subscribed to it.
+	Emit(ctx context.Context, userID uuid.UUID, event models.WebhookEvent, slug dtos.NoteSlug) error
+}
+
+var _ WebhookServicer = (*WebhookSrv)(nil)
+
+type Config struct {
+	// Timeout is how long to wait for the endpoint to respond.
+	Timeout time.Duration
+
+	// MaxAttempts is how many times the delivery is attempted before giving up.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, it's doubled after every failed attempt.
+	Backoff time.Duration
+
+	// DeliveryLogRetention is how long deliveries are kept after they're created.
+	DeliveryLogRetention time.Duration
+
+	// AllowInternalAddrs is whether deliveries can be sent to loopback, private, and other internal addresses,
+	// otherwise they're refused, so webhooks cannot be used to reach services behind the server.
+	AllowInternalAddrs bool
+}
+
+const (
+	// deliveryLogLimit is how many latest deliveries are returned to the user.
+	deliveryLogLimit = 100
+
+	secretPrefix = "whsec_"
+	secretSize   = 32
+)
+
+type WebhookSrv struct {
+	webhookrepo webhookrepo.WebhookStorer
+	client      *http.Client
+	cfg         Config
+}
+
+func New(webhookrepo webhookrepo.WebhookStorer, cfg Config) *WebhookSrv {
+	return &WebhookSrv{
+		webhookrepo: webhookrepo,
+		client: &http.Client{ //nolint:exhaustruct
+			Transport: newTransport(cfg.AllowInternalAddrs),
+			// redirects aren't followed, so the delivery goes only where the user asked
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: cfg,
+	}
+}
+
+// newTransport returns transport deliveries are sent with, addresses are checked when they're dialed,
+// after the host is resolved, so the check cannot be bypassed by DNS rebinding.
+func newTransport(allowInternalAddrs bool) *http.Transport {
+	dialer := &net.Dialer{ //nolint:exhaustruct
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !allowInternalAddrs {
+		dialer.Control = netguard.Control
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.DialContext = dialer.DialContext
+	// proxies would be dialed instead of the endpoint itself
+	transport.Proxy = nil
+
+	return transport
+}
+
+func (w *WebhookSrv) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	inp dtos.CreateWebhook,
+) (dtos.Webhook, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return dtos.Webhook{}, err
+	}
+
+	webhook := models.Webhook{
+		ID:        uuid.Must(uuid.NewV4()),
+		UserID:    userID,
+		URL:       inp.URL,
+		Events:    mapStringsToEvents(inp.Events),
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+	if err := webhook.Validate(); err != nil {
+		return dtos.Webhook{}, err
+	}
+
+	if err := w.webhookrepo.Create(ctx, webhook); err != nil {
+		return dtos.Webhook{}, err
+	}
+
+	res := mapWebhookModelToDto(webhook)
+	res.Secret = secret
+
+	return res, nil
+}
+
+func (w *WebhookSrv) GetAll(ctx context.Context, userID uuid.UUID) ([]dtos.Webhook, error) {
+	webhooks, err := w.webhookrepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dtos.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		res = append(res, mapWebhookModelToDto(webhook))
+	}
+
+	return res, nil
+}
+
+func (w *WebhookSrv) GetByID(ctx context.Context, id, userID uuid.UUID) (dtos.Webhook, error) {
+	webhook, err := w.webhookrepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return dtos.Webhook{}, err
+	}
+
+	return mapWebhookModelToDto(webhook), nil
+}
+
+func (w *WebhookSrv) Update(
+	ctx context.Context,
+	id, userID uuid.UUID,
+	patch dtos.PatchWebhook,
+) error {
+	if patch.URL != nil {
+		if err := models.ValidateWebhookURL(*patch.URL); err != nil {
+			return err
+		}
+	}
+
+	if patch.Events != nil {
+		if err := models.ValidateWebhookEvents(mapStringsToEvents(patch.Events)); err != nil {
+			return err
+		}
+	}
+
+	return w.webhookrepo.Update(ctx, id, userID, patch)
+}
+
+func (w *WebhookSrv) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	return w.webhookrepo.Delete(ctx, id, userID)
+}
+
+func (w *WebhookSrv) GetDeliveries(
+	ctx context.Context,
+	id, userID uuid.UUID,
+) ([]dtos.WebhookDelivery, error) {
+	if _, err := w.webhookrepo.GetByID(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := w.webhookrepo.GetDeliveriesByWebhookID(ctx, id, deliveryLogLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dtos.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		res = append(res, dtos.WebhookDelivery{
+			ID:             d.ID,
+			Event:          string(d.Event),
+			Payload:        d.Payload,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			ResponseStatus: d.ResponseStatus,
+			Error:          d.Error,
+			CreatedAt:      d.CreatedAt,
+			NextAttemptAt:  d.NextAttemptAt,
+			LastAttemptAt:  d.LastAttemptAt,
+		})
+	}
+
+	return res, nil
+}
+
+type payload struct {
+	Event      models.WebhookEvent `json:"event"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Note       payloadNote         `json:"note"`
+}
+
+type payloadNote struct {
+	Slug dtos.NoteSlug `json:"slug"`
+}
+
+func (w *WebhookSrv) Emit(
+	ctx context.Context,
+	userID uuid.UUID,
+	event models.WebhookEvent,
+	slug dtos.NoteSlug,
+) error {
+	body, err := json.Marshal(payload{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Note:       payloadNote{Slug: slug},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.webhookrepo.CreateDeliveries(ctx, userID, event, string(body))
+	return err
+}
+
+func (w *WebhookSrv) DeleteOldDeliveries(ctx context.Context) (int64, error) {
+	return w.webhookrepo.DeleteDeliveriesCreatedBefore(ctx, time.Now().Add(-w.cfg.DeliveryLogRetention))
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func mapWebhookModelToDto(webhook models.Webhook) dtos.Webhook {
+	events := make([]string, 0, len(webhook.Events))
+	for _, e := range webhook.Events {
+		events = append(events, string(e))
+	}
+
+	//nolint:exhaustruct // secret is set only on creation
+	return dtos.Webhook{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func mapStringsToEvents(events []string) []models.WebhookEvent {
+	res := make([]models.WebhookEvent, 0, len(events))
+	for _, e := range events {
+		res = append(res, models.WebhookEvent(e))
+	}
+
+	return res
+}
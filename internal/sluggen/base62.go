@@ -0,0 +1,48 @@
+package sluggen
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+)
+
+const (
+	base62Alphabet  = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	base62MaxLength = 64
+)
+
+var _ Generator = (*Base62)(nil)
+
+// Base62 generates short slugs of random alphanumeric characters, e.g. "q3ZrT8xK1b".
+type Base62 struct {
+	length int
+}
+
+// NewBase62 returns a generator of slugs of the length.
+// Returns [ErrLengthInvalid] if the length is not between 1 and 64.
+func NewBase62(length int) (*Base62, error) {
+	if length < 1 || length > base62MaxLength {
+		return nil, ErrLengthInvalid
+	}
+
+	return &Base62{length: length}, nil
+}
+
+func (g *Base62) Generate() (string, error) {
+	alphabetLen := big.NewInt(int64(len(base62Alphabet)))
+
+	slug := make([]byte, g.length)
+	for i := range slug {
+		n, err := rand.Int(rand.Reader, alphabetLen)
+		if err != nil {
+			return "", err
+		}
+		slug[i] = base62Alphabet[n.Int64()]
+	}
+
+	return string(slug), nil
+}
+
+func (g *Base62) Entropy() float64 {
+	return float64(g.length) * math.Log2(float64(len(base62Alphabet)))
+}
@@ -0,0 +1,61 @@
+package sluggen
+
+import "fmt"
+
+type Config struct {
+	// Strategy is the default strategy, used when a caller doesn't choose one.
+	Strategy Strategy
+
+	// Base62Length and WordsCount are the default lengths of their strategies.
+	Base62Length int
+	WordsCount   int
+
+	// MinEntropy is the least entropy, in bits, slugs of any strategy should have.
+	MinEntropy float64
+}
+
+// Policy lets callers choose a strategy of slugs, within the instance-wide default and minimum entropy.
+type Policy struct {
+	cfg Config
+}
+
+// NewPolicy returns a policy, the default strategy should satisfy the minimum entropy itself.
+func NewPolicy(cfg Config) (*Policy, error) {
+	p := &Policy{cfg: cfg}
+	if _, err := p.Generator("", 0); err != nil {
+		return nil, fmt.Errorf("default slug strategy: %w", err)
+	}
+
+	return p, nil
+}
+
+// Generator returns a generator of the strategy, or of the default one if strategy is empty.
+// Zero length means the default length of the strategy.
+//
+// Returns [ErrEntropyTooLow] if its slugs would have less entropy than the minimum,
+// and [New] errors if the strategy or length is invalid.
+func (p *Policy) Generator(strategy Strategy, length int) (Generator, error) { //nolint:ireturn // see [New]
+	if strategy == "" {
+		strategy = p.cfg.Strategy
+	}
+
+	if length == 0 {
+		switch strategy { //nolint:exhaustive // uuid has fixed length
+		case StrategyBase62:
+			length = p.cfg.Base62Length
+		case StrategyWords:
+			length = p.cfg.WordsCount
+		}
+	}
+
+	gen, err := New(strategy, length)
+	if err != nil {
+		return nil, err
+	}
+
+	if gen.Entropy() < p.cfg.MinEntropy {
+		return nil, ErrEntropyTooLow
+	}
+
+	return gen, nil
+}
@@ -0,0 +1,50 @@
+// Package sluggen generates random slugs of notes.
+package sluggen
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrStrategyUnknown = errors.New("slug: strategy should be one of: base62, words, uuid")
+	ErrLengthInvalid   = errors.New("slug: length is out of range of the strategy")
+	ErrEntropyTooLow   = errors.New("slug: would be too easy to guess, use a longer one")
+)
+
+type Strategy string
+
+const (
+	StrategyBase62 Strategy = "base62"
+	StrategyWords  Strategy = "words"
+	StrategyUUID   Strategy = "uuid"
+)
+
+type Generator interface {
+	// Generate returns a new random slug.
+	Generate() (string, error)
+
+	// Entropy returns how many bits of entropy generated slugs have,
+	// that is how hard they are to guess.
+	Entropy() float64
+}
+
+// New returns a generator of the strategy. The length is number of characters for [StrategyBase62],
+// and number of words for [StrategyWords], it should be zero for [StrategyUUID].
+//
+// Returns [ErrStrategyUnknown] if the strategy is unknown, and [ErrLengthInvalid] if the length is out of its range.
+func New(strategy Strategy, length int) (Generator, error) { //nolint:ireturn // the generator is selected by strategy
+	switch strategy {
+	case StrategyBase62:
+		return NewBase62(length)
+	case StrategyWords:
+		return NewWords(length)
+	case StrategyUUID:
+		if length != 0 {
+			return nil, fmt.Errorf("%w: uuid has fixed length", ErrLengthInvalid)
+		}
+		return NewUUID(), nil
+	default:
+		return nil, ErrStrategyUnknown
+	}
+}
@@ -0,0 +1,100 @@
+package sluggen
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase62(t *testing.T) {
+	gen, err := NewBase62(10)
+	require.NoError(t, err)
+
+	slug, err := gen.Generate()
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[0-9A-Za-z]{10}$`), slug)
+	require.InDelta(t, 59.5, gen.Entropy(), 0.1)
+
+	other, err := gen.Generate()
+	require.NoError(t, err)
+	require.NotEqual(t, slug, other)
+
+	_, err = NewBase62(0)
+	require.ErrorIs(t, err, ErrLengthInvalid)
+}
+
+func TestWords(t *testing.T) {
+	require.Len(t, words, 2048)
+
+	gen, err := NewWords(3)
+	require.NoError(t, err)
+
+	slug, err := gen.Generate()
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[a-z]+-[a-z]+-[a-z]+$`), slug)
+	require.InDelta(t, 33, gen.Entropy(), 0.1)
+
+	_, err = NewWords(17)
+	require.ErrorIs(t, err, ErrLengthInvalid)
+}
+
+func TestUUID(t *testing.T) {
+	slug, err := NewUUID().Generate()
+	require.NoError(t, err)
+
+	_, err = uuid.FromString(slug)
+	require.NoError(t, err)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("emoji", 3)
+	require.ErrorIs(t, err, ErrStrategyUnknown)
+
+	_, err = New(StrategyUUID, 10)
+	require.ErrorIs(t, err, ErrLengthInvalid)
+}
+
+func TestPolicy(t *testing.T) {
+	cfg := Config{
+		Strategy:     StrategyBase62,
+		Base62Length: 10,
+		WordsCount:   5,
+		MinEntropy:   48,
+	}
+	policy, err := NewPolicy(cfg)
+	require.NoError(t, err)
+
+	t.Run("uses the default strategy and length", func(t *testing.T) {
+		gen, err := policy.Generator("", 0)
+		require.NoError(t, err)
+
+		slug, err := gen.Generate()
+		require.NoError(t, err)
+		require.Len(t, slug, 10)
+	})
+	t.Run("uses the default length of the chosen strategy", func(t *testing.T) {
+		gen, err := policy.Generator(StrategyWords, 0)
+		require.NoError(t, err)
+
+		slug, err := gen.Generate()
+		require.NoError(t, err)
+		require.Len(t, strings.Split(slug, "-"), 5)
+	})
+	t.Run("should fail if entropy is lower than the minimum", func(t *testing.T) {
+		_, err := policy.Generator(StrategyWords, 3)
+		require.ErrorIs(t, err, ErrEntropyTooLow)
+
+		_, err = policy.Generator(StrategyBase62, 8)
+		require.ErrorIs(t, err, ErrEntropyTooLow)
+	})
+	t.Run("should fail if the default strategy is too weak", func(t *testing.T) {
+		cfg := cfg
+		cfg.Base62Length = 4
+
+		_, err := NewPolicy(cfg)
+		require.ErrorIs(t, err, ErrEntropyTooLow)
+	})
+}
@@ -0,0 +1,28 @@
+package sluggen
+
+import "github.com/gofrs/uuid/v5"
+
+// uuidEntropy is the number of random bits of uuid v4, the rest of them are its version and variant.
+const uuidEntropy = 122
+
+var _ Generator = (*UUID)(nil)
+
+// UUID generates uuid v4 slugs, they're the hardest to guess, but the longest ones.
+type UUID struct{}
+
+func NewUUID() *UUID {
+	return &UUID{}
+}
+
+func (g *UUID) Generate() (string, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (g *UUID) Entropy() float64 {
+	return uuidEntropy
+}
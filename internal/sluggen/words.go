@@ -0,0 +1,58 @@
+package sluggen
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	wordsSeparator = "-"
+	wordsMaxCount  = 16
+)
+
+// wordList is the english word list of BIP-39, its words are short, common, and
+// distinguishable by the first four letters, so they're easy to read out loud or type.
+//
+//go:embed words.txt
+var wordList string
+
+var words = strings.Fields(wordList)
+
+var _ Generator = (*Words)(nil)
+
+// Words generates passphrase-like slugs of random words, e.g. "correct-horse-battery".
+type Words struct {
+	count int
+}
+
+// NewWords returns a generator of slugs of the number of words.
+// Returns [ErrLengthInvalid] if the count is not between 1 and 16.
+func NewWords(count int) (*Words, error) {
+	if count < 1 || count > wordsMaxCount {
+		return nil, ErrLengthInvalid
+	}
+
+	return &Words{count: count}, nil
+}
+
+func (g *Words) Generate() (string, error) {
+	wordsLen := big.NewInt(int64(len(words)))
+
+	slug := make([]string, g.count)
+	for i := range slug {
+		n, err := rand.Int(rand.Reader, wordsLen)
+		if err != nil {
+			return "", err
+		}
+		slug[i] = words[n.Int64()]
+	}
+
+	return strings.Join(slug, wordsSeparator), nil
+}
+
+func (g *Words) Entropy() float64 {
+	return float64(g.count) * math.Log2(float64(len(words)))
+}
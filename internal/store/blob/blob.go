@@ -0,0 +1,23 @@
+// Package blob defines storage of binary large objects, like note attachments.
+// Implementations live in subpackages.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob: not found")
+
+type Storer interface {
+	// Put stores content read from r under the key, overwrites existing blob if any.
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get returns reader of the blob's content, the caller should close it.
+	// Returns [ErrNotFound] if there's no blob with the key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete deletes the blob by key, it's not an error if there's no such blob.
+	Delete(ctx context.Context, key string) error
+}
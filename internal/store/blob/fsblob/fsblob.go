@@ -0,0 +1,75 @@
+// Package fsblob implements [blob.Storer] on top of local filesystem.
+package fsblob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/olexsmir/onasty/internal/store/blob"
+)
+
+var _ blob.Storer = (*Store)(nil)
+
+type Store struct {
+	root *os.Root
+}
+
+// New creates a [Store], that keeps blobs as files in dir, which is created if it doesn't exist.
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	// os.Root makes sure that keys cannot escape the dir
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{root: root}, nil
+}
+
+func (s *Store) Put(_ context.Context, key string, r io.Reader) error {
+	// the content is written into temporary file first,
+	// so readers never see partially written blobs
+	tmpName := key + ".tmp"
+	f, err := s.root.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		return errors.Join(err, f.Close(), s.root.Remove(tmpName))
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.Join(err, s.root.Remove(tmpName))
+	}
+
+	return s.root.Rename(tmpName, key)
+}
+
+func (s *Store) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := s.root.Open(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, blob.ErrNotFound
+	}
+
+	return f, err
+}
+
+func (s *Store) Delete(_ context.Context, key string) error {
+	err := s.root.Remove(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *Store) Close() error {
+	return s.root.Close()
+}
@@ -0,0 +1,60 @@
+package fsblob
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/olexsmir/onasty/internal/store/blob"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := t.Context()
+	s, err := New(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, s.Close()) })
+
+	t.Run("put and get", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "key", strings.NewReader("content")))
+
+		r, err := s.Get(ctx, "key")
+		require.NoError(t, err)
+		defer r.Close()
+
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(content))
+	})
+
+	t.Run("put overwrites", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "overwritten", strings.NewReader("old")))
+		require.NoError(t, s.Put(ctx, "overwritten", strings.NewReader("new")))
+
+		r, err := s.Get(ctx, "overwritten")
+		require.NoError(t, err)
+		defer r.Close()
+
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, "new", string(content))
+	})
+
+	t.Run("get not found", func(t *testing.T) {
+		_, err := s.Get(ctx, "not-found")
+		require.ErrorIs(t, err, blob.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, "deleted", strings.NewReader("content")))
+		require.NoError(t, s.Delete(ctx, "deleted"))
+		require.NoError(t, s.Delete(ctx, "deleted"))
+
+		_, err := s.Get(ctx, "deleted")
+		require.ErrorIs(t, err, blob.ErrNotFound)
+	})
+
+	t.Run("key cannot escape the dir", func(t *testing.T) {
+		require.Error(t, s.Put(ctx, "../escaped", strings.NewReader("content")))
+	})
+}
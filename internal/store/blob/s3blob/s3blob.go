@@ -0,0 +1,91 @@
+// Package s3blob implements [blob.Storer] on top of S3 compatible storage.
+package s3blob
+
+import (
+	"context"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/olexsmir/onasty/internal/store/blob"
+)
+
+var _ blob.Storer = (*Store)(nil)
+
+// partSize is size of parts blobs are uploaded with, since their size is not known in advance.
+// Without it, the client would buffer parts of maximum possible size.
+const partSize = 5 * 1024 * 1024
+
+type Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+type Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// New creates a [Store], the bucket is created if it doesn't exist.
+func New(ctx context.Context, cfg Config) (*Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{ //nolint:exhaustruct
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{ //nolint:exhaustruct
+			Region: cfg.Region,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Store{
+		client: client,
+		bucket: cfg.Bucket,
+	}, nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, r io.Reader) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, -1, minio.PutObjectOptions{ //nolint:exhaustruct
+		ContentType: "application/octet-stream",
+		PartSize:    partSize,
+	})
+	return err
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{}) //nolint:exhaustruct
+	if err != nil {
+		return nil, err
+	}
+
+	// the object is requested lazily, stat makes sure that it exists
+	if _, err := obj.Stat(); err != nil {
+		obj.Close() //nolint:errcheck
+		if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+			return nil, blob.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}) //nolint:exhaustruct
+}
@@ -0,0 +1,179 @@
+package attachmentrepo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+)
+
+// Attachments are created along with their note, see [noterepo.NoteStorer.Create].
+// Attachments that are not linked to any note are called detached,
+// it happens when the note is deleted or burnt, their blobs are to be deleted.
+
+type AttachmentStorer interface {
+	// GetAllByNoteSlug returns all attachments of the note, in order they were created.
+	GetAllByNoteSlug(ctx context.Context, slug dtos.NoteSlug) ([]models.NoteAttachment, error)
+
+	// GetDownloadable returns the attachment if it can be downloaded:
+	// its note is not expired, and it's either unread or was read after readAfter.
+	// Also reports whether the note is read, in which case the attachment can be downloaded only once.
+	//
+	// Returns [models.ErrNoteAttachmentNotFound] if there's no such attachment, or it cannot be downloaded.
+	GetDownloadable(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		id uuid.UUID,
+		readAfter, now time.Time,
+	) (models.NoteAttachment, bool, error)
+
+	// Detach detaches the attachment from its note.
+	// Returns [models.ErrNoteAttachmentNotFound] if there's no such attachment, or it's already detached.
+	Detach(ctx context.Context, id uuid.UUID) error
+
+	// DetachStale detaches attachments of notes that expired before now, or were read before readBefore.
+	// Returns number of detached attachments.
+	DetachStale(ctx context.Context, now, readBefore time.Time) (int64, error)
+
+	// GetDetached returns up to limit IDs of the detached attachments.
+	GetDetached(ctx context.Context, limit int) ([]uuid.UUID, error)
+
+	// Delete deletes the attachment.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// GetContentKeyIDs returns IDs of keys, attachments that aren't detached are encrypted with.
+	// Attachments aren't re-encrypted, so the keys are needed until the attachments are gone.
+	GetContentKeyIDs(ctx context.Context) ([]string, error)
+}
+
+var _ AttachmentStorer = (*AttachmentRepo)(nil)
+
+type AttachmentRepo struct {
+	db *psqlutil.DB
+}
+
+func New(db *psqlutil.DB) *AttachmentRepo {
+	return &AttachmentRepo{db: db}
+}
+
+func (r *AttachmentRepo) GetAllByNoteSlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+) ([]models.NoteAttachment, error) {
+	query := `--sql
+select a.id, a.filename, a.content_type, a.size, a.content_key_id, a.created_at
+from note_attachments a
+inner join notes n on n.id = a.note_id
+where n.slug = $1
+order by a.created_at, a.id`
+
+	rows, err := r.db.Query(ctx, query, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.NoteAttachment
+	for rows.Next() {
+		var a models.NoteAttachment
+		if err := rows.Scan(&a.ID, &a.Filename, &a.ContentType, &a.Size, &a.ContentKeyID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (r *AttachmentRepo) GetDownloadable(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	id uuid.UUID,
+	readAfter, now time.Time,
+) (models.NoteAttachment, bool, error) {
+	query := `--sql
+select a.id, a.filename, a.content_type, a.size, a.content_key_id, a.created_at, n.read_at is not null
+from note_attachments a
+inner join notes n on n.id = a.note_id
+where n.slug = $1
+  and a.id = $2
+  and (n.expires_at <= 'epoch' or n.expires_at > $3)
+  and (n.read_at is null or n.read_at > $4)`
+
+	var a models.NoteAttachment
+	var isRead bool
+	err := r.db.QueryRow(ctx, query, slug, id, now, readAfter).
+		Scan(&a.ID, &a.Filename, &a.ContentType, &a.Size, &a.ContentKeyID, &a.CreatedAt, &isRead)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.NoteAttachment{}, false, models.ErrNoteAttachmentNotFound
+	}
+
+	return a, isRead, err
+}
+
+func (r *AttachmentRepo) Detach(ctx context.Context, id uuid.UUID) error {
+	ct, err := r.db.Exec(ctx,
+		"update note_attachments set note_id = null where id = $1 and note_id is not null",
+		id)
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrNoteAttachmentNotFound
+	}
+
+	return nil
+}
+
+func (r *AttachmentRepo) DetachStale(ctx context.Context, now, readBefore time.Time) (int64, error) {
+	query := `--sql
+update note_attachments a
+set note_id = null
+from notes n
+where n.id = a.note_id
+  and ((n.expires_at > 'epoch' and n.expires_at < $1)
+    or (n.read_at is not null and n.read_at < $2))`
+
+	ct, err := r.db.Exec(ctx, query, now, readBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+func (r *AttachmentRepo) GetDetached(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx,
+		"select id from note_attachments where note_id is null order by id limit $1",
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+func (r *AttachmentRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "delete from note_attachments where id = $1", id)
+	return err
+}
+
+func (r *AttachmentRepo) GetContentKeyIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `--sql
+select distinct content_key_id
+from note_attachments
+where note_id is not null
+  and content_key_id <> ''
+order by content_key_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -10,43 +10,104 @@ import (
 	"github.com/henvic/pgq"
 	"github.com/jackc/pgx/v5"
 	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/envelope"
 	"github.com/olexsmir/onasty/internal/models"
 	"github.com/olexsmir/onasty/internal/store/psqlutil"
 )
 
+// NewNote is the note that's created, along with what's linked to it.
+type NewNote struct {
+	Note models.Note
+
+	// Attachments are linked to the note, their blobs should be already stored.
+	Attachments []models.NoteAttachment
+
+	// AuthorID is the note's author, if it's [uuid.Nil], the note is counted toward quota
+	// of the ip it was created from by AnonymousIPHash.
+	AuthorID        uuid.UUID
+	AnonymousIPHash string
+
+	// Switch is the note's dead man's switch, nil if it has none.
+	Switch *models.NoteSwitch
+
+	// ManagementTokenHash is hash of the token the note without an author is managed with,
+	// empty if it has none.
+	ManagementTokenHash string
+
+	// MaxActiveNotes is how many active notes the author, or the ip, can have, 0 if unlimited.
+	// The note is created only if it fits in, with notes created concurrently counted one after another.
+	MaxActiveNotes int
+}
+
 type NoteStorer interface {
-	// Create creates a note.
-	Create(ctx context.Context, note models.Note) error
+	// Create creates a note, along with what's linked to it, in a single transaction.
+	// Returns [models.ErrNoteSlugIsAlreadyInUse] if the note's slug is taken,
+	// or [models.ErrQuotaActiveNotesExceeded] if the note doesn't fit into [NewNote.MaxActiveNotes].
+	Create(ctx context.Context, inp NewNote) error
+
+	// CreateWithRecipients creates a note for every recipient, with the recipient's slug and label,
+	// along with their author and management token, in a single transaction, their content is stored once.
+	// Notes with recipients cannot have attachments, nor a switch, so they're not created.
+	// Returns [models.ErrNoteSlugIsAlreadyInUse] if any of the slugs is taken,
+	// or [models.ErrQuotaActiveNotesExceeded] if the note doesn't fit into [NewNote.MaxActiveNotes].
+	CreateWithRecipients(ctx context.Context, inp NewNote, recipients models.NoteRecipients) error
+
+	// CreateReply creates the reply to the note, owned by the note's author,
+	// if the note allows replies, and it's either read or not expired at the specified time.
+	// Returns [models.ErrNoteNotFound] if there's no such note, [models.ErrNoteAlreadyReplied] if it's been replied,
+	// and [models.ErrNoteSlugIsAlreadyInUse] if the reply's slug is taken.
+	CreateReply(ctx context.Context, slug dtos.NoteSlug, reply models.Note, now time.Time) error
 
 	// GetBySlug gets a note by slug.
 	// Returns [models.ErrNoteNotFound] if note is not found.
 	GetBySlug(ctx context.Context, slug dtos.NoteSlug) (models.Note, error)
 
 	// GetMetadataBySlug gets note's metadata by its slug.
-	// Returns [models.ErrNoteNotFound] if note is not found OR read.
-	GetMetadataBySlug(ctx context.Context, slug dtos.NoteSlug) (dtos.NoteMetadata, error)
-
-	// GetAllByAuthorID returns all notes with specified author.
-	GetAllByAuthorID(ctx context.Context, authorID uuid.UUID) ([]models.Note, error)
+	// Returns [models.ErrNoteNotFound] if note is not found OR read,
+	// or if it was sent to an inbox that isn't userID's.
+	GetMetadataBySlug(ctx context.Context, slug dtos.NoteSlug, userID uuid.UUID) (dtos.NoteMetadata, error)
 
-	// GetAllReadByAuthorID returns all notes that are read and authored by specified author.
-	GetAllReadByAuthorID(ctx context.Context, authorID uuid.UUID) ([]models.Note, error)
+	// ListByAuthorID returns a page of notes with specified author, that match the query at the specified time.
+	ListByAuthorID(
+		ctx context.Context,
+		authorID uuid.UUID,
+		query models.NoteListQuery,
+		now time.Time,
+	) ([]models.Note, error)
 
-	// GetAllUnreadByAuthorID returns all notes that are unread and authored by specified author.
-	GetAllUnreadByAuthorID(ctx context.Context, authorID uuid.UUID) ([]models.Note, error)
+	// GetCountByAuthorID returns count of notes with specified author, that match the filter at the specified time.
+	GetCountByAuthorID(
+		ctx context.Context,
+		authorID uuid.UUID,
+		filter models.NoteListFilter,
+		now time.Time,
+	) (int64, error)
 
 	// GetCountOfNotesByAuthorID returns count of notes created by specified author.
 	GetCountOfNotesByAuthorID(ctx context.Context, authorID uuid.UUID) (int64, error)
 
-	// GetBySlugAndPassword gets a note by slug and password.
-	// the "password" should be hashed.
-	//
-	// Returns [models.ErrNoteNotFound] if note is not found.
-	GetBySlugAndPassword(
+	// GetCountOfActiveNotesByAuthorID returns count of notes created by specified author,
+	// that are neither read nor expired at the specified time, notes with recipients are counted once.
+	GetCountOfActiveNotesByAuthorID(
 		ctx context.Context,
-		slug dtos.NoteSlug,
-		password string,
-	) (models.Note, error)
+		authorID uuid.UUID,
+		now time.Time,
+	) (int64, error)
+
+	// GetCountOfActiveNotesByAnonymousAuthor returns count of notes created without an author
+	// from the ip with specified hash, that are neither read nor expired at the specified time,
+	// notes with recipients are counted once.
+	GetCountOfActiveNotesByAnonymousAuthor(
+		ctx context.Context,
+		ipHash string,
+		now time.Time,
+	) (int64, error)
+
+	// GetBySlugWithPassword gets a note that has a password by slug,
+	// along with the password's hash, so it can be verified by the caller.
+	//
+	// Returns [models.ErrNoteNotFound] if note is not found, or has no password.
+	GetBySlugWithPassword(ctx context.Context, slug dtos.NoteSlug) (models.Note, error)
 
 	// UpdateExpirationTimeSettingsBySlug patches note by updating expiresAt and keepBeforeExpiration if one is passwd
 	// Returns [models.ErrNoteNotFound] if note is not found.
@@ -57,18 +118,65 @@ type NoteStorer interface {
 		authorID uuid.UUID,
 	) error
 
-	// RemoveBySlug marks note as read, deletes it's content, and keeps meta data
+	// RemoveBySlug marks note as read, deletes it's content and attachments, and keeps meta data
 	// Returns [models.ErrNoteNotFound] if note is not found.
 	RemoveBySlug(ctx context.Context, slug dtos.NoteSlug, readAt time.Time) error
 
-	// DeleteNoteBySlug deletes(unlike [RemoveBySlug]) note by slug.
+	// ConsumeBySlug atomically returns note's content and uses one of its views,
+	// the note is burnt(marked as read, and its content deleted) when its last view is used.
+	// It's done in a single statement, so no more than views left concurrent readers can ever get the content.
+	// The "password" should be the hash the reader's password is verified against, or empty if note has no password,
+	// so the note isn't consumed if its password has been changed in the meantime.
+	// If accessTokenHash is not empty, the approved access request with it is used in the same transaction,
+	// see [NoteStorer.UseAccessRequest], so it's used only if the note is consumed.
+	//
+	// Returns [models.ErrNoteNotFound] if note is not found, already read, expired or not yet available at readAt,
+	// or password doesn't match, and [models.ErrNoteApprovalRequired] if there's no access request to use.
+	ConsumeBySlug(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		password string,
+		accessTokenHash string,
+		readAt time.Time,
+	) (models.Note, error)
+
+	// MarkViewedBySlug marks the note that's kept before expiration as viewed,
+	// and reports whether it's the first view of the note.
+	MarkViewedBySlug(ctx context.Context, slug dtos.NoteSlug, viewedAt time.Time) (bool, error)
+
+	// DeleteNoteBySlug deletes(unlike [RemoveBySlug]) note by slug, its attachments are detached.
 	// Returns [models.ErrNoteNotFound] if note is not found.
 	DeleteNoteBySlug(ctx context.Context, slug dtos.NoteSlug, authorID uuid.UUID) error
 
+	// BulkUpdateByAuthorID applies the query's action to the author's notes it selects, in one transaction.
+	// Returns results of the selected notes, slugs that don't match any of the author's notes are omitted.
+	BulkUpdateByAuthorID(
+		ctx context.Context,
+		authorID uuid.UUID,
+		inp models.NoteBulkQuery,
+		now time.Time,
+	) ([]models.NoteBulkResult, error)
+
 	// SetAuthorIDBySlug assigns author to note by slug.
 	// Returns [models.ErrNoteNotFound] if note is not found.
 	SetAuthorIDBySlug(ctx context.Context, slug dtos.NoteSlug, authorID uuid.UUID) error
 
+	// GetByAuthorIDAndSlug returns the author's note by slug, without its content.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	GetByAuthorIDAndSlug(ctx context.Context, authorID uuid.UUID, slug dtos.NoteSlug) (models.Note, error)
+
+	// UpdateContentBySlug replaces content of the author's note, if it's still of the version,
+	// and nobody has viewed it yet. Returns the new version of the note.
+	// Returns [models.ErrNoteVersionMismatch] if the note has been changed, viewed, or expired in the meantime.
+	UpdateContentBySlug(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		authorID uuid.UUID,
+		content string,
+		version int,
+		now time.Time,
+	) (int, error)
+
 	// UpdatePasswordBySlug updates or sets password on a note.
 	UpdatePasswordBySlug(
 		ctx context.Context,
@@ -76,187 +184,1784 @@ type NoteStorer interface {
 		authorID uuid.UUID,
 		passwd string,
 	) error
+
+	// UpdateNotifyAuthorBySlug sets whether the author is notified when the note is read or expires.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	UpdateNotifyAuthorBySlug(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		authorID uuid.UUID,
+		notify bool,
+	) error
+
+	// GetNotifiableAuthorEmailBySlug returns email of the note's author, if they want to be notified about it.
+	// Returns [models.ErrNoteNotFound] if note is not found, or there's no one to notify.
+	GetNotifiableAuthorEmailBySlug(ctx context.Context, slug dtos.NoteSlug) (string, error)
+
+	// GetAuthorIDBySlug returns ID of the note's author.
+	// Returns [models.ErrNoteNotFound] if note is not found, or has no author.
+	GetAuthorIDBySlug(ctx context.Context, slug dtos.NoteSlug) (uuid.UUID, error)
+
+	// GetSwitchByNoteID returns the note's dead man's switch.
+	// Returns [models.ErrNoteSwitchNotFound] if the note has none.
+	GetSwitchByNoteID(ctx context.Context, noteID uuid.UUID) (models.NoteSwitch, error)
+
+	// CheckInSwitch records the author's check-in at now, and postpones the note's availability
+	// until the switch's next release. Returns the time the switch is released at.
+	// Returns [models.ErrNoteSwitchReleased] if the switch is already released.
+	CheckInSwitch(ctx context.Context, noteID uuid.UUID, now time.Time) (time.Time, error)
+
+	// ClaimSwitchReminders marks switches that are released within remindBefore after now,
+	// and which authors haven't been reminded since their last check-in, as reminded.
+	// remindBefore is capped by half of the switch's interval, so authors aren't reminded right after checking in.
+	// Returns reminders of the claimed switches.
+	ClaimSwitchReminders(ctx context.Context, now time.Time, remindBefore time.Duration) ([]dtos.NoteSwitchReminder, error)
+
+	// GetDueSwitches returns switches that should be released at now,
+	// of notes that are neither read nor expired,
+	// with recipients the notes haven't been sent to yet, see [NoteStorer.MarkSwitchSentTo].
+	GetDueSwitches(ctx context.Context, now time.Time) ([]dtos.DueNoteSwitch, error)
+
+	// MarkSwitchSentTo records that the released note has been sent to the recipient.
+	MarkSwitchSentTo(ctx context.Context, noteID uuid.UUID, recipient string) error
+
+	// ReleaseSwitch marks the note's switch as released at now, and makes the note available.
+	ReleaseSwitch(ctx context.Context, noteID uuid.UUID, now time.Time) error
+
+	// GetBoundEmailBySlug returns email the unread note is bound to.
+	// Returns [models.ErrNoteNotFound] if note is not found, is read, or isn't bound.
+	GetBoundEmailBySlug(ctx context.Context, slug dtos.NoteSlug) (string, error)
+
+	// GetAuthorEmailBySlug returns email of the note's author.
+	// Returns [models.ErrNoteNotFound] if note is not found, or has no author.
+	GetAuthorEmailBySlug(ctx context.Context, slug dtos.NoteSlug) (string, error)
+
+	// CreateLinkSend records that the link to the note has been emailed by the sender.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	CreateLinkSend(ctx context.Context, slug dtos.NoteSlug, senderID uuid.UUID, send models.NoteLinkSend) error
+
+	// GetLinkSendsByNoteID returns emails with the link to the note, sorted from the oldest one.
+	GetLinkSendsByNoteID(ctx context.Context, noteID uuid.UUID) ([]models.NoteLinkSend, error)
+
+	// GetByManagementTokenHash returns the note, without its content, managed with the token.
+	// Returns [models.ErrNoteNotFound] if note is not found, or the token doesn't match.
+	GetByManagementTokenHash(ctx context.Context, slug dtos.NoteSlug, tokenHash string) (models.Note, error)
+
+	// UpdateExpirationTimeSettingsByManagementTokenHash is [NoteStorer.UpdateExpirationTimeSettingsBySlug]
+	// for notes managed with the token.
+	// Returns [models.ErrNoteNotFound] if note is not found, or the token doesn't match.
+	UpdateExpirationTimeSettingsByManagementTokenHash(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		tokenHash string,
+		patch dtos.PatchNote,
+	) error
+
+	// DeleteByManagementTokenHash deletes the note managed with the token.
+	// Returns [models.ErrNoteNotFound] if note is not found, or the token doesn't match.
+	DeleteByManagementTokenHash(ctx context.Context, slug dtos.NoteSlug, tokenHash string) error
+
+	// ClaimByManagementTokenHashes sets the author of notes managed with any of the tokens,
+	// after that they're no longer counted toward quota of the ip, and cannot be managed with the tokens.
+	// Returns slugs of the claimed notes.
+	ClaimByManagementTokenHashes(
+		ctx context.Context,
+		authorID uuid.UUID,
+		tokenHashes []string,
+	) ([]dtos.NoteSlug, error)
+
+	// CreateAccessEvent records the reader's access to the note.
+	// Returns [models.ErrNoteNotFound] if note is not found.
+	CreateAccessEvent(ctx context.Context, slug dtos.NoteSlug, event models.NoteAccessEvent) error
+
+	// GetAccessEventsByNoteID returns up to limit latest accesses to the note, sorted from the newest one.
+	GetAccessEventsByNoteID(ctx context.Context, noteID uuid.UUID, limit int) ([]models.NoteAccessEvent, error)
+
+	// DeleteAccessEventsBefore deletes accesses to notes recorded before the specified time.
+	// Returns number of deleted accesses.
+	DeleteAccessEventsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// GetInboxUserIDByEmail returns id of the activated user with the email, notes are sent to their inbox.
+	// Returns [models.ErrNoteInboxRecipientNotFound] if there's no such user.
+	GetInboxUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error)
+
+	// GetInboxByUserID returns notes sent to the user's inbox, that are neither read nor expired at now,
+	// sorted from the newest one. Notes listed for the first time are marked as delivered at now.
+	GetInboxByUserID(ctx context.Context, userID uuid.UUID, now time.Time) ([]dtos.InboxNote, error)
+
+	// GetRequiresApprovalBySlug returns whether the unread note requires approval of its author to be read.
+	// Returns [models.ErrNoteNotFound] if note is not found, or is read.
+	GetRequiresApprovalBySlug(ctx context.Context, slug dtos.NoteSlug) (bool, error)
+
+	// CreateAccessRequest creates the reader's access request to the note, only hash of its token is stored.
+	// Returns id of the request, or [models.ErrNoteNotFound] if note is not found.
+	CreateAccessRequest(
+		ctx context.Context,
+		slug dtos.NoteSlug,
+		tokenHash string,
+		req models.NoteAccessRequest,
+	) (uuid.UUID, error)
+
+	// GetAccessRequestsByNoteID returns up to limit latest access requests to the note, sorted from the newest one.
+	GetAccessRequestsByNoteID(ctx context.Context, noteID uuid.UUID, limit int) ([]models.NoteAccessRequest, error)
+
+	// DecideAccessRequest sets status of the pending access request to the note, if it hasn't expired at decidedAt,
+	// and sets when the request expires.
+	// Returns the decided request, [models.ErrNoteAccessRequestNotFound] if the request is not found,
+	// or [models.ErrNoteAccessRequestNotPending] if it's already decided, or has expired.
+	DecideAccessRequest(
+		ctx context.Context,
+		noteID, requestID uuid.UUID,
+		status models.NoteAccessRequestStatus,
+		decidedAt, expiresAt time.Time,
+	) (models.NoteAccessRequest, error)
+
+	// UseAccessRequest marks the approved access request to the note, with the token hash, as used at now.
+	// Returns [models.ErrNoteApprovalRequired] if there's no such approved request, that's unused and unexpired.
+	UseAccessRequest(ctx context.Context, slug dtos.NoteSlug, tokenHash string, now time.Time) error
+
+	// PurgeExpiredContent deletes content of notes that expired before now, but keeps their metadata.
+	// Returns purged notes, the author's email is set only for the ones that expired without being viewed,
+	// and which authors want to be notified about it.
+	PurgeExpiredContent(ctx context.Context, now time.Time) ([]dtos.ExpiredNote, error)
+
+	// DeleteStale deletes notes that were read or expired before the specified time.
+	// Returns number of deleted notes.
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+
+	// ReencryptContent re-encrypts content of all notes that are not encrypted with the current key,
+	// including ones stored before the encryption was enabled, and the shared content of notes with recipients.
+	// Notes are processed in batches of batchSize, returns number of re-encrypted notes.
+	ReencryptContent(ctx context.Context, batchSize int) (int64, error)
 }
 
 var _ NoteStorer = (*NoteRepo)(nil)
 
 type NoteRepo struct {
-	db *psqlutil.DB
+	db  *psqlutil.DB
+	enc envelope.Encryptor
 }
 
-func New(db *psqlutil.DB) *NoteRepo {
-	return &NoteRepo{db}
+// noteContentColumns selects content of notes aliased as "n", and id of the key it's encrypted with,
+// the content of notes with recipients is stored once, and joined with [noteContentJoin].
+const (
+	noteContentColumns = "coalesce(c.content, n.content), coalesce(c.content_key_id, n.content_key_id)"
+	noteContentJoin    = "note_contents c on c.id = n.content_id"
+	noteInboxJoin      = "users iu on iu.id = n.inbox_user_id"
+
+	// noteInboxRecipientDeletedColumn selects whether the user the note was sent to has deleted their account.
+	noteInboxRecipientDeletedColumn = "n.sent_to_inbox and n.inbox_user_id is null"
+)
+
+// deleteUnreferencedContentsQuery deletes content of notes with recipients, by its ids,
+// once every one of them is burnt, expired, or deleted.
+const deleteUnreferencedContentsQuery = `--sql
+delete from note_contents c
+where c.id = any($1::uuid[])
+  and not exists (select 1 from notes n where n.content_id = c.id)`
+
+// deleteUnreferencedContents deletes content the notes were linked to, unless other notes still link to it,
+// it should be called in the same transaction the links are removed in.
+func deleteUnreferencedContents(ctx context.Context, tx pgx.Tx, contentIDs []string) error {
+	if len(contentIDs) == 0 {
+		return nil
+	}
+
+	// the content is locked, so of concurrent transactions that unlink the same content,
+	// the last one sees links removed by the others, and deletes it
+	_, err := tx.Exec(ctx,
+		"select from note_contents where id = any($1::uuid[]) order by id for update",
+		contentIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, deleteUnreferencedContentsQuery, contentIDs)
+	return err
 }
 
-func (s *NoteRepo) Create(ctx context.Context, inp models.Note) error {
-	query, args, err := pgq.
+// collectContentIDs collects ids of content from rows with the single, nullable, content_id column.
+func collectContentIDs(rows pgx.Rows) ([]string, error) {
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[*string])
+	if err != nil {
+		return nil, err
+	}
+
+	var contentIDs []string
+	for _, id := range ids {
+		if id != nil {
+			contentIDs = append(contentIDs, *id)
+		}
+	}
+
+	return contentIDs, nil
+}
+
+// queryContentIDs runs the query, that returns content_id of notes it unlinks from their content.
+func queryContentIDs(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectContentIDs(rows)
+}
+
+// New creates a [NoteRepo], notes' content is encrypted at rest with enc.
+func New(db *psqlutil.DB, enc envelope.Encryptor) *NoteRepo {
+	return &NoteRepo{
+		db:  db,
+		enc: enc,
+	}
+}
+
+func (s *NoteRepo) Create(ctx context.Context, inp NewNote) error {
+	query, args, err := s.insertNoteQuery(ctx, inp.Note)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := checkActiveNotes(ctx, tx, inp); err != nil {
+		return err
+	}
+
+	var noteID uuid.UUID
+	err = tx.QueryRow(ctx, query+" returning id", args...).Scan(&noteID)
+	if psqlutil.IsDuplicateErr(err, "notes_slug_key") {
+		return models.ErrNoteSlugIsAlreadyInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	if inp.ManagementTokenHash != "" {
+		_, err = tx.Exec(ctx,
+			"update notes set management_token_hash = $2 where id = $1",
+			noteID, inp.ManagementTokenHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range inp.Attachments {
+		_, err := tx.Exec(ctx, `--sql
+insert into note_attachments (id, note_id, filename, content_type, size, content_key_id, created_at)
+values ($1, $2, $3, $4, $5, $6, $7)`,
+			a.ID, noteID, a.Filename, a.ContentType, a.Size, a.ContentKeyID, a.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if inp.AuthorID.IsNil() {
+		_, err = tx.Exec(ctx,
+			"insert into notes_anonymous_authors (note_id, ip_hash) values ($1, $2)",
+			noteID, inp.AnonymousIPHash)
+	} else {
+		_, err = tx.Exec(ctx,
+			"insert into notes_authors (note_id, user_id) values ($1, $2)",
+			noteID, inp.AuthorID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if inp.Switch != nil {
+		_, err = tx.Exec(ctx, `--sql
+insert into note_switches (note_id, check_in_interval_seconds, recipients, last_check_in_at)
+values ($1, $2, $3, $4)`,
+			noteID, int64(inp.Switch.CheckInInterval.Seconds()), inp.Switch.Recipients, inp.Switch.LastCheckInAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *NoteRepo) CreateWithRecipients(
+	ctx context.Context,
+	inp NewNote,
+	recipients models.NoteRecipients,
+) error {
+	content, contentKeyID, err := s.enc.Encrypt(ctx, inp.Note.Content)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := checkActiveNotes(ctx, tx, inp); err != nil {
+		return err
+	}
+
+	var contentID uuid.UUID
+	err = tx.QueryRow(ctx,
+		"insert into note_contents (content, content_key_id) values ($1, $2) returning id",
+		content, contentKeyID).Scan(&contentID)
+	if err != nil {
+		return err
+	}
+
+	// the content's id is used as id of the group, since it's kept after the content is deleted
+	builder := pgq.
 		Insert("notes").
-		Columns("content", "slug", "password", "keep_before_expiration", "created_at", "expires_at").
-		Values(inp.Content, inp.Slug, inp.Password, inp.KeepBeforeExpiration, inp.CreatedAt, inp.ExpiresAt).
-		SQL()
+		Columns(
+			"content", "content_id", "group_id", "recipient_label", "slug", "password", "keep_before_expiration",
+			"created_at", "expires_at", "encryption_scheme", "encryption_version", "max_views", "views_left",
+			"notify_author", "burn_on_lockout", "allow_reply", "available_from", "requires_approval",
+			"management_token_hash",
+		)
+	note := inp.Note
+	for _, r := range recipients {
+		builder = builder.Values(
+			"", contentID, contentID, r.Label, r.Slug, note.Password, note.KeepBeforeExpiration,
+			note.CreatedAt, note.ExpiresAt, note.EncryptionScheme, note.EncryptionVersion, note.MaxViews, note.ViewsLeft,
+			note.NotifyAuthor, note.BurnOnLockout, note.AllowReply, psqlutil.TimeToNullTime(note.AvailableFrom),
+			note.RequiresApproval, sql.NullString{String: inp.ManagementTokenHash, Valid: inp.ManagementTokenHash != ""},
+		)
+	}
+
+	query, args, err := builder.Suffix("returning id").SQL()
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	noteIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if psqlutil.IsDuplicateErr(err, "notes_slug_key") {
+		return models.ErrNoteSlugIsAlreadyInUse
+	}
+	if err != nil {
+		return err
+	}
+
+	if inp.AuthorID.IsNil() {
+		_, err = tx.Exec(ctx,
+			"insert into notes_anonymous_authors (note_id, ip_hash) select unnest($1::uuid[]), $2",
+			noteIDs, inp.AnonymousIPHash)
+	} else {
+		_, err = tx.Exec(ctx,
+			"insert into notes_authors (note_id, user_id) select unnest($1::uuid[]), $2",
+			noteIDs, inp.AuthorID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// checkActiveNotes checks whether the note fits into [NewNote.MaxActiveNotes] of its author, or of the ip.
+// Notes of the author are locked until the transaction ends, so notes created concurrently are counted
+// one after another, it should be called in the transaction the note is created in, before it's created.
+func checkActiveNotes(ctx context.Context, tx pgx.Tx, inp NewNote) error {
+	if inp.MaxActiveNotes <= 0 {
+		return nil
+	}
+
+	query, subject, lockKey := activeNotesByAuthorQuery, any(inp.AuthorID), "user:"+inp.AuthorID.String()
+	if inp.AuthorID.IsNil() {
+		query, subject, lockKey = activeNotesByAnonymousAuthorQuery, inp.AnonymousIPHash, "ip:"+inp.AnonymousIPHash
+	}
+
+	if _, err := tx.Exec(ctx, "select pg_advisory_xact_lock(hashtextextended($1, 0))", lockKey); err != nil {
+		return err
+	}
+
+	var active int64
+	if err := tx.QueryRow(ctx, query, subject, inp.Note.CreatedAt).Scan(&active); err != nil {
+		return err
+	}
+
+	if active >= int64(inp.MaxActiveNotes) {
+		return models.ErrQuotaActiveNotesExceeded
+	}
+
+	return nil
+}
+
+func (s *NoteRepo) CreateReply(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	reply models.Note,
+	now time.Time,
+) error {
+	query, args, err := s.insertNoteQuery(ctx, reply)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.db.Exec(ctx, query, args...)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// the note is locked, so concurrent readers cannot reply to it both
+	var noteID, authorID uuid.UUID
+	var replySlug sql.NullString
+	err = tx.QueryRow(ctx, `--sql
+select n.id, na.user_id, n.reply_slug
+from notes n
+inner join notes_authors na on na.note_id = n.id
+where n.slug = $1
+  and n.allow_reply
+  and (n.read_at is not null or n.expires_at <= 'epoch' or n.expires_at > $2)
+  and (n.available_from is null or n.available_from <= $2)
+for update of n`, slug, now).Scan(&noteID, &authorID, &replySlug)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNoteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if replySlug.Valid {
+		return models.ErrNoteAlreadyReplied
+	}
+
+	var replyID uuid.UUID
+	err = tx.QueryRow(ctx, query+" returning id", args...).Scan(&replyID)
 	if psqlutil.IsDuplicateErr(err, "notes_slug_key") {
 		return models.ErrNoteSlugIsAlreadyInUse
 	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "insert into notes_authors (note_id, user_id) values ($1, $2)", replyID, authorID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "update notes set reply_slug = $1 where id = $2", reply.Slug, noteID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// insertNoteQuery returns query that inserts the note, its content is encrypted.
+func (s *NoteRepo) insertNoteQuery(ctx context.Context, inp models.Note) (string, []any, error) {
+	content, contentKeyID, err := s.enc.Encrypt(ctx, inp.Content)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return pgq.
+		Insert("notes").
+		Columns(
+			"content", "content_key_id", "slug", "password", "keep_before_expiration", "created_at", "expires_at",
+			"encryption_scheme", "encryption_version", "max_views", "views_left", "notify_author", "burn_on_lockout",
+			"allow_reply", "available_from", "bound_email", "inbox_user_id", "sent_to_inbox", "requires_approval",
+		).
+		Values(
+			content, contentKeyID, inp.Slug, inp.Password, inp.KeepBeforeExpiration, inp.CreatedAt, inp.ExpiresAt,
+			inp.EncryptionScheme, inp.EncryptionVersion, inp.MaxViews, inp.ViewsLeft, inp.NotifyAuthor, inp.BurnOnLockout,
+			inp.AllowReply, psqlutil.TimeToNullTime(inp.AvailableFrom), inp.BoundEmail,
+			uuid.NullUUID{UUID: inp.InboxUserID, Valid: !inp.InboxUserID.IsNil()}, !inp.InboxUserID.IsNil(),
+			inp.RequiresApproval,
+		).
+		SQL()
+}
+
+func (s *NoteRepo) GetBySlug(ctx context.Context, slug dtos.NoteSlug) (models.Note, error) {
+	query, args, err := pgq.
+		Select(
+			noteContentColumns, "n.slug", "n.keep_before_expiration", "n.read_at", "n.created_at", "n.expires_at",
+			"n.encryption_scheme", "n.encryption_version", "n.max_views", "n.views_left", "n.allow_reply",
+			"coalesce(n.reply_slug, '')", "n.available_from", "n.bound_email", "n.inbox_user_id",
+			noteInboxRecipientDeletedColumn, "n.requires_approval",
+		).
+		From("notes n").
+		LeftJoin(noteContentJoin).
+		Where("(n.password is null or n.password = '')").
+		Where(pgq.Eq{"n.slug": slug}).
+		SQL()
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	var note models.Note
+	var readAt, availableFrom sql.NullTime
+	var inboxUserID uuid.NullUUID
+	var contentKeyID string
+	err = s.db.QueryRow(ctx, query, args...).
+		Scan(&note.Content, &contentKeyID, &note.Slug, &note.KeepBeforeExpiration, &readAt, &note.CreatedAt,
+			&note.ExpiresAt, &note.EncryptionScheme, &note.EncryptionVersion, &note.MaxViews, &note.ViewsLeft,
+			&note.AllowReply, &note.ReplySlug, &availableFrom, &note.BoundEmail, &inboxUserID,
+			&note.InboxRecipientDeleted, &note.RequiresApproval)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Note{}, models.ErrNoteNotFound
+	}
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	note.ReadAt = psqlutil.NullTimeToTime(readAt)
+	note.AvailableFrom = psqlutil.NullTimeToTime(availableFrom)
+	note.InboxUserID = inboxUserID.UUID
+	note.Content, err = s.enc.Decrypt(ctx, note.Content, contentKeyID)
+
+	return note, err
+}
+
+func (s *NoteRepo) GetMetadataBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	userID uuid.UUID,
+) (dtos.NoteMetadata, error) {
+	// inbox notes are only visible to their recipient, and to no one once the recipient is deleted
+	query := `--sql
+select n.created_at, (n.password is not null and n.password <> '') has_password, n.read_at,
+  n.encryption_scheme, n.views_left, n.bound_email <> '' code_required, n.requires_approval
+from notes n
+where slug = $1
+  and (not n.sent_to_inbox or n.inbox_user_id = $2)`
+
+	var readAt sql.NullTime
+	var metadata dtos.NoteMetadata
+	err := s.db.QueryRow(ctx, query, slug, userID).
+		Scan(&metadata.CreatedAt, &metadata.HasPassword, &readAt, &metadata.EncryptionScheme, &metadata.ViewsLeft,
+			&metadata.CodeRequired, &metadata.ApprovalRequired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dtos.NoteMetadata{}, models.ErrNoteNotFound
+	}
+
+	if !psqlutil.NullTimeToTime(readAt).IsZero() {
+		return dtos.NoteMetadata{}, models.ErrNoteNotFound
+	}
+
+	return metadata, err
+}
+
+func (s *NoteRepo) ListByAuthorID(
+	ctx context.Context,
+	authorID uuid.UUID,
+	inp models.NoteListQuery,
+	now time.Time,
+) ([]models.Note, error) {
+	sortExpr := noteSortExprs[inp.SortBy]
+	cmp, order := ">", "asc"
+	if inp.Order == models.SortOrderDesc {
+		cmp, order = "<", "desc"
+	}
+
+	builder := whereNoteListFilter(
+		pgq.
+			Select(
+				"n.id", noteContentColumns, "n.slug", "n.keep_before_expiration", "n.password",
+				"n.read_at", "n.created_at", "n.expires_at", "n.encryption_scheme", "n.encryption_version",
+				"n.max_views", "n.views_left", "n.notify_author", "n.burn_on_lockout", "n.allow_reply",
+				"coalesce(n.reply_slug, '')", "n.group_id", "n.recipient_label", "n.available_from", "n.version",
+				"coalesce(iu.email, '')", "n.inbox_delivered_at", noteInboxRecipientDeletedColumn,
+			).
+			From("notes n").
+			LeftJoin(noteContentJoin).
+			LeftJoin(noteInboxJoin).
+			InnerJoin("notes_authors na on n.id = na.note_id").
+			Where(pgq.Eq{"na.user_id": authorID}),
+		inp.Filter,
+		now,
+	)
+
+	// keyset pagination, notes with the same sort value are ordered by id
+	if inp.After != nil {
+		builder = builder.Where(
+			"("+sortExpr+", n.id) "+cmp+" (?, ?)",
+			inp.After.SortValue, inp.After.ID,
+		)
+	}
+
+	query, args, err := builder.
+		OrderBy(sortExpr+" "+order, "n.id "+order).
+		Limit(uint64(inp.Limit)). //nolint:gosec // limit is validated
+		SQL()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getAllNotes(ctx, query, args...)
+}
+
+func (s *NoteRepo) GetCountByAuthorID(
+	ctx context.Context,
+	authorID uuid.UUID,
+	filter models.NoteListFilter,
+	now time.Time,
+) (int64, error) {
+	query, args, err := whereNoteListFilter(
+		pgq.
+			Select("count(*)").
+			From("notes n").
+			InnerJoin("notes_authors na on n.id = na.note_id").
+			Where(pgq.Eq{"na.user_id": authorID}),
+		filter,
+		now,
+	).SQL()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = s.db.QueryRow(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+func (s *NoteRepo) GetCountOfNotesByAuthorID(
+	ctx context.Context,
+	authorID uuid.UUID,
+) (int64, error) {
+	var count int64
+	err := s.db.QueryRow(
+		ctx,
+		`select count(*) from notes_authors where user_id = $1`,
+		authorID.String(),
+	).Scan(&count)
+
+	return count, err
+}
+
+// activeNotesByAuthorQuery counts notes of the author that are neither read nor expired,
+// notes with recipients are counted once, the args are the author's id, and the current time.
+const activeNotesByAuthorQuery = `--sql
+select count(distinct coalesce(n.group_id, n.id))
+from notes n
+inner join notes_authors na on n.id = na.note_id
+where na.user_id = $1
+  and n.read_at is null
+  and (n.expires_at <= 'epoch' or n.expires_at > $2)`
+
+// activeNotesByAnonymousAuthorQuery is [activeNotesByAuthorQuery] for notes created without an author,
+// the args are hash of the ip they're created from, and the current time.
+const activeNotesByAnonymousAuthorQuery = `--sql
+select count(distinct coalesce(n.group_id, n.id))
+from notes n
+inner join notes_anonymous_authors naa on n.id = naa.note_id
+where naa.ip_hash = $1
+  and n.read_at is null
+  and (n.expires_at <= 'epoch' or n.expires_at > $2)`
+
+func (s *NoteRepo) GetCountOfActiveNotesByAuthorID(
+	ctx context.Context,
+	authorID uuid.UUID,
+	now time.Time,
+) (int64, error) {
+	var count int64
+	err := s.db.QueryRow(ctx, activeNotesByAuthorQuery, authorID, now).Scan(&count)
+	return count, err
+}
+
+func (s *NoteRepo) GetCountOfActiveNotesByAnonymousAuthor(
+	ctx context.Context,
+	ipHash string,
+	now time.Time,
+) (int64, error) {
+	var count int64
+	err := s.db.QueryRow(ctx, activeNotesByAnonymousAuthorQuery, ipHash, now).Scan(&count)
+	return count, err
+}
+
+func (s *NoteRepo) GetBySlugWithPassword(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+) (models.Note, error) {
+	query, args, err := pgq.
+		Select(
+			noteContentColumns, "n.slug", "n.password", "n.keep_before_expiration", "n.read_at", "n.created_at",
+			"n.expires_at", "n.encryption_scheme", "n.encryption_version", "n.max_views", "n.views_left",
+			"n.burn_on_lockout", "n.allow_reply", "coalesce(n.reply_slug, '')", "n.available_from", "n.bound_email",
+			"n.inbox_user_id", noteInboxRecipientDeletedColumn, "n.requires_approval",
+		).
+		From("notes n").
+		LeftJoin(noteContentJoin).
+		Where("(n.password is not null and n.password <> '')").
+		Where(pgq.Eq{"n.slug": slug}).
+		SQL()
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	var note models.Note
+	var readAt, availableFrom sql.NullTime
+	var inboxUserID uuid.NullUUID
+	var contentKeyID string
+	err = s.db.QueryRow(ctx, query, args...).
+		Scan(&note.Content, &contentKeyID, &note.Slug, &note.Password, &note.KeepBeforeExpiration, &readAt,
+			&note.CreatedAt, &note.ExpiresAt, &note.EncryptionScheme, &note.EncryptionVersion, &note.MaxViews,
+			&note.ViewsLeft, &note.BurnOnLockout, &note.AllowReply, &note.ReplySlug, &availableFrom, &note.BoundEmail,
+			&inboxUserID, &note.InboxRecipientDeleted, &note.RequiresApproval)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Note{}, models.ErrNoteNotFound
+	}
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	note.ReadAt = psqlutil.NullTimeToTime(readAt)
+	note.AvailableFrom = psqlutil.NullTimeToTime(availableFrom)
+	note.InboxUserID = inboxUserID.UUID
+	note.Content, err = s.enc.Decrypt(ctx, note.Content, contentKeyID)
+
+	return note, err
+}
+
+func (s *NoteRepo) UpdateExpirationTimeSettingsBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	patch dtos.PatchNote,
+	authorID uuid.UUID,
+) error {
+	query := `--sql
+update notes n
+set keep_before_expiration = COALESCE($1, n.keep_before_expiration),
+    expires_at = COALESCE($2, n.expires_at),
+    available_from = case when $3::boolean then $4::timestamptz else n.available_from end
+from notes_authors na
+where n.slug = $5
+  and na.user_id = $6
+  and na.note_id = n.id`
+
+	// zero available from makes the note available right away
+	var availableFrom sql.NullTime
+	if patch.AvailableFrom != nil {
+		availableFrom = psqlutil.TimeToNullTime(*patch.AvailableFrom)
+	}
+
+	ct, err := s.db.Exec(ctx, query,
+		patch.KeepBeforeExpiration, patch.ExpiresAt,
+		patch.AvailableFrom != nil, availableFrom,
+		slug, authorID.String())
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrNoteNotFound
+	}
+
+	return nil
+}
+
+func (s *NoteRepo) RemoveBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	readAt time.Time,
+) error {
+	// note's attachments are detached, so their blobs are deleted along with the content
+	query := `--sql
+with removed as (
+  update notes n
+  set content = '',
+      content_key_id = '',
+      content_id = null,
+      read_at = $1
+  from notes o
+  where o.id = n.id
+    and n.slug = $2
+    and n.read_at is null
+  returning n.id, o.content_id
+), detached as (
+  update note_attachments
+  set note_id = null
+  where note_id in (select id from removed)
+)
+select content_id from removed`
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, query, readAt, slug)
+	if err != nil {
+		return err
+	}
+
+	contentIDs, err := collectContentIDs(rows)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteUnreferencedContents(ctx, tx, contentIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *NoteRepo) ConsumeBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	passwd string,
+	accessTokenHash string,
+	readAt time.Time,
+) (models.Note, error) {
+	// the self-join is used to get the note's state before the update.
+	// concurrent updates of the same row are serialized by postgres, and the
+	// "read_at is null" condition is re-evaluated for all of them except the first one.
+	// expiration and availability are checked once more, since they could've changed after the note was checked.
+	query := `--sql
+update notes n
+set views_left = greatest(n.views_left - 1, 0),
+    content = case when n.views_left <= 1 then '' else n.content end,
+    content_key_id = case when n.views_left <= 1 then '' else n.content_key_id end,
+    content_id = case when n.views_left <= 1 then null else n.content_id end,
+    read_at = case when n.views_left <= 1 then $1::timestamptz end,
+    version = n.version + 1
+from notes o
+left join note_contents c on c.id = o.content_id
+where o.id = n.id
+  and n.slug = $2
+  and n.read_at is null
+  and (n.expires_at <= 'epoch' or n.expires_at > $1)
+  and (n.available_from is null or n.available_from <= $1)
+  and coalesce(n.password, '') = $3
+returning coalesce(c.content, o.content), coalesce(c.content_key_id, o.content_key_id), o.slug,
+  o.keep_before_expiration, o.created_at, o.expires_at, o.encryption_scheme, o.encryption_version,
+  n.max_views, n.views_left, n.allow_reply, coalesce(n.reply_slug, ''), n.group_id, o.content_id`
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return models.Note{}, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if accessTokenHash != "" {
+		ct, err := tx.Exec(ctx, useAccessRequestQuery, slug, accessTokenHash, readAt)
+		if err != nil {
+			return models.Note{}, err
+		}
+
+		if ct.RowsAffected() == 0 {
+			return models.Note{}, models.ErrNoteApprovalRequired
+		}
+	}
+
+	var note models.Note
+	var groupID uuid.NullUUID
+	var contentKeyID string
+	var contentID *string
+	err = tx.QueryRow(ctx, query, readAt, slug, passwd).
+		Scan(&note.Content, &contentKeyID, &note.Slug, &note.KeepBeforeExpiration, &note.CreatedAt,
+			&note.ExpiresAt, &note.EncryptionScheme, &note.EncryptionVersion, &note.MaxViews, &note.ViewsLeft,
+			&note.AllowReply, &note.ReplySlug, &groupID, &contentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Note{}, models.ErrNoteNotFound
+	}
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	note.GroupID = groupID.UUID
+	note.Content, err = s.enc.Decrypt(ctx, note.Content, contentKeyID)
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	// the content is already returned, so it's deleted if this was the last recipient who hasn't read it
+	if contentID != nil && note.ViewsLeft == 0 {
+		if err := deleteUnreferencedContents(ctx, tx, []string{*contentID}); err != nil {
+			return models.Note{}, err
+		}
+	}
+
+	return note, tx.Commit(ctx)
+}
+
+func (s *NoteRepo) MarkViewedBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	viewedAt time.Time,
+) (bool, error) {
+	// every view bumps the version, so the content isn't replaced under readers,
+	// and the view is the first one only if it's the one that has set viewed_at
+	query := `--sql
+update notes
+set viewed_at = coalesce(viewed_at, $1),
+    version = version + 1
+where slug = $2
+returning viewed_at = $1`
+
+	var first bool
+	err := s.db.QueryRow(ctx, query, viewedAt, slug).Scan(&first)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	return first, err
+}
+
+func (s *NoteRepo) DeleteNoteBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	authorID uuid.UUID,
+) error {
+	query := `--sql
+delete from notes n
+using notes_authors na
+where n.slug = $1
+  and na.note_id = n.id
+  and na.user_id = $2
+returning n.content_id`
+
+	return s.deleteNote(ctx, query, slug, authorID.String())
+}
+
+// deleteNote deletes the note with the query, that returns its content_id,
+// and the content, if no other note links to it.
+// Returns [models.ErrNoteNotFound] if the query deletes nothing.
+func (s *NoteRepo) deleteNote(ctx context.Context, query string, args ...any) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var contentID *string
+	err = tx.QueryRow(ctx, query, args...).Scan(&contentID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrNoteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if contentID != nil {
+		if err := deleteUnreferencedContents(ctx, tx, []string{*contentID}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *NoteRepo) BulkUpdateByAuthorID(
+	ctx context.Context,
+	authorID uuid.UUID,
+	inp models.NoteBulkQuery,
+	now time.Time,
+) ([]models.NoteBulkResult, error) {
+	builder := pgq.
+		Select("n.id", "n.slug").
+		From("notes n").
+		InnerJoin("notes_authors na on n.id = na.note_id").
+		Where(pgq.Eq{"na.user_id": authorID})
+
+	// whether the action can be applied to the note, any note can be deleted,
+	// but only unread and unexpired ones can be changed,
+	// and notes cannot expire before they're available, see [models.Note.ValidateAvailability]
+	switch inp.Action {
+	case models.NoteBulkActionDelete:
+		builder = builder.Column("true")
+	case models.NoteBulkActionSetExpiration:
+		builder = builder.Column(
+			"n.read_at is null and (n.expires_at <= 'epoch' or n.expires_at > ?)"+
+				" and (n.available_from is null or n.available_from < ?)",
+			now, inp.ExpiresAt)
+	default:
+		builder = builder.Column("n.read_at is null and (n.expires_at <= 'epoch' or n.expires_at > ?)", now)
+	}
+
+	if inp.Filter != nil {
+		builder = whereNoteListFilter(builder, *inp.Filter, now)
+	} else {
+		builder = builder.Where(pgq.Eq{"n.slug": inp.Slugs})
+	}
+
+	query, args, err := builder.
+		OrderBy("n.created_at", "n.id").
+		Suffix("for update of n").
+		SQL()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		results []models.NoteBulkResult
+		ids     []string
+	)
+	for rows.Next() {
+		var id uuid.UUID
+		var slug string
+		var applicable bool
+		if err := rows.Scan(&id, &slug, &applicable); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		status := models.NoteBulkStatusSkipped
+		if applicable {
+			status = models.NoteBulkStatusDone
+			ids = append(ids, id.String())
+		}
+
+		results = append(results, models.NoteBulkResult{Slug: slug, Status: status})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	var contentIDs []string
+	switch inp.Action {
+	case models.NoteBulkActionDelete:
+		contentIDs, err = queryContentIDs(ctx, tx,
+			"delete from notes where id = any($1::uuid[]) returning content_id", ids)
+	case models.NoteBulkActionExpire:
+		_, err = tx.Exec(ctx, "update notes set expires_at = $2 where id = any($1::uuid[])", ids, now)
+	case models.NoteBulkActionSetExpiration:
+		_, err = tx.Exec(ctx, "update notes set expires_at = $2 where id = any($1::uuid[])", ids, inp.ExpiresAt)
+	case models.NoteBulkActionSetPassword:
+		_, err = tx.Exec(ctx, "update notes set password = $2 where id = any($1::uuid[])", ids, inp.Password)
+	case models.NoteBulkActionBurn:
+		// the same way as in [NoteRepo.RemoveBySlug], attachments of burnt notes are detached
+		contentIDs, err = queryContentIDs(ctx, tx, `--sql
+with burnt as (
+  update notes n
+  set content = '',
+      content_key_id = '',
+      content_id = null,
+      views_left = 0,
+      read_at = $2
+  from notes o
+  where o.id = n.id
+    and n.id = any($1::uuid[])
+  returning n.id, o.content_id
+), detached as (
+  update note_attachments
+  set note_id = null
+  where note_id in (select id from burnt)
+)
+select content_id from burnt`, ids, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := deleteUnreferencedContents(ctx, tx, contentIDs); err != nil {
+		return nil, err
+	}
+
+	return results, tx.Commit(ctx)
+}
+
+func (s *NoteRepo) SetAuthorIDBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	authorID uuid.UUID,
+) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var noteID uuid.UUID
+	err = tx.QueryRow(ctx, "select id from notes where slug = $1", slug).Scan(&noteID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrNoteNotFound
+		}
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"insert into notes_authors (note_id, user_id) values ($1, $2)",
+		noteID, authorID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *NoteRepo) GetByAuthorIDAndSlug(
+	ctx context.Context,
+	authorID uuid.UUID,
+	slug dtos.NoteSlug,
+) (models.Note, error) {
+	query := `--sql
+select n.id, n.slug, n.keep_before_expiration, n.password, n.read_at, n.created_at, n.expires_at,
+  n.encryption_scheme, n.encryption_version, n.max_views, n.views_left, n.notify_author, n.group_id,
+  n.recipient_label, n.available_from, n.version, n.viewed_at
+from notes n
+inner join notes_authors na on n.id = na.note_id
+where n.slug = $1
+  and na.user_id = $2`
+
+	var note models.Note
+	var readAt, availableFrom, viewedAt sql.NullTime
+	var groupID uuid.NullUUID
+	err := s.db.QueryRow(ctx, query, slug, authorID.String()).
+		Scan(&note.ID, &note.Slug, &note.KeepBeforeExpiration, &note.Password, &readAt, &note.CreatedAt,
+			&note.ExpiresAt, &note.EncryptionScheme, &note.EncryptionVersion, &note.MaxViews, &note.ViewsLeft,
+			&note.NotifyAuthor, &groupID, &note.RecipientLabel, &availableFrom, &note.Version, &viewedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Note{}, models.ErrNoteNotFound
+	}
+	if err != nil {
+		return models.Note{}, err
+	}
+
+	note.ReadAt = psqlutil.NullTimeToTime(readAt)
+	note.AvailableFrom = psqlutil.NullTimeToTime(availableFrom)
+	note.ViewedAt = psqlutil.NullTimeToTime(viewedAt)
+	note.GroupID = groupID.UUID
+
+	return note, nil
+}
+
+func (s *NoteRepo) UpdateContentBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	authorID uuid.UUID,
+	content string,
+	version int,
+	now time.Time,
+) (int, error) {
+	encrypted, contentKeyID, err := s.enc.Encrypt(ctx, content)
+	if err != nil {
+		return 0, err
+	}
+
+	// readers bump the version as well, so the content is never replaced under them
+	query := `--sql
+update notes n
+set content = $1,
+    content_key_id = $2,
+    version = n.version + 1
+from notes_authors na
+where n.slug = $3
+  and na.user_id = $4
+  and na.note_id = n.id
+  and n.version = $5
+  and n.group_id is null
+  and n.read_at is null
+  and n.views_left = n.max_views
+  and n.viewed_at is null
+  and (n.expires_at <= 'epoch' or n.expires_at > $6)
+returning n.version`
+
+	var newVersion int
+	err = s.db.QueryRow(ctx, query, encrypted, contentKeyID, slug, authorID.String(), version, now).
+		Scan(&newVersion)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, models.ErrNoteVersionMismatch
+	}
+
+	return newVersion, err
+}
+
+func (s *NoteRepo) UpdatePasswordBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	authorID uuid.UUID,
+	passwd string,
+) error {
+	query := `--sql
+update notes n
+set password = $1
+from notes_authors na
+where n.slug = $2
+  and na.user_id = $3
+  and na.note_id = n.id`
+
+	ct, err := s.db.Exec(ctx, query, passwd, slug, authorID.String())
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrNoteNotFound
+	}
+
+	return nil
+}
+
+func (s *NoteRepo) UpdateNotifyAuthorBySlug(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	authorID uuid.UUID,
+	notify bool,
+) error {
+	query := `--sql
+update notes n
+set notify_author = $1
+from notes_authors na
+where n.slug = $2
+  and na.user_id = $3
+  and na.note_id = n.id`
+
+	ct, err := s.db.Exec(ctx, query, notify, slug, authorID.String())
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrNoteNotFound
+	}
+
+	return nil
+}
+
+func (s *NoteRepo) GetNotifiableAuthorEmailBySlug(ctx context.Context, slug dtos.NoteSlug) (string, error) {
+	query := `--sql
+select u.email
+from notes n
+inner join notes_authors na on na.note_id = n.id
+inner join users u on u.id = na.user_id
+where n.slug = $1
+  and n.notify_author`
+
+	var email string
+	err := s.db.QueryRow(ctx, query, slug).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", models.ErrNoteNotFound
+	}
+
+	return email, err
+}
+
+func (s *NoteRepo) GetAuthorIDBySlug(ctx context.Context, slug dtos.NoteSlug) (uuid.UUID, error) {
+	query := `--sql
+select na.user_id
+from notes n
+inner join notes_authors na on na.note_id = n.id
+where n.slug = $1`
+
+	var authorID uuid.UUID
+	err := s.db.QueryRow(ctx, query, slug).Scan(&authorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, models.ErrNoteNotFound
+	}
+
+	return authorID, err
+}
+
+func (s *NoteRepo) PurgeExpiredContent(ctx context.Context, now time.Time) ([]dtos.ExpiredNote, error) {
+	// views of notes that are kept before expiration aren't counted, so they're unread until viewed
+	query := `--sql
+with purged as (
+  update notes n
+  set content = '',
+      content_key_id = '',
+      content_id = null
+  from notes o
+  where o.id = n.id
+    and (n.content <> '' or n.content_id is not null)
+    and n.expires_at > 'epoch'
+    and n.expires_at < $1
+  returning n.id, n.slug, n.expires_at, n.notify_author, o.content_id,
+    n.max_views = n.views_left and n.viewed_at is null as unread
+)
+select p.slug, p.expires_at, na.user_id, coalesce(u.email, ''), p.content_id
+from purged p
+left join notes_authors na on na.note_id = p.id
+left join users u on u.id = na.user_id and p.notify_author and p.unread`
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []dtos.ExpiredNote
+	var contentIDs []string
+	for rows.Next() {
+		var note dtos.ExpiredNote
+		var authorID uuid.NullUUID
+		var contentID *string
+		if err := rows.Scan(&note.Slug, &note.ExpiresAt, &authorID, &note.AuthorEmail, &contentID); err != nil {
+			return nil, err
+		}
+		note.AuthorID = authorID.UUID
+		notes = append(notes, note)
+		if contentID != nil {
+			contentIDs = append(contentIDs, *contentID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := deleteUnreferencedContents(ctx, tx, contentIDs); err != nil {
+		return nil, err
+	}
+
+	return notes, tx.Commit(ctx)
+}
+
+func (s *NoteRepo) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	query := `--sql
+delete from notes
+where (read_at is not null and read_at < $1)
+   or (expires_at > 'epoch' and expires_at < $1)
+returning content_id`
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	// every deleted note returns a row, even if it has no linked content
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[*string])
+	if err != nil {
+		return 0, err
+	}
+
+	var contentIDs []string
+	for _, id := range ids {
+		if id != nil {
+			contentIDs = append(contentIDs, *id)
+		}
+	}
+
+	if err := deleteUnreferencedContents(ctx, tx, contentIDs); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), tx.Commit(ctx)
+}
+
+func (s *NoteRepo) ReencryptContent(ctx context.Context, batchSize int) (int64, error) {
+	currentKeyID, err := s.enc.CurrentKeyID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, table := range []string{"notes", "note_contents"} {
+		for {
+			selected, reencrypted, err := s.reencryptContentBatch(ctx, table, currentKeyID, batchSize)
+			if err != nil {
+				return total, err
+			}
+
+			total += reencrypted
+			if selected == 0 {
+				break
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// reencryptContentBatch re-encrypts up to batchSize rows of the table that are not encrypted with currentKeyID,
+// the table is either notes, or note_contents.
+// Returns number of selected and re-encrypted rows, they could differ if notes were read
+// or changed concurrently, such notes are picked up by the next batch, if they still need re-encryption.
+func (s *NoteRepo) reencryptContentBatch(
+	ctx context.Context,
+	table string,
+	currentKeyID string,
+	batchSize int,
+) (int, int64, error) {
+	//nolint:gosec // table is not user input
+	query := `--sql
+select id, content, content_key_id
+from ` + table + `
+where content <> ''
+  and content_key_id <> $1
+order by id
+limit $2`
+
+	rows, err := s.db.Query(ctx, query, currentKeyID, batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	type encryptedNote struct {
+		id           uuid.UUID
+		content      string
+		contentKeyID string
+	}
+
+	var notes []encryptedNote
+	for rows.Next() {
+		var note encryptedNote
+		if err := rows.Scan(&note.id, &note.content, &note.contentKeyID); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		notes = append(notes, note)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	//nolint:gosec // table is not user input
+	updateQuery := `--sql
+update ` + table + `
+set content = $1,
+    content_key_id = $2
+where id = $3
+  and content = $4
+  and content_key_id = $5`
+
+	var reencrypted int64
+	for _, note := range notes {
+		plaintext, err := s.enc.Decrypt(ctx, note.content, note.contentKeyID)
+		if err != nil {
+			return len(notes), reencrypted, err
+		}
+
+		content, contentKeyID, err := s.enc.Encrypt(ctx, plaintext)
+		if err != nil {
+			return len(notes), reencrypted, err
+		}
+
+		// the content is compared to make sure that note wasn't read or changed in the meantime
+		ct, err := s.db.Exec(ctx, updateQuery,
+			content, contentKeyID, note.id, note.content, note.contentKeyID)
+		if err != nil {
+			return len(notes), reencrypted, err
+		}
+
+		reencrypted += ct.RowsAffected()
+	}
+
+	return len(notes), reencrypted, nil
+}
+
+func (s *NoteRepo) GetSwitchByNoteID(ctx context.Context, noteID uuid.UUID) (models.NoteSwitch, error) {
+	query := `--sql
+select note_id, check_in_interval_seconds, recipients, last_check_in_at, reminded_at, released_at
+from note_switches
+where note_id = $1`
+
+	var sw models.NoteSwitch
+	var intervalSeconds int64
+	var remindedAt, releasedAt sql.NullTime
+	err := s.db.QueryRow(ctx, query, noteID).
+		Scan(&sw.NoteID, &intervalSeconds, &sw.Recipients, &sw.LastCheckInAt, &remindedAt, &releasedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.NoteSwitch{}, models.ErrNoteSwitchNotFound
+	}
+	if err != nil {
+		return models.NoteSwitch{}, err
+	}
+
+	sw.CheckInInterval = time.Duration(intervalSeconds) * time.Second
+	sw.RemindedAt = psqlutil.NullTimeToTime(remindedAt)
+	sw.ReleasedAt = psqlutil.NullTimeToTime(releasedAt)
+
+	return sw, nil
+}
+
+func (s *NoteRepo) CheckInSwitch(ctx context.Context, noteID uuid.UUID, now time.Time) (time.Time, error) {
+	query := `--sql
+with checked_in as (
+  update note_switches
+  set last_check_in_at = $2,
+      reminded_at = null
+  where note_id = $1
+    and released_at is null
+  returning note_id, last_check_in_at + make_interval(secs => check_in_interval_seconds) release_at
+)
+update notes n
+set available_from = c.release_at
+from checked_in c
+where n.id = c.note_id
+returning n.available_from`
+
+	var releaseAt time.Time
+	err := s.db.QueryRow(ctx, query, noteID, now).Scan(&releaseAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, models.ErrNoteSwitchReleased
+	}
+
+	return releaseAt, err
+}
+
+func (s *NoteRepo) ClaimSwitchReminders(
+	ctx context.Context,
+	now time.Time,
+	remindBefore time.Duration,
+) ([]dtos.NoteSwitchReminder, error) {
+	query := `--sql
+with claimed as (
+  update note_switches sw
+  set reminded_at = $1
+  where sw.released_at is null
+    and sw.reminded_at is null
+    and sw.last_check_in_at + make_interval(secs => sw.check_in_interval_seconds) > $1
+    and sw.last_check_in_at + make_interval(secs => sw.check_in_interval_seconds)
+      - make_interval(secs => least($2, sw.check_in_interval_seconds / 2)) <= $1
+  returning sw.note_id, sw.last_check_in_at + make_interval(secs => sw.check_in_interval_seconds) release_at
+)
+select n.slug, u.email, c.release_at
+from claimed c
+inner join notes n on n.id = c.note_id
+inner join notes_authors na on na.note_id = n.id
+inner join users u on u.id = na.user_id
+where n.read_at is null`
+
+	rows, err := s.db.Query(ctx, query, now, int64(remindBefore.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []dtos.NoteSwitchReminder
+	for rows.Next() {
+		var r dtos.NoteSwitchReminder
+		if err := rows.Scan(&r.Slug, &r.AuthorEmail, &r.ReleaseAt); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, r)
+	}
+
+	return reminders, rows.Err()
+}
+
+func (s *NoteRepo) GetDueSwitches(ctx context.Context, now time.Time) ([]dtos.DueNoteSwitch, error) {
+	query := `--sql
+select n.id, n.slug, na.user_id, u.email,
+  array(select r from unnest(sw.recipients) r where r <> all(sw.released_to))
+from note_switches sw
+inner join notes n on n.id = sw.note_id
+inner join notes_authors na on na.note_id = n.id
+inner join users u on u.id = na.user_id
+where sw.released_at is null
+  and sw.last_check_in_at + make_interval(secs => sw.check_in_interval_seconds) <= $1
+  and n.read_at is null
+  and (n.expires_at <= 'epoch' or n.expires_at > $1)`
+
+	rows, err := s.db.Query(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var switches []dtos.DueNoteSwitch
+	for rows.Next() {
+		var sw dtos.DueNoteSwitch
+		if err := rows.Scan(&sw.NoteID, &sw.Slug, &sw.AuthorID, &sw.AuthorEmail, &sw.Recipients); err != nil {
+			return nil, err
+		}
+		switches = append(switches, sw)
+	}
+
+	return switches, rows.Err()
+}
+
+func (s *NoteRepo) MarkSwitchSentTo(ctx context.Context, noteID uuid.UUID, recipient string) error {
+	query := `--sql
+update note_switches
+set released_to = array_append(released_to, $2)
+where note_id = $1
+  and $2 <> all(released_to)`
+
+	_, err := s.db.Exec(ctx, query, noteID, recipient)
+	return err
+}
+
+func (s *NoteRepo) ReleaseSwitch(ctx context.Context, noteID uuid.UUID, now time.Time) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx,
+		"update note_switches set released_at = $2 where note_id = $1 and released_at is null",
+		noteID, now)
+	if err != nil {
+		return err
+	}
 
-	return err
-}
-
-func (s *NoteRepo) GetBySlug(ctx context.Context, slug dtos.NoteSlug) (models.Note, error) {
-	query, args, err := pgq.
-		Select("content", "slug", "keep_before_expiration", "read_at", "created_at", "expires_at").
-		From("notes").
-		Where("(password is null or password = '')").
-		Where(pgq.Eq{"slug": slug}).
-		SQL()
+	// the note could've been made unavailable for longer by its author in the meantime
+	_, err = tx.Exec(ctx,
+		"update notes set available_from = $2 where id = $1 and available_from > $2",
+		noteID, now)
 	if err != nil {
-		return models.Note{}, err
+		return err
 	}
 
-	var note models.Note
-	var readAt sql.NullTime
-	err = s.db.QueryRow(ctx, query, args...).
-		Scan(&note.Content, &note.Slug, &note.KeepBeforeExpiration, &readAt, &note.CreatedAt, &note.ExpiresAt)
+	return tx.Commit(ctx)
+}
+
+func (s *NoteRepo) GetBoundEmailBySlug(ctx context.Context, slug dtos.NoteSlug) (string, error) {
+	var email string
+	err := s.db.QueryRow(ctx,
+		"select bound_email from notes where slug = $1 and read_at is null and bound_email <> ''",
+		slug).Scan(&email)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return models.Note{}, models.ErrNoteNotFound
+		return "", models.ErrNoteNotFound
 	}
 
-	note.ReadAt = psqlutil.NullTimeToTime(readAt)
-
-	return note, err
+	return email, err
 }
 
-func (s *NoteRepo) GetMetadataBySlug(
-	ctx context.Context,
-	slug dtos.NoteSlug,
-) (dtos.NoteMetadata, error) {
+func (s *NoteRepo) GetAuthorEmailBySlug(ctx context.Context, slug dtos.NoteSlug) (string, error) {
 	query := `--sql
-select n.created_at, (n.password is not null and n.password <> '') has_password, n.read_at
+select u.email
 from notes n
-where slug = $1`
+inner join notes_authors na on na.note_id = n.id
+inner join users u on u.id = na.user_id
+where n.slug = $1`
 
-	var readAt sql.NullTime
-	var metadata dtos.NoteMetadata
-	err := s.db.QueryRow(ctx, query, slug).Scan(&metadata.CreatedAt, &metadata.HasPassword, &readAt)
+	var email string
+	err := s.db.QueryRow(ctx, query, slug).Scan(&email)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return dtos.NoteMetadata{}, models.ErrNoteNotFound
-	}
-
-	if !psqlutil.NullTimeToTime(readAt).IsZero() {
-		return dtos.NoteMetadata{}, models.ErrNoteNotFound
+		return "", models.ErrNoteNotFound
 	}
 
-	return metadata, err
+	return email, err
 }
 
-func (s *NoteRepo) GetAllByAuthorID(
+func (s *NoteRepo) CreateLinkSend(
 	ctx context.Context,
-	authorID uuid.UUID,
-) ([]models.Note, error) {
+	slug dtos.NoteSlug,
+	senderID uuid.UUID,
+	send models.NoteLinkSend,
+) error {
 	query := `--sql
-select n.content, n.slug, n.keep_before_expiration, n.password, n.read_at, n.created_at, n.expires_at
-from notes n
-inner join notes_authors na on n.id = na.note_id
-where na.user_id = $1`
+insert into note_link_sends (note_id, sender_id, recipient_email, sent_at)
+select id, $2, $3, $4
+from notes
+where slug = $1`
 
-	return s.getAllNotes(ctx, query, authorID)
-}
+	ct, err := s.db.Exec(ctx, query, slug, senderID, send.RecipientEmail, send.SentAt)
+	if err != nil {
+		return err
+	}
 
-func (s *NoteRepo) GetAllReadByAuthorID(
-	ctx context.Context,
-	authorID uuid.UUID,
-) ([]models.Note, error) {
-	query := `--sql
-select n.content, n.slug, n.keep_before_expiration, n.password, n.read_at, n.created_at, n.expires_at
-from notes n
-inner join notes_authors na on n.id = na.note_id
-where na.user_id = $1
-	and n.read_at is not null`
+	if ct.RowsAffected() == 0 {
+		return models.ErrNoteNotFound
+	}
 
-	return s.getAllNotes(ctx, query, authorID)
+	return nil
 }
 
-func (s *NoteRepo) GetAllUnreadByAuthorID(
-	ctx context.Context,
-	authorID uuid.UUID,
-) ([]models.Note, error) {
+func (s *NoteRepo) GetLinkSendsByNoteID(ctx context.Context, noteID uuid.UUID) ([]models.NoteLinkSend, error) {
 	query := `--sql
-select n.content, n.slug, n.keep_before_expiration, n.password, n.read_at, n.created_at, n.expires_at
-from notes n
-inner join notes_authors na on n.id = na.note_id
-where na.user_id = $1
-	and n.read_at is null`
+select recipient_email, sent_at
+from note_link_sends
+where note_id = $1
+order by sent_at`
 
-	return s.getAllNotes(ctx, query, authorID)
-}
+	rows, err := s.db.Query(ctx, query, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-func (s *NoteRepo) GetCountOfNotesByAuthorID(
-	ctx context.Context,
-	authorID uuid.UUID,
-) (int64, error) {
-	var count int64
-	err := s.db.QueryRow(
-		ctx,
-		`select count(*) from notes_authors where user_id = $1`,
-		authorID.String(),
-	).Scan(&count)
+	var sends []models.NoteLinkSend
+	for rows.Next() {
+		var send models.NoteLinkSend
+		if err := rows.Scan(&send.RecipientEmail, &send.SentAt); err != nil {
+			return nil, err
+		}
+		sends = append(sends, send)
+	}
 
-	return count, err
+	return sends, rows.Err()
 }
 
-func (s *NoteRepo) GetBySlugAndPassword(
+func (s *NoteRepo) GetByManagementTokenHash(
 	ctx context.Context,
 	slug dtos.NoteSlug,
-	passwd string,
+	tokenHash string,
 ) (models.Note, error) {
-	query, args, err := pgq.
-		Select("content", "slug", "keep_before_expiration", "read_at", "created_at", "expires_at").
-		From("notes").
-		Where(pgq.Eq{
-			"slug":     slug,
-			"password": passwd,
-		}).
-		SQL()
-	if err != nil {
-		return models.Note{}, err
-	}
+	query := `--sql
+select n.id, n.slug, n.keep_before_expiration, n.read_at, n.created_at, n.expires_at,
+  n.max_views, n.views_left, n.available_from
+from notes n
+where n.slug = $1
+  and n.management_token_hash = $2`
 
 	var note models.Note
-	var readAt sql.NullTime
-	err = s.db.QueryRow(ctx, query, args...).
-		Scan(&note.Content, &note.Slug, &note.KeepBeforeExpiration, &readAt, &note.CreatedAt, &note.ExpiresAt)
-
+	var readAt, availableFrom sql.NullTime
+	err := s.db.QueryRow(ctx, query, slug, tokenHash).
+		Scan(&note.ID, &note.Slug, &note.KeepBeforeExpiration, &readAt, &note.CreatedAt, &note.ExpiresAt,
+			&note.MaxViews, &note.ViewsLeft, &availableFrom)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return models.Note{}, models.ErrNoteNotFound
 	}
+	if err != nil {
+		return models.Note{}, err
+	}
 
 	note.ReadAt = psqlutil.NullTimeToTime(readAt)
+	note.AvailableFrom = psqlutil.NullTimeToTime(availableFrom)
 
-	return note, err
+	return note, nil
 }
 
-func (s *NoteRepo) UpdateExpirationTimeSettingsBySlug(
+func (s *NoteRepo) UpdateExpirationTimeSettingsByManagementTokenHash(
 	ctx context.Context,
 	slug dtos.NoteSlug,
+	tokenHash string,
 	patch dtos.PatchNote,
-	authorID uuid.UUID,
 ) error {
 	query := `--sql
 update notes n
 set keep_before_expiration = COALESCE($1, n.keep_before_expiration),
-    expires_at = COALESCE($2, n.expires_at)
-from notes_authors na
-where n.slug = $3
-  and na.user_id = $4
-  and na.note_id = n.id`
+    expires_at = COALESCE($2, n.expires_at),
+    available_from = case when $3::boolean then $4::timestamptz else n.available_from end
+where n.slug = $5
+  and n.management_token_hash = $6`
+
+	// zero available from makes the note available right away
+	var availableFrom sql.NullTime
+	if patch.AvailableFrom != nil {
+		availableFrom = psqlutil.TimeToNullTime(*patch.AvailableFrom)
+	}
 
 	ct, err := s.db.Exec(ctx, query,
 		patch.KeepBeforeExpiration, patch.ExpiresAt,
-		slug, authorID.String())
+		patch.AvailableFrom != nil, availableFrom,
+		slug, tokenHash)
 	if err != nil {
 		return err
 	}
@@ -268,42 +1973,69 @@ where n.slug = $3
 	return nil
 }
 
-func (s *NoteRepo) RemoveBySlug(
+func (s *NoteRepo) DeleteByManagementTokenHash(
 	ctx context.Context,
 	slug dtos.NoteSlug,
-	readAt time.Time,
+	tokenHash string,
 ) error {
-	query, args, err := pgq.
-		Update("notes").
-		Set("content", "").
-		Set("read_at", readAt).
-		Where(pgq.Eq{"slug": slug}).
-		Where("read_at is null").
-		SQL()
+	return s.deleteNote(ctx,
+		"delete from notes where slug = $1 and management_token_hash = $2 returning content_id",
+		slug, tokenHash)
+}
+
+func (s *NoteRepo) ClaimByManagementTokenHashes(
+	ctx context.Context,
+	authorID uuid.UUID,
+	tokenHashes []string,
+) ([]dtos.NoteSlug, error) {
+	// the token is removed, so notes cannot be claimed twice, or managed by whoever else has the token
+	query := `--sql
+with claimed as (
+  update notes
+  set management_token_hash = null
+  where management_token_hash = any($2)
+  returning id, slug
+), authored as (
+  insert into notes_authors (note_id, user_id)
+  select id, $1 from claimed
+), unanonymized as (
+  delete from notes_anonymous_authors naa
+  using claimed c
+  where naa.note_id = c.id
+)
+select slug from claimed`
+
+	rows, err := s.db.Query(ctx, query, authorID, tokenHashes)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
 
-	_, err = s.db.Exec(ctx, query, args...)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return models.ErrNoteNotFound
+	var slugs []dtos.NoteSlug
+	for rows.Next() {
+		var slug dtos.NoteSlug
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
 	}
 
-	return err
+	return slugs, rows.Err()
 }
 
-func (s *NoteRepo) DeleteNoteBySlug(
+func (s *NoteRepo) CreateAccessEvent(
 	ctx context.Context,
 	slug dtos.NoteSlug,
-	authorID uuid.UUID,
+	event models.NoteAccessEvent,
 ) error {
 	query := `--sql
-delete from notes n
-using notes_authors na
-where n.slug = $1
-  and na.user_id = $2`
+insert into note_access_events (note_id, kind, ip, user_agent, created_at)
+select id, $2, $3, $4, $5
+from notes
+where slug = $1`
 
-	ct, err := s.db.Exec(ctx, query, slug, authorID.String())
+	ct, err := s.db.Exec(ctx, query,
+		slug, event.Kind, event.IP, event.UserAgent, event.CreatedAt)
 	if err != nil {
 		return err
 	}
@@ -315,73 +2047,253 @@ where n.slug = $1
 	return nil
 }
 
-func (s *NoteRepo) SetAuthorIDBySlug(
+func (s *NoteRepo) GetAccessEventsByNoteID(
 	ctx context.Context,
-	slug dtos.NoteSlug,
-	authorID uuid.UUID,
-) error {
-	tx, err := s.db.Begin(ctx)
+	noteID uuid.UUID,
+	limit int,
+) ([]models.NoteAccessEvent, error) {
+	query := `--sql
+select kind, ip, user_agent, created_at
+from note_access_events
+where note_id = $1
+order by created_at desc
+limit $2`
+
+	rows, err := s.db.Query(ctx, query, noteID, limit)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer tx.Rollback(ctx) //nolint:errcheck
+	defer rows.Close()
 
-	var noteID uuid.UUID
-	err = tx.QueryRow(ctx, "select id from notes where slug = $1", slug).Scan(&noteID)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return models.ErrNoteNotFound
+	var events []models.NoteAccessEvent
+	for rows.Next() {
+		var event models.NoteAccessEvent
+		if err := rows.Scan(&event.Kind, &event.IP, &event.UserAgent, &event.CreatedAt); err != nil {
+			return nil, err
 		}
-		return err
+		events = append(events, event)
 	}
 
-	_, err = tx.Exec(
-		ctx,
-		"insert into notes_authors (note_id, user_id) values ($1, $2)",
-		noteID, authorID,
-	)
+	return events, rows.Err()
+}
+
+func (s *NoteRepo) DeleteAccessEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	ct, err := s.db.Exec(ctx, "delete from note_access_events where created_at < $1", before)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return tx.Commit(ctx)
+	return ct.RowsAffected(), nil
 }
 
-func (s *NoteRepo) UpdatePasswordBySlug(
+func (s *NoteRepo) GetInboxUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := s.db.QueryRow(ctx,
+		"select id from users where email = $1 and activated = true",
+		email).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, models.ErrNoteInboxRecipientNotFound
+	}
+
+	return userID, err
+}
+
+func (s *NoteRepo) GetInboxByUserID(
+	ctx context.Context,
+	userID uuid.UUID,
+	now time.Time,
+) ([]dtos.InboxNote, error) {
+	// the update isn't visible to the select, so both see the same notes
+	query := `--sql
+with delivered as (
+  update notes
+  set inbox_delivered_at = $2
+  where inbox_user_id = $1
+    and inbox_delivered_at is null
+    and read_at is null
+    and (expires_at <= 'epoch' or expires_at > $2)
+)
+select n.slug, coalesce(u.email, ''), (n.password is not null and n.password <> ''),
+  n.created_at, n.expires_at, n.available_from
+from notes n
+left join notes_authors na on na.note_id = n.id
+left join users u on u.id = na.user_id
+where n.inbox_user_id = $1
+  and n.read_at is null
+  and (n.expires_at <= 'epoch' or n.expires_at > $2)
+order by n.created_at desc`
+
+	rows, err := s.db.Query(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []dtos.InboxNote
+	for rows.Next() {
+		var note dtos.InboxNote
+		var availableFrom sql.NullTime
+		if err := rows.Scan(&note.Slug, &note.SenderEmail, &note.HasPassword,
+			&note.CreatedAt, &note.ExpiresAt, &availableFrom); err != nil {
+			return nil, err
+		}
+
+		note.AvailableFrom = psqlutil.NullTimeToTime(availableFrom)
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+func (s *NoteRepo) GetRequiresApprovalBySlug(ctx context.Context, slug dtos.NoteSlug) (bool, error) {
+	var requiresApproval bool
+	err := s.db.QueryRow(ctx,
+		"select requires_approval from notes where slug = $1 and read_at is null",
+		slug).Scan(&requiresApproval)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, models.ErrNoteNotFound
+	}
+
+	return requiresApproval, err
+}
+
+func (s *NoteRepo) CreateAccessRequest(
 	ctx context.Context,
 	slug dtos.NoteSlug,
-	authorID uuid.UUID,
-	passwd string,
-) error {
+	tokenHash string,
+	req models.NoteAccessRequest,
+) (uuid.UUID, error) {
 	query := `--sql
-update notes n
-set password = $1
-from notes_authors na
-where n.slug = $2
-  and na.user_id = $3
-  and na.note_id = n.id`
+insert into note_access_requests (note_id, token_hash, reason, status, ip, user_agent, created_at, expires_at)
+select id, $2, $3, $4, $5, $6, $7, $8
+from notes
+where slug = $1
+returning id`
+
+	var id uuid.UUID
+	err := s.db.QueryRow(ctx, query,
+		slug, tokenHash, req.Reason, req.Status, req.IP, req.UserAgent, req.CreatedAt, req.ExpiresAt).
+		Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, models.ErrNoteNotFound
+	}
 
-	ct, err := s.db.Exec(ctx, query, passwd, slug, authorID.String())
+	return id, err
+}
+
+func (s *NoteRepo) GetAccessRequestsByNoteID(
+	ctx context.Context,
+	noteID uuid.UUID,
+	limit int,
+) ([]models.NoteAccessRequest, error) {
+	query := `--sql
+select id, reason, status, ip, user_agent, created_at, expires_at, decided_at, used_at
+from note_access_requests
+where note_id = $1
+order by created_at desc
+limit $2`
+
+	rows, err := s.db.Query(ctx, query, noteID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []models.NoteAccessRequest
+	for rows.Next() {
+		var req models.NoteAccessRequest
+		var decidedAt, usedAt sql.NullTime
+		if err := rows.Scan(&req.ID, &req.Reason, &req.Status, &req.IP, &req.UserAgent,
+			&req.CreatedAt, &req.ExpiresAt, &decidedAt, &usedAt); err != nil {
+			return nil, err
+		}
+
+		req.DecidedAt = psqlutil.NullTimeToTime(decidedAt)
+		req.UsedAt = psqlutil.NullTimeToTime(usedAt)
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+func (s *NoteRepo) DecideAccessRequest(
+	ctx context.Context,
+	noteID, requestID uuid.UUID,
+	status models.NoteAccessRequestStatus,
+	decidedAt, expiresAt time.Time,
+) (models.NoteAccessRequest, error) {
+	query := `--sql
+update note_access_requests
+set status = $3, decided_at = $4, expires_at = $5
+where note_id = $1
+  and id = $2
+  and status = 'pending'
+  and expires_at > $4
+returning id, reason, status, ip, user_agent, created_at, expires_at, decided_at`
+
+	var req models.NoteAccessRequest
+	err := s.db.QueryRow(ctx, query, noteID, requestID, status, decidedAt, expiresAt).
+		Scan(&req.ID, &req.Reason, &req.Status, &req.IP, &req.UserAgent, &req.CreatedAt, &req.ExpiresAt,
+			&req.DecidedAt)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return req, err
+	}
+
+	// nothing is updated, either if there's no such request, or if it cannot be decided anymore
+	var exists bool
+	err = s.db.QueryRow(ctx,
+		"select exists(select 1 from note_access_requests where note_id = $1 and id = $2)",
+		noteID, requestID).Scan(&exists)
+	if err != nil {
+		return models.NoteAccessRequest{}, err
+	}
+
+	if !exists {
+		return models.NoteAccessRequest{}, models.ErrNoteAccessRequestNotFound
+	}
+
+	return models.NoteAccessRequest{}, models.ErrNoteAccessRequestNotPending
+}
+
+// useAccessRequestQuery marks the approved access request to the note, with the token hash, as used,
+// the args are the note's slug, the token hash, and the time it's used at.
+const useAccessRequestQuery = `--sql
+update note_access_requests r
+set used_at = $3
+from notes n
+where n.id = r.note_id
+  and n.slug = $1
+  and r.token_hash = $2
+  and r.status = 'approved'
+  and r.used_at is null
+  and r.expires_at > $3`
+
+func (s *NoteRepo) UseAccessRequest(
+	ctx context.Context,
+	slug dtos.NoteSlug,
+	tokenHash string,
+	now time.Time,
+) error {
+	ct, err := s.db.Exec(ctx, useAccessRequestQuery, slug, tokenHash, now)
 	if err != nil {
 		return err
 	}
 
 	if ct.RowsAffected() == 0 {
-		return models.ErrNoteNotFound
+		return models.ErrNoteApprovalRequired
 	}
 
 	return nil
 }
 
-// getAllNotes is a helper function for [NoteRepo.GetAllByAuthorID], [NoteRepo.GetAllReadByAuthorID],
-// and [NoteRepo.GetAllUnreadByAuthorID].
+// getAllNotes is a helper function for [NoteRepo.ListByAuthorID].
 // The query's SELECT elements order should be consistent across all function calls.
 func (s *NoteRepo) getAllNotes(
 	ctx context.Context,
 	query string,
-	authorID uuid.UUID,
+	args ...any,
 ) ([]models.Note, error) {
-	rows, err := s.db.Query(ctx, query, authorID.String())
+	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -391,15 +2303,79 @@ func (s *NoteRepo) getAllNotes(
 	var notes []models.Note
 	for rows.Next() {
 		var note models.Note
-		var readAt sql.NullTime
-		if err := rows.Scan(&note.Content, &note.Slug, &note.KeepBeforeExpiration, &note.Password,
-			&readAt, &note.CreatedAt, &note.ExpiresAt); err != nil {
+		var readAt, availableFrom, deliveredAt sql.NullTime
+		var groupID uuid.NullUUID
+		var contentKeyID string
+		if err := rows.Scan(&note.ID, &note.Content, &contentKeyID, &note.Slug, &note.KeepBeforeExpiration, &note.Password,
+			&readAt, &note.CreatedAt, &note.ExpiresAt,
+			&note.EncryptionScheme, &note.EncryptionVersion, &note.MaxViews, &note.ViewsLeft,
+			&note.NotifyAuthor, &note.BurnOnLockout, &note.AllowReply, &note.ReplySlug, &groupID,
+			&note.RecipientLabel, &availableFrom, &note.Version, &note.InboxEmail, &deliveredAt,
+			&note.InboxRecipientDeleted); err != nil {
+			return nil, err
+		}
+
+		if note.Content, err = s.enc.Decrypt(ctx, note.Content, contentKeyID); err != nil {
 			return nil, err
 		}
 
 		note.ReadAt = psqlutil.NullTimeToTime(readAt)
+		note.AvailableFrom = psqlutil.NullTimeToTime(availableFrom)
+		note.GroupID = groupID.UUID
+		note.DeliveredAt = psqlutil.NullTimeToTime(deliveredAt)
 		notes = append(notes, note)
 	}
 
 	return notes, rows.Err()
 }
+
+// noteSortExprs maps fields notes can be sorted by to sql expressions,
+// notes that aren't read are sorted as if they were read at zero time, the same way unexpiring notes are.
+var noteSortExprs = map[models.NoteSortField]string{
+	models.NoteSortByCreatedAt: "n.created_at",
+	models.NoteSortByExpiresAt: "n.expires_at",
+	models.NoteSortByReadAt:    "coalesce(n.read_at, '0001-01-01 00:00:00+00')",
+}
+
+// whereNoteListFilter adds conditions of the filter to the query of notes aliased as "n".
+func whereNoteListFilter(
+	b pgq.SelectBuilder,
+	filter models.NoteListFilter,
+	now time.Time,
+) pgq.SelectBuilder {
+	switch filter.Status {
+	case models.NoteStatusRead:
+		b = b.Where("n.read_at is not null")
+	case models.NoteStatusUnread:
+		b = b.Where("n.read_at is null and (n.expires_at <= 'epoch' or n.expires_at > ?)", now)
+	case models.NoteStatusExpired:
+		b = b.Where("n.read_at is null and n.expires_at > 'epoch' and n.expires_at <= ?", now)
+	}
+
+	if filter.HasPassword != nil {
+		if *filter.HasPassword {
+			b = b.Where("(n.password is not null and n.password != '')")
+		} else {
+			b = b.Where("(n.password is null or n.password = '')")
+		}
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		b = b.Where("n.created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		b = b.Where("n.created_at < ?", filter.CreatedBefore)
+	}
+
+	if !filter.ExpiresAfter.IsZero() || !filter.ExpiresBefore.IsZero() {
+		b = b.Where("n.expires_at > 'epoch'")
+	}
+	if !filter.ExpiresAfter.IsZero() {
+		b = b.Where("n.expires_at >= ?", filter.ExpiresAfter)
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		b = b.Where("n.expires_at < ?", filter.ExpiresBefore)
+	}
+
+	return b
+}
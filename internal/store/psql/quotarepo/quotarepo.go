@@ -0,0 +1,121 @@
+package quotarepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+)
+
+type QuotaStorer interface {
+	// GetOverrideByUserID returns override of the user's quota.
+	// Returns [models.ErrQuotaOverrideNotFound] if the user has none.
+	GetOverrideByUserID(ctx context.Context, userID uuid.UUID) (models.QuotaOverride, error)
+
+	// SetOverride sets override of the user's quota, replacing the previous one.
+	// Returns [models.ErrUserNotFound] if the user is not found.
+	SetOverride(ctx context.Context, override models.QuotaOverride) error
+
+	// DeleteOverrideByUserID deletes override of the user's quota, so the tier's limits apply again.
+	// Returns [models.ErrQuotaOverrideNotFound] if the user has none.
+	DeleteOverrideByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+var _ QuotaStorer = (*QuotaRepo)(nil)
+
+type QuotaRepo struct {
+	db *psqlutil.DB
+}
+
+func New(db *psqlutil.DB) *QuotaRepo {
+	return &QuotaRepo{
+		db: db,
+	}
+}
+
+func (r *QuotaRepo) GetOverrideByUserID(
+	ctx context.Context,
+	userID uuid.UUID,
+) (models.QuotaOverride, error) {
+	query := `--sql
+select max_content_bytes, notes_per_day, max_active_notes, max_expires_in_seconds
+from user_quotas
+where user_id = $1`
+
+	var maxContentBytes, notesPerDay, maxActiveNotes sql.NullInt32
+	var maxExpiresIn sql.NullInt64
+	err := r.db.QueryRow(ctx, query, userID).
+		Scan(&maxContentBytes, &notesPerDay, &maxActiveNotes, &maxExpiresIn)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.QuotaOverride{}, models.ErrQuotaOverrideNotFound
+	}
+	if err != nil {
+		return models.QuotaOverride{}, err
+	}
+
+	var maxExpiresInDur *time.Duration
+	if maxExpiresIn.Valid {
+		d := time.Duration(maxExpiresIn.Int64) * time.Second
+		maxExpiresInDur = &d
+	}
+
+	return models.QuotaOverride{
+		UserID:          userID,
+		MaxContentBytes: nullInt32ToIntPtr(maxContentBytes),
+		NotesPerDay:     nullInt32ToIntPtr(notesPerDay),
+		MaxActiveNotes:  nullInt32ToIntPtr(maxActiveNotes),
+		MaxExpiresIn:    maxExpiresInDur,
+	}, nil
+}
+
+func (r *QuotaRepo) SetOverride(ctx context.Context, override models.QuotaOverride) error {
+	query := `--sql
+insert into user_quotas (user_id, max_content_bytes, notes_per_day, max_active_notes, max_expires_in_seconds)
+values ($1, $2, $3, $4, $5)
+on conflict (user_id) do update
+set max_content_bytes = excluded.max_content_bytes,
+    notes_per_day = excluded.notes_per_day,
+    max_active_notes = excluded.max_active_notes,
+    max_expires_in_seconds = excluded.max_expires_in_seconds`
+
+	var maxExpiresIn *int64
+	if override.MaxExpiresIn != nil {
+		s := int64(override.MaxExpiresIn.Seconds())
+		maxExpiresIn = &s
+	}
+
+	_, err := r.db.Exec(ctx, query,
+		override.UserID, override.MaxContentBytes, override.NotesPerDay, override.MaxActiveNotes, maxExpiresIn)
+	if psqlutil.IsForeignKeyErr(err, "user_quotas_user_id_fkey") {
+		return models.ErrUserNotFound
+	}
+
+	return err
+}
+
+func (r *QuotaRepo) DeleteOverrideByUserID(ctx context.Context, userID uuid.UUID) error {
+	ct, err := r.db.Exec(ctx, "delete from user_quotas where user_id = $1", userID)
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrQuotaOverrideNotFound
+	}
+
+	return nil
+}
+
+func nullInt32ToIntPtr(n sql.NullInt32) *int {
+	if !n.Valid {
+		return nil
+	}
+
+	v := int(n.Int32)
+	return &v
+}
@@ -38,6 +38,10 @@ type UserStorer interface {
 	// password should be hashed
 	SetPassword(ctx context.Context, userID uuid.UUID, newPassword string) error
 
+	// RehashPassword replaces the password hash, only if it's still the old one,
+	// so a password changed in the meantime isn't overwritten.
+	RehashPassword(ctx context.Context, userID uuid.UUID, oldHash, newHash string) error
+
 	// SetEmail sets new email for user by their id
 	SetEmail(ctx context.Context, userID uuid.UUID, email string) error
 
@@ -232,6 +236,27 @@ func (r *UserRepo) SetPassword(ctx context.Context, userID uuid.UUID, password s
 	return nil
 }
 
+func (r *UserRepo) RehashPassword(
+	ctx context.Context,
+	userID uuid.UUID,
+	oldHash, newHash string,
+) error {
+	query, args, err := pgq.
+		Update("users").
+		Set("password", newHash).
+		Where(pgq.Eq{
+			"id":       userID.String(),
+			"password": oldHash,
+		}).
+		SQL()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, query, args...)
+	return err
+}
+
 func (r *UserRepo) SetEmail(ctx context.Context, userID uuid.UUID, email string) error {
 	query, args, err := pgq.
 		Update("users").
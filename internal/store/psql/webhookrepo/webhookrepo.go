@@ -0,0 +1,411 @@
+package webhookrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/envelope"
+	"github.com/olexsmir/onasty/internal/models"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+)
+
+type WebhookStorer interface {
+	// Create creates a webhook.
+	Create(ctx context.Context, webhook models.Webhook) error
+
+	// GetAllByUserID returns all webhooks of the user, without their secrets.
+	GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error)
+
+	// GetByID returns the user's webhook, without its secret.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	GetByID(ctx context.Context, id, userID uuid.UUID) (models.Webhook, error)
+
+	// Update updates webhook's url and events, if they're set.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	Update(ctx context.Context, id, userID uuid.UUID, patch dtos.PatchWebhook) error
+
+	// Delete deletes the webhook, and its deliveries.
+	// Returns [models.ErrWebhookNotFound] if webhook is not found.
+	Delete(ctx context.Context, id, userID uuid.UUID) error
+
+	// CreateDeliveries queues delivery of the payload to every user's webhook subscribed to the event.
+	// Returns number of queued deliveries.
+	CreateDeliveries(
+		ctx context.Context,
+		userID uuid.UUID,
+		event models.WebhookEvent,
+		payload string,
+	) (int64, error)
+
+	// GetDeliveriesByWebhookID returns up to limit latest deliveries of the webhook, newest first.
+	GetDeliveriesByWebhookID(ctx context.Context, webhookID uuid.UUID, limit int) ([]models.WebhookDelivery, error)
+
+	// ClaimDueDeliveries returns up to limit pending deliveries, which next attempt is due by now,
+	// and counts the attempt. Claimed deliveries are not returned again until leaseUntil,
+	// so if their result is not recorded by then(e.g. replica crashed), they're retried.
+	// Concurrent callers never get the same delivery.
+	ClaimDueDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]DueDelivery, error)
+
+	// UpdateDelivery records result of the delivery attempt.
+	UpdateDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+
+	// DeleteDeliveriesCreatedBefore deletes succeeded and failed deliveries created before the specified time.
+	// Returns number of deleted deliveries.
+	DeleteDeliveriesCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// ReencryptSecrets re-encrypts secrets of all webhooks that are not encrypted with the current key.
+	// Returns number of re-encrypted secrets.
+	ReencryptSecrets(ctx context.Context) (int64, error)
+}
+
+var _ WebhookStorer = (*WebhookRepo)(nil)
+
+// DueDelivery is a delivery that is due to be attempted, along with where, and how to sign it.
+type DueDelivery struct {
+	Delivery models.WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+type WebhookRepo struct {
+	db  *psqlutil.DB
+	enc envelope.Encryptor
+}
+
+// New creates a [WebhookRepo], webhooks' secrets are encrypted at rest with enc.
+func New(db *psqlutil.DB, enc envelope.Encryptor) *WebhookRepo {
+	return &WebhookRepo{
+		db:  db,
+		enc: enc,
+	}
+}
+
+func (r *WebhookRepo) Create(ctx context.Context, webhook models.Webhook) error {
+	secret, secretKeyID, err := r.enc.Encrypt(ctx, webhook.Secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(ctx, `--sql
+insert into webhooks (id, user_id, url, events, secret, secret_key_id, created_at)
+values ($1, $2, $3, $4, $5, $6, $7)`,
+		webhook.ID, webhook.UserID, webhook.URL, mapEventsToStrings(webhook.Events),
+		secret, secretKeyID, webhook.CreatedAt)
+
+	return err
+}
+
+func (r *WebhookRepo) GetAllByUserID(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error) {
+	query := `--sql
+select id, user_id, url, events, created_at
+from webhooks
+where user_id = $1
+order by created_at, id`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id, userID uuid.UUID) (models.Webhook, error) {
+	query := `--sql
+select id, user_id, url, events, created_at
+from webhooks
+where id = $1
+  and user_id = $2`
+
+	webhook, err := scanWebhook(r.db.QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Webhook{}, models.ErrWebhookNotFound
+	}
+
+	return webhook, err
+}
+
+func (r *WebhookRepo) Update(
+	ctx context.Context,
+	id, userID uuid.UUID,
+	patch dtos.PatchWebhook,
+) error {
+	query := `--sql
+update webhooks
+set url = coalesce($1, url),
+    events = coalesce($2, events)
+where id = $3
+  and user_id = $4`
+
+	ct, err := r.db.Exec(ctx, query, patch.URL, patch.Events, id, userID)
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	ct, err := r.db.Exec(ctx, "delete from webhooks where id = $1 and user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return models.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepo) CreateDeliveries(
+	ctx context.Context,
+	userID uuid.UUID,
+	event models.WebhookEvent,
+	payload string,
+) (int64, error) {
+	query := `--sql
+insert into webhook_deliveries (webhook_id, event, payload)
+select id, $2::varchar, $3
+from webhooks
+where user_id = $1
+  and $2::varchar = any(events)`
+
+	ct, err := r.db.Exec(ctx, query, userID, string(event), payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+func (r *WebhookRepo) GetDeliveriesByWebhookID(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	query := `--sql
+select id, webhook_id, event, payload, status, attempts, response_status, error,
+  created_at, next_attempt_at, last_attempt_at
+from webhook_deliveries
+where webhook_id = $1
+order by created_at desc, id
+limit $2`
+
+	rows, err := r.db.Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepo) ClaimDueDeliveries(
+	ctx context.Context,
+	now, leaseUntil time.Time,
+	limit int,
+) ([]DueDelivery, error) {
+	// rows locked by concurrent callers are skipped, so each delivery is claimed only once
+	query := `--sql
+with due as (
+  select id
+  from webhook_deliveries
+  where status = 'pending'
+    and next_attempt_at <= $1
+  order by next_attempt_at
+  limit $3
+  for update skip locked
+)
+update webhook_deliveries d
+set attempts = d.attempts + 1,
+    next_attempt_at = $2
+from due, webhooks w
+where d.id = due.id
+  and w.id = d.webhook_id
+returning d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.response_status, d.error,
+  d.created_at, d.next_attempt_at, d.last_attempt_at, w.url, w.secret, w.secret_key_id`
+
+	rows, err := r.db.Query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []DueDelivery
+	for rows.Next() {
+		var d DueDelivery
+		var secretKeyID string
+		d.Delivery, err = scanDelivery(rows, &d.URL, &d.Secret, &secretKeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.Secret, err = r.enc.Decrypt(ctx, d.Secret, secretKeyID); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	query := `--sql
+update webhook_deliveries
+set status = $1,
+    response_status = $2,
+    error = $3,
+    next_attempt_at = $4,
+    last_attempt_at = $5
+where id = $6`
+
+	_, err := r.db.Exec(ctx, query,
+		string(delivery.Status), delivery.ResponseStatus, delivery.Error,
+		delivery.NextAttemptAt, delivery.LastAttemptAt, delivery.ID)
+
+	return err
+}
+
+func (r *WebhookRepo) DeleteDeliveriesCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `--sql
+delete from webhook_deliveries
+where status <> 'pending'
+  and created_at < $1`
+
+	ct, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+func (r *WebhookRepo) ReencryptSecrets(ctx context.Context) (int64, error) {
+	currentKeyID, err := r.enc.CurrentKeyID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := r.db.Query(ctx,
+		"select id, secret, secret_key_id from webhooks where secret_key_id <> $1",
+		currentKeyID)
+	if err != nil {
+		return 0, err
+	}
+
+	type encryptedSecret struct {
+		id          uuid.UUID
+		secret      string
+		secretKeyID string
+	}
+
+	var secrets []encryptedSecret
+	for rows.Next() {
+		var s encryptedSecret
+		if err := rows.Scan(&s.id, &s.secret, &s.secretKeyID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		secrets = append(secrets, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var reencrypted int64
+	for _, s := range secrets {
+		plaintext, err := r.enc.Decrypt(ctx, s.secret, s.secretKeyID)
+		if err != nil {
+			return reencrypted, err
+		}
+
+		secret, secretKeyID, err := r.enc.Encrypt(ctx, plaintext)
+		if err != nil {
+			return reencrypted, err
+		}
+
+		// the webhook could be deleted in the meantime
+		ct, err := r.db.Exec(ctx,
+			"update webhooks set secret = $1, secret_key_id = $2 where id = $3 and secret_key_id = $4",
+			secret, secretKeyID, s.id, s.secretKeyID)
+		if err != nil {
+			return reencrypted, err
+		}
+
+		reencrypted += ct.RowsAffected()
+	}
+
+	return reencrypted, nil
+}
+
+func scanWebhook(row pgx.Row) (models.Webhook, error) {
+	var webhook models.Webhook
+	var events []string
+	if err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &events, &webhook.CreatedAt); err != nil {
+		return models.Webhook{}, err
+	}
+
+	for _, e := range events {
+		webhook.Events = append(webhook.Events, models.WebhookEvent(e))
+	}
+
+	return webhook, nil
+}
+
+// scanDelivery scans the delivery, the query's SELECT elements order should be consistent across all calls,
+// extra is scanned after the delivery's columns.
+func scanDelivery(row pgx.Row, extra ...any) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var lastAttemptAt sql.NullTime
+	dest := append([]any{
+		&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &d.ResponseStatus, &d.Error,
+		&d.CreatedAt, &d.NextAttemptAt, &lastAttemptAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	d.LastAttemptAt = psqlutil.NullTimeToTime(lastAttemptAt)
+
+	return d, nil
+}
+
+func mapEventsToStrings(events []models.WebhookEvent) []string {
+	res := make([]string, 0, len(events))
+	for _, e := range events {
+		res = append(res, string(e))
+	}
+
+	return res
+}
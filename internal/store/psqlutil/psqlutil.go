@@ -32,6 +32,42 @@ func (db *DB) Close() error {
 	return nil
 }
 
+// WithTryAdvisoryLock runs fn only if session-level advisory lock with the key is acquired,
+// so only one of the app replicas does the work. The lock is released after fn returns.
+// Returns false if the lock is held by someone else, fn is not called in that case.
+func (db *DB) WithTryAdvisoryLock(
+	ctx context.Context,
+	key int64,
+	fn func(ctx context.Context) error,
+) (bool, error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "select pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return false, err
+	}
+
+	if !locked {
+		return false, nil
+	}
+
+	fnErr := fn(ctx)
+
+	// the lock has to be released even if ctx is canceled, otherwise the connection
+	// is returned to the pool with the lock still being held
+	unlockCtx := context.WithoutCancel(ctx)
+	if _, err := conn.Exec(unlockCtx, "select pg_advisory_unlock($1)", key); err != nil {
+		// closing the session releases all of its locks
+		return true, errors.Join(fnErr, err, conn.Conn().Close(unlockCtx))
+	}
+
+	return true, fnErr
+}
+
 // IsDuplicateErr function that checks if the error is a duplicate key violation.
 func IsDuplicateErr(err error, constraintName string) bool {
 	var pgErr *pgconn.PgError
@@ -42,6 +78,16 @@ func IsDuplicateErr(err error, constraintName string) bool {
 	return false
 }
 
+// IsForeignKeyErr function that checks if the error is a foreign key violation.
+func IsForeignKeyErr(err error, constraintName string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23503" && // foreign_key_violation
+			pgErr.ConstraintName == constraintName
+	}
+	return false
+}
+
 // NullTimeToTime converts sql.NullTime to time.Time.
 // Returns zero [time.Time] if NullTime is not valid.
 func NullTimeToTime(t sql.NullTime) time.Time {
@@ -50,3 +96,9 @@ func NullTimeToTime(t sql.NullTime) time.Time {
 	}
 	return time.Time{}
 }
+
+// TimeToNullTime converts time.Time to sql.NullTime.
+// Zero [time.Time] is converted to not valid NullTime.
+func TimeToNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,59 @@
+package noteattempts
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/store/rdb"
+)
+
+// NoteAttempter counts passwords given to a note, the count is reset once the right one is given.
+type NoteAttempter interface {
+	// Increment increments count of passwords given to the note, and returns it.
+	// The count is reset when the lockout passes since the first of them.
+	Increment(ctx context.Context, slug string) (int64, error)
+
+	// Reset resets count of passwords given to the note.
+	Reset(ctx context.Context, slug string) error
+}
+
+var _ NoteAttempter = (*NoteAttempts)(nil)
+
+type NoteAttempts struct {
+	rdb     *rdb.DB
+	lockout time.Duration
+}
+
+func New(rdb *rdb.DB, lockout time.Duration) *NoteAttempts {
+	return &NoteAttempts{
+		rdb:     rdb,
+		lockout: lockout,
+	}
+}
+
+func (n *NoteAttempts) Increment(ctx context.Context, slug string) (int64, error) {
+	key := getKey(slug)
+
+	// the expiration is set only by the first attempt,
+	// so the following ones don't prolong the lockout
+	pipe := n.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, n.lockout)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (n *NoteAttempts) Reset(ctx context.Context, slug string) error {
+	return n.rdb.Del(ctx, getKey(slug)).Err()
+}
+
+func getKey(slug string) string {
+	var sb strings.Builder
+	sb.WriteString("noteattempts:")
+	sb.WriteString(slug)
+	return sb.String()
+}
@@ -14,6 +14,7 @@ import (
 type NoteCacher interface {
 	SetNote(ctx context.Context, slug string, note models.Note) error
 	GetNote(ctx context.Context, slug string) (models.Note, error)
+	DeleteNote(ctx context.Context, slug string) error
 }
 
 type NoteCache struct {
@@ -52,6 +53,10 @@ func (n *NoteCache) GetNote(ctx context.Context, slug string) (models.Note, erro
 	return note, err
 }
 
+func (n *NoteCache) DeleteNote(ctx context.Context, slug string) error {
+	return n.rdb.Del(ctx, getKey(slug)).Err()
+}
+
 func getKey(slug string) string {
 	var sb strings.Builder
 	sb.WriteString("note:")
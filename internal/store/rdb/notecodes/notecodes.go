@@ -0,0 +1,93 @@
+package notecodes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/store/rdb"
+	"github.com/redis/go-redis/v9"
+)
+
+// NoteCoder stores one-time codes notes bound to an email are released with,
+// and counts codes given to them.
+type NoteCoder interface {
+	// Set stores hash of the note's code, replacing the previous one, the code expires after the ttl.
+	Set(ctx context.Context, slug, codeHash string) error
+
+	// Get returns hash of the note's code, empty if there's none, or it's expired.
+	Get(ctx context.Context, slug string) (string, error)
+
+	// Delete deletes the note's code, and resets count of codes given to it.
+	Delete(ctx context.Context, slug string) error
+
+	// IncrementAttempts increments count of codes given to the note, and returns it.
+	// The count is reset when the ttl passes since the first of them.
+	IncrementAttempts(ctx context.Context, slug string) (int64, error)
+
+	// GetAttempts returns count of codes given to the note.
+	GetAttempts(ctx context.Context, slug string) (int64, error)
+}
+
+var _ NoteCoder = (*NoteCodes)(nil)
+
+type NoteCodes struct {
+	rdb *rdb.DB
+	ttl time.Duration
+}
+
+func New(rdb *rdb.DB, ttl time.Duration) *NoteCodes {
+	return &NoteCodes{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+func (n *NoteCodes) Set(ctx context.Context, slug, codeHash string) error {
+	return n.rdb.Set(ctx, getKey("notecode:", slug), codeHash, n.ttl).Err()
+}
+
+func (n *NoteCodes) Get(ctx context.Context, slug string) (string, error) {
+	codeHash, err := n.rdb.Get(ctx, getKey("notecode:", slug)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	return codeHash, err
+}
+
+func (n *NoteCodes) Delete(ctx context.Context, slug string) error {
+	return n.rdb.Del(ctx, getKey("notecode:", slug), getKey("notecodeattempts:", slug)).Err()
+}
+
+func (n *NoteCodes) IncrementAttempts(ctx context.Context, slug string) (int64, error) {
+	key := getKey("notecodeattempts:", slug)
+
+	// the expiration is set only by the first attempt,
+	// so requesting new codes doesn't give more attempts
+	pipe := n.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, n.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (n *NoteCodes) GetAttempts(ctx context.Context, slug string) (int64, error) {
+	count, err := n.rdb.Get(ctx, getKey("notecodeattempts:", slug)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+func getKey(prefix, slug string) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteString(slug)
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package notecounter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/store/rdb"
+	"github.com/redis/go-redis/v9"
+)
+
+// ttl is how long the counter is kept since it was last incremented,
+// it's longer than a day, so the counter outlives the day it counts.
+const ttl = 48 * time.Hour
+
+// NoteCounterer counts notes created by a subject (user, or anonymous ip) during a UTC day.
+type NoteCounterer interface {
+	// Increment increments count of notes created by the subject during the day, and returns it.
+	Increment(ctx context.Context, subject string, day time.Time) (int64, error)
+
+	// Decrement decrements count of notes created by the subject during the day.
+	Decrement(ctx context.Context, subject string, day time.Time) error
+
+	// Get returns count of notes created by the subject during the day.
+	Get(ctx context.Context, subject string, day time.Time) (int64, error)
+}
+
+var _ NoteCounterer = (*NoteCounter)(nil)
+
+type NoteCounter struct {
+	rdb *rdb.DB
+}
+
+func New(rdb *rdb.DB) *NoteCounter {
+	return &NoteCounter{
+		rdb: rdb,
+	}
+}
+
+func (n *NoteCounter) Increment(ctx context.Context, subject string, day time.Time) (int64, error) {
+	key := getKey(subject, day)
+
+	pipe := n.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (n *NoteCounter) Decrement(ctx context.Context, subject string, day time.Time) error {
+	return n.rdb.Decr(ctx, getKey(subject, day)).Err()
+}
+
+func (n *NoteCounter) Get(ctx context.Context, subject string, day time.Time) (int64, error) {
+	count, err := n.rdb.Get(ctx, getKey(subject, day)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return count, err
+}
+
+func getKey(subject string, day time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("notecount:")
+	sb.WriteString(subject)
+	sb.WriteString(":")
+	sb.WriteString(day.UTC().Format(time.DateOnly))
+	return sb.String()
+}
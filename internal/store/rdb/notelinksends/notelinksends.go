@@ -0,0 +1,60 @@
+package notelinksends
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/store/rdb"
+)
+
+// window is how long links emailed by a user are counted since the first of them.
+const window = time.Hour
+
+// NoteLinkSender counts links to notes emailed by a user during an hour.
+type NoteLinkSender interface {
+	// Increment increments count of links emailed by the user, and returns it.
+	// The count is reset when an hour passes since the first of them.
+	Increment(ctx context.Context, userID string) (int64, error)
+
+	// Decrement decrements count of links emailed by the user, e.g. when the link isn't sent after all.
+	Decrement(ctx context.Context, userID string) error
+}
+
+var _ NoteLinkSender = (*NoteLinkSends)(nil)
+
+type NoteLinkSends struct {
+	rdb *rdb.DB
+}
+
+func New(rdb *rdb.DB) *NoteLinkSends {
+	return &NoteLinkSends{
+		rdb: rdb,
+	}
+}
+
+func (n *NoteLinkSends) Increment(ctx context.Context, userID string) (int64, error) {
+	key := getKey(userID)
+
+	// the expiration is set only by the first send,
+	// so the following ones don't prolong the window
+	pipe := n.rdb.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (n *NoteLinkSends) Decrement(ctx context.Context, userID string) error {
+	return n.rdb.Decr(ctx, getKey(userID)).Err()
+}
+
+func getKey(userID string) string {
+	var sb strings.Builder
+	sb.WriteString("notelinksends:")
+	sb.WriteString(userID)
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package notereads
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/store/rdb"
+	"github.com/redis/go-redis/v9"
+)
+
+// NoteReader stores read tokens, the token is issued to the reader on each read of a note,
+// and proves that they've passed all checks the note is read with.
+type NoteReader interface {
+	// Set stores slug of the note the token, by its hash, is issued for, the token expires after the ttl.
+	Set(ctx context.Context, tokenHash, slug string) error
+
+	// Get returns slug of the note the token, by its hash, is issued for, empty if there's none, or it's expired.
+	Get(ctx context.Context, tokenHash string) (string, error)
+}
+
+var _ NoteReader = (*NoteReads)(nil)
+
+type NoteReads struct {
+	rdb *rdb.DB
+	ttl time.Duration
+}
+
+func New(rdb *rdb.DB, ttl time.Duration) *NoteReads {
+	return &NoteReads{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
+
+func (n *NoteReads) Set(ctx context.Context, tokenHash, slug string) error {
+	return n.rdb.Set(ctx, getKey(tokenHash), slug, n.ttl).Err()
+}
+
+func (n *NoteReads) Get(ctx context.Context, tokenHash string) (string, error) {
+	slug, err := n.rdb.Get(ctx, getKey(tokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	return slug, err
+}
+
+func getKey(tokenHash string) string {
+	var sb strings.Builder
+	sb.WriteString("noteread:")
+	sb.WriteString(tokenHash)
+	return sb.String()
+}
@@ -6,13 +6,15 @@ import (
 	"github.com/olexsmir/onasty/internal/service/authsrv"
 	"github.com/olexsmir/onasty/internal/service/notesrv"
 	"github.com/olexsmir/onasty/internal/service/usersrv"
+	"github.com/olexsmir/onasty/internal/service/webhooksrv"
 	"github.com/olexsmir/onasty/internal/transport/http/ratelimit"
 )
 
 type APIV1 struct {
-	authsrv authsrv.AuthServicer
-	usersrv usersrv.UserServicer
-	notesrv notesrv.NoteServicer
+	authsrv    authsrv.AuthServicer
+	usersrv    usersrv.UserServicer
+	notesrv    notesrv.NoteServicer
+	webhooksrv webhooksrv.WebhookServicer
 
 	env              config.Environment
 	slowRatelimitCfg ratelimit.Config
@@ -25,6 +27,7 @@ func NewAPIV1(
 	as authsrv.AuthServicer,
 	us usersrv.UserServicer,
 	ns notesrv.NoteServicer,
+	ws webhooksrv.WebhookServicer,
 	slowRatelimitCfg ratelimit.Config,
 	env config.Environment,
 	appURL string,
@@ -34,6 +37,7 @@ func NewAPIV1(
 		authsrv:          as,
 		usersrv:          us,
 		notesrv:          ns,
+		webhooksrv:       ws,
 		slowRatelimitCfg: slowRatelimitCfg,
 		env:              env,
 		appURL:           appURL,
@@ -76,11 +80,21 @@ func (a APIV1) Routes(r *gin.RouterGroup) {
 	{
 		note.GET("/:slug", a.getNoteBySlugHandler)
 		note.POST("/:slug/view", a.getNoteBySlugAndPasswordHandler)
-		note.GET("/:slug/meta", a.getNoteMetadataByIDHandler)
+		note.POST("/:slug/code", a.slowRateLimit(), a.requestNoteCodeHandler)
+		note.POST("/:slug/unlock", a.unlockNoteHandler)
+		note.POST("/:slug/access-requests", a.slowRateLimit(), a.requestNoteAccessHandler)
+		note.POST("/:slug/reply", a.replyToNoteHandler)
+		note.GET("/:slug/attachments/:id", a.getNoteAttachmentHandler)
+
+		// notes created without signing in are managed with the token returned on creation
+		note.GET("/:slug/manage", a.getManagedNoteHandler)
+		note.PATCH("/:slug/manage/expires", a.updateManagedNoteHandler)
+		note.DELETE("/:slug/manage", a.deleteManagedNoteHandler)
 
 		possiblyAuthorized := note.Group("", a.couldBeAuthorizedMiddleware)
 		{
 			possiblyAuthorized.POST("", a.createNoteHandler)
+			possiblyAuthorized.GET("/:slug/meta", a.getNoteMetadataByIDHandler)
 		}
 
 		authorized := note.Group("", a.authorizedMiddleware)
@@ -88,11 +102,40 @@ func (a APIV1) Routes(r *gin.RouterGroup) {
 			authorized.GET("", a.getNotesHandler)
 			authorized.GET("/read", a.getReadNotesHandler)
 			authorized.GET("/unread", a.getUnReadNotesHandler)
+			authorized.POST("/bulk", a.bulkNotesHandler)
+			authorized.POST("/panic", a.burnUnreadNotesHandler)
+			authorized.POST("/claim", a.claimNotesHandler)
 			authorized.PATCH(":slug/expires", a.updateNoteHandler)
 			authorized.PATCH(":slug/password", a.setNotePasswordHandler)
+			authorized.PATCH(":slug/content", a.updateNoteContentHandler)
+			authorized.PATCH(":slug/notifications", a.setNoteNotificationsHandler)
+			authorized.POST(":slug/check-in", a.checkInNoteHandler)
+			authorized.GET(":slug/sends", a.getNoteLinkSendsHandler)
+			authorized.GET(":slug/audit", a.getNoteAccessEventsHandler)
+			authorized.GET(":slug/access-requests", a.getNoteAccessRequestsHandler)
+			authorized.POST(":slug/access-requests/:id/approve", a.approveNoteAccessRequestHandler)
+			authorized.POST(":slug/access-requests/:id/deny", a.denyNoteAccessRequestHandler)
 			authorized.DELETE(":slug", a.deleteNoteHandler)
 		}
 	}
+
+	// notes sent to the user's inbox are read only by the user, so they're read with the user's token
+	inbox := r.Group("/inbox", a.authorizedMiddleware)
+	{
+		inbox.GET("", a.getInboxHandler)
+		inbox.GET("/:slug", a.getInboxNoteHandler)
+		inbox.POST("/:slug/view", a.getInboxNoteWithPasswordHandler)
+	}
+
+	webhooks := r.Group("/webhooks", a.authorizedMiddleware)
+	{
+		webhooks.POST("", a.createWebhookHandler)
+		webhooks.GET("", a.getWebhooksHandler)
+		webhooks.GET("/:id", a.getWebhookHandler)
+		webhooks.PATCH("/:id", a.updateWebhookHandler)
+		webhooks.DELETE("/:id", a.deleteWebhookHandler)
+		webhooks.GET("/:id/deliveries", a.getWebhookDeliveriesHandler)
+	}
 }
 
 func (a APIV1) slowRateLimit() gin.HandlerFunc {
@@ -0,0 +1,88 @@
+package apiv1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/olexsmir/onasty/internal/service/notesrv"
+)
+
+type inboxNoteResponse struct {
+	Slug          string    `json:"slug"`
+	SenderEmail   string    `json:"sender_email"`
+	HasPassword   bool      `json:"has_password"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at,omitzero"`
+	AvailableFrom time.Time `json:"available_from,omitzero"`
+}
+
+func (a APIV1) getInboxHandler(c *gin.Context) {
+	notes, err := a.notesrv.GetInbox(c.Request.Context(), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	response := make([]inboxNoteResponse, 0, len(notes))
+	for _, n := range notes {
+		response = append(response, inboxNoteResponse{
+			Slug:          n.Slug,
+			SenderEmail:   n.SenderEmail,
+			HasPassword:   n.HasPassword,
+			CreatedAt:     n.CreatedAt,
+			ExpiresAt:     n.ExpiresAt,
+			AvailableFrom: n.AvailableFrom,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getInboxNoteHandler reads the note sent to the user's inbox, unlike [APIV1.getNoteBySlugHandler]
+// it doesn't pass the note's access token, since notes sent to an inbox cannot require approval,
+// creating such notes is rejected with models.ErrNoteApprovalWithInbox.
+func (a APIV1) getInboxNoteHandler(c *gin.Context) {
+	note, err := a.notesrv.GetBySlugAndRemoveIfNeeded(
+		c.Request.Context(),
+		notesrv.GetNoteBySlugInput{
+			Slug:     c.Param("slug"),
+			Password: notesrv.EmptyPassword,
+			UserID:   a.getUserID(c),
+			Reader:   getReader(c),
+		},
+	)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	newGetNoteResponse(c, note)
+}
+
+// getInboxNoteWithPasswordHandler is [APIV1.getInboxNoteHandler] for notes with a password, or a code,
+// it doesn't pass the note's access token either.
+func (a APIV1) getInboxNoteWithPasswordHandler(c *gin.Context) {
+	var req unlockNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	note, err := a.notesrv.GetBySlugAndRemoveIfNeeded(
+		c.Request.Context(),
+		notesrv.GetNoteBySlugInput{
+			Slug:     c.Param("slug"),
+			Password: req.Password,
+			Code:     req.Code,
+			UserID:   a.getUserID(c),
+			Reader:   getReader(c),
+		},
+	)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	newGetNoteResponse(c, note)
+}
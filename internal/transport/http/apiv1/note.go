@@ -1,64 +1,207 @@
 package apiv1
 
 import (
+	"iter"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
 	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
 	"github.com/olexsmir/onasty/internal/service/notesrv"
 )
 
 type createNoteRequest struct {
 	Content              string    `json:"content"`
 	Slug                 string    `json:"slug"`
+	SlugStrategy         string    `json:"slug_strategy"`
+	SlugLength           int       `json:"slug_length"`
 	Password             string    `json:"password"`
 	KeepBeforeExpiration bool      `json:"keep_before_expiration"`
+	EncryptionScheme     string    `json:"encryption_scheme"`
+	EncryptionVersion    int       `json:"encryption_version"`
+	MaxViews             int       `json:"max_views"`
+	NotifyAuthor         bool      `json:"notify_author"`
+	BurnOnLockout        bool      `json:"burn_on_lockout"`
+	AllowReply           bool      `json:"allow_reply"`
 	ExpiresAt            time.Time `json:"expires_at"`
+	AvailableFrom        time.Time `json:"available_from"`
+
+	// the note is sent to several recipients if either of these is set
+	Recipients      int      `json:"recipients"`
+	RecipientLabels []string `json:"recipient_labels"`
+
+	DeadManSwitch *createNoteSwitchRequest `json:"dead_man_switch"`
+
+	// the link to the note is emailed to the recipient, if it's set
+	RecipientEmail string `json:"recipient_email"`
+	Message        string `json:"message"`
+
+	// the note is released only with the one-time code emailed to this address, if it's set
+	BoundEmail string `json:"bound_email"`
+
+	// the note is sent to inbox of the registered user with this email, only the user can read it
+	InboxEmail string `json:"inbox_email"`
+
+	// the note is released only to readers whose access request the author has approved
+	RequiresApproval bool `json:"requires_approval"`
+}
+
+type createNoteSwitchRequest struct {
+	CheckInIntervalHours int      `json:"check_in_interval_hours"`
+	Recipients           []string `json:"recipients"`
 }
 
 type createNoteResponse struct {
-	Slug string `json:"slug"`
+	Slug       string                  `json:"slug"`
+	Recipients []noteRecipientResponse `json:"recipients,omitempty"`
+
+	// ManagementToken is returned only for notes created without signing in.
+	ManagementToken string `json:"management_token,omitempty"`
+}
+
+type noteRecipientResponse struct {
+	Label string `json:"label,omitempty"`
+	Slug  string `json:"slug"`
 }
 
 func (a APIV1) createNoteHandler(c *gin.Context) {
 	var req createNoteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	var attachments iter.Seq2[dtos.CreateNoteAttachment, error]
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		mr, err := c.Request.MultipartReader()
+		if err != nil || readCreateNoteRequestPart(mr, &req) != nil {
+			invalidRequest(c)
+			return
+		}
+		attachments = readAttachmentParts(mr)
+	} else if err := c.ShouldBindJSON(&req); err != nil {
 		invalidRequest(c)
 		return
 	}
 
-	slug, err := a.notesrv.Create(c.Request.Context(), dtos.CreateNote{
+	inp := dtos.CreateNote{
 		Content:              req.Content,
 		UserID:               a.getUserID(c),
 		Slug:                 req.Slug,
+		SlugStrategy:         req.SlugStrategy,
+		SlugLength:           req.SlugLength,
 		Password:             req.Password,
 		KeepBeforeExpiration: req.KeepBeforeExpiration,
+		EncryptionScheme:     req.EncryptionScheme,
+		EncryptionVersion:    req.EncryptionVersion,
+		MaxViews:             req.MaxViews,
+		NotifyAuthor:         req.NotifyAuthor,
+		BurnOnLockout:        req.BurnOnLockout,
+		AllowReply:           req.AllowReply,
 		CreatedAt:            time.Now(),
 		ExpiresAt:            req.ExpiresAt,
-	}, a.getUserID(c))
+		AvailableFrom:        req.AvailableFrom,
+		RecipientsCount:      req.Recipients,
+		RecipientLabels:      req.RecipientLabels,
+		CreatorIP:            c.ClientIP(),
+		Attachments:          attachments,
+		Switch:               nil,
+		Link:                 nil,
+		BoundEmail:           req.BoundEmail,
+		InboxEmail:           req.InboxEmail,
+		RequiresApproval:     req.RequiresApproval,
+	}
+
+	if req.DeadManSwitch != nil {
+		inp.Switch = &dtos.CreateNoteSwitch{
+			CheckInInterval: time.Duration(req.DeadManSwitch.CheckInIntervalHours) * time.Hour,
+			Recipients:      req.DeadManSwitch.Recipients,
+		}
+	}
+
+	if req.RecipientEmail != "" || req.Message != "" {
+		inp.Link = &dtos.CreateNoteLink{
+			RecipientEmail: req.RecipientEmail,
+			Message:        req.Message,
+		}
+	}
+
+	var note dtos.CreatedNote
+	var err error
+	if req.Recipients != 0 || len(req.RecipientLabels) != 0 {
+		note, err = a.notesrv.CreateWithRecipients(c.Request.Context(), inp, a.getUserID(c))
+	} else {
+		note, err = a.notesrv.Create(c.Request.Context(), inp, a.getUserID(c))
+	}
 	if err != nil {
 		errorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, createNoteResponse{slug})
+	// slug of the first recipient is returned as well, for clients that expect a single one
+	var recipients []noteRecipientResponse
+	for _, r := range note.Recipients {
+		recipients = append(recipients, noteRecipientResponse{Label: r.Label, Slug: r.Slug})
+	}
+
+	c.JSON(http.StatusCreated, createNoteResponse{
+		Slug:            note.Slug,
+		Recipients:      recipients,
+		ManagementToken: note.ManagementToken,
+	})
 }
 
 type getNoteBySlugResponse struct {
 	Content              string    `json:"content"`
 	ReadAt               time.Time `json:"read_at,omitzero"`
 	KeepBeforeExpiration bool      `json:"keep_before_expiration"`
+	EncryptionScheme     string    `json:"encryption_scheme,omitempty"`
+	EncryptionVersion    int       `json:"encryption_version,omitempty"`
 	CreatedAt            time.Time `json:"created_at"`
 	ExpiresAt            time.Time `json:"expires_at,omitzero"`
+	ReplyAllowed         bool      `json:"reply_allowed"`
+
+	Attachments []noteAttachmentResponse `json:"attachments,omitempty"`
+	ReadToken   string                   `json:"read_token,omitempty"`
 }
 
 func (a APIV1) getNoteBySlugHandler(c *gin.Context) {
 	note, err := a.notesrv.GetBySlugAndRemoveIfNeeded(
 		c.Request.Context(),
 		notesrv.GetNoteBySlugInput{
-			Slug:     c.Param("slug"),
-			Password: notesrv.EmptyPassword,
+			Slug:        c.Param("slug"),
+			Password:    notesrv.EmptyPassword,
+			AccessToken: getNoteAccessToken(c),
+			Reader:      getReader(c),
+		},
+	)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	newGetNoteResponse(c, note)
+}
+
+type unlockNoteRequest struct {
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+func (a APIV1) unlockNoteHandler(c *gin.Context) {
+	var req unlockNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	note, err := a.notesrv.GetBySlugAndRemoveIfNeeded(
+		c.Request.Context(),
+		notesrv.GetNoteBySlugInput{
+			Slug:        c.Param("slug"),
+			Password:    req.Password,
+			Code:        req.Code,
+			AccessToken: getNoteAccessToken(c),
+			Reader:      getReader(c),
 		},
 	)
 	if err != nil {
@@ -66,6 +209,20 @@ func (a APIV1) getNoteBySlugHandler(c *gin.Context) {
 		return
 	}
 
+	newGetNoteResponse(c, note)
+}
+
+func (a APIV1) requestNoteCodeHandler(c *gin.Context) {
+	if err := a.notesrv.RequestCode(c.Request.Context(), c.Param("slug")); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newGetNoteResponse responds with the note, read notes are responded with [http.StatusNotFound].
+func newGetNoteResponse(c *gin.Context, note dtos.GetNote) {
 	status := http.StatusOK
 	if !note.ReadAt.IsZero() {
 		status = http.StatusNotFound
@@ -77,6 +234,11 @@ func (a APIV1) getNoteBySlugHandler(c *gin.Context) {
 		CreatedAt:            note.CreatedAt,
 		ExpiresAt:            note.ExpiresAt,
 		KeepBeforeExpiration: note.KeepBeforeExpiration,
+		EncryptionScheme:     note.EncryptionScheme,
+		EncryptionVersion:    note.EncryptionVersion,
+		ReplyAllowed:         note.ReplyAllowed,
+		Attachments:          mapNoteAttachmentsDTOToResponse(note.Attachments),
+		ReadToken:            note.ReadToken,
 	})
 }
 
@@ -94,8 +256,10 @@ func (a APIV1) getNoteBySlugAndPasswordHandler(c *gin.Context) {
 	note, err := a.notesrv.GetBySlugAndRemoveIfNeeded(
 		c.Request.Context(),
 		notesrv.GetNoteBySlugInput{
-			Slug:     c.Param("slug"),
-			Password: req.Password,
+			Slug:        c.Param("slug"),
+			Password:    req.Password,
+			AccessToken: getNoteAccessToken(c),
+			Reader:      getReader(c),
 		},
 	)
 	if err != nil {
@@ -103,35 +267,70 @@ func (a APIV1) getNoteBySlugAndPasswordHandler(c *gin.Context) {
 		return
 	}
 
-	status := http.StatusOK
-	if !note.ReadAt.IsZero() {
-		status = http.StatusNotFound
+	newGetNoteResponse(c, note)
+}
+
+// readTokenHeader is the header attachments are downloaded, and replies are made with,
+// the token is given along with the note's content.
+const readTokenHeader = "X-Read-Token"
+
+type replyToNoteRequest struct {
+	Content           string `json:"content"`
+	Password          string `json:"password"`
+	EncryptionScheme  string `json:"encryption_scheme"`
+	EncryptionVersion int    `json:"encryption_version"`
+}
+
+func (a APIV1) replyToNoteHandler(c *gin.Context) {
+	var req replyToNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
 	}
 
-	c.JSON(status, getNoteBySlugResponse{
-		Content:              note.Content,
-		ReadAt:               note.ReadAt,
-		CreatedAt:            note.CreatedAt,
-		ExpiresAt:            note.ExpiresAt,
-		KeepBeforeExpiration: note.KeepBeforeExpiration,
-	})
+	if err := a.notesrv.Reply(c.Request.Context(), c.Param("slug"), dtos.CreateNoteReply{
+		ReadToken:         c.GetHeader(readTokenHeader),
+		Content:           req.Content,
+		Password:          req.Password,
+		EncryptionScheme:  req.EncryptionScheme,
+		EncryptionVersion: req.EncryptionVersion,
+		CreatedAt:         time.Now(),
+	}); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusCreated)
 }
 
 type getNoteMetadataBySlugResponse struct {
-	CreatedAt   time.Time `json:"created_at"`
-	HasPassword bool      `json:"has_password"`
+	CreatedAt        time.Time `json:"created_at"`
+	HasPassword      bool      `json:"has_password"`
+	EncryptionScheme string    `json:"encryption_scheme,omitempty"`
+	ViewsLeft        int       `json:"views_left,omitempty"`
+	CodeRequired     bool      `json:"code_required"`
+	ApprovalRequired bool      `json:"approval_required"`
 }
 
 func (a APIV1) getNoteMetadataByIDHandler(c *gin.Context) {
-	meta, err := a.notesrv.GetNoteMetadataBySlug(c.Request.Context(), c.Param("slug"))
+	meta, err := a.notesrv.GetNoteMetadataBySlug(
+		c.Request.Context(),
+		c.Param("slug"),
+		a.getUserID(c),
+		getReader(c),
+	)
 	if err != nil {
 		errorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, getNoteMetadataBySlugResponse{
-		CreatedAt:   meta.CreatedAt,
-		HasPassword: meta.HasPassword,
+		CreatedAt:        meta.CreatedAt,
+		HasPassword:      meta.HasPassword,
+		EncryptionScheme: meta.EncryptionScheme,
+		ViewsLeft:        meta.ViewsLeft,
+		CodeRequired:     meta.CodeRequired,
+		ApprovalRequired: meta.ApprovalRequired,
 	})
 }
 
@@ -140,44 +339,108 @@ type getNotesResponse struct {
 	Slug                 string    `json:"slug"`
 	KeepBeforeExpiration bool      `json:"keep_before_expiration"`
 	HasPassword          bool      `json:"has_password"`
+	EncryptionScheme     string    `json:"encryption_scheme,omitempty"`
+	EncryptionVersion    int       `json:"encryption_version,omitempty"`
+	MaxViews             int       `json:"max_views,omitempty"`
+	Views                int       `json:"views"`
+	NotifyAuthor         bool      `json:"notify_author"`
+	BurnOnLockout        bool      `json:"burn_on_lockout"`
+	AllowReply           bool      `json:"allow_reply"`
+	ReplySlug            string    `json:"reply_slug,omitempty"`
+	GroupID              string    `json:"group_id,omitempty"`
+	RecipientLabel       string    `json:"recipient_label,omitempty"`
+	Version              int       `json:"version"`
 	CreatedAt            time.Time `json:"created_at"`
 	ExpiresAt            time.Time `json:"expires_at,omitzero"`
+	AvailableFrom        time.Time `json:"available_from,omitzero"`
 	ReadAt               time.Time `json:"read_at,omitzero"`
+	InboxEmail           string    `json:"inbox_email,omitempty"`
+	DeliveredAt          time.Time `json:"delivered_at,omitzero"`
+
+	InboxRecipientDeleted bool `json:"inbox_recipient_deleted,omitempty"`
 }
 
-func (a APIV1) getNotesHandler(c *gin.Context) {
-	notes, err := a.notesrv.GetAllByAuthorID(c.Request.Context(), a.getUserID(c))
-	if err != nil {
-		errorResponse(c, err)
-		return
-	}
+type getNotesRequest struct {
+	Status        string    `form:"status"`
+	HasPassword   *bool     `form:"has_password"`
+	CreatedAfter  time.Time `form:"created_after"  time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	ExpiresAfter  time.Time `form:"expires_after"  time_format:"2006-01-02T15:04:05Z07:00"`
+	ExpiresBefore time.Time `form:"expires_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string    `form:"sort"`
+	Order         string    `form:"order"`
+	Limit         int       `form:"limit"`
+	Cursor        string    `form:"cursor"`
+}
 
-	c.JSON(http.StatusOK, mapNotesDTOToResponse(notes))
+type getNotesPageResponse struct {
+	Notes      []getNotesResponse `json:"notes"`
+	Total      int                `json:"total"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
+func (a APIV1) getNotesHandler(c *gin.Context) {
+	a.listNotes(c, "")
+}
+
+// getReadNotesHandler is [APIV1.getNotesHandler] that lists only read notes, it's deprecated.
 func (a APIV1) getReadNotesHandler(c *gin.Context) {
-	notes, err := a.notesrv.GetAllReadByAuthorID(c.Request.Context(), a.getUserID(c))
-	if err != nil {
-		errorResponse(c, err)
+	a.listNotes(c, models.NoteStatusRead)
+}
+
+// getUnReadNotesHandler is [APIV1.getNotesHandler] that lists only unread notes, it's deprecated.
+func (a APIV1) getUnReadNotesHandler(c *gin.Context) {
+	a.listNotes(c, models.NoteStatusUnread)
+}
+
+// listNotes responds with a page of the user's notes, that match the query,
+// if status is set, it overrides the one from the query.
+func (a APIV1) listNotes(c *gin.Context, status models.NoteStatus) {
+	var req getNotesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		invalidRequest(c)
 		return
 	}
 
-	c.JSON(http.StatusOK, mapNotesDTOToResponse(notes))
-}
+	if status != "" {
+		req.Status = string(status)
+	}
 
-func (a APIV1) getUnReadNotesHandler(c *gin.Context) {
-	notes, err := a.notesrv.GetAllUnreadByAuthorID(c.Request.Context(), a.getUserID(c))
+	page, err := a.notesrv.ListByAuthorID(c.Request.Context(), a.getUserID(c), dtos.ListNotes{
+		NotesFilter: dtos.NotesFilter{
+			Status:        req.Status,
+			HasPassword:   req.HasPassword,
+			CreatedAfter:  req.CreatedAfter,
+			CreatedBefore: req.CreatedBefore,
+			ExpiresAfter:  req.ExpiresAfter,
+			ExpiresBefore: req.ExpiresBefore,
+		},
+		SortBy: req.Sort,
+		Order:  req.Order,
+		Limit:  req.Limit,
+		Cursor: req.Cursor,
+	})
 	if err != nil {
 		errorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, mapNotesDTOToResponse(notes))
+	notes := mapNotesDTOToResponse(page.Notes)
+	if notes == nil {
+		notes = []getNotesResponse{}
+	}
+
+	c.JSON(http.StatusOK, getNotesPageResponse{
+		Notes:      notes,
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	})
 }
 
 type updateNoteRequest struct {
 	ExpiresAt            *time.Time `json:"expires_at,omitempty"`
 	KeepBeforeExpiration *bool      `json:"keep_before_expiration,omitempty"`
+	AvailableFrom        *time.Time `json:"available_from,omitempty"`
 }
 
 func (a APIV1) updateNoteHandler(c *gin.Context) {
@@ -192,6 +455,7 @@ func (a APIV1) updateNoteHandler(c *gin.Context) {
 		dtos.PatchNote{
 			KeepBeforeExpiration: req.KeepBeforeExpiration,
 			ExpiresAt:            req.ExpiresAt,
+			AvailableFrom:        req.AvailableFrom,
 		},
 		c.Param("slug"),
 		a.getUserID(c),
@@ -203,6 +467,190 @@ func (a APIV1) updateNoteHandler(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+type checkInNoteResponse struct {
+	ReleaseAt time.Time `json:"release_at"`
+}
+
+func (a APIV1) checkInNoteHandler(c *gin.Context) {
+	releaseAt, err := a.notesrv.CheckIn(c.Request.Context(), c.Param("slug"), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkInNoteResponse{ReleaseAt: releaseAt})
+}
+
+// managementTokenHeader is the header notes created without signing in are managed with.
+const managementTokenHeader = "X-Management-Token"
+
+type getManagedNoteResponse struct {
+	Slug                 string    `json:"slug"`
+	KeepBeforeExpiration bool      `json:"keep_before_expiration"`
+	MaxViews             int       `json:"max_views,omitempty"`
+	Views                int       `json:"views"`
+	CreatedAt            time.Time `json:"created_at"`
+	ExpiresAt            time.Time `json:"expires_at,omitzero"`
+	AvailableFrom        time.Time `json:"available_from,omitzero"`
+	ReadAt               time.Time `json:"read_at,omitzero"`
+}
+
+func (a APIV1) getManagedNoteHandler(c *gin.Context) {
+	token := c.GetHeader(managementTokenHeader)
+	if token == "" {
+		errorResponse(c, ErrUnauthorized)
+		return
+	}
+
+	note, err := a.notesrv.GetByManagementToken(c.Request.Context(), c.Param("slug"), token)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getManagedNoteResponse{
+		Slug:                 note.Slug,
+		KeepBeforeExpiration: note.KeepBeforeExpiration,
+		MaxViews:             note.MaxViews,
+		Views:                note.Views,
+		CreatedAt:            note.CreatedAt,
+		ExpiresAt:            note.ExpiresAt,
+		AvailableFrom:        note.AvailableFrom,
+		ReadAt:               note.ReadAt,
+	})
+}
+
+func (a APIV1) updateManagedNoteHandler(c *gin.Context) {
+	token := c.GetHeader(managementTokenHeader)
+	if token == "" {
+		errorResponse(c, ErrUnauthorized)
+		return
+	}
+
+	var req updateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	if err := a.notesrv.UpdateExpirationTimeSettingsByManagementToken(
+		c.Request.Context(),
+		c.Param("slug"),
+		token,
+		dtos.PatchNote{
+			KeepBeforeExpiration: req.KeepBeforeExpiration,
+			ExpiresAt:            req.ExpiresAt,
+			AvailableFrom:        req.AvailableFrom,
+		},
+	); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (a APIV1) deleteManagedNoteHandler(c *gin.Context) {
+	token := c.GetHeader(managementTokenHeader)
+	if token == "" {
+		errorResponse(c, ErrUnauthorized)
+		return
+	}
+
+	if err := a.notesrv.DeleteByManagementToken(c.Request.Context(), c.Param("slug"), token); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type claimNotesRequest struct {
+	ManagementTokens []string `json:"management_tokens"`
+}
+
+type claimNotesResponse struct {
+	Slugs []string `json:"slugs"`
+}
+
+func (a APIV1) claimNotesHandler(c *gin.Context) {
+	var req claimNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	slugs, err := a.notesrv.ClaimNotes(c.Request.Context(), a.getUserID(c), req.ManagementTokens)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	if slugs == nil {
+		slugs = []string{}
+	}
+
+	c.JSON(http.StatusOK, claimNotesResponse{Slugs: slugs})
+}
+
+type noteLinkSendResponse struct {
+	RecipientEmail string    `json:"recipient_email"`
+	SentAt         time.Time `json:"sent_at"`
+}
+
+func (a APIV1) getNoteLinkSendsHandler(c *gin.Context) {
+	sends, err := a.notesrv.GetLinkSends(c.Request.Context(), c.Param("slug"), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	response := make([]noteLinkSendResponse, 0, len(sends))
+	for _, s := range sends {
+		response = append(response, noteLinkSendResponse{
+			RecipientEmail: s.RecipientEmail,
+			SentAt:         s.SentAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type noteAccessEventResponse struct {
+	Event     string    `json:"event"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (a APIV1) getNoteAccessEventsHandler(c *gin.Context) {
+	events, err := a.notesrv.GetAccessEvents(c.Request.Context(), c.Param("slug"), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	response := make([]noteAccessEventResponse, 0, len(events))
+	for _, e := range events {
+		response = append(response, noteAccessEventResponse{
+			Event:     string(e.Kind),
+			IP:        e.IP,
+			UserAgent: e.UserAgent,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getReader returns the client that accesses a note.
+func getReader(c *gin.Context) notesrv.Reader {
+	return notesrv.Reader{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
 func (a APIV1) deleteNoteHandler(c *gin.Context) {
 	if err := a.notesrv.DeleteBySlug(
 		c.Request.Context(),
@@ -216,6 +664,79 @@ func (a APIV1) deleteNoteHandler(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+type setNoteNotificationsRequest struct {
+	NotifyAuthor bool `json:"notify_author"`
+}
+
+func (a APIV1) setNoteNotificationsHandler(c *gin.Context) {
+	var req setNoteNotificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	if err := a.notesrv.UpdateNotifyAuthor(
+		c.Request.Context(),
+		c.Param("slug"),
+		req.NotifyAuthor,
+		a.getUserID(c),
+	); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+type updateNoteContentRequest struct {
+	Content string `json:"content"`
+
+	// Version could be passed in the If-Match header instead.
+	Version int `json:"version"`
+}
+
+type updateNoteContentResponse struct {
+	Version int `json:"version"`
+}
+
+func (a APIV1) updateNoteContentHandler(c *gin.Context) {
+	var req updateNoteContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		version, err := parseNoteETag(ifMatch)
+		if err != nil {
+			invalidRequest(c)
+			return
+		}
+		req.Version = version
+	}
+
+	version, err := a.notesrv.UpdateContent(
+		c.Request.Context(),
+		c.Param("slug"),
+		req.Content,
+		req.Version,
+		a.getUserID(c),
+	)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+	c.JSON(http.StatusOK, updateNoteContentResponse{Version: version})
+}
+
+// parseNoteETag parses the version from the note's etag, weak etags are accepted as well.
+func parseNoteETag(etag string) (int, error) {
+	etag = strings.TrimPrefix(etag, "W/")
+	return strconv.Atoi(strings.Trim(etag, `"`))
+}
+
 type setNotePasswordRequest struct {
 	Password string `json:"password"`
 }
@@ -240,19 +761,124 @@ func (a APIV1) setNotePasswordHandler(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+type notesFilterRequest struct {
+	Status        string    `json:"status"`
+	HasPassword   *bool     `json:"has_password"`
+	CreatedAfter  time.Time `json:"created_after"`
+	CreatedBefore time.Time `json:"created_before"`
+	ExpiresAfter  time.Time `json:"expires_after"`
+	ExpiresBefore time.Time `json:"expires_before"`
+}
+
+type bulkNotesRequest struct {
+	Action    string              `json:"action"`
+	Slugs     []string            `json:"slugs"`
+	Filter    *notesFilterRequest `json:"filter"`
+	ExpiresAt time.Time           `json:"expires_at"`
+	Password  string              `json:"password"`
+}
+
+type bulkNoteResultResponse struct {
+	Slug   string `json:"slug"`
+	Status string `json:"status"`
+}
+
+type bulkNotesResponse struct {
+	Results []bulkNoteResultResponse `json:"results"`
+	Done    int                      `json:"done"`
+}
+
+func (a APIV1) bulkNotesHandler(c *gin.Context) {
+	var req bulkNotesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	inp := dtos.BulkNotes{
+		Action:    req.Action,
+		Slugs:     req.Slugs,
+		Filter:    nil,
+		ExpiresAt: req.ExpiresAt,
+		Password:  req.Password,
+	}
+	if req.Filter != nil {
+		inp.Filter = &dtos.NotesFilter{
+			Status:        req.Filter.Status,
+			HasPassword:   req.Filter.HasPassword,
+			CreatedAfter:  req.Filter.CreatedAfter,
+			CreatedBefore: req.Filter.CreatedBefore,
+			ExpiresAfter:  req.Filter.ExpiresAfter,
+			ExpiresBefore: req.Filter.ExpiresBefore,
+		}
+	}
+
+	res, err := a.notesrv.BulkUpdate(c.Request.Context(), a.getUserID(c), inp)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapBulkNotesResultToResponse(res))
+}
+
+func (a APIV1) burnUnreadNotesHandler(c *gin.Context) {
+	res, err := a.notesrv.BurnAllUnread(c.Request.Context(), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapBulkNotesResultToResponse(res))
+}
+
+func mapBulkNotesResultToResponse(res dtos.BulkNotesResult) bulkNotesResponse {
+	results := make([]bulkNoteResultResponse, 0, len(res.Results))
+	for _, r := range res.Results {
+		results = append(results, bulkNoteResultResponse{Slug: r.Slug, Status: r.Status})
+	}
+
+	return bulkNotesResponse{
+		Results: results,
+		Done:    res.Done,
+	}
+}
+
 func mapNotesDTOToResponse(notes []dtos.NoteDetailed) []getNotesResponse {
 	var response []getNotesResponse
 	for _, note := range notes {
 		response = append(response, getNotesResponse{
-			Content:              note.Content,
-			Slug:                 note.Slug,
-			KeepBeforeExpiration: note.KeepBeforeExpiration,
-			HasPassword:          note.HasPassword,
-			CreatedAt:            note.CreatedAt,
-			ExpiresAt:            note.ExpiresAt,
-			ReadAt:               note.ReadAt,
+			Content:               note.Content,
+			Slug:                  note.Slug,
+			KeepBeforeExpiration:  note.KeepBeforeExpiration,
+			HasPassword:           note.HasPassword,
+			EncryptionScheme:      note.EncryptionScheme,
+			EncryptionVersion:     note.EncryptionVersion,
+			MaxViews:              note.MaxViews,
+			Views:                 note.Views,
+			NotifyAuthor:          note.NotifyAuthor,
+			BurnOnLockout:         note.BurnOnLockout,
+			AllowReply:            note.AllowReply,
+			ReplySlug:             note.ReplySlug,
+			GroupID:               mapNoteGroupIDToResponse(note.GroupID),
+			RecipientLabel:        note.RecipientLabel,
+			Version:               note.Version,
+			CreatedAt:             note.CreatedAt,
+			ExpiresAt:             note.ExpiresAt,
+			AvailableFrom:         note.AvailableFrom,
+			ReadAt:                note.ReadAt,
+			InboxEmail:            note.InboxEmail,
+			DeliveredAt:           note.DeliveredAt,
+			InboxRecipientDeleted: note.InboxRecipientDeleted,
 		})
 	}
 
 	return response
 }
+
+func mapNoteGroupIDToResponse(groupID uuid.UUID) string {
+	if groupID.IsNil() {
+		return ""
+	}
+	return groupID.String()
+}
@@ -0,0 +1,139 @@
+package apiv1
+
+import (
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// noteAccessCookie keeps token of the reader's access request, so the note is released only to the browser
+// that has made the request. It's scoped to the note's path, so each note has its own.
+const noteAccessCookie = "note_access"
+
+type requestNoteAccessRequest struct {
+	Reason string `json:"reason"`
+}
+
+type requestNoteAccessResponse struct {
+	ID        uuid.UUID `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (a APIV1) requestNoteAccessHandler(c *gin.Context) {
+	var req requestNoteAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	accessReq, err := a.notesrv.RequestAccess(
+		c.Request.Context(),
+		c.Param("slug"),
+		req.Reason,
+		getReader(c),
+	)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	// the cookie is sent along with reads of the note, i.e. GET /note/:slug, and POST /note/:slug/view,
+	// it outlives the pending request, so it's still there once the request is approved.
+	// the token grants a read, so it's never sent along with cross-site requests
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(
+		noteAccessCookie,
+		accessReq.Token,
+		int(time.Until(accessReq.TokenExpiresAt).Seconds()),
+		path.Dir(c.Request.URL.Path),
+		"",
+		!a.env.IsDevMode(),
+		true,
+	)
+
+	c.JSON(http.StatusCreated, requestNoteAccessResponse{
+		ID:        accessReq.ID,
+		ExpiresAt: accessReq.ExpiresAt,
+	})
+}
+
+type noteAccessRequestResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	DecidedAt time.Time `json:"decided_at,omitzero"`
+	UsedAt    time.Time `json:"used_at,omitzero"`
+}
+
+func (a APIV1) getNoteAccessRequestsHandler(c *gin.Context) {
+	requests, err := a.notesrv.GetAccessRequests(c.Request.Context(), c.Param("slug"), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	now := time.Now()
+	response := make([]noteAccessRequestResponse, 0, len(requests))
+	for _, r := range requests {
+		response = append(response, noteAccessRequestResponse{
+			ID:        r.ID,
+			Reason:    r.Reason,
+			Status:    string(r.StatusAt(now)),
+			IP:        r.IP,
+			UserAgent: r.UserAgent,
+			CreatedAt: r.CreatedAt,
+			ExpiresAt: r.ExpiresAt,
+			DecidedAt: r.DecidedAt,
+			UsedAt:    r.UsedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (a APIV1) approveNoteAccessRequestHandler(c *gin.Context) {
+	a.decideNoteAccessRequest(c, true)
+}
+
+func (a APIV1) denyNoteAccessRequestHandler(c *gin.Context) {
+	a.decideNoteAccessRequest(c, false)
+}
+
+func (a APIV1) decideNoteAccessRequest(c *gin.Context, approve bool) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		errorResponse(c, models.ErrNoteAccessRequestNotFound)
+		return
+	}
+
+	if err := a.notesrv.DecideAccessRequest(
+		c.Request.Context(),
+		c.Param("slug"),
+		a.getUserID(c),
+		id,
+		approve,
+	); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getNoteAccessToken returns token of the reader's access request, empty if the reader hasn't made one.
+func getNoteAccessToken(c *gin.Context) string {
+	token, err := c.Cookie(noteAccessCookie)
+	if err != nil {
+		return ""
+	}
+
+	return token
+}
@@ -0,0 +1,117 @@
+package apiv1
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+// Notes with attachments are created with multipart/form-data request, where the first part is
+// the note itself(named "note", in the same format as json request), followed by file parts named "attachments".
+// Parts are processed in order they are sent, so attachments are never buffered in memory.
+const (
+	createNotePartName  = "note"
+	attachmentsPartName = "attachments"
+)
+
+var ErrInvalidAttachmentPart = errors.New("invalid attachment")
+
+type noteAttachmentResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+}
+
+func (a APIV1) getNoteAttachmentHandler(c *gin.Context) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		errorResponse(c, models.ErrNoteAttachmentNotFound)
+		return
+	}
+
+	attachment, content, err := a.notesrv.GetAttachment(
+		c.Request.Context(),
+		c.Param("slug"),
+		id,
+		c.GetHeader(readTokenHeader),
+	)
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{
+			"filename": attachment.Filename,
+		}),
+	})
+}
+
+func readCreateNoteRequestPart(mr *multipart.Reader, req *createNoteRequest) error {
+	part, err := mr.NextPart()
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+
+	if part.FormName() != createNotePartName {
+		return ErrInvalidAttachmentPart
+	}
+
+	return json.NewDecoder(part).Decode(req)
+}
+
+func readAttachmentParts(mr *multipart.Reader) iter.Seq2[dtos.CreateNoteAttachment, error] {
+	return func(yield func(dtos.CreateNoteAttachment, error) bool) {
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(dtos.CreateNoteAttachment{}, errors.Join(ErrInvalidAttachmentPart, err))
+				return
+			}
+
+			if part.FormName() != attachmentsPartName {
+				yield(dtos.CreateNoteAttachment{}, ErrInvalidAttachmentPart)
+				return
+			}
+
+			if !yield(dtos.CreateNoteAttachment{
+				Filename:    part.FileName(),
+				ContentType: part.Header.Get("Content-Type"),
+				Content:     part,
+			}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func mapNoteAttachmentsDTOToResponse(attachments []dtos.NoteAttachment) []noteAttachmentResponse {
+	var response []noteAttachmentResponse
+	for _, a := range attachments {
+		response = append(response, noteAttachmentResponse{
+			ID:          a.ID,
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+		})
+	}
+
+	return response
+}
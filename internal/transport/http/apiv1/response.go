@@ -4,12 +4,14 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/olexsmir/onasty/internal/jwtutil"
 	"github.com/olexsmir/onasty/internal/models"
 	"github.com/olexsmir/onasty/internal/service/authsrv"
 	"github.com/olexsmir/onasty/internal/service/notesrv"
+	"github.com/olexsmir/onasty/internal/sluggen"
 )
 
 var ErrUnauthorized = errors.New("unauthorized")
@@ -18,6 +20,11 @@ type response struct {
 	Message string `json:"message"`
 }
 
+type noteNotAvailableYetResponse struct {
+	Message       string    `json:"message"`
+	AvailableFrom time.Time `json:"available_from"`
+}
+
 func errorResponse(c *gin.Context, err error) {
 	if errors.Is(err, authsrv.ErrProviderNotSupported) ||
 		errors.Is(err, models.ErrResetPasswordTokenAlreadyUsed) ||
@@ -33,17 +40,127 @@ func errorResponse(c *gin.Context, err error) {
 		errors.Is(err, models.ErrNoteContentIsEmpty) ||
 		errors.Is(err, models.ErrNoteCannotBeKept) ||
 		errors.Is(err, models.ErrNoteSlugIsAlreadyInUse) ||
-		errors.Is(err, models.ErrNoteSlugIsInvalid) {
+		errors.Is(err, models.ErrNoteSlugIsInvalid) ||
+		errors.Is(err, sluggen.ErrStrategyUnknown) ||
+		errors.Is(err, sluggen.ErrLengthInvalid) ||
+		errors.Is(err, sluggen.ErrEntropyTooLow) ||
+		errors.Is(err, models.ErrNoteEncryptionSchemeUnsupported) ||
+		errors.Is(err, models.ErrNoteContentIsNotCiphertext) ||
+		errors.Is(err, models.ErrNoteMaxViewsIsInvalid) ||
+		errors.Is(err, models.ErrNoteCannotBeKeptWithMaxViews) ||
+		errors.Is(err, models.ErrNoteNotifyAuthorWithoutAuthor) ||
+		errors.Is(err, models.ErrNoteBurnOnLockoutWithoutPassword) ||
+		errors.Is(err, models.ErrNoteAvailabilityAfterExpiration) ||
+		errors.Is(err, models.ErrNoteReplyWithoutAuthor) ||
+		errors.Is(err, models.ErrNoteRecipientsInvalid) ||
+		errors.Is(err, models.ErrNoteRecipientLabelTooLong) ||
+		errors.Is(err, models.ErrNoteRecipientsWithSlug) ||
+		errors.Is(err, models.ErrNoteRecipientsWithAttachments) ||
+		errors.Is(err, models.ErrNoteSwitchIntervalInvalid) ||
+		errors.Is(err, models.ErrNoteSwitchRecipientsInvalid) ||
+		errors.Is(err, models.ErrNoteSwitchWithoutAuthor) ||
+		errors.Is(err, models.ErrNoteSwitchWithAvailableFrom) ||
+		errors.Is(err, models.ErrNoteSwitchWithRecipients) ||
+		errors.Is(err, models.ErrNoteLinkRecipientInvalid) ||
+		errors.Is(err, models.ErrNoteLinkMessageTooLong) ||
+		errors.Is(err, models.ErrNoteLinkWithoutAuthor) ||
+		errors.Is(err, models.ErrNoteLinkWithRecipients) ||
+		errors.Is(err, models.ErrNoteBoundEmailInvalid) ||
+		errors.Is(err, models.ErrNoteBoundWithRecipients) ||
+		errors.Is(err, models.ErrNoteInboxRecipientNotFound) ||
+		errors.Is(err, models.ErrNoteInboxWithoutAuthor) ||
+		errors.Is(err, models.ErrNoteInboxWithRecipients) ||
+		errors.Is(err, models.ErrNoteInboxWithSwitch) ||
+		errors.Is(err, models.ErrNoteApprovalWithoutAuthor) ||
+		errors.Is(err, models.ErrNoteApprovalWithSwitch) ||
+		errors.Is(err, models.ErrNoteApprovalWithInbox) ||
+		errors.Is(err, models.ErrNoteApprovalWithBound) ||
+		errors.Is(err, models.ErrNoteApprovalNotRequired) ||
+		errors.Is(err, models.ErrNoteAccessRequestReasonInvalid) ||
+		errors.Is(err, models.ErrNoteClaimTokensInvalid) ||
+		errors.Is(err, models.ErrNoteTooManyAttachments) ||
+		errors.Is(err, models.ErrNoteAttachmentFilenameInvalid) ||
+		errors.Is(err, ErrInvalidAttachmentPart) ||
+		errors.Is(err, models.ErrNoteListStatusInvalid) ||
+		errors.Is(err, models.ErrNoteListSortInvalid) ||
+		errors.Is(err, models.ErrNoteListLimitInvalid) ||
+		errors.Is(err, models.ErrNoteListRangeInvalid) ||
+		errors.Is(err, models.ErrNoteListCursorInvalid) ||
+		errors.Is(err, models.ErrNoteBulkActionInvalid) ||
+		errors.Is(err, models.ErrNoteBulkSelectorInvalid) ||
+		errors.Is(err, models.ErrNoteBulkTooManySlugs) ||
+		errors.Is(err, models.ErrNoteBulkExpirationInvalid) ||
+		// quotas
+		errors.Is(err, models.ErrQuotaExpirationTooFar) ||
+		errors.Is(err, models.ErrQuotaExpirationIsRequired) ||
+		// webhooks
+		errors.Is(err, models.ErrWebhookURLInvalid) ||
+		errors.Is(err, models.ErrWebhookNoEvents) ||
+		errors.Is(err, models.ErrWebhookEventUnknown) {
 		newError(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	if errors.Is(err, models.ErrNoteAttachmentTooLarge) ||
+		errors.Is(err, models.ErrQuotaContentTooLarge) {
+		newError(c, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+
+	if errors.Is(err, models.ErrQuotaDailyNotesExceeded) ||
+		errors.Is(err, models.ErrQuotaActiveNotesExceeded) ||
+		errors.Is(err, models.ErrNoteLinkSendsExceeded) ||
+		errors.Is(err, models.ErrNoteCodeAttemptsExceeded) {
+		newError(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+
+	if errors.Is(err, models.ErrNoteCodeRequired) ||
+		errors.Is(err, models.ErrNoteCodeInvalid) ||
+		errors.Is(err, models.ErrNoteApprovalRequired) {
+		newError(c, http.StatusForbidden, err.Error())
+		return
+	}
+
+	if errors.Is(err, models.ErrNoteLocked) {
+		newError(c, http.StatusLocked, err.Error())
+		return
+	}
+
+	if notAvailableYetErr := (models.NoteNotAvailableYetError{}); errors.As(err, &notAvailableYetErr) {
+		newNoteNotAvailableYetError(c, notAvailableYetErr)
+		return
+	}
+
+	if errors.Is(err, models.ErrNoteCannotBeEdited) ||
+		errors.Is(err, models.ErrNoteAlreadyReplied) ||
+		errors.Is(err, models.ErrNoteRecipientsCannotBeEdited) ||
+		errors.Is(err, models.ErrNoteSwitchReleased) ||
+		errors.Is(err, models.ErrNoteAccessRequestNotPending) {
+		newError(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	if errors.Is(err, models.ErrNoteVersionMismatch) {
+		newError(c, http.StatusPreconditionFailed, err.Error())
+		return
+	}
+
+	if errors.Is(err, models.ErrNoteVersionRequired) {
+		newError(c, http.StatusPreconditionRequired, err.Error())
+		return
+	}
+
 	if errors.Is(err, models.ErrNoteExpired) {
 		newError(c, http.StatusGone, err.Error())
 		return
 	}
 
 	if errors.Is(err, models.ErrNoteNotFound) ||
+		errors.Is(err, models.ErrNoteAttachmentNotFound) ||
+		errors.Is(err, models.ErrNoteSwitchNotFound) ||
+		errors.Is(err, models.ErrNoteAccessRequestNotFound) ||
+		errors.Is(err, models.ErrWebhookNotFound) ||
 		errors.Is(err, models.ErrVerificationTokenNotFound) {
 		newErrorStatus(c, http.StatusNotFound, err.Error())
 		return
@@ -68,6 +185,17 @@ func newError(c *gin.Context, status int, msg string) {
 	c.AbortWithStatusJSON(status, response{msg})
 }
 
+// newNoteNotAvailableYetError responds with the time the note becomes available,
+// so clients know when to come back.
+func newNoteNotAvailableYetError(c *gin.Context, err models.NoteNotAvailableYetError) {
+	slog.ErrorContext(c.Request.Context(), err.Error(), "status", http.StatusTooEarly)
+	c.Header("Retry-After", err.AvailableFrom.UTC().Format(http.TimeFormat))
+	c.AbortWithStatusJSON(http.StatusTooEarly, noteNotAvailableYetResponse{
+		Message:       err.Error(),
+		AvailableFrom: err.AvailableFrom,
+	})
+}
+
 func newErrorStatus(c *gin.Context, status int, msg string) {
 	slog.ErrorContext(c.Request.Context(), msg, "status", status)
 	c.AbortWithStatus(status)
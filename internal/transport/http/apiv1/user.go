@@ -9,10 +9,31 @@ import (
 )
 
 type getMeResponse struct {
-	Email        string    `json:"email"`
-	CreatedAt    time.Time `json:"created_at"`
-	LastLoginAt  time.Time `json:"last_login_at"`
-	NotesCreated int       `json:"notes_created"`
+	Email        string        `json:"email"`
+	CreatedAt    time.Time     `json:"created_at"`
+	LastLoginAt  time.Time     `json:"last_login_at"`
+	NotesCreated int           `json:"notes_created"`
+	Quota        quotaResponse `json:"quota"`
+}
+
+// quotaResponse describes user's quota, zero limit means there's no limit.
+type quotaResponse struct {
+	Tier   string              `json:"tier"`
+	Limits quotaLimitsResponse `json:"limits"`
+	Usage  quotaUsageResponse  `json:"usage"`
+}
+
+type quotaLimitsResponse struct {
+	MaxContentBytes     int   `json:"max_content_bytes"`
+	NotesPerDay         int   `json:"notes_per_day"`
+	MaxActiveNotes      int   `json:"max_active_notes"`
+	MaxExpiresInSeconds int64 `json:"max_expires_in_seconds"`
+}
+
+type quotaUsageResponse struct {
+	NotesToday  int       `json:"notes_today"`
+	ActiveNotes int       `json:"active_notes"`
+	ResetsAt    time.Time `json:"resets_at"`
 }
 
 func (a APIV1) getMeHandler(c *gin.Context) {
@@ -27,6 +48,20 @@ func (a APIV1) getMeHandler(c *gin.Context) {
 		CreatedAt:    uinfo.CreatedAt,
 		LastLoginAt:  uinfo.LastLoginAt,
 		NotesCreated: uinfo.NotesCreated,
+		Quota: quotaResponse{
+			Tier: uinfo.Quota.Tier,
+			Limits: quotaLimitsResponse{
+				MaxContentBytes:     uinfo.Quota.MaxContentBytes,
+				NotesPerDay:         uinfo.Quota.NotesPerDay,
+				MaxActiveNotes:      uinfo.Quota.MaxActiveNotes,
+				MaxExpiresInSeconds: int64(uinfo.Quota.MaxExpiresIn.Seconds()),
+			},
+			Usage: quotaUsageResponse{
+				NotesToday:  uinfo.Quota.NotesToday,
+				ActiveNotes: uinfo.Quota.ActiveNotes,
+				ResetsAt:    uinfo.Quota.ResetsAt,
+			},
+		},
 	})
 }
 
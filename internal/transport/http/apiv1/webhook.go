@@ -0,0 +1,185 @@
+package apiv1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"github.com/olexsmir/onasty/internal/dtos"
+	"github.com/olexsmir/onasty/internal/models"
+)
+
+type createWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+type webhookResponse struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (a APIV1) createWebhookHandler(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	webhook, err := a.webhooksrv.Create(c.Request.Context(), a.getUserID(c), dtos.CreateWebhook{
+		URL:    req.URL,
+		Events: req.Events,
+	})
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapWebhookDTOToResponse(webhook))
+}
+
+func (a APIV1) getWebhooksHandler(c *gin.Context) {
+	webhooks, err := a.webhooksrv.GetAll(c.Request.Context(), a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	response := make([]webhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, mapWebhookDTOToResponse(webhook))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (a APIV1) getWebhookHandler(c *gin.Context) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		errorResponse(c, models.ErrWebhookNotFound)
+		return
+	}
+
+	webhook, err := a.webhooksrv.GetByID(c.Request.Context(), id, a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mapWebhookDTOToResponse(webhook))
+}
+
+type updateWebhookRequest struct {
+	URL    *string  `json:"url,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+func (a APIV1) updateWebhookHandler(c *gin.Context) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		errorResponse(c, models.ErrWebhookNotFound)
+		return
+	}
+
+	var req updateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c)
+		return
+	}
+
+	if err := a.webhooksrv.Update(
+		c.Request.Context(),
+		id,
+		a.getUserID(c),
+		dtos.PatchWebhook{
+			URL:    req.URL,
+			Events: req.Events,
+		},
+	); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (a APIV1) deleteWebhookHandler(c *gin.Context) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		errorResponse(c, models.ErrWebhookNotFound)
+		return
+	}
+
+	if err := a.webhooksrv.Delete(c.Request.Context(), id, a.getUserID(c)); err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type webhookDeliveryResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Event          string    `json:"event"`
+	Payload        string    `json:"payload"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	NextAttemptAt  time.Time `json:"next_attempt_at,omitzero"`
+	LastAttemptAt  time.Time `json:"last_attempt_at,omitzero"`
+}
+
+func (a APIV1) getWebhookDeliveriesHandler(c *gin.Context) {
+	id, err := uuid.FromString(c.Param("id"))
+	if err != nil {
+		errorResponse(c, models.ErrWebhookNotFound)
+		return
+	}
+
+	deliveries, err := a.webhooksrv.GetDeliveries(c.Request.Context(), id, a.getUserID(c))
+	if err != nil {
+		errorResponse(c, err)
+		return
+	}
+
+	response := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		res := webhookDeliveryResponse{
+			ID:             d.ID,
+			Event:          d.Event,
+			Payload:        d.Payload,
+			Status:         d.Status,
+			Attempts:       d.Attempts,
+			ResponseStatus: d.ResponseStatus,
+			Error:          d.Error,
+			CreatedAt:      d.CreatedAt,
+			NextAttemptAt:  time.Time{},
+			LastAttemptAt:  d.LastAttemptAt,
+		}
+
+		// only pending deliveries are going to be attempted
+		if d.Status == string(models.WebhookDeliveryStatusPending) {
+			res.NextAttemptAt = d.NextAttemptAt
+		}
+
+		response = append(response, res)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func mapWebhookDTOToResponse(webhook dtos.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Secret:    webhook.Secret,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
@@ -9,15 +9,17 @@ import (
 	"github.com/olexsmir/onasty/internal/service/authsrv"
 	"github.com/olexsmir/onasty/internal/service/notesrv"
 	"github.com/olexsmir/onasty/internal/service/usersrv"
+	"github.com/olexsmir/onasty/internal/service/webhooksrv"
 	"github.com/olexsmir/onasty/internal/transport/http/apiv1"
 	"github.com/olexsmir/onasty/internal/transport/http/ratelimit"
 	"github.com/olexsmir/onasty/internal/transport/http/reqid"
 )
 
 type Transport struct {
-	authsrv authsrv.AuthServicer
-	usersrv usersrv.UserServicer
-	notesrv notesrv.NoteServicer
+	authsrv    authsrv.AuthServicer
+	usersrv    usersrv.UserServicer
+	notesrv    notesrv.NoteServicer
+	webhooksrv webhooksrv.WebhookServicer
 
 	env         config.Environment
 	appURL      string
@@ -33,6 +35,7 @@ func NewTransport(
 	as authsrv.AuthServicer,
 	us usersrv.UserServicer,
 	ns notesrv.NoteServicer,
+	ws webhooksrv.WebhookServicer,
 	env config.Environment,
 	appURL, frontendURL string,
 	corsAllowedOrigins []string,
@@ -44,6 +47,7 @@ func NewTransport(
 		authsrv:            as,
 		usersrv:            us,
 		notesrv:            ns,
+		webhooksrv:         ws,
 		env:                env,
 		appURL:             appURL,
 		frontendURL:        frontendURL,
@@ -72,6 +76,7 @@ func (t *Transport) Handler() http.Handler {
 				t.authsrv,
 				t.usersrv,
 				t.notesrv,
+				t.webhooksrv,
 				t.slowRatelimitCfg,
 				t.env,
 				t.appURL,
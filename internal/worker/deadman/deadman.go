@@ -0,0 +1,74 @@
+// Package deadman implements the background worker of notes' dead man's switches:
+// it reminds authors to check in before their notes are released,
+// and sends notes, which authors have missed the check-in, to their recipients.
+package deadman
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/metrics"
+	"github.com/olexsmir/onasty/internal/service/notesrv"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+	"github.com/olexsmir/onasty/internal/worker"
+)
+
+// lockKey is the key of postgres advisory lock, that ensures only one replica checks switches at a time.
+const lockKey int64 = 0x6f6e61737479_02 // "onasty", 2
+
+type Config struct {
+	// Interval is how often the switches are checked.
+	Interval time.Duration
+
+	// RemindBefore is how long before the release authors are reminded to check in.
+	RemindBefore time.Duration
+}
+
+type Deadman struct {
+	notesrv notesrv.NoteServicer
+	cfg     Config
+	job     *worker.LockedJob
+}
+
+func New(db *psqlutil.DB, notesrv notesrv.NoteServicer, cfg Config) *Deadman {
+	d := &Deadman{
+		notesrv: notesrv,
+		cfg:     cfg,
+		job:     nil,
+	}
+	d.job = worker.NewLockedJob(db, "deadman", lockKey, cfg.Interval, d.check, metrics.RecordDeadmanRunMetric)
+
+	return d
+}
+
+// Run checks switches every [Config.Interval], blocks until ctx is canceled.
+func (d *Deadman) Run(ctx context.Context) {
+	d.job.Run(ctx)
+}
+
+// Check does a single check of the switches.
+// Returns false if it was skipped, because other replica is already checking them.
+func (d *Deadman) Check(ctx context.Context) (bool, error) {
+	return d.job.RunOnce(ctx)
+}
+
+func (d *Deadman) check(ctx context.Context) error {
+	reminded, err := d.notesrv.RemindSwitches(ctx, d.cfg.RemindBefore)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordDeadmanRemindersSentMetric(reminded)
+
+	released, err := d.notesrv.ReleaseSwitches(ctx)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordDeadmanNotesReleasedMetric(released)
+
+	slog.DebugContext(ctx, "deadman", "reminded", reminded, "released", released)
+
+	return nil
+}
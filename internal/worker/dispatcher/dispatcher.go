@@ -0,0 +1,102 @@
+// Package dispatcher implements the background worker that delivers webhooks:
+// it attempts pending deliveries once they're due, and deletes old ones from the delivery log.
+//
+// Every replica runs the dispatcher, deliveries are claimed in the database,
+// so each of them is attempted by a single replica at a time.
+package dispatcher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/metrics"
+	"github.com/olexsmir/onasty/internal/service/webhooksrv"
+)
+
+// cleanupInterval is how often old deliveries are deleted.
+const cleanupInterval = time.Hour
+
+type Config struct {
+	// Interval is how often the dispatcher checks for due deliveries.
+	Interval time.Duration
+}
+
+type Dispatcher struct {
+	webhooksrv webhooksrv.WebhookServicer
+	cfg        Config
+}
+
+func New(webhooksrv webhooksrv.WebhookServicer, cfg Config) *Dispatcher {
+	return &Dispatcher{
+		webhooksrv: webhooksrv,
+		cfg:        cfg,
+	}
+}
+
+// Run dispatches due deliveries every [Config.Interval], blocks until ctx is canceled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	cleanupTicker := time.NewTicker(cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil {
+			slog.ErrorContext(ctx, "dispatcher", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanupTicker.C:
+			if err := d.Cleanup(ctx); err != nil {
+				slog.ErrorContext(ctx, "dispatcher", "err", err)
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch attempts all due deliveries, one batch after another.
+// Returns number of attempted deliveries.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	var total int
+	for {
+		attempts, err := d.webhooksrv.DeliverDue(ctx)
+		if err != nil {
+			metrics.RecordWebhookDispatcherRunMetric("failure")
+			return total, err
+		}
+
+		for _, a := range attempts {
+			metrics.RecordWebhookDeliveryAttemptMetric(a.Status, a.Duration)
+		}
+
+		total += len(attempts)
+		if len(attempts) == 0 || ctx.Err() != nil {
+			break
+		}
+	}
+
+	metrics.RecordWebhookDispatcherRunMetric("success")
+	if total != 0 {
+		slog.DebugContext(ctx, "dispatcher", "attempted", total)
+	}
+
+	return total, nil
+}
+
+// Cleanup deletes old deliveries from the delivery log.
+func (d *Dispatcher) Cleanup(ctx context.Context) error {
+	deleted, err := d.webhooksrv.DeleteOldDeliveries(ctx)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordWebhookDeliveriesDeletedMetric(deleted)
+	slog.DebugContext(ctx, "dispatcher", "deleted", deleted)
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package reaper implements the background worker that cleans up notes:
+// it purges content of expired notes, deletes read or expired notes after the retention period,
+// deletes attachments that are no longer downloadable, and accesses to notes after their retention period.
+package reaper
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/metrics"
+	"github.com/olexsmir/onasty/internal/service/notesrv"
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+	"github.com/olexsmir/onasty/internal/worker"
+)
+
+// lockKey is the key of postgres advisory lock, that ensures only one replica reaps at a time.
+const lockKey int64 = 0x6f6e61737479_01 // "onasty", 1
+
+type Config struct {
+	// Interval is how often the reaper runs.
+	Interval time.Duration
+
+	// Retention is how long metadata of read or expired notes is kept.
+	Retention time.Duration
+
+	// AuditRetention is how long accesses to notes are kept.
+	AuditRetention time.Duration
+}
+
+type Reaper struct {
+	notesrv notesrv.NoteServicer
+	cfg     Config
+	job     *worker.LockedJob
+}
+
+func New(db *psqlutil.DB, notesrv notesrv.NoteServicer, cfg Config) *Reaper {
+	r := &Reaper{
+		notesrv: notesrv,
+		cfg:     cfg,
+		job:     nil,
+	}
+	r.job = worker.NewLockedJob(db, "reaper", lockKey, cfg.Interval, r.reap, metrics.RecordReaperRunMetric)
+
+	return r
+}
+
+// Run reaps notes every [Config.Interval], blocks until ctx is canceled.
+func (r *Reaper) Run(ctx context.Context) {
+	r.job.Run(ctx)
+}
+
+// Reap does a single clean up.
+// Returns false if it was skipped, because other replica is already reaping.
+func (r *Reaper) Reap(ctx context.Context) (bool, error) {
+	return r.job.RunOnce(ctx)
+}
+
+func (r *Reaper) reap(ctx context.Context) error {
+	purged, err := r.notesrv.PurgeExpired(ctx)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordReaperNotesContentPurgedMetric(purged)
+
+	deleted, err := r.notesrv.DeleteStale(ctx, r.cfg.Retention)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordReaperNotesDeletedMetric(deleted)
+
+	attachments, err := r.notesrv.PurgeAttachments(ctx)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordReaperAttachmentsDeletedMetric(attachments)
+
+	accesses, err := r.notesrv.DeleteOldAccessEvents(ctx, r.cfg.AuditRetention)
+	if err != nil {
+		return err
+	}
+
+	metrics.RecordReaperAccessEventsDeletedMetric(accesses)
+
+	slog.DebugContext(ctx, "reaper",
+		"purged", purged,
+		"deleted", deleted,
+		"attachments", attachments,
+		"accesses", accesses)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Package worker implements what's shared by the background workers.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/olexsmir/onasty/internal/store/psqlutil"
+)
+
+// LockedJob is a job that runs periodically, on a single replica at a time,
+// which is ensured by postgres advisory lock.
+type LockedJob struct {
+	db        *psqlutil.DB
+	name      string
+	lockKey   int64
+	interval  time.Duration
+	job       func(context.Context) error
+	recordRun func(status string)
+}
+
+// NewLockedJob creates a job, that runs job every interval while holding the advisory lock with lockKey.
+// recordRun is called after every run with its status: "success", "failure", or "skipped".
+func NewLockedJob(
+	db *psqlutil.DB,
+	name string,
+	lockKey int64,
+	interval time.Duration,
+	job func(context.Context) error,
+	recordRun func(status string),
+) *LockedJob {
+	return &LockedJob{
+		db:        db,
+		name:      name,
+		lockKey:   lockKey,
+		interval:  interval,
+		job:       job,
+		recordRun: recordRun,
+	}
+}
+
+// Run runs the job every interval, blocks until ctx is canceled.
+func (j *LockedJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(ctx); err != nil {
+			slog.ErrorContext(ctx, j.name, "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs the job a single time.
+// Returns false if it was skipped, because other replica is already running it.
+func (j *LockedJob) RunOnce(ctx context.Context) (bool, error) {
+	ran, err := j.db.WithTryAdvisoryLock(ctx, j.lockKey, j.job)
+	switch {
+	case err != nil:
+		j.recordRun("failure")
+	case !ran:
+		j.recordRun("skipped")
+	default:
+		j.recordRun("success")
+	}
+
+	return ran, err
+}
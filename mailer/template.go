@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"fmt"
+	"html"
+	"strings"
 )
 
 var ErrInvalidTemplate = errors.New("failed to get template")
@@ -22,6 +24,20 @@ func getTemplate(appURL, frontendURL string, templateName string) (TemplateFunc,
 		return passwordResetTemplate(frontendURL), nil
 	case "confirm_email_change":
 		return confirmEmailChangeTemplate(appURL), nil
+	case "note_read":
+		return noteReadTemplate(), nil
+	case "note_expired":
+		return noteExpiredTemplate(), nil
+	case "note_switch_reminder":
+		return noteSwitchReminderTemplate(), nil
+	case "note_released":
+		return noteReleasedTemplate(frontendURL), nil
+	case "send_note_link":
+		return sendNoteLinkTemplate(frontendURL), nil
+	case "note_code":
+		return noteCodeTemplate(), nil
+	case "note_access_request":
+		return noteAccessRequestTemplate(), nil
 	default:
 		return nil, ErrInvalidTemplate
 	}
@@ -77,3 +93,119 @@ This link will expire after 24 hours.
 		}
 	}
 }
+
+func noteReadTemplate() TemplateFunc {
+	return func(opts map[string]string) Template {
+		return Template{
+			Subject: "Onasty: your note has been read",
+			Body: fmt.Sprintf(`Your note <b>%[1]s</b> has been read at %[2]s.
+<br>
+<br>
+You're receiving this email because you asked to be notified about this note.`,
+				opts["slug"], opts["read_at"]),
+		}
+	}
+}
+
+func noteExpiredTemplate() TemplateFunc {
+	return func(opts map[string]string) Template {
+		return Template{
+			Subject: "Onasty: your note has expired unread",
+			Body: fmt.Sprintf(`Your note <b>%[1]s</b> has expired at %[2]s, and nobody has read it.
+<br>
+<br>
+You're receiving this email because you asked to be notified about this note.`,
+				opts["slug"], opts["expired_at"]),
+		}
+	}
+}
+
+func noteSwitchReminderTemplate() TemplateFunc {
+	return func(opts map[string]string) Template {
+		return Template{
+			Subject: "Onasty: check in to keep your note from being released",
+			Body: fmt.Sprintf(`Your note <b>%[1]s</b> will be released to its recipients at %[2]s.
+<br>
+<br>
+If you don't want that to happen yet, check in before then.`,
+				opts["slug"], opts["release_at"]),
+		}
+	}
+}
+
+func noteReleasedTemplate(frontendURL string) TemplateFunc {
+	return func(opts map[string]string) Template {
+		link := fmt.Sprintf("%[1]s/secret/%[2]s", frontendURL, opts["slug"])
+
+		return Template{
+			Subject: "Onasty: a note has been released to you",
+			Body: fmt.Sprintf(`%[1]s has left a note for you, in case they couldn't be reached:
+<a href="%[2]s">%[2]s</a>
+<br>
+<br>
+The note may be burnt once it's read, so keep its content somewhere safe.`,
+				opts["author"], link),
+		}
+	}
+}
+
+func sendNoteLinkTemplate(frontendURL string) TemplateFunc {
+	return func(opts map[string]string) Template {
+		link := fmt.Sprintf("%[1]s/secret/%[2]s", frontendURL, opts["slug"])
+		sender := html.EscapeString(opts["sender"])
+
+		// the message is written by the sender, so it's never trusted to be html
+		var message string
+		if opts["message"] != "" {
+			message = fmt.Sprintf("<br>\n<br>\n<i>%s</i>",
+				strings.ReplaceAll(html.EscapeString(opts["message"]), "\n", "<br>\n"))
+		}
+
+		return Template{
+			Subject: fmt.Sprintf("Onasty: %s has sent you a note", opts["sender"]),
+			Body: fmt.Sprintf(`%[1]s has sent you a note:
+<a href="%[2]s">%[2]s</a>%[3]s
+<br>
+<br>
+The note may be burnt once it's read, so keep its content somewhere safe.`,
+				sender, link, message),
+		}
+	}
+}
+
+func noteCodeTemplate() TemplateFunc {
+	return func(opts map[string]string) Template {
+		return Template{
+			Subject: "Onasty: your code to read the note",
+			Body: fmt.Sprintf(`Your one-time code to read the note "%[1]s":
+<br>
+<br>
+<b>%[2]s</b>
+<br>
+<br>
+The code will expire in a few minutes, and can be used only once.
+If you haven't requested it, someone else may have the link to the note, don't share the code with anyone.`,
+				html.EscapeString(opts["slug"]), html.EscapeString(opts["code"])),
+		}
+	}
+}
+
+func noteAccessRequestTemplate() TemplateFunc {
+	return func(opts map[string]string) Template {
+		// the reason is written by the reader, so it's never trusted to be html
+		reason := strings.ReplaceAll(html.EscapeString(opts["reason"]), "\n", "<br>\n")
+
+		return Template{
+			Subject: "Onasty: someone has requested access to your note",
+			Body: fmt.Sprintf(`Someone has requested access to your note <b>%[1]s</b>, the reason they gave:
+<br>
+<br>
+<i>%[2]s</i>
+<br>
+<br>
+The note is released to them only if you approve the request from your dashboard before %[3]s.
+If you don't know who it is, deny the request.`,
+				html.EscapeString(opts["slug"]), reason, opts["expires_at"]),
+		}
+	}
+}
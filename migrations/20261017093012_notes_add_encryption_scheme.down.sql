@@ -0,0 +1,3 @@
+ALTER TABLE notes
+    DROP COLUMN encryption_scheme,
+    DROP COLUMN encryption_version;
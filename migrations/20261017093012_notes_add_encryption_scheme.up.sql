@@ -0,0 +1,3 @@
+ALTER TABLE notes
+    ADD COLUMN encryption_scheme varchar(32) NOT NULL DEFAULT '',
+    ADD COLUMN encryption_version integer NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE notes
+    ADD COLUMN content_key_id varchar(64) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE notes
+    DROP COLUMN max_views,
+    DROP COLUMN views_left;
@@ -0,0 +1,12 @@
+ALTER TABLE notes
+    ADD COLUMN max_views integer NOT NULL DEFAULT 1,
+    ADD COLUMN views_left integer NOT NULL DEFAULT 1;
+
+UPDATE notes
+SET views_left = 0
+WHERE read_at IS NOT NULL;
+
+UPDATE notes
+SET max_views = 0,
+    views_left = 0
+WHERE keep_before_expiration;
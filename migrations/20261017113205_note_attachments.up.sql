@@ -0,0 +1,13 @@
+-- note_id is set to null when the note is deleted or burnt,
+-- such attachments are detached, and their blobs are deleted by the reaper
+CREATE TABLE note_attachments (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    note_id uuid REFERENCES notes (id) ON DELETE SET NULL,
+    filename varchar(255) NOT NULL,
+    content_type varchar(255) NOT NULL,
+    size bigint NOT NULL,
+    content_key_id varchar(64) NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX note_attachments_note_id_idx ON note_attachments (note_id);
@@ -0,0 +1,2 @@
+ALTER TABLE notes
+    DROP COLUMN notify_author;
@@ -0,0 +1,2 @@
+ALTER TABLE notes
+    ADD COLUMN notify_author boolean NOT NULL DEFAULT FALSE;
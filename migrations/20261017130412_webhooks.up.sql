@@ -0,0 +1,31 @@
+-- secret is encrypted at rest, the same way notes' content is
+CREATE TABLE webhooks (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url varchar(2048) NOT NULL,
+    events varchar(32)[] NOT NULL,
+    secret text NOT NULL,
+    secret_key_id varchar(64) NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id);
+
+-- pending deliveries are picked up by the dispatcher once next_attempt_at has passed
+CREATE TABLE webhook_deliveries (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    webhook_id uuid NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event varchar(32) NOT NULL,
+    payload text NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    response_status integer NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    last_attempt_at timestamptz
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_created_at_idx ON webhook_deliveries (created_at);
@@ -0,0 +1,2 @@
+DROP TABLE notes_anonymous_authors;
+DROP TABLE user_quotas;
@@ -0,0 +1,18 @@
+-- overrides of the registered users' quota, null limits are inherited from the tier
+CREATE TABLE user_quotas (
+    user_id uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    max_content_bytes integer,
+    notes_per_day integer,
+    max_active_notes integer,
+    max_expires_in_seconds bigint,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- notes created without an author are counted toward quota of the ip they came from,
+-- only a keyed hash of the ip is stored
+CREATE TABLE notes_anonymous_authors (
+    note_id uuid PRIMARY KEY REFERENCES notes (id) ON DELETE CASCADE,
+    ip_hash varchar(64) NOT NULL
+);
+
+CREATE INDEX notes_anonymous_authors_ip_hash_idx ON notes_anonymous_authors (ip_hash);
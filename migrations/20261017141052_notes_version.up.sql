@@ -0,0 +1,4 @@
+-- version is bumped whenever the content is edited, or the note is viewed,
+-- so edits based on a stale version are rejected
+ALTER TABLE notes
+    ADD COLUMN version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+ALTER TABLE notes
+    DROP COLUMN burn_on_lockout;
@@ -0,0 +1,3 @@
+-- notes that are locked after too many wrong passwords are burnt instead, if the author asked for it
+ALTER TABLE notes
+    ADD COLUMN burn_on_lockout boolean NOT NULL DEFAULT false;
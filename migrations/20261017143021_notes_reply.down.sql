@@ -0,0 +1,3 @@
+ALTER TABLE notes
+    DROP COLUMN allow_reply,
+    DROP COLUMN reply_slug;
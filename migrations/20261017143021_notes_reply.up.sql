@@ -0,0 +1,4 @@
+-- readers of notes that allow it can reply once, reply_slug is the slug of the reply note
+ALTER TABLE notes
+    ADD COLUMN allow_reply boolean NOT NULL DEFAULT false,
+    ADD COLUMN reply_slug varchar(255);
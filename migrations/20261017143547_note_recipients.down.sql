@@ -0,0 +1,6 @@
+ALTER TABLE notes
+    DROP COLUMN content_id,
+    DROP COLUMN group_id,
+    DROP COLUMN recipient_label;
+
+DROP TABLE note_contents;
@@ -0,0 +1,18 @@
+-- content of a note sent to several recipients is stored once, and every recipient gets
+-- their own note that links to it. The link is removed once the recipient's note is burnt or expires,
+-- and the content is deleted once there's no links to it.
+CREATE TABLE note_contents (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    content text NOT NULL,
+    content_key_id varchar(64) NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- group_id is shared by all recipients of the note, and is kept after the content is deleted
+ALTER TABLE notes
+    ADD COLUMN content_id uuid REFERENCES note_contents (id),
+    ADD COLUMN group_id uuid,
+    ADD COLUMN recipient_label varchar(255) NOT NULL DEFAULT '';
+
+CREATE INDEX notes_content_id_idx ON notes (content_id);
+CREATE INDEX notes_group_id_idx ON notes (group_id);
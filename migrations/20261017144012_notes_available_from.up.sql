@@ -0,0 +1,3 @@
+-- notes cannot be read before available_from, null means they're available right away
+ALTER TABLE notes
+    ADD COLUMN available_from timestamptz;
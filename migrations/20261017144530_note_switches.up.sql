@@ -0,0 +1,12 @@
+-- dead man's switches of notes: the note is released to the recipients once its author
+-- misses a check-in, that is when check_in_interval_seconds has passed since last_check_in_at
+CREATE TABLE note_switches (
+    note_id uuid PRIMARY KEY REFERENCES notes (id) ON DELETE CASCADE,
+    check_in_interval_seconds bigint NOT NULL,
+    recipients varchar(255)[] NOT NULL,
+    last_check_in_at timestamptz NOT NULL DEFAULT now(),
+    reminded_at timestamptz,
+    released_at timestamptz
+);
+
+CREATE INDEX note_switches_pending_idx ON note_switches (last_check_in_at) WHERE released_at IS NULL;
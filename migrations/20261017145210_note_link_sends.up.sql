@@ -0,0 +1,11 @@
+-- links to notes emailed by their authors, the personal message isn't stored
+CREATE TABLE note_link_sends (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    note_id uuid NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    sender_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_email varchar(255) NOT NULL,
+    sent_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX note_link_sends_note_id_idx ON note_link_sends (note_id);
+CREATE INDEX note_link_sends_sender_id_idx ON note_link_sends (sender_id, sent_at);
@@ -0,0 +1,6 @@
+-- notes created without an author are managed with the token returned on creation, only its hash is stored,
+-- it's shared by every link of a note sent to several recipients, and it's removed once the note is claimed
+ALTER TABLE notes
+    ADD COLUMN management_token_hash varchar(64);
+
+CREATE INDEX notes_management_token_hash_idx ON notes (management_token_hash) WHERE management_token_hash IS NOT NULL;
@@ -0,0 +1,13 @@
+-- reads, wrong passwords, and metadata probes of notes, shown to their authors.
+-- ip is stored truncated to its network, so readers cannot be identified by it
+CREATE TABLE note_access_events (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    note_id uuid NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    kind varchar(16) NOT NULL,
+    ip varchar(64) NOT NULL,
+    user_agent varchar(512) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX note_access_events_note_id_idx ON note_access_events (note_id, created_at);
+CREATE INDEX note_access_events_created_at_idx ON note_access_events (created_at);
@@ -0,0 +1,4 @@
+-- content of notes bound to an email is released only to readers who give the one-time code sent to it,
+-- it's empty for notes that aren't bound
+ALTER TABLE notes
+    ADD COLUMN bound_email varchar(255) NOT NULL DEFAULT '';
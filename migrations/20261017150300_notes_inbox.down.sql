@@ -0,0 +1,3 @@
+ALTER TABLE notes
+    DROP COLUMN inbox_delivered_at,
+    DROP COLUMN inbox_user_id;
@@ -0,0 +1,7 @@
+-- notes sent to inbox of a registered user can be read only by the user,
+-- inbox_delivered_at is when the note has been listed in the inbox for the first time
+ALTER TABLE notes
+    ADD COLUMN inbox_user_id uuid REFERENCES users (id) ON DELETE CASCADE,
+    ADD COLUMN inbox_delivered_at timestamptz;
+
+CREATE INDEX notes_inbox_user_id_idx ON notes (inbox_user_id) WHERE inbox_user_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE note_access_requests;
+
+ALTER TABLE notes
+    DROP COLUMN requires_approval;
@@ -0,0 +1,21 @@
+-- notes that require approval are released only to readers whose access request the author has approved.
+-- only hash of the reader's token is stored, and ip is stored truncated, the same way as in note_access_events
+ALTER TABLE notes
+    ADD COLUMN requires_approval boolean NOT NULL DEFAULT FALSE;
+
+CREATE TABLE note_access_requests (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    note_id uuid NOT NULL REFERENCES notes (id) ON DELETE CASCADE,
+    token_hash varchar(64) NOT NULL UNIQUE,
+    reason varchar(500) NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    ip varchar(64) NOT NULL,
+    user_agent varchar(512) NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    expires_at timestamptz NOT NULL,
+    decided_at timestamptz,
+    used_at timestamptz
+);
+
+CREATE INDEX note_access_requests_note_id_idx ON note_access_requests (note_id, created_at);
+CREATE INDEX note_access_requests_expires_at_idx ON note_access_requests (expires_at);
@@ -0,0 +1,3 @@
+-- views of notes that are kept before expiration aren't counted,
+-- so viewed_at is when such note has been read for the first time
+ALTER TABLE notes ADD COLUMN viewed_at timestamptz;
@@ -0,0 +1 @@
+ALTER TABLE note_switches DROP COLUMN released_to;
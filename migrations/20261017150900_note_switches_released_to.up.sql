@@ -0,0 +1,3 @@
+-- recipients the released note has already been sent to,
+-- so if sending fails for some of them, only those are retried
+ALTER TABLE note_switches ADD COLUMN released_to varchar(255)[] NOT NULL DEFAULT '{}';
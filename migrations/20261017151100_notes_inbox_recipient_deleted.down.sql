@@ -0,0 +1,6 @@
+DELETE FROM notes WHERE sent_to_inbox AND inbox_user_id IS NULL;
+
+ALTER TABLE notes
+    DROP CONSTRAINT notes_inbox_user_id_fkey,
+    ADD CONSTRAINT notes_inbox_user_id_fkey FOREIGN KEY (inbox_user_id) REFERENCES users (id) ON DELETE CASCADE,
+    DROP COLUMN sent_to_inbox;
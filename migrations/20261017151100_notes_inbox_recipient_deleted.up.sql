@@ -0,0 +1,8 @@
+-- notes aren't deleted along with account of the user they're sent to, the sender still sees them,
+-- sent_to_inbox tells such notes apart from ones that can be read by anyone with the link
+ALTER TABLE notes
+    ADD COLUMN sent_to_inbox boolean NOT NULL DEFAULT FALSE,
+    DROP CONSTRAINT notes_inbox_user_id_fkey,
+    ADD CONSTRAINT notes_inbox_user_id_fkey FOREIGN KEY (inbox_user_id) REFERENCES users (id) ON DELETE SET NULL;
+
+UPDATE notes SET sent_to_inbox = TRUE WHERE inbox_user_id IS NOT NULL;
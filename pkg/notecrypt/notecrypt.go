@@ -0,0 +1,147 @@
+// Package notecrypt is the reference implementation of client-side note encryption.
+//
+// The content is encrypted with AES-256-GCM before it's sent to the server,
+// and the key is kept in the URL fragment, which is never sent to the server by browsers,
+// so the server only ever sees the ciphertext.
+package notecrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/url"
+)
+
+const (
+	// Scheme is the encryption scheme identifier, that is sent to the server alongside the ciphertext.
+	Scheme = "aes-256-gcm"
+
+	// Version is the version of the [Scheme] implemented by this package.
+	Version = 1
+
+	// KeySize is the size of the key in bytes.
+	KeySize = 32
+)
+
+var (
+	ErrInvalidKey        = errors.New("notecrypt: invalid key")
+	ErrInvalidCiphertext = errors.New("notecrypt: invalid ciphertext")
+	ErrKeyNotInLink      = errors.New("notecrypt: link has no key")
+)
+
+// additionalData binds the ciphertext to the scheme and its version.
+var additionalData = []byte("onasty:" + Scheme + ":v1")
+
+var encoding = base64.RawURLEncoding
+
+// GenerateKey generates a new random key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// EncodeKey encodes the key, so it can be used in the URL fragment.
+func EncodeKey(key []byte) string {
+	return encoding.EncodeToString(key)
+}
+
+// DecodeKey decodes the key encoded by [EncodeKey].
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := encoding.DecodeString(encoded)
+	if err != nil || len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// Encrypt encrypts the plaintext with the key.
+// Returns base64(url) encoded nonce followed by the sealed content.
+func Encrypt(key, plaintext []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+// Decrypt decrypts the ciphertext produced by [Encrypt].
+func Decrypt(key []byte, ciphertext string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := encoding.DecodeString(ciphertext)
+	if err != nil || len(raw) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, sealed := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+
+	return plaintext, nil
+}
+
+// IsCiphertext reports whether s looks like output of [Encrypt].
+// It doesn't(and can't) check whether it's decryptable.
+func IsCiphertext(s string) bool {
+	raw, err := encoding.DecodeString(s)
+	if err != nil {
+		return false
+	}
+
+	// 12 bytes of nonce, and 16 bytes of authentication tag
+	return len(raw) >= 12+16
+}
+
+// LinkWithKey returns the note link with the key set as its fragment.
+func LinkWithKey(link string, key []byte) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+
+	u.Fragment = EncodeKey(key)
+	return u.String(), nil
+}
+
+// KeyFromLink extracts the key from the link's fragment.
+func KeyFromLink(link string) ([]byte, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Fragment == "" {
+		return nil, ErrKeyNotInLink
+	}
+
+	return DecodeKey(u.Fragment)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
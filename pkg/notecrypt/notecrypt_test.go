@@ -0,0 +1,87 @@
+package notecrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	plaintext := []byte("the secret content")
+
+	t.Run("round trip", func(t *testing.T) {
+		ciphertext, err := Encrypt(key, plaintext)
+		require.NoError(t, err)
+		require.NotContains(t, ciphertext, string(plaintext))
+		require.True(t, IsCiphertext(ciphertext))
+
+		decrypted, err := Decrypt(key, ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("same plaintext gives different ciphertexts", func(t *testing.T) {
+		c1, err := Encrypt(key, plaintext)
+		require.NoError(t, err)
+
+		c2, err := Encrypt(key, plaintext)
+		require.NoError(t, err)
+
+		require.NotEqual(t, c1, c2)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		ciphertext, err := Encrypt(key, plaintext)
+		require.NoError(t, err)
+
+		otherKey, err := GenerateKey()
+		require.NoError(t, err)
+
+		_, err = Decrypt(otherKey, ciphertext)
+		require.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		ciphertext, err := Encrypt(key, plaintext)
+		require.NoError(t, err)
+
+		tampered := []byte(ciphertext)
+		tampered[len(tampered)/2] ^= 'a' ^ 'b'
+
+		_, err = Decrypt(key, string(tampered))
+		require.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := Encrypt(key[:16], plaintext)
+		require.ErrorIs(t, err, ErrInvalidKey)
+	})
+}
+
+func TestIsCiphertext(t *testing.T) {
+	require.False(t, IsCiphertext("plain text content"))
+	require.False(t, IsCiphertext(""))
+	require.False(t, IsCiphertext("c2hvcnQ"))
+}
+
+func TestLinkWithKey(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	link, err := LinkWithKey("https://onasty.local/n/the-slug", key)
+	require.NoError(t, err)
+	require.Contains(t, link, "#"+EncodeKey(key))
+
+	got, err := KeyFromLink(link)
+	require.NoError(t, err)
+	require.Equal(t, key, got)
+
+	_, err = KeyFromLink("https://onasty.local/n/the-slug")
+	require.ErrorIs(t, err, ErrKeyNotInLink)
+
+	_, err = KeyFromLink("https://onasty.local/n/the-slug#not-a-key")
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -1,13 +1,14 @@
 module Api.Note exposing (create, delete, get, getAll, getMetadata)
 
 import Api
-import Data.Note as Note exposing (CreateResponse, Metadata, Note)
+import Data.Note as Note exposing (CreateResponse, Metadata, Note, Page)
 import Effect exposing (Effect)
 import Http
 import Iso8601
 import Json.Decode as D
 import Json.Encode as E
 import Time exposing (Posix)
+import Url.Builder
 
 
 create :
@@ -105,12 +106,18 @@ getMetadata options =
         }
 
 
-getAll : { onResponse : Result Api.Error (List Note) -> msg } -> Effect msg
+getAll : { onResponse : Result Api.Error Page -> msg, cursor : Maybe String } -> Effect msg
 getAll opts =
     Effect.sendApiRequest
-        { endpoint = "/api/v1/note"
+        { endpoint =
+            case opts.cursor of
+                Just cursor ->
+                    Url.Builder.absolute [ "api", "v1", "note" ] [ Url.Builder.string "cursor" cursor ]
+
+                Nothing ->
+                    "/api/v1/note"
         , method = "GET"
         , body = Http.emptyBody
         , onResponse = opts.onResponse
-        , decoder = D.list Note.decode
+        , decoder = Note.decodePage
         }
@@ -1,4 +1,4 @@
-module Data.Note exposing (CreateResponse, Metadata, Note, decode, decodeCreateResponse, decodeMetadata)
+module Data.Note exposing (CreateResponse, Metadata, Note, Page, decode, decodeCreateResponse, decodeMetadata, decodePage)
 
 import Iso8601
 import Json.Decode as D exposing (Decoder)
@@ -48,3 +48,18 @@ decodeMetadata =
     D.map2 Metadata
         (D.field "created_at" Iso8601.decoder)
         (D.field "has_password" D.bool)
+
+
+type alias Page =
+    { notes : List Note
+    , total : Int
+    , nextCursor : Maybe String
+    }
+
+
+decodePage : Decoder Page
+decodePage =
+    D.map3 Page
+        (D.field "notes" (D.list decode))
+        (D.field "total" D.int)
+        (D.maybe (D.field "next_cursor" D.string))
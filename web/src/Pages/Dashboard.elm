@@ -6,7 +6,7 @@ import Auth
 import Components.Box
 import Components.Form
 import Components.Utils
-import Data.Note exposing (Note)
+import Data.Note exposing (Note, Page)
 import Effect exposing (Effect)
 import Html as H exposing (Html)
 import Html.Attributes as A
@@ -46,7 +46,7 @@ init () =
       , noteToDeleteSlug = Nothing
       , apiError = Nothing
       }
-    , Api.Note.getAll { onResponse = ApiNotesResponded }
+    , Api.Note.getAll { onResponse = ApiNotesResponded, cursor = Nothing }
     )
 
 
@@ -59,7 +59,7 @@ type Msg
     | UserClickedViewNote String
     | UserClickedDeleteNote String
     | UserConfirmedDeleteion Bool
-    | ApiNotesResponded (Result Api.Error (List Note))
+    | ApiNotesResponded (Result Api.Error Page)
     | ApiNoteDeleted (Result Api.Error ())
 
 
@@ -96,8 +96,24 @@ update msg model =
                 _ ->
                     ( { model | noteToDeleteSlug = Nothing }, Effect.none )
 
-        ApiNotesResponded (Ok notes) ->
-            ( { model | notes = Api.Success notes }, Effect.none )
+        ApiNotesResponded (Ok notesPage) ->
+            let
+                notes =
+                    case model.notes of
+                        Success loaded ->
+                            loaded ++ notesPage.notes
+
+                        _ ->
+                            notesPage.notes
+            in
+            ( { model | notes = Api.Success notes }
+            , case notesPage.nextCursor of
+                Just cursor ->
+                    Api.Note.getAll { onResponse = ApiNotesResponded, cursor = Just cursor }
+
+                Nothing ->
+                    Effect.none
+            )
 
         ApiNotesResponded (Err error) ->
             ( { model | notes = Api.Failure error }, Effect.none )